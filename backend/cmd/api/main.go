@@ -1,9 +1,30 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 
 	"alchemorsel/backend/internal/config"
+	"alchemorsel/backend/internal/domain/collection"
+	"alchemorsel/backend/internal/domain/comment"
+	"alchemorsel/backend/internal/domain/gallery"
+	"alchemorsel/backend/internal/domain/importjob"
+	"alchemorsel/backend/internal/domain/mealplan"
+	"alchemorsel/backend/internal/domain/nutrition"
+	"alchemorsel/backend/internal/domain/pantry"
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/recommendation"
+	"alchemorsel/backend/internal/domain/review"
+	"alchemorsel/backend/internal/domain/shopping"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+	"alchemorsel/backend/internal/infrastructure/database/postgres/repository"
+	"alchemorsel/backend/internal/infrastructure/external/deepseek"
+	"alchemorsel/backend/internal/infrastructure/storage/local"
 	httpserver "alchemorsel/backend/internal/interfaces/http"
 	"alchemorsel/backend/internal/pkg/logger"
 )
@@ -11,11 +32,93 @@ import (
 func main() {
 	cfg := config.Load()
 
+	db, err := postgres.Connect(cfg.Database, cfg.Database.MigrationsPath)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer db.Close()
+
+	recipeRepo := repository.NewRecipeRepository(db)
+	userRepo := repository.NewUserRepository(db)
+
+	recipeOpts := []recipe.Option{
+		recipe.WithNutrition(nutrition.NewService(repository.NewFoodRepository(db))),
+	}
+	if ds := cfg.External.DeepSeek; ds.APIKey != "" {
+		client := deepseek.NewClient(ds.APIKey, ds.APIURL, &http.Client{Timeout: 60 * time.Second})
+		recipeOpts = append(recipeOpts, recipe.WithGenerator(client), recipe.WithEmbedder(client))
+	}
+
+	moderators := make([]uuid.UUID, 0, len(cfg.Moderation.ModeratorIDs))
+	for _, s := range cfg.Moderation.ModeratorIDs {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			logger.Fatal(fmt.Errorf("invalid moderator id %q: %w", s, err))
+		}
+		moderators = append(moderators, id)
+	}
+
+	recipeService := recipe.NewService(recipeRepo, userRepo, recipeOpts...)
+	importService := importjob.NewService(repository.NewImportJobRepository(db), recipeService)
+	if err := importService.FailInterrupted(context.Background()); err != nil {
+		logger.Fatal(err)
+	}
+	galleryService := gallery.NewService(repository.NewGalleryRepository(db),
+		local.New(cfg.Storage.Dir, cfg.Storage.BaseURL), recipeService)
+	go purgeTrashedImages(galleryService)
+	mealPlanService := mealplan.NewService(repository.NewMealPlanRepository(db), recipeService, userRepo)
+	recommendationService := recommendation.NewService(repository.NewRecommendationRepository(db), recipeService, userRepo)
+	go refreshRecommendations(recommendationService)
+
+	services := httpserver.Services{
+		Recipe:          recipeService,
+		Review:          review.NewService(repository.NewReviewRepository(db), recipeService),
+		Comment:         comment.NewService(repository.NewCommentRepository(db), recipeService, comment.WithModerators(moderators...)),
+		Collection:      collection.NewService(repository.NewCollectionRepository(db), recipeService, userRepo),
+		Import:          importService,
+		Gallery:         galleryService,
+		MealPlans:       mealPlanService,
+		Shopping:        shopping.NewService(repository.NewShoppingRepository(db), recipeService, mealPlanService, userRepo),
+		Pantry:          pantry.NewService(repository.NewPantryRepository(db), recipeService),
+		Recommendations: recommendationService,
+	}
+
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	logger.Infof("Starting server on %s", addr)
 
-	router := httpserver.SetupRouter()
+	router := httpserver.SetupRouter(services)
+	if strings.HasPrefix(cfg.Storage.BaseURL, "/") {
+		router.Static(cfg.Storage.BaseURL, cfg.Storage.Dir)
+	}
 	if err := router.Run(addr); err != nil {
 		logger.Fatal(err)
 	}
 }
+
+// purgeTrashedImages deletes the images of recipes left in the trash past
+// their retention, once a day.
+func purgeTrashedImages(svc gallery.Service) {
+	for {
+		n, err := svc.PurgeTrashed(context.Background())
+		if err != nil {
+			logger.Errorf("failed to purge images of trashed recipes: %v", err)
+		} else if n > 0 {
+			logger.Infof("Purged %d images of trashed recipes", n)
+		}
+		time.Sleep(24 * time.Hour)
+	}
+}
+
+// refreshRecommendations keeps the cached recommendations up to date with
+// what users favorite, rate and generate, a batch of users a minute.
+func refreshRecommendations(svc recommendation.Service) {
+	for {
+		n, err := svc.RefreshStale(context.Background())
+		if err != nil {
+			logger.Errorf("failed to refresh recommendations: %v", err)
+		} else if n > 0 {
+			logger.Infof("Refreshed recommendations of %d users", n)
+		}
+		time.Sleep(time.Minute)
+	}
+}
@@ -0,0 +1,39 @@
+// Command import-foods loads a USDA FoodData Central CSV download into the
+// nutrient database used to compute recipe nutrition.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"alchemorsel/backend/internal/config"
+	"alchemorsel/backend/internal/domain/nutrition"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+	"alchemorsel/backend/internal/infrastructure/database/postgres/repository"
+	"alchemorsel/backend/internal/pkg/logger"
+)
+
+func main() {
+	dir := flag.String("dir", "", "directory holding food.csv, food_nutrient.csv and optionally food_portion.csv")
+	flag.Parse()
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "usage: import-foods -dir <FoodData Central CSV directory>")
+		os.Exit(2)
+	}
+
+	cfg := config.Load()
+	db, err := postgres.Connect(cfg.Database, cfg.Database.MigrationsPath)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer db.Close()
+
+	svc := nutrition.NewService(repository.NewFoodRepository(db))
+	n, err := svc.Import(context.Background(), os.DirFS(*dir))
+	if err != nil {
+		logger.Fatal(err)
+	}
+	logger.Infof("Imported %d foods", n)
+}
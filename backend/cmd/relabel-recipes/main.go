@@ -0,0 +1,30 @@
+// Command relabel-recipes brings the allergens and dietary categories stored
+// on existing recipes up to date with the current normalization, detection
+// and diet rules. Run it after upgrading to a release that changes them.
+package main
+
+import (
+	"context"
+
+	"alchemorsel/backend/internal/config"
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+	"alchemorsel/backend/internal/infrastructure/database/postgres/repository"
+	"alchemorsel/backend/internal/pkg/logger"
+)
+
+func main() {
+	cfg := config.Load()
+	db, err := postgres.Connect(cfg.Database, cfg.Database.MigrationsPath)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer db.Close()
+
+	svc := recipe.NewService(repository.NewRecipeRepository(db), repository.NewUserRepository(db))
+	n, err := svc.Relabel(context.Background())
+	if err != nil {
+		logger.Fatal(err)
+	}
+	logger.Infof("Relabeled %d recipes", n)
+}
@@ -5,13 +5,17 @@ import (
 	"os"
 
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config holds all application configuration loaded from environment variables.
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	External   ExternalConfig
+	Moderation ModerationConfig
+	Storage    StorageConfig
 }
 
 type ServerConfig struct {
@@ -30,6 +34,31 @@ type DatabaseConfig struct {
 	SSLMode      string
 	MaxOpenConns int
 	MaxIdleConns int
+	// MigrationsPath is the directory holding SQL migration files.
+	MigrationsPath string
+}
+
+type ExternalConfig struct {
+	DeepSeek DeepSeekConfig
+}
+
+// DeepSeekConfig configures the LLM used for recipe generation and embeddings.
+// Both features are disabled when APIKey is empty.
+type DeepSeekConfig struct {
+	APIKey string
+	APIURL string
+}
+
+// ModerationConfig lists the users allowed to work the moderation queue.
+type ModerationConfig struct {
+	ModeratorIDs []string
+}
+
+// StorageConfig locates uploaded files on disk and the URL they are served
+// at. A BaseURL starting with "/" is served by the API server itself.
+type StorageConfig struct {
+	Dir     string
+	BaseURL string
 }
 
 // Load reads configuration from environment variables with sane defaults.
@@ -42,14 +71,28 @@ func Load() Config {
 			WriteTimeout: 10 * time.Second,
 		},
 		Database: DatabaseConfig{
-			Host:         getEnv("DB_HOST", "localhost"),
-			Port:         getEnvInt("DB_PORT", 5432),
-			User:         getEnv("DB_USER", "postgres"),
-			Password:     getEnv("DB_PASSWORD", ""),
-			Name:         getEnv("DB_NAME", "alchemorsel"),
-			SSLMode:      getEnv("DB_SSLMODE", "disable"),
-			MaxOpenConns: getEnvInt("DB_MAX_OPEN_CONNS", 10),
-			MaxIdleConns: getEnvInt("DB_MAX_IDLE_CONNS", 5),
+			Host:           getEnv("DB_HOST", "localhost"),
+			Port:           getEnvInt("DB_PORT", 5432),
+			User:           getEnv("DB_USER", "postgres"),
+			Password:       getEnv("DB_PASSWORD", ""),
+			Name:           getEnv("DB_NAME", "alchemorsel"),
+			SSLMode:        getEnv("DB_SSLMODE", "disable"),
+			MaxOpenConns:   getEnvInt("DB_MAX_OPEN_CONNS", 10),
+			MaxIdleConns:   getEnvInt("DB_MAX_IDLE_CONNS", 5),
+			MigrationsPath: getEnv("DB_MIGRATIONS_PATH", "internal/infrastructure/database/postgres/migrations"),
+		},
+		External: ExternalConfig{
+			DeepSeek: DeepSeekConfig{
+				APIKey: getEnv("DEEPSEEK_API_KEY", ""),
+				APIURL: getEnv("DEEPSEEK_API_URL", "https://api.deepseek.com"),
+			},
+		},
+		Moderation: ModerationConfig{
+			ModeratorIDs: getEnvList("MODERATOR_IDS"),
+		},
+		Storage: StorageConfig{
+			Dir:     getEnv("MEDIA_DIR", "media"),
+			BaseURL: getEnv("MEDIA_BASE_URL", "/media"),
 		},
 	}
 
@@ -72,3 +115,14 @@ func getEnvInt(key string, fallback int) int {
 	log.Printf("%s not set, using default %d", key, fallback)
 	return fallback
 }
+
+// getEnvList reads a comma separated list, ignoring empty items.
+func getEnvList(key string) []string {
+	var out []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
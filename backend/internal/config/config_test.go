@@ -14,6 +14,9 @@ func TestLoadDefaults(t *testing.T) {
 	os.Unsetenv("DB_PASSWORD")
 	os.Unsetenv("DB_NAME")
 	os.Unsetenv("DB_SSLMODE")
+	os.Unsetenv("DB_MIGRATIONS_PATH")
+	os.Unsetenv("MEDIA_DIR")
+	os.Unsetenv("MEDIA_BASE_URL")
 
 	cfg := Load()
 
@@ -41,6 +44,12 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.Database.SSLMode != "disable" {
 		t.Errorf("expected default sslmode disable, got %s", cfg.Database.SSLMode)
 	}
+	if cfg.Database.MigrationsPath != "internal/infrastructure/database/postgres/migrations" {
+		t.Errorf("unexpected default migrations path %s", cfg.Database.MigrationsPath)
+	}
+	if cfg.Storage.Dir != "media" || cfg.Storage.BaseURL != "/media" {
+		t.Errorf("unexpected default storage %+v", cfg.Storage)
+	}
 }
 
 func TestLoadEnvOverrides(t *testing.T) {
@@ -91,3 +100,14 @@ func TestLoadEnvOverrides(t *testing.T) {
 		t.Errorf("expected sslmode require, got %s", cfg.Database.SSLMode)
 	}
 }
+
+func TestLoadModeratorIDs(t *testing.T) {
+	os.Setenv("MODERATOR_IDS", " a , ,b")
+	defer os.Unsetenv("MODERATOR_IDS")
+
+	cfg := Load()
+
+	if len(cfg.Moderation.ModeratorIDs) != 2 || cfg.Moderation.ModeratorIDs[0] != "a" || cfg.Moderation.ModeratorIDs[1] != "b" {
+		t.Errorf("unexpected moderator ids %q", cfg.Moderation.ModeratorIDs)
+	}
+}
@@ -0,0 +1,71 @@
+package collection
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+// Collaborator statuses.
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+)
+
+// Collection is a named, ordered list of recipes curated by its owner and any
+// collaborators who accepted an invitation to edit it.
+type Collection struct {
+	ID            uuid.UUID `json:"id"`
+	OwnerID       uuid.UUID `json:"owner_id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	CoverImageURL *string   `json:"cover_image_url"`
+	IsPublic      bool      `json:"is_public"`
+	// RecipeCount counts the recipes in the collection that have not been
+	// deleted.
+	RecipeCount   int             `json:"recipe_count"`
+	Collaborators []*Collaborator `json:"collaborators,omitempty"`
+	// Recipes holds the recipes visible to the viewer in collection order.
+	// It is only populated when a single collection is fetched.
+	Recipes   []*recipe.Recipe `json:"recipes,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// Collaborator is a user invited to edit a collection.
+type Collaborator struct {
+	UserID     uuid.UUID  `json:"user_id"`
+	Username   string     `json:"username"`
+	InvitedBy  uuid.UUID  `json:"invited_by"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+}
+
+// collaborator returns the user's collaborator entry, if any.
+func (c *Collection) collaborator(userID uuid.UUID) *Collaborator {
+	for _, cb := range c.Collaborators {
+		if cb.UserID == userID {
+			return cb
+		}
+	}
+	return nil
+}
+
+// canEdit reports whether the user may change the collection's contents and
+// details: its owner and accepted collaborators.
+func (c *Collection) canEdit(userID uuid.UUID) bool {
+	if c.OwnerID == userID {
+		return true
+	}
+	cb := c.collaborator(userID)
+	return cb != nil && cb.Status == StatusAccepted
+}
+
+// canView reports whether the user may see the collection. Pending invitees
+// may view it so they can decide whether to accept.
+func (c *Collection) canView(userID uuid.UUID) bool {
+	return c.IsPublic || c.OwnerID == userID || c.collaborator(userID) != nil
+}
@@ -0,0 +1,49 @@
+package collection
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+// Repository defines persistence operations for collections.
+type Repository interface {
+	Create(ctx context.Context, c *Collection) error
+	// GetByID returns the collection with its collaborators.
+	GetByID(ctx context.Context, id uuid.UUID) (*Collection, error)
+	Update(ctx context.Context, c *Collection) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListForMember returns the collections the user owns or collaborates
+	// on, most recently updated first.
+	ListForMember(ctx context.Context, userID uuid.UUID) ([]*Collection, error)
+	// ListPublic returns the owner's public collections, most recently
+	// updated first.
+	ListPublic(ctx context.Context, ownerID uuid.UUID) ([]*Collection, error)
+	// ListInvitations returns the collections the user has a pending
+	// invitation to.
+	ListInvitations(ctx context.Context, userID uuid.UUID) ([]*Collection, error)
+
+	// ListRecipes returns the collection's recipes that the viewer may see,
+	// in collection order.
+	ListRecipes(ctx context.Context, collectionID uuid.UUID, viewerID *uuid.UUID) ([]*recipe.Recipe, error)
+	// AddRecipe inserts the recipe at position, shifting later recipes down.
+	// A negative position or one past the end appends the recipe. It returns
+	// ErrRecipeAlreadyAdded if the recipe is already in the collection.
+	AddRecipe(ctx context.Context, collectionID, recipeID, addedBy uuid.UUID, position int) error
+	// RemoveRecipe removes the recipe and closes the gap it leaves.
+	RemoveRecipe(ctx context.Context, collectionID, recipeID uuid.UUID) error
+	// ReorderRecipes moves recipeIDs to the front of the collection in the
+	// given order. Recipes not listed, such as those hidden from the caller,
+	// keep their relative order after them. It returns ErrOrderMismatch if a
+	// listed recipe is not in the collection.
+	ReorderRecipes(ctx context.Context, collectionID uuid.UUID, recipeIDs []uuid.UUID) error
+
+	// AddCollaborator records a pending invitation. It returns
+	// ErrAlreadyCollaborator if the user was already invited.
+	AddCollaborator(ctx context.Context, collectionID uuid.UUID, cb *Collaborator) error
+	AcceptInvitation(ctx context.Context, collectionID, userID uuid.UUID, at time.Time) error
+	RemoveCollaborator(ctx context.Context, collectionID, userID uuid.UUID) error
+}
@@ -0,0 +1,328 @@
+package collection
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/user"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// Limits on collection details, counted in characters after sanitization.
+const (
+	MaxNameLength        = 100
+	MaxDescriptionLength = 1000
+)
+
+var (
+	// ErrCollectionNotFound is returned when a collection does not exist or
+	// is hidden from the user.
+	ErrCollectionNotFound = apperrors.New("collection_not_found", "collection not found", 404)
+	// ErrCollaboratorNotFound is returned when the user is not invited to
+	// the collection.
+	ErrCollaboratorNotFound = apperrors.New("collaborator_not_found", "collaborator not found", 404)
+	// ErrRecipeAlreadyAdded is returned when a recipe is already in the
+	// collection.
+	ErrRecipeAlreadyAdded = apperrors.New("recipe_already_added", "recipe is already in the collection", 409)
+	// ErrRecipeNotInCollection is returned when removing a recipe the
+	// collection does not hold.
+	ErrRecipeNotInCollection = apperrors.New("recipe_not_in_collection", "recipe is not in the collection", 404)
+	// ErrAlreadyCollaborator is returned when inviting a user twice.
+	ErrAlreadyCollaborator = apperrors.New("already_collaborator", "user is already invited to the collection", 409)
+	// ErrInviteOwner is returned when owners invite themselves.
+	ErrInviteOwner = apperrors.New("invite_owner", "you cannot invite the collection owner", 422)
+	// ErrOrderMismatch is returned when a new order repeats a recipe or lists
+	// one that is not in the collection.
+	ErrOrderMismatch = validator.InvalidField("recipe_ids", "recipe_ids must list recipes in the collection at most once")
+)
+
+// Service defines business logic for collections.
+type Service interface {
+	Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*Collection, error)
+	// Get returns the collection with the recipes the viewer may see.
+	Get(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) (*Collection, error)
+	// Export packs the recipes of a collection that the viewer may see into
+	// a zip archive of files in one of the recipe export formats.
+	Export(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, format string) (*recipe.Document, error)
+	// List returns the user's own and shared collections when ownerID is
+	// nil, or the public collections of ownerID otherwise.
+	List(ctx context.Context, userID uuid.UUID, ownerID *uuid.UUID) ([]*Collection, error)
+	Update(ctx context.Context, userID, id uuid.UUID, req UpdateRequest) (*Collection, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+
+	// AddRecipe adds a recipe at the given zero-based position, or at the
+	// end when position is nil.
+	AddRecipe(ctx context.Context, userID, id, recipeID uuid.UUID, position *int) error
+	RemoveRecipe(ctx context.Context, userID, id, recipeID uuid.UUID) error
+	ReorderRecipes(ctx context.Context, userID, id uuid.UUID, recipeIDs []uuid.UUID) error
+
+	// Invite asks a user, by username, to collaborate on a collection.
+	Invite(ctx context.Context, userID, id uuid.UUID, username string) (*Collaborator, error)
+	ListInvitations(ctx context.Context, userID uuid.UUID) ([]*Collection, error)
+	AcceptInvitation(ctx context.Context, userID, id uuid.UUID) error
+	// RemoveCollaborator revokes an invitation or collaboration. Owners may
+	// remove anyone; other users may only remove themselves.
+	RemoveCollaborator(ctx context.Context, userID, id, collaboratorID uuid.UUID) error
+}
+
+type CreateRequest struct {
+	Name          string
+	Description   string
+	CoverImageURL *string
+	IsPublic      bool
+}
+
+// UpdateRequest changes the set fields of a collection. Only the owner may
+// change its visibility.
+type UpdateRequest struct {
+	Name          *string
+	Description   *string
+	CoverImageURL *string
+	IsPublic      *bool
+}
+
+type service struct {
+	repo    Repository
+	recipes recipe.Service
+	users   user.Repository
+}
+
+// NewService creates a collection service. Recipe visibility is checked
+// through the recipe service.
+func NewService(repo Repository, recipes recipe.Service, users user.Repository) Service {
+	return &service{repo: repo, recipes: recipes, users: users}
+}
+
+func (s *service) Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*Collection, error) {
+	name, err := validator.Text("name", req.Name, 1, MaxNameLength)
+	if err != nil {
+		return nil, err
+	}
+	description, err := validator.Text("description", req.Description, 0, MaxDescriptionLength)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	c := &Collection{
+		ID:            uuid.New(),
+		OwnerID:       userID,
+		Name:          name,
+		Description:   description,
+		CoverImageURL: coverImage(req.CoverImageURL),
+		IsPublic:      req.IsPublic,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.repo.Create(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *service) Get(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) (*Collection, error) {
+	c, err := s.getVisible(ctx, viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.Recipes, err = s.repo.ListRecipes(ctx, id, viewerID); err != nil {
+		return nil, err
+	}
+	if err := s.recipes.FlagAllergens(ctx, viewerID, c.Recipes); err != nil {
+		return nil, err
+	}
+	c.RecipeCount = len(c.Recipes)
+	return c, nil
+}
+
+func (s *service) Export(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, format string) (*recipe.Document, error) {
+	c, err := s.Get(ctx, viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+	return recipe.ExportArchive(c.Name, c.Recipes, format)
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID, ownerID *uuid.UUID) ([]*Collection, error) {
+	if ownerID == nil {
+		return s.repo.ListForMember(ctx, userID)
+	}
+	return s.repo.ListPublic(ctx, *ownerID)
+}
+
+func (s *service) Update(ctx context.Context, userID, id uuid.UUID, req UpdateRequest) (*Collection, error) {
+	c, err := s.getEditable(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.IsPublic != nil && *req.IsPublic != c.IsPublic && c.OwnerID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+	if req.Name != nil {
+		if c.Name, err = validator.Text("name", *req.Name, 1, MaxNameLength); err != nil {
+			return nil, err
+		}
+	}
+	if req.Description != nil {
+		if c.Description, err = validator.Text("description", *req.Description, 0, MaxDescriptionLength); err != nil {
+			return nil, err
+		}
+	}
+	if req.CoverImageURL != nil {
+		c.CoverImageURL = coverImage(req.CoverImageURL)
+	}
+	if req.IsPublic != nil {
+		c.IsPublic = *req.IsPublic
+	}
+	c.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) AddRecipe(ctx context.Context, userID, id, recipeID uuid.UUID, position *int) error {
+	if _, err := s.getEditable(ctx, userID, id); err != nil {
+		return err
+	}
+	pos := -1
+	if position != nil {
+		if *position < 0 {
+			return validator.InvalidField("position", "position must not be negative")
+		}
+		pos = *position
+	}
+	if _, err := s.recipes.Get(ctx, &userID, recipeID); err != nil {
+		return err
+	}
+	return s.repo.AddRecipe(ctx, id, recipeID, userID, pos)
+}
+
+func (s *service) RemoveRecipe(ctx context.Context, userID, id, recipeID uuid.UUID) error {
+	if _, err := s.getEditable(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.RemoveRecipe(ctx, id, recipeID)
+}
+
+func (s *service) ReorderRecipes(ctx context.Context, userID, id uuid.UUID, recipeIDs []uuid.UUID) error {
+	if _, err := s.getEditable(ctx, userID, id); err != nil {
+		return err
+	}
+	seen := make(map[uuid.UUID]bool, len(recipeIDs))
+	for _, rid := range recipeIDs {
+		if seen[rid] {
+			return ErrOrderMismatch
+		}
+		seen[rid] = true
+	}
+	return s.repo.ReorderRecipes(ctx, id, recipeIDs)
+}
+
+func (s *service) Invite(ctx context.Context, userID, id uuid.UUID, username string) (*Collaborator, error) {
+	c, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, validator.InvalidField("username", "username is required")
+	}
+	invitee, err := s.users.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if invitee.ID == c.OwnerID {
+		return nil, ErrInviteOwner
+	}
+	cb := &Collaborator{
+		UserID:    invitee.ID,
+		Username:  invitee.Username,
+		InvitedBy: userID,
+		Status:    StatusPending,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.AddCollaborator(ctx, id, cb); err != nil {
+		return nil, err
+	}
+	return cb, nil
+}
+
+func (s *service) ListInvitations(ctx context.Context, userID uuid.UUID) ([]*Collection, error) {
+	return s.repo.ListInvitations(ctx, userID)
+}
+
+func (s *service) AcceptInvitation(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.AcceptInvitation(ctx, id, userID, time.Now().UTC())
+}
+
+func (s *service) RemoveCollaborator(ctx context.Context, userID, id, collaboratorID uuid.UUID) error {
+	c, err := s.getVisible(ctx, &userID, id)
+	if err != nil {
+		return err
+	}
+	if c.OwnerID != userID && collaboratorID != userID {
+		return apperrors.ErrForbidden
+	}
+	return s.repo.RemoveCollaborator(ctx, id, collaboratorID)
+}
+
+// getVisible loads a collection the viewer may see. Hidden collections are
+// reported as missing.
+func (s *service) getVisible(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) (*Collection, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !c.IsPublic && (viewerID == nil || !c.canView(*viewerID)) {
+		return nil, ErrCollectionNotFound
+	}
+	return c, nil
+}
+
+// getEditable loads a collection the user may edit.
+func (s *service) getEditable(ctx context.Context, userID, id uuid.UUID) (*Collection, error) {
+	c, err := s.getVisible(ctx, &userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !c.canEdit(userID) {
+		return nil, apperrors.ErrForbidden
+	}
+	return c, nil
+}
+
+// getOwned loads a collection owned by the user.
+func (s *service) getOwned(ctx context.Context, userID, id uuid.UUID) (*Collection, error) {
+	c, err := s.getVisible(ctx, &userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.OwnerID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+	return c, nil
+}
+
+// coverImage trims a cover image URL; an empty URL clears the cover.
+func coverImage(url *string) *string {
+	if url == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*url)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
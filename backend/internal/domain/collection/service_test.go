@@ -0,0 +1,150 @@
+package collection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/recipe/recipetest"
+	"alchemorsel/backend/internal/domain/user"
+	"alchemorsel/backend/internal/domain/user/usertest"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// fakeRepository implements the Repository methods used by the tests. Calls to
+// any other method panic through the nil embedded interface.
+type fakeRepository struct {
+	Repository
+	collections map[uuid.UUID]*Collection
+	added       []uuid.UUID
+	positions   []int
+	updated     *Collection
+	invited     *Collaborator
+}
+
+func (f *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*Collection, error) {
+	if c, ok := f.collections[id]; ok {
+		copy := *c
+		return &copy, nil
+	}
+	return nil, ErrCollectionNotFound
+}
+
+func (f *fakeRepository) Update(ctx context.Context, c *Collection) error {
+	f.updated = c
+	return nil
+}
+
+func (f *fakeRepository) AddRecipe(ctx context.Context, collectionID, recipeID, addedBy uuid.UUID, position int) error {
+	f.added = append(f.added, recipeID)
+	f.positions = append(f.positions, position)
+	return nil
+}
+
+func (f *fakeRepository) AddCollaborator(ctx context.Context, collectionID uuid.UUID, cb *Collaborator) error {
+	f.invited = cb
+	return nil
+}
+
+func TestPermissions(t *testing.T) {
+	owner, editor, invitee, stranger := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	private := &Collection{ID: uuid.New(), OwnerID: owner, Name: "Holiday baking", Collaborators: []*Collaborator{
+		{UserID: editor, Status: StatusAccepted},
+		{UserID: invitee, Status: StatusPending},
+	}}
+	repo := &fakeRepository{collections: map[uuid.UUID]*Collection{private.ID: private}}
+	svc := NewService(repo, recipetest.NewRecipes(), usertest.NewUsers())
+	ctx := context.Background()
+	name := "Winter baking"
+	public := true
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		req    UpdateRequest
+		want   error
+	}{
+		{"stranger cannot see private collection", stranger, UpdateRequest{Name: &name}, ErrCollectionNotFound},
+		{"pending invitee cannot edit", invitee, UpdateRequest{Name: &name}, apperrors.ErrForbidden},
+		{"collaborator cannot change visibility", editor, UpdateRequest{IsPublic: &public}, apperrors.ErrForbidden},
+		{"collaborator can rename", editor, UpdateRequest{Name: &name}, nil},
+		{"owner can publish", owner, UpdateRequest{IsPublic: &public}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.Update(ctx, tt.userID, private.ID, tt.req); err != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := svc.Get(ctx, &stranger, private.ID); err != ErrCollectionNotFound {
+		t.Fatalf("expected private collection to be hidden, got %v", err)
+	}
+	if err := svc.Delete(ctx, editor, private.ID); err != apperrors.ErrForbidden {
+		t.Fatalf("expected collaborators to be unable to delete, got %v", err)
+	}
+	if err := svc.RemoveCollaborator(ctx, editor, private.ID, invitee); err != apperrors.ErrForbidden {
+		t.Fatalf("expected collaborators to be unable to remove others, got %v", err)
+	}
+}
+
+func TestAddRecipeChecksVisibility(t *testing.T) {
+	owner := uuid.New()
+	c := &Collection{ID: uuid.New(), OwnerID: owner, Name: "Weeknight dinners"}
+	public := &recipe.Recipe{ID: uuid.New(), UserID: uuid.New(), IsPublic: true}
+	hidden := &recipe.Recipe{ID: uuid.New(), UserID: uuid.New()}
+	repo := &fakeRepository{collections: map[uuid.UUID]*Collection{c.ID: c}}
+	recipes := recipetest.NewRecipes(public, hidden)
+	svc := NewService(repo, recipes, usertest.NewUsers())
+	ctx := context.Background()
+
+	if err := svc.AddRecipe(ctx, owner, c.ID, hidden.ID, nil); err != apperrors.ErrRecipeNotFound {
+		t.Fatalf("expected hidden recipe to be missing, got %v", err)
+	}
+	negative := -1
+	if err := svc.AddRecipe(ctx, owner, c.ID, public.ID, &negative); err == nil {
+		t.Fatalf("expected validation error for negative position")
+	}
+	first := 0
+	if err := svc.AddRecipe(ctx, owner, c.ID, public.ID, nil); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := svc.AddRecipe(ctx, owner, c.ID, public.ID, &first); err != nil {
+		t.Fatalf("add at position: %v", err)
+	}
+	if len(repo.positions) != 2 || repo.positions[0] != -1 || repo.positions[1] != 0 {
+		t.Fatalf("unexpected positions %v", repo.positions)
+	}
+
+	dup := []uuid.UUID{public.ID, public.ID}
+	if err := svc.ReorderRecipes(ctx, owner, c.ID, dup); err != ErrOrderMismatch {
+		t.Fatalf("expected order mismatch for duplicate ids, got %v", err)
+	}
+}
+
+func TestInvite(t *testing.T) {
+	owner := uuid.New()
+	friend := &user.User{ID: uuid.New(), Username: "friend"}
+	c := &Collection{ID: uuid.New(), OwnerID: owner, Name: "Soups"}
+	repo := &fakeRepository{collections: map[uuid.UUID]*Collection{c.ID: c}}
+	users := usertest.NewUsers(&user.User{ID: owner, Username: "owner"}, friend)
+	svc := NewService(repo, recipetest.NewRecipes(), users)
+	ctx := context.Background()
+
+	if _, err := svc.Invite(ctx, owner, c.ID, "owner"); err != ErrInviteOwner {
+		t.Fatalf("expected owner invite to be rejected, got %v", err)
+	}
+	if _, err := svc.Invite(ctx, owner, c.ID, "nobody"); err != apperrors.ErrUserNotFound {
+		t.Fatalf("expected unknown user, got %v", err)
+	}
+	cb, err := svc.Invite(ctx, owner, c.ID, " friend ")
+	if err != nil {
+		t.Fatalf("invite: %v", err)
+	}
+	if cb.UserID != friend.ID || cb.Status != StatusPending || cb.InvitedBy != owner || repo.invited != cb {
+		t.Fatalf("unexpected collaborator %+v", cb)
+	}
+}
@@ -0,0 +1,67 @@
+package comment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeletedPlaceholder replaces the body of deleted comments so that their
+// replies keep their context.
+const DeletedPlaceholder = "[deleted]"
+
+// Comment is a message in the discussion under a recipe. Top-level comments
+// may have replies; replies have a ParentID and cannot be replied to
+// themselves.
+type Comment struct {
+	ID       uuid.UUID  `json:"id"`
+	RecipeID uuid.UUID  `json:"recipe_id"`
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	UserID   uuid.UUID  `json:"-"`
+	// Author is nil once the comment is deleted.
+	Author     *Author    `json:"author"`
+	Body       string     `json:"body"`
+	Deleted    bool       `json:"deleted"`
+	ReplyCount int        `json:"reply_count"`
+	CreatedAt  time.Time  `json:"created_at"`
+	EditedAt   *time.Time `json:"edited_at,omitempty"`
+	DeletedAt  *time.Time `json:"-"`
+}
+
+// Author identifies the user who wrote a comment.
+type Author struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+// Report reasons accepted by ReportRequest.Reason.
+const (
+	ReasonSpam     = "spam"
+	ReasonAbuse    = "abuse"
+	ReasonOffTopic = "off_topic"
+	ReasonOther    = "other"
+)
+
+// Report statuses. Reports enter the moderation queue as open and are either
+// dismissed or resolved by removing the comment.
+const (
+	StatusOpen      = "open"
+	StatusDismissed = "dismissed"
+	StatusRemoved   = "removed"
+)
+
+// Report is a user's complaint about a comment, queued for moderation.
+type Report struct {
+	ID         uuid.UUID  `json:"id"`
+	CommentID  uuid.UUID  `json:"comment_id"`
+	ReporterID uuid.UUID  `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy *uuid.UUID `json:"resolved_by,omitempty"`
+	// Comment is the reported comment as stored, including the body of
+	// deleted comments. It is only shown to moderators.
+	Comment *Comment `json:"comment,omitempty"`
+}
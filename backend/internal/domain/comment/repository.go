@@ -0,0 +1,37 @@
+package comment
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+// Repository defines persistence operations for comments and their reports.
+// Lists return at most limit items positioned after the cursor, which is nil
+// for the first page.
+type Repository interface {
+	Create(ctx context.Context, c *Comment) error
+	// GetByID returns the comment even when it is deleted.
+	GetByID(ctx context.Context, id uuid.UUID) (*Comment, error)
+	UpdateBody(ctx context.Context, id uuid.UUID, body string, editedAt time.Time) error
+	SoftDelete(ctx context.Context, id uuid.UUID, at time.Time) error
+	// ListThreads returns a recipe's top-level comments, newest first.
+	ListThreads(ctx context.Context, recipeID uuid.UUID, after *pagination.Cursor, limit int) ([]*Comment, error)
+	// ListReplies returns the replies to a comment, oldest first.
+	ListReplies(ctx context.Context, parentID uuid.UUID, after *pagination.Cursor, limit int) ([]*Comment, error)
+
+	// CreateReport fails with ErrAlreadyReported when the user has already
+	// reported the comment.
+	CreateReport(ctx context.Context, r *Report) error
+	GetReport(ctx context.Context, id uuid.UUID) (*Report, error)
+	// ListReports returns reports with the given status together with the
+	// reported comments, oldest first.
+	ListReports(ctx context.Context, status string, after *pagination.Cursor, limit int) ([]*Report, error)
+	// ResolveReport closes an open report. ResolveCommentReports closes all
+	// open reports on a comment.
+	ResolveReport(ctx context.Context, id uuid.UUID, status string, by uuid.UUID, at time.Time) error
+	ResolveCommentReports(ctx context.Context, commentID uuid.UUID, status string, by uuid.UUID, at time.Time) error
+}
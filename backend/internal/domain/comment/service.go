@@ -0,0 +1,376 @@
+package comment
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/pagination"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+const (
+	// MaxBodyLength is the maximum length of a comment in characters,
+	// counted after sanitization.
+	MaxBodyLength = 2000
+	// MaxReportDetailsLength bounds the free text attached to a report.
+	MaxReportDetailsLength = 1000
+)
+
+// Moderation actions accepted by ResolveReport.
+const (
+	ActionDismiss = "dismiss"
+	ActionRemove  = "remove"
+)
+
+var (
+	// ErrCommentNotFound is returned when a comment cannot be located.
+	ErrCommentNotFound = apperrors.New("comment_not_found", "comment not found", 404)
+	// ErrReportNotFound is returned when a report cannot be located.
+	ErrReportNotFound = apperrors.New("report_not_found", "report not found", 404)
+	// ErrAlreadyReported is returned when a user reports the same comment twice.
+	ErrAlreadyReported = apperrors.New("already_reported", "you have already reported this comment", 409)
+	// ErrReportResolved is returned when a report has already been handled.
+	ErrReportResolved = apperrors.New("report_resolved", "report has already been resolved", 409)
+)
+
+var reasons = map[string]bool{
+	ReasonSpam:     true,
+	ReasonAbuse:    true,
+	ReasonOffTopic: true,
+	ReasonOther:    true,
+}
+
+var statuses = map[string]bool{
+	StatusOpen:      true,
+	StatusDismissed: true,
+	StatusRemoved:   true,
+}
+
+// ModerationHook is notified of moderation events, for example to alert
+// moderators or forward reports to an external review service. Hooks run
+// synchronously and should not block.
+type ModerationHook interface {
+	CommentReported(ctx context.Context, r *Report)
+	ReportResolved(ctx context.Context, r *Report)
+}
+
+// Service defines business logic for comments.
+type Service interface {
+	Create(ctx context.Context, userID, recipeID uuid.UUID, req CreateRequest) (*Comment, error)
+	// List returns a page of a recipe's top-level comments, newest first.
+	List(ctx context.Context, viewerID *uuid.UUID, recipeID uuid.UUID, cursor string, limit int) (*Page, error)
+	// ListReplies returns a page of replies to a comment, oldest first.
+	ListReplies(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, cursor string, limit int) (*Page, error)
+	Edit(ctx context.Context, userID, id uuid.UUID, body string) (*Comment, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	Report(ctx context.Context, userID, id uuid.UUID, req ReportRequest) (*Report, error)
+	// ListReports and ResolveReport make up the moderation queue and are
+	// restricted to moderators.
+	ListReports(ctx context.Context, moderatorID uuid.UUID, status, cursor string, limit int) (*ReportPage, error)
+	ResolveReport(ctx context.Context, moderatorID, reportID uuid.UUID, action string) (*Report, error)
+}
+
+// CreateRequest describes a new comment. A reply to a reply is attached to
+// the top-level comment of its thread.
+type CreateRequest struct {
+	Body     string
+	ParentID *uuid.UUID
+}
+
+type ReportRequest struct {
+	Reason  string
+	Details string
+}
+
+// Page is a page of comments. NextCursor is empty on the last page.
+type Page struct {
+	Comments   []*Comment `json:"comments"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+// ReportPage is a page of the moderation queue.
+type ReportPage struct {
+	Reports    []*Report `json:"reports"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+type service struct {
+	repo       Repository
+	recipes    recipe.Service
+	moderators map[uuid.UUID]bool
+	hooks      []ModerationHook
+}
+
+// Option configures optional service dependencies.
+type Option func(*service)
+
+// WithModerators grants the given users access to the moderation queue.
+func WithModerators(ids ...uuid.UUID) Option {
+	return func(s *service) {
+		for _, id := range ids {
+			s.moderators[id] = true
+		}
+	}
+}
+
+// WithModerationHook registers a hook notified of moderation events.
+func WithModerationHook(h ModerationHook) Option {
+	return func(s *service) { s.hooks = append(s.hooks, h) }
+}
+
+// NewService creates a comment service. Recipe visibility is checked through
+// the recipe service.
+func NewService(repo Repository, recipes recipe.Service, opts ...Option) Service {
+	s := &service{repo: repo, recipes: recipes, moderators: map[uuid.UUID]bool{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *service) Create(ctx context.Context, userID, recipeID uuid.UUID, req CreateRequest) (*Comment, error) {
+	body, err := validator.Text("body", req.Body, 1, MaxBodyLength)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.recipes.Get(ctx, &userID, recipeID); err != nil {
+		return nil, err
+	}
+	c := &Comment{
+		ID:        uuid.New(),
+		RecipeID:  recipeID,
+		UserID:    userID,
+		Body:      body,
+		CreatedAt: time.Now().UTC(),
+	}
+	if req.ParentID != nil {
+		parent, err := s.repo.GetByID(ctx, *req.ParentID)
+		if err == ErrCommentNotFound || (err == nil && (parent.DeletedAt != nil || parent.RecipeID != recipeID)) {
+			return nil, validator.InvalidField("parent_id", "parent comment does not exist")
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.ParentID = &parent.ID
+		if parent.ParentID != nil {
+			c.ParentID = parent.ParentID
+		}
+	}
+	if err := s.repo.Create(ctx, c); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, c.ID)
+}
+
+func (s *service) List(ctx context.Context, viewerID *uuid.UUID, recipeID uuid.UUID, cursor string, limit int) (*Page, error) {
+	after, ok := pagination.DecodeCursor(cursor)
+	if !ok {
+		return nil, validator.InvalidField("cursor", "cursor is invalid")
+	}
+	if _, err := s.recipes.Get(ctx, viewerID, recipeID); err != nil {
+		return nil, err
+	}
+	limit = pagination.NormalizeLimit(limit)
+	comments, err := s.repo.ListThreads(ctx, recipeID, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return newPage(comments, limit), nil
+}
+
+func (s *service) ListReplies(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, cursor string, limit int) (*Page, error) {
+	after, ok := pagination.DecodeCursor(cursor)
+	if !ok {
+		return nil, validator.InvalidField("cursor", "cursor is invalid")
+	}
+	if _, err := s.getVisible(ctx, viewerID, id); err != nil {
+		return nil, err
+	}
+	limit = pagination.NormalizeLimit(limit)
+	replies, err := s.repo.ListReplies(ctx, id, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return newPage(replies, limit), nil
+}
+
+func (s *service) Edit(ctx context.Context, userID, id uuid.UUID, body string) (*Comment, error) {
+	body, err := validator.Text("body", body, 1, MaxBodyLength)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateBody(ctx, c.ID, body, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	c, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.SoftDelete(ctx, c.ID, time.Now().UTC())
+}
+
+func (s *service) Report(ctx context.Context, userID, id uuid.UUID, req ReportRequest) (*Report, error) {
+	if !reasons[req.Reason] {
+		return nil, validator.InvalidField("reason", "reason must be one of spam, abuse, off_topic or other")
+	}
+	details, err := validator.Text("details", req.Details, 0, MaxReportDetailsLength)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.getVisible(ctx, &userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.DeletedAt != nil {
+		return nil, ErrCommentNotFound
+	}
+	r := &Report{
+		ID:         uuid.New(),
+		CommentID:  id,
+		ReporterID: userID,
+		Reason:     req.Reason,
+		Details:    details,
+		Status:     StatusOpen,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err := s.repo.CreateReport(ctx, r); err != nil {
+		return nil, err
+	}
+	for _, h := range s.hooks {
+		h.CommentReported(ctx, r)
+	}
+	return r, nil
+}
+
+func (s *service) ListReports(ctx context.Context, moderatorID uuid.UUID, status, cursor string, limit int) (*ReportPage, error) {
+	if !s.moderators[moderatorID] {
+		return nil, apperrors.ErrForbidden
+	}
+	if status == "" {
+		status = StatusOpen
+	}
+	if !statuses[status] {
+		return nil, validator.InvalidField("status", "status must be open, dismissed or removed")
+	}
+	after, ok := pagination.DecodeCursor(cursor)
+	if !ok {
+		return nil, validator.InvalidField("cursor", "cursor is invalid")
+	}
+	limit = pagination.NormalizeLimit(limit)
+	reports, err := s.repo.ListReports(ctx, status, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	page := &ReportPage{Reports: reports}
+	if len(reports) > limit {
+		page.Reports = reports[:limit]
+		last := page.Reports[limit-1]
+		page.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return page, nil
+}
+
+// ResolveReport handles a report from the moderation queue. Removing the
+// comment resolves every open report on it.
+func (s *service) ResolveReport(ctx context.Context, moderatorID, reportID uuid.UUID, action string) (*Report, error) {
+	if !s.moderators[moderatorID] {
+		return nil, apperrors.ErrForbidden
+	}
+	r, err := s.repo.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != StatusOpen {
+		return nil, ErrReportResolved
+	}
+	now := time.Now().UTC()
+	switch action {
+	case ActionDismiss:
+		err = s.repo.ResolveReport(ctx, r.ID, StatusDismissed, moderatorID, now)
+	case ActionRemove:
+		if err = s.repo.SoftDelete(ctx, r.CommentID, now); err == nil {
+			err = s.repo.ResolveCommentReports(ctx, r.CommentID, StatusRemoved, moderatorID, now)
+		}
+	default:
+		return nil, validator.InvalidField("action", "action must be dismiss or remove")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r, err = s.repo.GetReport(ctx, reportID); err != nil {
+		return nil, err
+	}
+	for _, h := range s.hooks {
+		h.ReportResolved(ctx, r)
+	}
+	return r, nil
+}
+
+// getVisible loads a comment on a recipe the viewer may read. Comments on
+// hidden recipes are reported as missing.
+func (s *service) getVisible(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) (*Comment, error) {
+	c, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.recipes.Get(ctx, viewerID, c.RecipeID); err != nil {
+		if err == apperrors.ErrRecipeNotFound {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// getOwned loads a live comment written by the user.
+func (s *service) getOwned(ctx context.Context, userID, id uuid.UUID) (*Comment, error) {
+	c, err := s.getVisible(ctx, &userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.DeletedAt != nil {
+		return nil, ErrCommentNotFound
+	}
+	if c.UserID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+	return c, nil
+}
+
+// newPage trims comments fetched with one extra row to limit and derives the
+// next cursor from the last comment kept.
+func newPage(comments []*Comment, limit int) *Page {
+	page := &Page{Comments: comments}
+	if len(comments) > limit {
+		page.Comments = comments[:limit]
+		last := page.Comments[limit-1]
+		page.NextCursor = pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	for _, c := range page.Comments {
+		redact(c)
+	}
+	return page
+}
+
+// redact replaces the content of a deleted comment with a placeholder.
+func redact(c *Comment) {
+	if c.DeletedAt == nil {
+		return
+	}
+	c.Deleted = true
+	c.Body = DeletedPlaceholder
+	c.Author = nil
+	c.EditedAt = nil
+}
@@ -0,0 +1,201 @@
+package comment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/recipe/recipetest"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+// fakeRepository keeps comments and reports in memory. Calls to any other
+// method panic through the nil embedded interface.
+type fakeRepository struct {
+	Repository
+	comments map[uuid.UUID]*Comment
+	reports  map[uuid.UUID]*Report
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{comments: map[uuid.UUID]*Comment{}, reports: map[uuid.UUID]*Report{}}
+}
+
+func (f *fakeRepository) Create(ctx context.Context, c *Comment) error {
+	copy := *c
+	f.comments[c.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*Comment, error) {
+	c, ok := f.comments[id]
+	if !ok {
+		return nil, ErrCommentNotFound
+	}
+	copy := *c
+	return &copy, nil
+}
+
+func (f *fakeRepository) SoftDelete(ctx context.Context, id uuid.UUID, at time.Time) error {
+	f.comments[id].DeletedAt = &at
+	return nil
+}
+
+func (f *fakeRepository) ListThreads(ctx context.Context, recipeID uuid.UUID, after *pagination.Cursor, limit int) ([]*Comment, error) {
+	var out []*Comment
+	for _, c := range f.comments {
+		if c.RecipeID == recipeID && c.ParentID == nil && len(out) < limit {
+			copy := *c
+			out = append(out, &copy)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) CreateReport(ctx context.Context, r *Report) error {
+	f.reports[r.ID] = r
+	return nil
+}
+
+func (f *fakeRepository) GetReport(ctx context.Context, id uuid.UUID) (*Report, error) {
+	r, ok := f.reports[id]
+	if !ok {
+		return nil, ErrReportNotFound
+	}
+	return r, nil
+}
+
+func (f *fakeRepository) ResolveCommentReports(ctx context.Context, commentID uuid.UUID, status string, by uuid.UUID, at time.Time) error {
+	for _, r := range f.reports {
+		if r.CommentID == commentID && r.Status == StatusOpen {
+			r.Status, r.ResolvedBy, r.ResolvedAt = status, &by, &at
+		}
+	}
+	return nil
+}
+
+type recordingHook struct {
+	reported, resolved []*Report
+}
+
+func (h *recordingHook) CommentReported(ctx context.Context, r *Report) {
+	h.reported = append(h.reported, r)
+}
+func (h *recordingHook) ReportResolved(ctx context.Context, r *Report) {
+	h.resolved = append(h.resolved, r)
+}
+
+func setup(opts ...Option) (Service, *fakeRepository, *recipe.Recipe) {
+	rec := &recipe.Recipe{ID: uuid.New(), UserID: uuid.New(), IsPublic: true}
+	repo := newFakeRepository()
+	svc := NewService(repo, recipetest.NewRecipes(rec), opts...)
+	return svc, repo, rec
+}
+
+func TestCreateSanitizesAndFlattensReplies(t *testing.T) {
+	svc, _, rec := setup()
+	ctx := context.Background()
+	user := uuid.New()
+
+	if _, err := svc.Create(ctx, user, rec.ID, CreateRequest{Body: "<b></b>  "}); err == nil {
+		t.Fatal("expected empty body to be rejected")
+	}
+	root, err := svc.Create(ctx, user, rec.ID, CreateRequest{Body: "<i>Delicious</i>"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if root.Body != "Delicious" {
+		t.Fatalf("expected sanitized body, got %q", root.Body)
+	}
+	reply, err := svc.Create(ctx, user, rec.ID, CreateRequest{Body: "Agreed", ParentID: &root.ID})
+	if err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	nested, err := svc.Create(ctx, user, rec.ID, CreateRequest{Body: "Me too", ParentID: &reply.ID})
+	if err != nil {
+		t.Fatalf("nested reply: %v", err)
+	}
+	if nested.ParentID == nil || *nested.ParentID != root.ID {
+		t.Fatalf("expected reply to a reply to join the thread of %s, got %v", root.ID, nested.ParentID)
+	}
+	missing := uuid.New()
+	if _, err := svc.Create(ctx, user, rec.ID, CreateRequest{Body: "hi", ParentID: &missing}); err == nil {
+		t.Fatal("expected unknown parent to be rejected")
+	}
+}
+
+func TestDeleteLeavesPlaceholder(t *testing.T) {
+	svc, _, rec := setup()
+	ctx := context.Background()
+	author, other := uuid.New(), uuid.New()
+
+	c, err := svc.Create(ctx, author, rec.ID, CreateRequest{Body: "First!"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := svc.Delete(ctx, other, c.ID); err != apperrors.ErrForbidden {
+		t.Fatalf("expected forbidden for non-author, got %v", err)
+	}
+	if _, err := svc.Edit(ctx, other, c.ID, "hijacked"); err != apperrors.ErrForbidden {
+		t.Fatalf("expected forbidden edit for non-author, got %v", err)
+	}
+	if err := svc.Delete(ctx, author, c.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := svc.Delete(ctx, author, c.ID); err != ErrCommentNotFound {
+		t.Fatalf("expected deleted comment to be gone, got %v", err)
+	}
+
+	page, err := svc.List(ctx, nil, rec.ID, "", 10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	got := page.Comments[0]
+	if !got.Deleted || got.Body != DeletedPlaceholder || got.Author != nil {
+		t.Fatalf("expected placeholder, got %+v", got)
+	}
+	if _, err := svc.List(ctx, nil, rec.ID, "garbage", 10); err == nil {
+		t.Fatal("expected invalid cursor to be rejected")
+	}
+}
+
+func TestModerationQueue(t *testing.T) {
+	moderator := uuid.New()
+	hook := &recordingHook{}
+	svc, repo, rec := setup(WithModerators(moderator), WithModerationHook(hook))
+	ctx := context.Background()
+	author, reporter := uuid.New(), uuid.New()
+
+	c, err := svc.Create(ctx, author, rec.ID, CreateRequest{Body: "buy cheap pans"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Report(ctx, reporter, c.ID, ReportRequest{Reason: "boring"}); err == nil {
+		t.Fatal("expected unknown reason to be rejected")
+	}
+	report, err := svc.Report(ctx, reporter, c.ID, ReportRequest{Reason: ReasonSpam, Details: "<a>link</a> spam"})
+	if err != nil {
+		t.Fatalf("report: %v", err)
+	}
+	if report.Details != "link spam" || len(hook.reported) != 1 {
+		t.Fatalf("unexpected report %+v, hook calls %d", report, len(hook.reported))
+	}
+
+	if _, err := svc.ResolveReport(ctx, reporter, report.ID, ActionRemove); err != apperrors.ErrForbidden {
+		t.Fatalf("expected non-moderator to be forbidden, got %v", err)
+	}
+	resolved, err := svc.ResolveReport(ctx, moderator, report.ID, ActionRemove)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if resolved.Status != StatusRemoved || repo.comments[c.ID].DeletedAt == nil || len(hook.resolved) != 1 {
+		t.Fatalf("expected comment removal, got report %+v", resolved)
+	}
+	if _, err := svc.ResolveReport(ctx, moderator, report.ID, ActionDismiss); err != ErrReportResolved {
+		t.Fatalf("expected resolved report to be final, got %v", err)
+	}
+}
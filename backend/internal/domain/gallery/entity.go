@@ -0,0 +1,53 @@
+package gallery
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Image is a photo in a recipe's gallery. One image of each gallery is its
+// cover, which is also the recipe's image_url.
+type Image struct {
+	ID       uuid.UUID `json:"id"`
+	RecipeID uuid.UUID `json:"recipe_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Position int       `json:"position"`
+	IsCover  bool      `json:"is_cover"`
+	AltText  string    `json:"alt_text"`
+	// Step is the number, starting at 1, of the instruction the photo
+	// illustrates.
+	Step        *int   `json:"step"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	// URL serves the largest variant, or the file as uploaded when it has
+	// no variants.
+	URL string `json:"url"`
+	// Key locates the file as uploaded in storage. Only pictures that
+	// cannot be resized are kept as uploaded.
+	Key       string    `json:"-"`
+	Variants  []Variant `json:"variants"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Variant is a copy of an image scaled down for smaller screens.
+type Variant struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+	Key    string `json:"-"`
+}
+
+// keys returns the storage keys of all of an image's files.
+func (img *Image) keys() []string {
+	var keys []string
+	if img.Key != "" {
+		keys = append(keys, img.Key)
+	}
+	for _, v := range img.Variants {
+		keys = append(keys, v.Key)
+	}
+	return keys
+}
@@ -0,0 +1,34 @@
+package gallery
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository defines persistence operations for recipe images.
+type Repository interface {
+	Create(ctx context.Context, img *Image) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Image, error)
+	// ListByRecipe returns a recipe's images in gallery order.
+	ListByRecipe(ctx context.Context, recipeID uuid.UUID) ([]*Image, error)
+	// Update saves the alt text and step of an image.
+	Update(ctx context.Context, img *Image) error
+	// SetCover makes an image the only cover of its recipe's gallery.
+	SetCover(ctx context.Context, recipeID, id uuid.UUID) error
+	// Reorder sets the positions of a recipe's images to their index in ids.
+	Reorder(ctx context.Context, recipeID uuid.UUID, ids []uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListTrashed returns the images of recipes moved to the trash before
+	// the given time.
+	ListTrashed(ctx context.Context, before time.Time) ([]*Image, error)
+}
+
+// Storage holds image files and serves them at public URLs.
+type Storage interface {
+	// Put stores data under key and returns the URL it is served at.
+	Put(ctx context.Context, key, contentType string, data []byte) (string, error)
+	// Delete removes the file stored under key. Missing files are ignored.
+	Delete(ctx context.Context, key string) error
+}
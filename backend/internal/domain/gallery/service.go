@@ -0,0 +1,363 @@
+package gallery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/imaging"
+	"alchemorsel/backend/internal/pkg/logger"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// Limits on galleries.
+const (
+	MaxImages        = 20
+	MaxAltTextLength = 250
+)
+
+// TrashRetention is how long the images of a recipe in the trash are kept
+// in case the recipe is restored.
+const TrashRetention = 30 * 24 * time.Hour
+
+// Sizes are the responsive variants made of every uploaded image.
+var Sizes = []imaging.Size{
+	{Name: "thumbnail", Width: 320},
+	{Name: "medium", Width: 800},
+	{Name: "large", Width: 1600},
+}
+
+var (
+	// ErrImageNotFound is returned when an image does not exist or belongs
+	// to another recipe.
+	ErrImageNotFound = apperrors.New("image_not_found", "image not found", 404)
+	// ErrGalleryFull is returned when a recipe already has MaxImages images.
+	ErrGalleryFull = apperrors.NewWithDetails("gallery_full", "recipe already has the maximum number of images", 422,
+		map[string]any{"limit": MaxImages})
+	// ErrOrderMismatch is returned when a new order repeats an image or
+	// lists one that is not in the gallery.
+	ErrOrderMismatch = validator.InvalidField("image_ids", "image_ids must list images in the gallery at most once")
+)
+
+// Service manages the photo galleries of recipes.
+type Service interface {
+	// List returns the gallery of a recipe visible to the viewer.
+	List(ctx context.Context, viewerID *uuid.UUID, recipeID uuid.UUID) ([]*Image, error)
+	// Upload adds a photo to the end of a recipe's gallery. The first photo
+	// of a gallery becomes its cover.
+	Upload(ctx context.Context, userID, recipeID uuid.UUID, req UploadRequest) (*Image, error)
+	Update(ctx context.Context, userID, recipeID, id uuid.UUID, req UpdateRequest) (*Image, error)
+	// Reorder moves the listed images to the front of the gallery in the
+	// given order and returns the gallery.
+	Reorder(ctx context.Context, userID, recipeID uuid.UUID, ids []uuid.UUID) ([]*Image, error)
+	// Delete removes an image and its files. When it was the cover, the
+	// first remaining image takes its place.
+	Delete(ctx context.Context, userID, recipeID, id uuid.UUID) error
+	// PurgeTrashed deletes the images of recipes that have been in the
+	// trash for longer than TrashRetention and returns how many it deleted.
+	PurgeTrashed(ctx context.Context) (int, error)
+}
+
+// UploadRequest describes a photo to add. Step attaches it to an
+// instruction and Cover makes it the cover.
+type UploadRequest struct {
+	Data    []byte
+	AltText string
+	Step    *int
+	Cover   bool
+}
+
+// UpdateRequest changes the set fields of an image. A Step of 0 detaches
+// the image from its instruction. Cover makes the image the cover; a cover
+// is replaced by choosing another one.
+type UpdateRequest struct {
+	AltText *string
+	Step    *int
+	Cover   bool
+}
+
+type service struct {
+	repo    Repository
+	storage Storage
+	recipes recipe.Service
+}
+
+// NewService creates a gallery service. Recipe ownership and visibility are
+// checked through the recipe service, which also receives cover changes.
+func NewService(repo Repository, storage Storage, recipes recipe.Service) Service {
+	return &service{repo: repo, storage: storage, recipes: recipes}
+}
+
+func (s *service) List(ctx context.Context, viewerID *uuid.UUID, recipeID uuid.UUID) ([]*Image, error) {
+	if _, err := s.recipes.Get(ctx, viewerID, recipeID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByRecipe(ctx, recipeID)
+}
+
+func (s *service) Upload(ctx context.Context, userID, recipeID uuid.UUID, req UploadRequest) (*Image, error) {
+	r, err := s.getOwned(ctx, userID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	alt, err := validator.Text("alt_text", req.AltText, 0, MaxAltTextLength)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStep(r, req.Step); err != nil {
+		return nil, err
+	}
+	images, err := s.repo.ListByRecipe(ctx, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(images) >= MaxImages {
+		return nil, ErrGalleryFull
+	}
+	info, err := imaging.Inspect(req.Data)
+	if err != nil {
+		return nil, err
+	}
+	renditions, err := imaging.Render(req.Data, info, Sizes)
+	if err != nil {
+		return nil, err
+	}
+
+	img := &Image{
+		ID:          uuid.New(),
+		RecipeID:    recipeID,
+		UserID:      userID,
+		Position:    nextPosition(images),
+		AltText:     alt,
+		Step:        req.Step,
+		ContentType: info.ContentType,
+		Width:       info.Width,
+		Height:      info.Height,
+		Variants:    []Variant{},
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := s.store(ctx, img, req.Data, renditions); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, img); err != nil {
+		s.removeFiles(ctx, img)
+		return nil, err
+	}
+	if req.Cover || len(images) == 0 {
+		if err := s.setCover(ctx, userID, img); err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
+func (s *service) Update(ctx context.Context, userID, recipeID, id uuid.UUID, req UpdateRequest) (*Image, error) {
+	r, err := s.getOwned(ctx, userID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	img, err := s.getImage(ctx, recipeID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.AltText != nil {
+		if img.AltText, err = validator.Text("alt_text", *req.AltText, 0, MaxAltTextLength); err != nil {
+			return nil, err
+		}
+	}
+	if req.Step != nil {
+		img.Step = req.Step
+		if *req.Step == 0 {
+			img.Step = nil
+		}
+		if err := checkStep(r, img.Step); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.Update(ctx, img); err != nil {
+		return nil, err
+	}
+	if req.Cover && !img.IsCover {
+		if err := s.setCover(ctx, userID, img); err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
+func (s *service) Reorder(ctx context.Context, userID, recipeID uuid.UUID, ids []uuid.UUID) ([]*Image, error) {
+	if _, err := s.getOwned(ctx, userID, recipeID); err != nil {
+		return nil, err
+	}
+	images, err := s.repo.ListByRecipe(ctx, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*Image, len(images))
+	for _, img := range images {
+		byID[img.ID] = img
+	}
+	ordered := make([]*Image, 0, len(images))
+	for _, id := range ids {
+		img, ok := byID[id]
+		if !ok {
+			return nil, ErrOrderMismatch
+		}
+		ordered = append(ordered, img)
+		delete(byID, id)
+	}
+	for _, img := range images {
+		if _, rest := byID[img.ID]; rest {
+			ordered = append(ordered, img)
+		}
+	}
+	order := make([]uuid.UUID, len(ordered))
+	for i, img := range ordered {
+		img.Position = i
+		order[i] = img.ID
+	}
+	if err := s.repo.Reorder(ctx, recipeID, order); err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, recipeID, id uuid.UUID) error {
+	if _, err := s.getOwned(ctx, userID, recipeID); err != nil {
+		return err
+	}
+	img, err := s.getImage(ctx, recipeID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.removeFiles(ctx, img)
+	if !img.IsCover {
+		return nil
+	}
+	images, err := s.repo.ListByRecipe(ctx, recipeID)
+	if err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return s.syncCover(ctx, userID, recipeID, "")
+	}
+	return s.setCover(ctx, userID, images[0])
+}
+
+func (s *service) PurgeTrashed(ctx context.Context) (int, error) {
+	images, err := s.repo.ListTrashed(ctx, time.Now().Add(-TrashRetention))
+	if err != nil {
+		return 0, err
+	}
+	for i, img := range images {
+		s.removeFiles(ctx, img)
+		if err := s.repo.Delete(ctx, img.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(images), nil
+}
+
+// getOwned loads a recipe the user is about to change the gallery of.
+// Private recipes of other users are reported as missing.
+func (s *service) getOwned(ctx context.Context, userID, recipeID uuid.UUID) (*recipe.Recipe, error) {
+	r, err := s.recipes.Get(ctx, &userID, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	if r.UserID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+	return r, nil
+}
+
+func (s *service) getImage(ctx context.Context, recipeID, id uuid.UUID) (*Image, error) {
+	img, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if img.RecipeID != recipeID {
+		return nil, ErrImageNotFound
+	}
+	return img, nil
+}
+
+// store writes an image's renditions, or the upload itself when it has
+// none, and fills in their URLs and keys. Files already written are removed
+// when one fails.
+func (s *service) store(ctx context.Context, img *Image, data []byte, renditions []imaging.Rendition) error {
+	prefix := fmt.Sprintf("recipes/%s/%s/", img.RecipeID, img.ID)
+	ext := "." + strings.TrimPrefix(img.ContentType, "image/")
+	if ext == ".jpeg" {
+		ext = ".jpg"
+	}
+	if len(renditions) == 0 {
+		url, err := s.storage.Put(ctx, prefix+"original"+ext, img.ContentType, data)
+		if err != nil {
+			return err
+		}
+		img.URL, img.Key = url, prefix+"original"+ext
+		return nil
+	}
+	for _, r := range renditions {
+		key := prefix + r.Name + ext
+		url, err := s.storage.Put(ctx, key, r.ContentType, r.Data)
+		if err != nil {
+			s.removeFiles(ctx, img)
+			return err
+		}
+		img.Variants = append(img.Variants, Variant{Name: r.Name, Width: r.Width, Height: r.Height, URL: url, Key: key})
+		img.URL = url
+	}
+	return nil
+}
+
+// removeFiles deletes an image's files. Failures are logged rather than
+// returned, since the image is already gone for the user.
+func (s *service) removeFiles(ctx context.Context, img *Image) {
+	for _, key := range img.keys() {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			logger.FromContext(ctx).Warnw("failed to delete image file", "image_id", img.ID, "key", key, "error", err)
+		}
+	}
+}
+
+// setCover makes img the cover of its gallery and the image of its recipe.
+func (s *service) setCover(ctx context.Context, userID uuid.UUID, img *Image) error {
+	if err := s.repo.SetCover(ctx, img.RecipeID, img.ID); err != nil {
+		return err
+	}
+	img.IsCover = true
+	return s.syncCover(ctx, userID, img.RecipeID, img.URL)
+}
+
+// syncCover stores url as the recipe's image, clearing it when url is
+// empty.
+func (s *service) syncCover(ctx context.Context, userID, recipeID uuid.UUID, url string) error {
+	return s.recipes.SetImage(ctx, userID, recipeID, url)
+}
+
+// checkStep checks that step, when set, numbers one of the recipe's
+// instructions.
+func checkStep(r *recipe.Recipe, step *int) error {
+	if step != nil && (*step < 1 || *step > len(r.Instructions)) {
+		return validator.InvalidField("step", fmt.Sprintf("step must be between 1 and %d", len(r.Instructions)))
+	}
+	return nil
+}
+
+// nextPosition returns the position after the last image.
+func nextPosition(images []*Image) int {
+	if len(images) == 0 {
+		return 0
+	}
+	return images[len(images)-1].Position + 1
+}
@@ -0,0 +1,271 @@
+package gallery
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/recipe/recipetest"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+type fakeRepository struct {
+	Repository
+	images  map[uuid.UUID]*Image
+	trashed []*Image
+}
+
+func (f *fakeRepository) Create(ctx context.Context, img *Image) error {
+	copy := *img
+	f.images[img.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*Image, error) {
+	if img, ok := f.images[id]; ok {
+		copy := *img
+		return &copy, nil
+	}
+	return nil, ErrImageNotFound
+}
+
+func (f *fakeRepository) ListByRecipe(ctx context.Context, recipeID uuid.UUID) ([]*Image, error) {
+	images := []*Image{}
+	for _, img := range f.images {
+		if img.RecipeID == recipeID {
+			copy := *img
+			images = append(images, &copy)
+		}
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].Position < images[j].Position })
+	return images, nil
+}
+
+func (f *fakeRepository) Update(ctx context.Context, img *Image) error {
+	f.images[img.ID].AltText, f.images[img.ID].Step = img.AltText, img.Step
+	return nil
+}
+
+func (f *fakeRepository) SetCover(ctx context.Context, recipeID, id uuid.UUID) error {
+	for _, img := range f.images {
+		if img.RecipeID == recipeID {
+			img.IsCover = img.ID == id
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepository) Reorder(ctx context.Context, recipeID uuid.UUID, ids []uuid.UUID) error {
+	for i, id := range ids {
+		f.images[id].Position = i
+	}
+	return nil
+}
+
+func (f *fakeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(f.images, id)
+	return nil
+}
+
+func (f *fakeRepository) ListTrashed(ctx context.Context, before time.Time) ([]*Image, error) {
+	return f.trashed, nil
+}
+
+type fakeStorage map[string][]byte
+
+func (f fakeStorage) Put(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	f[key] = data
+	return "/media/" + key, nil
+}
+
+func (f fakeStorage) Delete(ctx context.Context, key string) error {
+	delete(f, key)
+	return nil
+}
+
+// fakeRecipes adds the recipe.Service methods galleries use to change a
+// recipe to the shared in-memory recipes.
+type fakeRecipes struct {
+	*recipetest.Recipes
+}
+
+func (f *fakeRecipes) SetImage(ctx context.Context, userID, id uuid.UUID, url string) error {
+	r := f.ByID[id]
+	r.ImageURL = &url
+	if url == "" {
+		r.ImageURL = nil
+	}
+	return nil
+}
+
+func photo(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newTestService() (Service, *fakeRepository, fakeStorage, *recipe.Recipe) {
+	r := &recipe.Recipe{ID: uuid.New(), UserID: uuid.New(), IsPublic: true, Version: 1, Instructions: recipe.TextSteps([]string{"Mix.", "Bake."})}
+	repo := &fakeRepository{images: map[uuid.UUID]*Image{}}
+	storage := fakeStorage{}
+	svc := NewService(repo, storage, &fakeRecipes{recipetest.NewRecipes(r)})
+	return svc, repo, storage, r
+}
+
+func TestUploadResizesAndSetsCover(t *testing.T) {
+	svc, _, storage, r := newTestService()
+	ctx := context.Background()
+
+	step := 2
+	first, err := svc.Upload(ctx, r.UserID, r.ID, UploadRequest{Data: photo(t, 1000, 500), AltText: " Golden crust ", Step: &step})
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if !first.IsCover || first.AltText != "Golden crust" || *first.Step != 2 || len(first.Variants) != 3 {
+		t.Fatalf("unexpected image %+v", first)
+	}
+	if v := first.Variants[1]; v.Name != "medium" || v.Width != 800 || v.Height != 400 {
+		t.Fatalf("unexpected medium variant %+v", v)
+	}
+	if v := first.Variants[2]; v.Width != 1000 || first.URL != v.URL {
+		t.Fatalf("expected the large variant at full width to be the image URL, got %+v", first)
+	}
+	if len(storage) != 3 || r.ImageURL == nil || *r.ImageURL != first.URL || r.Version != 1 {
+		t.Fatalf("expected 3 stored files and the recipe image to be the cover, got %d, %v", len(storage), r.ImageURL)
+	}
+
+	second, err := svc.Upload(ctx, r.UserID, r.ID, UploadRequest{Data: photo(t, 300, 300)})
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if second.IsCover || second.Position != 1 || *r.ImageURL != first.URL {
+		t.Fatalf("expected later uploads to keep the cover, got %+v", second)
+	}
+	third, err := svc.Upload(ctx, r.UserID, r.ID, UploadRequest{Data: photo(t, 300, 300), Cover: true})
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	images, _ := svc.List(ctx, nil, r.ID)
+	if !third.IsCover || images[0].IsCover || *r.ImageURL != third.URL {
+		t.Fatalf("expected the third upload to become the cover, got %+v", images)
+	}
+}
+
+func TestUploadValidation(t *testing.T) {
+	svc, repo, storage, r := newTestService()
+	ctx := context.Background()
+	step := 3
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		req    UploadRequest
+		code   string
+	}{
+		{"not owner", uuid.New(), UploadRequest{Data: photo(t, 300, 300)}, "forbidden"},
+		{"unknown step", r.UserID, UploadRequest{Data: photo(t, 300, 300), Step: &step}, "invalid_input"},
+		{"too small", r.UserID, UploadRequest{Data: photo(t, 80, 300)}, "invalid_image_dimensions"},
+		{"not an image", r.UserID, UploadRequest{Data: []byte("hello")}, "unsupported_image"},
+	}
+	for _, tt := range tests {
+		_, err := svc.Upload(ctx, tt.userID, r.ID, tt.req)
+		if appErr, ok := err.(*apperrors.AppError); !ok || appErr.Code != tt.code {
+			t.Errorf("%s: error = %v, want %s", tt.name, err, tt.code)
+		}
+	}
+	if len(repo.images) != 0 || len(storage) != 0 {
+		t.Fatalf("expected rejected uploads to leave nothing behind")
+	}
+
+	for range MaxImages {
+		repo.Create(ctx, &Image{ID: uuid.New(), RecipeID: r.ID})
+	}
+	if _, err := svc.Upload(ctx, r.UserID, r.ID, UploadRequest{Data: photo(t, 300, 300)}); err != ErrGalleryFull {
+		t.Fatalf("expected ErrGalleryFull, got %v", err)
+	}
+}
+
+func TestDeleteMovesCoverAndRemovesFiles(t *testing.T) {
+	svc, _, storage, r := newTestService()
+	ctx := context.Background()
+	first, _ := svc.Upload(ctx, r.UserID, r.ID, UploadRequest{Data: photo(t, 300, 300)})
+	second, _ := svc.Upload(ctx, r.UserID, r.ID, UploadRequest{Data: photo(t, 300, 300)})
+
+	if err := svc.Delete(ctx, r.UserID, r.ID, first.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	images, _ := svc.List(ctx, nil, r.ID)
+	if len(images) != 1 || !images[0].IsCover || *r.ImageURL != second.URL || len(storage) != 3 {
+		t.Fatalf("expected the remaining image to become the cover, got %+v, %v", images, r.ImageURL)
+	}
+	if err := svc.Delete(ctx, r.UserID, r.ID, second.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if r.ImageURL != nil || len(storage) != 0 {
+		t.Fatalf("expected the recipe image and files to be cleared, got %v, %d files", r.ImageURL, len(storage))
+	}
+	if err := svc.Delete(ctx, r.UserID, uuid.New(), second.ID); err != apperrors.ErrRecipeNotFound {
+		t.Fatalf("expected ErrRecipeNotFound, got %v", err)
+	}
+}
+
+func TestUpdateAndReorder(t *testing.T) {
+	svc, _, _, r := newTestService()
+	ctx := context.Background()
+	var ids []uuid.UUID
+	for range 3 {
+		img, err := svc.Upload(ctx, r.UserID, r.ID, UploadRequest{Data: photo(t, 300, 300)})
+		if err != nil {
+			t.Fatalf("upload: %v", err)
+		}
+		ids = append(ids, img.ID)
+	}
+
+	step, alt := 1, "Mixing"
+	img, err := svc.Update(ctx, r.UserID, r.ID, ids[2], UpdateRequest{AltText: &alt, Step: &step, Cover: true})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if img.AltText != "Mixing" || *img.Step != 1 || !img.IsCover || *r.ImageURL != img.URL {
+		t.Fatalf("unexpected image %+v", img)
+	}
+	detach := 0
+	if img, _ = svc.Update(ctx, r.UserID, r.ID, ids[2], UpdateRequest{Step: &detach}); img.Step != nil {
+		t.Fatalf("expected step 0 to detach the image, got %v", *img.Step)
+	}
+
+	images, err := svc.Reorder(ctx, r.UserID, r.ID, []uuid.UUID{ids[2], ids[1]})
+	if err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+	if images[0].ID != ids[2] || images[1].ID != ids[1] || images[2].ID != ids[0] || images[2].Position != 2 {
+		t.Fatalf("unexpected order %+v", images)
+	}
+	if _, err := svc.Reorder(ctx, r.UserID, r.ID, []uuid.UUID{ids[0], ids[0]}); err != ErrOrderMismatch {
+		t.Fatalf("expected ErrOrderMismatch for a repeated image, got %v", err)
+	}
+}
+
+func TestPurgeTrashed(t *testing.T) {
+	svc, repo, storage, r := newTestService()
+	ctx := context.Background()
+	img, _ := svc.Upload(ctx, r.UserID, r.ID, UploadRequest{Data: photo(t, 300, 300)})
+	repo.trashed = []*Image{img}
+
+	n, err := svc.PurgeTrashed(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("purge = %d, %v", n, err)
+	}
+	if len(repo.images) != 0 || len(storage) != 0 {
+		t.Fatalf("expected the image and its files to be deleted")
+	}
+}
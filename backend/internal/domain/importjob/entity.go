@@ -0,0 +1,58 @@
+package importjob
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job statuses.
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Item statuses.
+const (
+	ItemImported  = "imported"
+	ItemDuplicate = "duplicate"
+	ItemFailed    = "failed"
+)
+
+// Job is an import of a file of recipes from another recipe manager into a
+// user's account, run in the background.
+type Job struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	Format string    `json:"format"`
+	Status string    `json:"status"`
+	// ImportDuplicates imports recipes that duplicate one of the user's
+	// recipes instead of skipping them.
+	ImportDuplicates bool `json:"import_duplicates"`
+	Total            int  `json:"total"`
+	Imported         int  `json:"imported"`
+	Duplicates       int  `json:"duplicates"`
+	Failed           int  `json:"failed"`
+	// Items reports the outcome for each recipe in the file, in file order.
+	// It is only populated when a single job is fetched.
+	Items []Item `json:"items,omitempty"`
+	// Error explains why a failed job could not import its file.
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Item is the outcome of importing one recipe of a file.
+type Item struct {
+	// Index is the zero-based position of the recipe in the file.
+	Index    int        `json:"index"`
+	Title    string     `json:"title"`
+	Status   string     `json:"status"`
+	RecipeID *uuid.UUID `json:"recipe_id,omitempty"`
+	// DuplicateOf is the user's recipe that a skipped duplicate matches.
+	DuplicateOf *uuid.UUID `json:"duplicate_of,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
@@ -0,0 +1,21 @@
+package importjob
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository defines persistence operations for import jobs.
+type Repository interface {
+	Create(ctx context.Context, job *Job) error
+	// Update saves the status, counts, items and timestamps of a job.
+	Update(ctx context.Context, job *Job) error
+	// GetByID returns the job with its items.
+	GetByID(ctx context.Context, id uuid.UUID) (*Job, error)
+	// ListByUser returns the user's jobs without their items, newest first.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Job, error)
+	// FailUnfinished marks all pending and running jobs as failed with the
+	// given error.
+	FailUnfinished(ctx context.Context, message string) error
+}
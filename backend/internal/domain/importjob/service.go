@@ -0,0 +1,197 @@
+package importjob
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/logger"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// ErrJobNotFound is returned when an import job does not exist or belongs
+// to another user.
+var ErrJobNotFound = apperrors.New("import_job_not_found", "import job not found", 404)
+
+// progressInterval is the number of recipes imported between saves of a
+// running job's progress.
+const progressInterval = 25
+
+// Service runs imports of other recipe managers' files.
+type Service interface {
+	// Start records an import and runs it in the background. The returned
+	// job is pending; Get reports its progress.
+	Start(ctx context.Context, userID uuid.UUID, req StartRequest) (*Job, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (*Job, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*Job, error)
+	// FailInterrupted marks the jobs a restart left unfinished as failed. It
+	// is called once at startup, before any job is started.
+	FailInterrupted(ctx context.Context) error
+}
+
+// StartRequest describes a file to import. Format is one of the recipe
+// import formats and is detected from the file when empty. Recipes
+// duplicating one of the user's recipes, or an earlier recipe in the file,
+// are skipped unless ImportDuplicates is set.
+type StartRequest struct {
+	Format           string
+	Data             []byte
+	ImportDuplicates bool
+}
+
+type service struct {
+	repo    Repository
+	recipes recipe.Service
+	// run starts a job's work; tests replace it to run jobs synchronously.
+	run func(func())
+}
+
+// NewService creates an import job service.
+func NewService(repo Repository, recipes recipe.Service) Service {
+	return &service{repo: repo, recipes: recipes, run: func(f func()) { go f() }}
+}
+
+func (s *service) Start(ctx context.Context, userID uuid.UUID, req StartRequest) (*Job, error) {
+	format := req.Format
+	if format == "" {
+		format = recipe.DetectFormat(req.Data)
+	}
+	switch format {
+	case recipe.FormatPaprika, recipe.FormatMealMaster, recipe.FormatCSV:
+	default:
+		return nil, validator.InvalidField("format", "format must be one of paprika, mealmaster or csv")
+	}
+	job := &Job{
+		ID:               uuid.New(),
+		UserID:           userID,
+		Format:           format,
+		Status:           StatusPending,
+		ImportDuplicates: req.ImportDuplicates,
+		CreatedAt:        time.Now().UTC(),
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+	started := *job
+	s.run(func() { s.process(context.Background(), job, req.Data) })
+	return &started, nil
+}
+
+func (s *service) Get(ctx context.Context, userID, id uuid.UUID) (*Job, error) {
+	job, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID) ([]*Job, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) FailInterrupted(ctx context.Context) error {
+	return s.repo.FailUnfinished(ctx, "import was interrupted by a restart")
+}
+
+// process imports the recipes of a file, saving the job's progress as it
+// goes. It runs detached from the request that started the job.
+func (s *service) process(ctx context.Context, job *Job, data []byte) {
+	defer func() {
+		if p := recover(); p != nil {
+			logger.FromContext(ctx).Errorw("import job panicked", "job_id", job.ID, "panic", p)
+			s.finish(ctx, job, fmt.Errorf("import failed unexpectedly"))
+		}
+	}()
+
+	now := time.Now().UTC()
+	job.Status, job.StartedAt = StatusRunning, &now
+	s.save(ctx, job)
+
+	items, err := recipe.ParseRecipes(job.Format, data)
+	if err != nil {
+		s.finish(ctx, job, err)
+		return
+	}
+	existing, err := s.recipes.ListByUser(ctx, job.UserID)
+	if err != nil {
+		s.finish(ctx, job, err)
+		return
+	}
+	seen := map[string]uuid.UUID{}
+	for _, r := range existing {
+		seen[fingerprint(r.Title, r.Ingredients)] = r.ID
+	}
+
+	job.Total = len(items)
+	job.Items = make([]Item, 0, len(items))
+	for i, it := range items {
+		item := Item{Index: i, Title: it.Title}
+		key := fingerprint(it.Request.Title, slices.Concat(it.Request.Ingredients, recipe.ParseIngredients(it.Request.IngredientLines)))
+		duplicateOf, duplicate := seen[key]
+		switch {
+		case it.Err != nil:
+			item.Status, item.Error = ItemFailed, it.Err.Error()
+			job.Failed++
+		case duplicate && !job.ImportDuplicates:
+			item.Status, item.DuplicateOf = ItemDuplicate, &duplicateOf
+			job.Duplicates++
+		default:
+			r, err := s.recipes.Create(ctx, job.UserID, it.Request)
+			if err != nil {
+				item.Status, item.Error = ItemFailed, err.Error()
+				job.Failed++
+				break
+			}
+			item.Status, item.RecipeID = ItemImported, &r.ID
+			job.Imported++
+			if !duplicate {
+				seen[key] = r.ID
+			}
+		}
+		job.Items = append(job.Items, item)
+		if (i+1)%progressInterval == 0 {
+			s.save(ctx, job)
+		}
+	}
+	s.finish(ctx, job, nil)
+}
+
+// finish records the end of a job, failed when err is set.
+func (s *service) finish(ctx context.Context, job *Job, err error) {
+	now := time.Now().UTC()
+	job.Status, job.FinishedAt = StatusCompleted, &now
+	if err != nil {
+		job.Status, job.Error = StatusFailed, err.Error()
+	}
+	s.save(ctx, job)
+}
+
+// save stores a job's progress. Failures are logged, since nobody is
+// waiting on the background work to report them to.
+func (s *service) save(ctx context.Context, job *Job) {
+	if err := s.repo.Update(ctx, job); err != nil {
+		logger.FromContext(ctx).Errorw("failed to save import job", "job_id", job.ID, "error", err)
+	}
+}
+
+// fingerprint identifies a recipe by its title and the names of its
+// ingredients, ignoring case, punctuation and order, so that a recipe is
+// recognized when it is imported again after being reformatted.
+func fingerprint(title string, ingredients []recipe.Ingredient) string {
+	names := make([]string, 0, len(ingredients))
+	for _, ing := range ingredients {
+		names = append(names, recipe.NormalizeTag(ing.Name))
+	}
+	sort.Strings(names)
+	return recipe.NormalizeTag(title) + "|" + strings.Join(slices.Compact(names), ",")
+}
@@ -0,0 +1,162 @@
+package importjob
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+type fakeRepository struct {
+	Repository
+	jobs  map[uuid.UUID]*Job
+	saves int
+}
+
+func (f *fakeRepository) Create(ctx context.Context, job *Job) error {
+	copy := *job
+	f.jobs[job.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) Update(ctx context.Context, job *Job) error {
+	copy := *job
+	f.jobs[job.ID] = &copy
+	f.saves++
+	return nil
+}
+
+func (f *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*Job, error) {
+	if job, ok := f.jobs[id]; ok {
+		copy := *job
+		return &copy, nil
+	}
+	return nil, ErrJobNotFound
+}
+
+// fakeRecipes implements the recipe.Service methods used by imports. Calls
+// to any other method panic through the nil embedded interface.
+type fakeRecipes struct {
+	recipe.Service
+	existing []*recipe.Recipe
+	created  []recipe.CreateRequest
+}
+
+func (f *fakeRecipes) ListByUser(ctx context.Context, userID uuid.UUID) ([]*recipe.Recipe, error) {
+	return f.existing, nil
+}
+
+func (f *fakeRecipes) Create(ctx context.Context, userID uuid.UUID, req recipe.CreateRequest) (*recipe.Recipe, error) {
+	if req.Title == "Broken" {
+		return nil, errors.New("recipe must have at least one ingredient")
+	}
+	f.created = append(f.created, req)
+	return &recipe.Recipe{ID: uuid.New(), UserID: userID, Title: req.Title}, nil
+}
+
+const library = `title,ingredients,instructions
+Pancakes,"2 cups flour
+1 egg",Mix and fry.
+"pancakes!","1 Egg
+3 cups flour",Mix and fry.
+Tacos,8 tortillas,Fill.
+,1 onion,Chop.
+Broken,,Nothing.
+Soup,1 onion,Simmer.
+`
+
+func newTestService(existing ...*recipe.Recipe) (*service, *fakeRepository, *fakeRecipes) {
+	repo := &fakeRepository{jobs: map[uuid.UUID]*Job{}}
+	recipes := &fakeRecipes{existing: existing}
+	svc := NewService(repo, recipes).(*service)
+	svc.run = func(f func()) { f() }
+	return svc, repo, recipes
+}
+
+func TestImportReportsEachRecipe(t *testing.T) {
+	soupID := uuid.New()
+	soup := &recipe.Recipe{ID: soupID, Title: "Soup", Ingredients: []recipe.Ingredient{{Name: "onion", Amount: 2}}}
+	svc, _, recipes := newTestService(soup)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	started, err := svc.Start(ctx, userID, StartRequest{Data: []byte(library)})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if started.Status != StatusPending || started.Format != recipe.FormatCSV {
+		t.Fatalf("unexpected started job %+v", started)
+	}
+	job, err := svc.Get(ctx, userID, started.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if job.Status != StatusCompleted || job.Total != 6 || job.Imported != 2 || job.Duplicates != 2 || job.Failed != 2 {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	want := []string{ItemImported, ItemDuplicate, ItemImported, ItemFailed, ItemFailed, ItemDuplicate}
+	for i, item := range job.Items {
+		if item.Status != want[i] {
+			t.Errorf("item %d status = %s, want %s (%+v)", i, item.Status, want[i], item)
+		}
+	}
+	if *job.Items[1].DuplicateOf != *job.Items[0].RecipeID || *job.Items[5].DuplicateOf != soupID {
+		t.Fatalf("expected duplicates to point at the recipes they repeat, got %+v", job.Items)
+	}
+	if job.Items[3].Error != "recipe has no title" || job.Items[4].Error == "" {
+		t.Fatalf("expected failed items to report their errors, got %+v", job.Items)
+	}
+	if len(recipes.created) != 2 {
+		t.Fatalf("expected 2 recipes created, got %d", len(recipes.created))
+	}
+}
+
+func TestImportDuplicatesWhenAsked(t *testing.T) {
+	svc, _, recipes := newTestService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	started, err := svc.Start(ctx, userID, StartRequest{Format: recipe.FormatCSV, Data: []byte(library), ImportDuplicates: true})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	job, _ := svc.Get(ctx, userID, started.ID)
+	if job.Imported != 4 || job.Duplicates != 0 || len(recipes.created) != 4 {
+		t.Fatalf("unexpected job %+v", job)
+	}
+}
+
+func TestImportFailsOnUnreadableFile(t *testing.T) {
+	svc, _, _ := newTestService()
+	ctx := context.Background()
+	userID := uuid.New()
+
+	started, err := svc.Start(ctx, userID, StartRequest{Format: recipe.FormatCSV, Data: []byte("name;;\n")})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	job, _ := svc.Get(ctx, userID, started.ID)
+	if job.Status != StatusFailed || job.Error == "" || job.FinishedAt == nil {
+		t.Fatalf("expected failed job, got %+v", job)
+	}
+
+	if _, err := svc.Start(ctx, userID, StartRequest{Format: recipe.FormatPDF, Data: []byte("x")}); err == nil {
+		t.Fatal("expected unsupported import format to be rejected")
+	}
+}
+
+func TestGetHidesOtherUsersJobs(t *testing.T) {
+	svc, _, _ := newTestService()
+	ctx := context.Background()
+
+	started, err := svc.Start(ctx, uuid.New(), StartRequest{Data: []byte(library)})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if _, err := svc.Get(ctx, uuid.New(), started.ID); err != ErrJobNotFound {
+		t.Fatalf("expected ErrJobNotFound, got %v", err)
+	}
+}
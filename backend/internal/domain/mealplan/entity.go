@@ -0,0 +1,74 @@
+package mealplan
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/pkg/date"
+)
+
+// Meal slots of a day, in the order they are eaten.
+const (
+	SlotBreakfast = "breakfast"
+	SlotLunch     = "lunch"
+	SlotDinner    = "dinner"
+	SlotSnack     = "snack"
+)
+
+// Slots lists the meal slots in the order they are eaten.
+var Slots = []string{SlotBreakfast, SlotLunch, SlotDinner, SlotSnack}
+
+// Plan is a user's plan of meals over a range of days.
+type Plan struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	StartDate date.Date `json:"start_date"`
+	EndDate   date.Date `json:"end_date"`
+	// Entries holds the planned meals by day, slot and position. It is only
+	// populated when a single plan is fetched.
+	Entries   []*Entry  `json:"entries,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Days returns the number of days the plan covers.
+func (p *Plan) Days() int {
+	return p.EndDate.Sub(p.StartDate) + 1
+}
+
+// covers reports whether the day is within the plan.
+func (p *Plan) covers(d date.Date) bool {
+	return !d.Before(p.StartDate) && !d.After(p.EndDate)
+}
+
+// Entry is a meal in a slot of a plan: a recipe cooked for a number of
+// servings, or free text such as "Leftovers" or "Dinner out".
+type Entry struct {
+	ID       uuid.UUID  `json:"id"`
+	PlanID   uuid.UUID  `json:"plan_id"`
+	Date     date.Date  `json:"date"`
+	Slot     string     `json:"slot"`
+	Position int        `json:"position"`
+	RecipeID *uuid.UUID `json:"recipe_id,omitempty"`
+	Servings int        `json:"servings,omitempty"`
+	Text     string     `json:"text,omitempty"`
+	// Recipe is the referenced recipe when the plan's owner may still see
+	// it. It is filled in when a plan is fetched and not persisted.
+	Recipe    *recipe.Recipe `json:"recipe,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// DayNutrition is the nutrition of the meals planned for a day: the
+// nutrition per serving of each recipe times its planned servings.
+type DayNutrition struct {
+	Date      date.Date              `json:"date"`
+	Nutrition recipe.NutritionalInfo `json:"nutrition"`
+	// Recipes counts the recipe entries of the day. Unknown counts those
+	// whose recipe has no nutritional information or can no longer be seen,
+	// so the totals leave them out.
+	Recipes int `json:"recipes"`
+	Unknown int `json:"unknown"`
+}
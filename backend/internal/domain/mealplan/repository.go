@@ -0,0 +1,32 @@
+package mealplan
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/pkg/date"
+)
+
+// Repository defines persistence operations for meal plans.
+type Repository interface {
+	Create(ctx context.Context, p *Plan) error
+	// GetByID returns the plan without its entries.
+	GetByID(ctx context.Context, id uuid.UUID) (*Plan, error)
+	Update(ctx context.Context, p *Plan) error
+	// Delete removes the plan and its entries.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListByUser returns the user's plans, latest start date first.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Plan, error)
+
+	// ListEntries returns the plan's entries ordered by date, slot and
+	// position. Slots are ordered as in Slots.
+	ListEntries(ctx context.Context, planID uuid.UUID) ([]*Entry, error)
+	GetEntry(ctx context.Context, planID, id uuid.UUID) (*Entry, error)
+	// CreateEntries stores entries in one transaction.
+	CreateEntries(ctx context.Context, entries []*Entry) error
+	UpdateEntry(ctx context.Context, e *Entry) error
+	DeleteEntry(ctx context.Context, planID, id uuid.UUID) error
+	// CountOutside counts the plan's entries dated outside from and to.
+	CountOutside(ctx context.Context, planID uuid.UUID, from, to date.Date) (int, error)
+}
@@ -0,0 +1,656 @@
+package mealplan
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/user"
+	"alchemorsel/backend/internal/pkg/date"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/pagination"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// Limits on meal plans. Text is counted in characters after sanitization.
+const (
+	MaxNameLength = 100
+	MaxTextLength = 200
+	MaxDays       = 31
+	MaxServings   = 100
+)
+
+var (
+	// ErrPlanNotFound is returned when a plan does not exist or belongs to
+	// another user.
+	ErrPlanNotFound = apperrors.New("meal_plan_not_found", "meal plan not found", 404)
+	// ErrEntryNotFound is returned when a plan has no such entry.
+	ErrEntryNotFound = apperrors.New("meal_plan_entry_not_found", "meal plan entry not found", 404)
+	// ErrEntriesOutsideRange is returned when new dates would leave planned
+	// meals outside the plan.
+	ErrEntriesOutsideRange = apperrors.New("entries_outside_range", "meals are planned outside the new dates", 409)
+)
+
+// slotWords lists, for each slot, the words in a recipe's category or tags
+// that suit it. Auto-fill only plans breakfasts and snacks with recipes
+// that suit them; lunches and dinners may also take recipes suited to no
+// slot.
+var slotWords = map[string][]string{
+	SlotBreakfast: {"breakfast", "brunch"},
+	SlotLunch:     {"lunch", "salad", "soup", "sandwich"},
+	SlotDinner:    {"dinner", "main", "main course", "main dish", "entree"},
+	SlotSnack:     {"snack", "appetizer", "dessert"},
+}
+
+// Service defines business logic for meal plans. Plans are private to the
+// user who made them.
+type Service interface {
+	Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*Plan, error)
+	// Get returns the plan with its entries and the recipes they refer to.
+	Get(ctx context.Context, userID, id uuid.UUID) (*Plan, error)
+	List(ctx context.Context, userID uuid.UUID) ([]*Plan, error)
+	// Update renames the plan or moves its dates. It returns
+	// ErrEntriesOutsideRange if planned meals would fall outside the dates.
+	Update(ctx context.Context, userID, id uuid.UUID, req UpdateRequest) (*Plan, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+
+	// AddEntry plans a meal at the end of its slot.
+	AddEntry(ctx context.Context, userID, id uuid.UUID, req EntryRequest) (*Entry, error)
+	// UpdateEntry replaces a meal. Moving it to another day or slot puts it
+	// at the end of that slot.
+	UpdateEntry(ctx context.Context, userID, id, entryID uuid.UUID, req EntryRequest) (*Entry, error)
+	RemoveEntry(ctx context.Context, userID, id, entryID uuid.UUID) error
+	// CopyWeek copies the meals of the seven days starting at from to the
+	// seven days starting at to, extending the plan to cover them. Meals
+	// already planned on those days are kept.
+	CopyWeek(ctx context.Context, userID, id uuid.UUID, from, to date.Date) (*Plan, error)
+
+	// Nutrition adds up the nutrition of the meals of each day of the plan.
+	Nutrition(ctx context.Context, userID, id uuid.UUID) ([]*DayNutrition, error)
+	// AutoFill plans recipes for the empty slots of the plan that fit the
+	// user's dietary preferences and none of their allergies, and returns
+	// the plan with the number of meals added.
+	AutoFill(ctx context.Context, userID, id uuid.UUID, req AutoFillRequest) (*Plan, int, error)
+}
+
+// CreateRequest describes a new plan. The name defaults to the week of its
+// start date.
+type CreateRequest struct {
+	Name      string
+	StartDate date.Date
+	EndDate   date.Date
+}
+
+// UpdateRequest changes the set fields of a plan.
+type UpdateRequest struct {
+	Name      *string
+	StartDate *date.Date
+	EndDate   *date.Date
+}
+
+// EntryRequest describes a meal: a recipe and the servings to cook, or
+// free text. Servings default to the recipe's own.
+type EntryRequest struct {
+	Date     date.Date
+	Slot     string
+	RecipeID *uuid.UUID
+	Servings int
+	Text     string
+}
+
+// AutoFillRequest selects the slots to fill. Slots default to breakfast,
+// lunch and dinner and the days to the whole plan; servings default to
+// each recipe's own.
+type AutoFillRequest struct {
+	Slots    []string
+	From     date.Date
+	To       date.Date
+	Servings int
+}
+
+type service struct {
+	repo    Repository
+	recipes recipe.Service
+	users   user.Repository
+}
+
+// NewService creates a meal plan service. Recipes are looked up, and
+// searched for auto-fill, through the recipe service so that only recipes
+// visible to the user are planned.
+func NewService(repo Repository, recipes recipe.Service, users user.Repository) Service {
+	return &service{repo: repo, recipes: recipes, users: users}
+}
+
+func (s *service) Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*Plan, error) {
+	if err := checkDates(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+	name, err := planName(req.Name, req.StartDate)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	p := &Plan{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Entries:   []*Entry{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.Create(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *service) Get(ctx context.Context, userID, id uuid.UUID) (*Plan, error) {
+	p, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Entries, err = s.repo.ListEntries(ctx, id); err != nil {
+		return nil, err
+	}
+	recipes, err := s.loadRecipes(ctx, userID, p.Entries)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range p.Entries {
+		if e.RecipeID != nil {
+			e.Recipe = recipes[*e.RecipeID]
+		}
+	}
+	return p, nil
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID) ([]*Plan, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Update(ctx context.Context, userID, id uuid.UUID, req UpdateRequest) (*Plan, error) {
+	p, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	start, end := p.StartDate, p.EndDate
+	if req.StartDate != nil {
+		start = *req.StartDate
+	}
+	if req.EndDate != nil {
+		end = *req.EndDate
+	}
+	if start != p.StartDate || end != p.EndDate {
+		if err := checkDates(start, end); err != nil {
+			return nil, err
+		}
+		n, err := s.repo.CountOutside(ctx, id, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			return nil, ErrEntriesOutsideRange
+		}
+		p.StartDate, p.EndDate = start, end
+	}
+	if req.Name != nil {
+		if p.Name, err = planName(*req.Name, p.StartDate); err != nil {
+			return nil, err
+		}
+	}
+	p.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) AddEntry(ctx context.Context, userID, id uuid.UUID, req EntryRequest) (*Entry, error) {
+	p, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	e := &Entry{ID: uuid.New(), PlanID: id, CreatedAt: time.Now().UTC()}
+	if err := s.fill(ctx, userID, p, e, req); err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.ListEntries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	e.Position = nextPosition(entries, e.Date, e.Slot)
+	if err := s.repo.CreateEntries(ctx, []*Entry{e}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (s *service) UpdateEntry(ctx context.Context, userID, id, entryID uuid.UUID, req EntryRequest) (*Entry, error) {
+	p, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.repo.GetEntry(ctx, id, entryID)
+	if err != nil {
+		return nil, err
+	}
+	day, slot := e.Date, e.Slot
+	if err := s.fill(ctx, userID, p, e, req); err != nil {
+		return nil, err
+	}
+	if e.Date != day || e.Slot != slot {
+		entries, err := s.repo.ListEntries(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		e.Position = nextPosition(entries, e.Date, e.Slot)
+	}
+	if err := s.repo.UpdateEntry(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (s *service) RemoveEntry(ctx context.Context, userID, id, entryID uuid.UUID) error {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteEntry(ctx, id, entryID)
+}
+
+func (s *service) CopyWeek(ctx context.Context, userID, id uuid.UUID, from, to date.Date) (*Plan, error) {
+	p, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case from.IsZero():
+		return nil, validator.InvalidField("from", "from is required")
+	case to.IsZero():
+		return nil, validator.InvalidField("to", "to is required")
+	case from == to:
+		return nil, validator.InvalidField("to", "to must differ from from")
+	}
+	start, end := p.StartDate, p.EndDate
+	if to.Before(start) {
+		start = to
+	}
+	if last := to.AddDays(6); last.After(end) {
+		end = last
+	}
+	if start != p.StartDate || end != p.EndDate {
+		if err := checkDates(start, end); err != nil {
+			return nil, err
+		}
+		p.StartDate, p.EndDate = start, end
+		p.UpdatedAt = time.Now().UTC()
+		if err := s.repo.Update(ctx, p); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := s.repo.ListEntries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	var copies []*Entry
+	for _, e := range entries {
+		if offset := e.Date.Sub(from); offset < 0 || offset > 6 {
+			continue
+		}
+		c := *e
+		c.ID = uuid.New()
+		c.Date = to.AddDays(e.Date.Sub(from))
+		c.CreatedAt = now
+		c.Position = nextPosition(slices.Concat(entries, copies), c.Date, c.Slot)
+		copies = append(copies, &c)
+	}
+	if err := s.repo.CreateEntries(ctx, copies); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+func (s *service) Nutrition(ctx context.Context, userID, id uuid.UUID) ([]*DayNutrition, error) {
+	p, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	days := make([]*DayNutrition, p.Days())
+	for i := range days {
+		days[i] = &DayNutrition{Date: p.StartDate.AddDays(i)}
+	}
+	for _, e := range p.Entries {
+		if e.RecipeID == nil {
+			continue
+		}
+		day := days[e.Date.Sub(p.StartDate)]
+		day.Recipes++
+		if e.Recipe == nil || e.Recipe.NutritionalInfo == (recipe.NutritionalInfo{}) {
+			day.Unknown++
+			continue
+		}
+		n := e.Recipe.NutritionalInfo.Times(e.Servings)
+		day.Nutrition.Calories += n.Calories
+		day.Nutrition.Protein += n.Protein
+		day.Nutrition.Carbohydrates += n.Carbohydrates
+		day.Nutrition.Fat += n.Fat
+		day.Nutrition.Fiber += n.Fiber
+		day.Nutrition.Sugar += n.Sugar
+		day.Nutrition.Sodium += n.Sodium
+	}
+	return days, nil
+}
+
+func (s *service) AutoFill(ctx context.Context, userID, id uuid.UUID, req AutoFillRequest) (*Plan, int, error) {
+	p, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	slots, err := autoFillSlots(req.Slots)
+	if err != nil {
+		return nil, 0, err
+	}
+	from, to := p.StartDate, p.EndDate
+	if !req.From.IsZero() {
+		from = req.From
+	}
+	if !req.To.IsZero() {
+		to = req.To
+	}
+	if !p.covers(from) || !p.covers(to) || to.Before(from) {
+		return nil, 0, validator.InvalidField("from", "from and to must be days of the plan, in order")
+	}
+	if req.Servings < 0 || req.Servings > MaxServings {
+		return nil, 0, validator.InvalidField("servings", fmt.Sprintf("servings must be between 1 and %d", MaxServings))
+	}
+
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The search hides recipes declaring one of the viewer's allergies.
+	res, err := s.recipes.Search(ctx, recipe.SearchParams{
+		ViewerID:   &userID,
+		Dietary:    u.DietaryPreferences,
+		Sort:       recipe.SortRating,
+		Order:      "desc",
+		Pagination: pagination.Params{PerPage: pagination.MaxPerPage},
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	var pool []*recipe.Recipe
+	for _, hit := range res.Recipes {
+		if len(hit.AllergenWarnings) == 0 {
+			pool = append(pool, hit.Recipe)
+		}
+	}
+
+	entries, err := s.repo.ListEntries(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	planned := map[string]bool{}
+	for _, e := range entries {
+		planned[e.Date.String()+e.Slot] = true
+	}
+	picker := newPicker(pool)
+	now := time.Now().UTC()
+	var added []*Entry
+	for day := from; !day.After(to); day = day.AddDays(1) {
+		picker.nextDay()
+		for _, slot := range slots {
+			if planned[day.String()+slot] {
+				continue
+			}
+			r := picker.pick(slot)
+			if r == nil {
+				continue
+			}
+			recipeID := r.ID
+			added = append(added, &Entry{
+				ID:        uuid.New(),
+				PlanID:    p.ID,
+				Date:      day,
+				Slot:      slot,
+				RecipeID:  &recipeID,
+				Servings:  servingsFor(req.Servings, r),
+				CreatedAt: now,
+			})
+		}
+	}
+	if err := s.repo.CreateEntries(ctx, added); err != nil {
+		return nil, 0, err
+	}
+	p, err = s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p, len(added), nil
+}
+
+// fill validates req and copies it onto e.
+func (s *service) fill(ctx context.Context, userID uuid.UUID, p *Plan, e *Entry, req EntryRequest) error {
+	switch {
+	case req.Date.IsZero():
+		return validator.InvalidField("date", "date is required")
+	case !p.covers(req.Date):
+		return validator.InvalidField("date", fmt.Sprintf("date must be between %s and %s", p.StartDate, p.EndDate))
+	case !slices.Contains(Slots, req.Slot):
+		return validator.InvalidField("slot", "slot must be one of "+strings.Join(Slots, ", "))
+	case req.RecipeID != nil && strings.TrimSpace(req.Text) != "":
+		return validator.InvalidField("text", "an entry has either a recipe or text")
+	case req.Servings < 0 || req.Servings > MaxServings:
+		return validator.InvalidField("servings", fmt.Sprintf("servings must be between 1 and %d", MaxServings))
+	}
+	e.Date, e.Slot = req.Date, req.Slot
+	e.RecipeID, e.Recipe, e.Servings, e.Text = nil, nil, 0, ""
+	if req.RecipeID == nil {
+		text, err := validator.Text("text", req.Text, 1, MaxTextLength)
+		if err != nil {
+			return err
+		}
+		e.Text = text
+		return nil
+	}
+	r, err := s.recipes.Get(ctx, &userID, *req.RecipeID)
+	if err != nil {
+		return err
+	}
+	e.RecipeID, e.Recipe, e.Servings = &r.ID, r, servingsFor(req.Servings, r)
+	return nil
+}
+
+// loadRecipes looks up the recipes the entries refer to. Recipes the user
+// can no longer see are left out.
+func (s *service) loadRecipes(ctx context.Context, userID uuid.UUID, entries []*Entry) (map[uuid.UUID]*recipe.Recipe, error) {
+	recipes := map[uuid.UUID]*recipe.Recipe{}
+	for _, e := range entries {
+		if e.RecipeID == nil {
+			continue
+		}
+		if _, ok := recipes[*e.RecipeID]; ok {
+			continue
+		}
+		r, err := s.recipes.Get(ctx, &userID, *e.RecipeID)
+		if err == apperrors.ErrRecipeNotFound {
+			r, err = nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		recipes[*e.RecipeID] = r
+	}
+	return recipes, nil
+}
+
+// getOwned loads a plan of the user. Other users' plans are reported as
+// missing.
+func (s *service) getOwned(ctx context.Context, userID, id uuid.UUID) (*Plan, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.UserID != userID {
+		return nil, ErrPlanNotFound
+	}
+	return p, nil
+}
+
+// picker chooses recipes for auto-fill. It prefers the least used recipes,
+// avoids those planned the day before and, for lunch and dinner, prefers
+// recipes suited to the slot over those suited to none. A recipe is planned
+// at most once a day.
+type picker struct {
+	pool      []*recipe.Recipe
+	uses      map[uuid.UUID]int
+	today     map[uuid.UUID]bool
+	yesterday map[uuid.UUID]bool
+}
+
+func newPicker(pool []*recipe.Recipe) *picker {
+	return &picker{pool: pool, uses: map[uuid.UUID]int{}, today: map[uuid.UUID]bool{}}
+}
+
+// nextDay starts planning the next day.
+func (p *picker) nextDay() {
+	p.yesterday, p.today = p.today, map[uuid.UUID]bool{}
+}
+
+// pick returns the recipe with the lowest score for the slot, the best
+// ranked first among equals, or nil when no recipe suits it.
+func (p *picker) pick(slot string) *recipe.Recipe {
+	var best *recipe.Recipe
+	bestScore := 0
+	for _, r := range p.pool {
+		if p.today[r.ID] {
+			continue
+		}
+		score := 2 * p.uses[r.ID]
+		if p.yesterday[r.ID] {
+			score += 3
+		}
+		switch {
+		case suits(r, slot):
+		case (slot == SlotLunch || slot == SlotDinner) && neutral(r):
+			score++
+		default:
+			continue
+		}
+		if best == nil || score < bestScore {
+			best, bestScore = r, score
+		}
+	}
+	if best != nil {
+		p.uses[best.ID]++
+		p.today[best.ID] = true
+	}
+	return best
+}
+
+// suits reports whether a recipe's category or tags name the slot.
+func suits(r *recipe.Recipe, slot string) bool {
+	labels := append([]string{strings.ToLower(r.Category)}, r.Tags...)
+	for _, l := range labels {
+		for _, w := range slotWords[slot] {
+			if l == w || strings.ReplaceAll(l, "-", " ") == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// neutral reports whether a recipe suits no slot in particular.
+func neutral(r *recipe.Recipe) bool {
+	for _, slot := range Slots {
+		if suits(r, slot) {
+			return false
+		}
+	}
+	return true
+}
+
+// autoFillSlots checks the slots to fill, defaulting to the three main
+// meals, and returns them in the order they are eaten.
+func autoFillSlots(slots []string) ([]string, error) {
+	if len(slots) == 0 {
+		return []string{SlotBreakfast, SlotLunch, SlotDinner}, nil
+	}
+	var out []string
+	for _, slot := range Slots {
+		if slices.Contains(slots, slot) {
+			out = append(out, slot)
+		}
+	}
+	for _, slot := range slots {
+		if !slices.Contains(Slots, slot) {
+			return nil, validator.InvalidField("slots", "slots must be among "+strings.Join(Slots, ", "))
+		}
+	}
+	return out, nil
+}
+
+// servingsFor returns the servings requested, or the recipe's own, or one.
+func servingsFor(servings int, r *recipe.Recipe) int {
+	switch {
+	case servings > 0:
+		return servings
+	case r.Servings > 0:
+		return r.Servings
+	}
+	return 1
+}
+
+// nextPosition returns the position after the last entry of a slot.
+func nextPosition(entries []*Entry, day date.Date, slot string) int {
+	n := 0
+	for _, e := range entries {
+		if e.Date == day && e.Slot == slot {
+			n = max(n, e.Position+1)
+		}
+	}
+	return n
+}
+
+func checkDates(start, end date.Date) error {
+	switch {
+	case start.IsZero():
+		return validator.InvalidField("start_date", "start_date is required")
+	case end.IsZero():
+		return validator.InvalidField("end_date", "end_date is required")
+	case end.Before(start):
+		return validator.InvalidField("end_date", "end_date must not be before start_date")
+	case end.Sub(start) >= MaxDays:
+		return validator.InvalidField("end_date", fmt.Sprintf("a plan covers at most %d days", MaxDays))
+	}
+	return nil
+}
+
+// planName checks a plan's name, naming unnamed plans after their first day.
+func planName(name string, start date.Date) (string, error) {
+	name, err := validator.Text("name", name, 0, MaxNameLength)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = "Week of " + start.String()
+	}
+	return name, nil
+}
@@ -0,0 +1,332 @@
+package mealplan
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/recipe/recipetest"
+	"alchemorsel/backend/internal/domain/user"
+	"alchemorsel/backend/internal/domain/user/usertest"
+	"alchemorsel/backend/internal/pkg/date"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+type fakeRepository struct {
+	Repository
+	plans   map[uuid.UUID]*Plan
+	entries map[uuid.UUID]*Entry
+}
+
+func (f *fakeRepository) Create(ctx context.Context, p *Plan) error {
+	copy := *p
+	f.plans[p.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*Plan, error) {
+	if p, ok := f.plans[id]; ok {
+		copy := *p
+		return &copy, nil
+	}
+	return nil, ErrPlanNotFound
+}
+
+func (f *fakeRepository) Update(ctx context.Context, p *Plan) error {
+	copy := *p
+	f.plans[p.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) ListEntries(ctx context.Context, planID uuid.UUID) ([]*Entry, error) {
+	entries := []*Entry{}
+	for _, e := range f.entries {
+		if e.PlanID == planID {
+			copy := *e
+			copy.Recipe = nil
+			entries = append(entries, &copy)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Date != b.Date {
+			return a.Date.Before(b.Date)
+		}
+		if a.Slot != b.Slot {
+			return slotIndex(a.Slot) < slotIndex(b.Slot)
+		}
+		return a.Position < b.Position
+	})
+	return entries, nil
+}
+
+func slotIndex(slot string) int {
+	for i, s := range Slots {
+		if s == slot {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *fakeRepository) GetEntry(ctx context.Context, planID, id uuid.UUID) (*Entry, error) {
+	if e, ok := f.entries[id]; ok && e.PlanID == planID {
+		copy := *e
+		return &copy, nil
+	}
+	return nil, ErrEntryNotFound
+}
+
+func (f *fakeRepository) CreateEntries(ctx context.Context, entries []*Entry) error {
+	for _, e := range entries {
+		copy := *e
+		f.entries[e.ID] = &copy
+	}
+	return nil
+}
+
+func (f *fakeRepository) UpdateEntry(ctx context.Context, e *Entry) error {
+	copy := *e
+	f.entries[e.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) CountOutside(ctx context.Context, planID uuid.UUID, from, to date.Date) (int, error) {
+	n := 0
+	for _, e := range f.entries {
+		if e.PlanID == planID && (e.Date.Before(from) || e.Date.After(to)) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// fakeRecipes adds a search over the recipes, in the order given, to the
+// shared in-memory recipes.
+type fakeRecipes struct {
+	*recipetest.Recipes
+	ordered []*recipe.Recipe
+	search  recipe.SearchParams
+}
+
+// Search returns the public recipes in order, flagging those containing
+// eggs as the search does for a user allergic to them.
+func (f *fakeRecipes) Search(ctx context.Context, params recipe.SearchParams) (*recipe.SearchResult, error) {
+	f.search = params
+	res := &recipe.SearchResult{}
+	for _, r := range f.ordered {
+		if !r.IsPublic {
+			continue
+		}
+		hit := *r
+		for _, a := range r.Allergens {
+			if a == "eggs" {
+				hit.AllergenWarnings = []string{"eggs"}
+			}
+		}
+		res.Recipes = append(res.Recipes, &recipe.SearchHit{Recipe: &hit})
+	}
+	return res, nil
+}
+
+func newTestService(recipes ...*recipe.Recipe) (Service, *fakeRepository, *fakeRecipes, uuid.UUID) {
+	userID := uuid.New()
+	repo := &fakeRepository{plans: map[uuid.UUID]*Plan{}, entries: map[uuid.UUID]*Entry{}}
+	rs := &fakeRecipes{Recipes: recipetest.NewRecipes(recipes...), ordered: recipes}
+	users := usertest.NewUsers(&user.User{ID: userID, DietaryPreferences: []string{"vegetarian"}, Allergies: []string{"eggs"}})
+	return NewService(repo, rs, users), repo, rs, userID
+}
+
+func newRecipe(title, category string, servings int, nutrition recipe.NutritionalInfo) *recipe.Recipe {
+	return &recipe.Recipe{ID: uuid.New(), UserID: uuid.New(), Title: title, Category: category, Servings: servings,
+		NutritionalInfo: nutrition, IsPublic: true}
+}
+
+var monday = date.New(2024, time.June, 3)
+
+func TestCreateChecksDates(t *testing.T) {
+	svc, _, _, userID := newTestService()
+	ctx := context.Background()
+
+	p, err := svc.Create(ctx, userID, CreateRequest{StartDate: monday, EndDate: monday.AddDays(6)})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if p.Name != "Week of 2024-06-03" || p.Days() != 7 {
+		t.Fatalf("unexpected plan %+v", p)
+	}
+	for name, req := range map[string]CreateRequest{
+		"missing end":  {StartDate: monday},
+		"backwards":    {StartDate: monday, EndDate: monday.AddDays(-1)},
+		"too long":     {StartDate: monday, EndDate: monday.AddDays(MaxDays)},
+		"long name":    {Name: strings.Repeat("a", MaxNameLength+1), StartDate: monday, EndDate: monday},
+		"missing both": {},
+	} {
+		if _, err := svc.Create(ctx, userID, req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEntries(t *testing.T) {
+	soup := newRecipe("Soup", "Soup", 4, recipe.NutritionalInfo{})
+	private := newRecipe("Secret", "", 2, recipe.NutritionalInfo{})
+	private.IsPublic = false
+	svc, _, _, userID := newTestService(soup, private)
+	ctx := context.Background()
+	p, _ := svc.Create(ctx, userID, CreateRequest{StartDate: monday, EndDate: monday.AddDays(6)})
+
+	first, err := svc.AddEntry(ctx, userID, p.ID, EntryRequest{Date: monday, Slot: SlotLunch, RecipeID: &soup.ID})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	second, err := svc.AddEntry(ctx, userID, p.ID, EntryRequest{Date: monday, Slot: SlotLunch, Text: " Bread "})
+	if err != nil {
+		t.Fatalf("add text: %v", err)
+	}
+	if first.Servings != 4 || first.Position != 0 || second.Text != "Bread" || second.Position != 1 {
+		t.Fatalf("unexpected entries %+v, %+v", first, second)
+	}
+
+	tests := []struct {
+		name string
+		req  EntryRequest
+		code string
+	}{
+		{"outside the plan", EntryRequest{Date: monday.AddDays(7), Slot: SlotLunch, Text: "x"}, "invalid_input"},
+		{"unknown slot", EntryRequest{Date: monday, Slot: "brunch", Text: "x"}, "invalid_input"},
+		{"recipe and text", EntryRequest{Date: monday, Slot: SlotLunch, RecipeID: &soup.ID, Text: "x"}, "invalid_input"},
+		{"neither", EntryRequest{Date: monday, Slot: SlotLunch}, "invalid_input"},
+		{"too many servings", EntryRequest{Date: monday, Slot: SlotLunch, RecipeID: &soup.ID, Servings: MaxServings + 1}, "invalid_input"},
+		{"hidden recipe", EntryRequest{Date: monday, Slot: SlotLunch, RecipeID: &private.ID}, "recipe_not_found"},
+	}
+	for _, tt := range tests {
+		_, err := svc.AddEntry(ctx, userID, p.ID, tt.req)
+		if appErr, ok := err.(*apperrors.AppError); !ok || appErr.Code != tt.code {
+			t.Errorf("%s: error = %v, want %s", tt.name, err, tt.code)
+		}
+	}
+	if _, err := svc.AddEntry(ctx, uuid.New(), p.ID, EntryRequest{Date: monday, Slot: SlotLunch, Text: "x"}); err != ErrPlanNotFound {
+		t.Fatalf("expected other users' plans to be hidden, got %v", err)
+	}
+
+	moved, err := svc.UpdateEntry(ctx, userID, p.ID, first.ID, EntryRequest{Date: monday.AddDays(1), Slot: SlotDinner, RecipeID: &soup.ID, Servings: 2})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if moved.Servings != 2 || moved.Slot != SlotDinner || moved.Position != 0 {
+		t.Fatalf("unexpected moved entry %+v", moved)
+	}
+	if _, err := svc.Update(ctx, userID, p.ID, UpdateRequest{StartDate: ptr(monday.AddDays(2))}); err != ErrEntriesOutsideRange {
+		t.Fatalf("expected ErrEntriesOutsideRange, got %v", err)
+	}
+}
+
+func TestCopyWeekExtendsPlan(t *testing.T) {
+	soup := newRecipe("Soup", "Soup", 4, recipe.NutritionalInfo{})
+	svc, repo, _, userID := newTestService(soup)
+	ctx := context.Background()
+	p, _ := svc.Create(ctx, userID, CreateRequest{StartDate: monday, EndDate: monday.AddDays(6)})
+	svc.AddEntry(ctx, userID, p.ID, EntryRequest{Date: monday, Slot: SlotLunch, RecipeID: &soup.ID})
+	svc.AddEntry(ctx, userID, p.ID, EntryRequest{Date: monday.AddDays(6), Slot: SlotDinner, Text: "Pizza night"})
+
+	p, err := svc.CopyWeek(ctx, userID, p.ID, monday, monday.AddDays(7))
+	if err != nil {
+		t.Fatalf("copy week: %v", err)
+	}
+	if p.EndDate != monday.AddDays(13) || len(p.Entries) != 4 {
+		t.Fatalf("expected the plan to cover two weeks with 4 meals, got %s and %d", p.EndDate, len(p.Entries))
+	}
+	copied := p.Entries[2]
+	if copied.Date != monday.AddDays(7) || *copied.RecipeID != soup.ID || copied.Recipe == nil || copied.Servings != 4 {
+		t.Fatalf("unexpected copy %+v", copied)
+	}
+	if p.Entries[3].Text != "Pizza night" || p.Entries[3].Date != monday.AddDays(13) {
+		t.Fatalf("unexpected copy %+v", p.Entries[3])
+	}
+	if len(repo.entries) != 4 {
+		t.Fatalf("expected the originals to stay, got %d entries", len(repo.entries))
+	}
+	if _, err := svc.CopyWeek(ctx, userID, p.ID, monday, monday.AddDays(30)); err == nil {
+		t.Fatal("expected copying past the longest plan to fail")
+	}
+}
+
+func TestNutritionAddsUpPlannedServings(t *testing.T) {
+	oats := newRecipe("Oats", "Breakfast", 1, recipe.NutritionalInfo{Calories: 300, Protein: 10, Sodium: 100})
+	stew := newRecipe("Stew", "Main Course", 4, recipe.NutritionalInfo{Calories: 500, Protein: 30, Fat: 20})
+	mystery := newRecipe("Mystery", "", 2, recipe.NutritionalInfo{})
+	svc, _, _, userID := newTestService(oats, stew, mystery)
+	ctx := context.Background()
+	p, _ := svc.Create(ctx, userID, CreateRequest{StartDate: monday, EndDate: monday.AddDays(1)})
+	svc.AddEntry(ctx, userID, p.ID, EntryRequest{Date: monday, Slot: SlotBreakfast, RecipeID: &oats.ID})
+	svc.AddEntry(ctx, userID, p.ID, EntryRequest{Date: monday, Slot: SlotDinner, RecipeID: &stew.ID, Servings: 2})
+	svc.AddEntry(ctx, userID, p.ID, EntryRequest{Date: monday, Slot: SlotSnack, RecipeID: &mystery.ID})
+	svc.AddEntry(ctx, userID, p.ID, EntryRequest{Date: monday, Slot: SlotSnack, Text: "An apple"})
+
+	days, err := svc.Nutrition(ctx, userID, p.ID)
+	if err != nil {
+		t.Fatalf("nutrition: %v", err)
+	}
+	want := []*DayNutrition{
+		{Date: monday, Nutrition: recipe.NutritionalInfo{Calories: 1300, Protein: 70, Fat: 40, Sodium: 100}, Recipes: 3, Unknown: 1},
+		{Date: monday.AddDays(1)},
+	}
+	if !reflect.DeepEqual(days, want) {
+		t.Fatalf("nutrition = %+v, want %+v", days, want)
+	}
+}
+
+func TestAutoFillHonorsPreferencesAndVaries(t *testing.T) {
+	pancakes := newRecipe("Pancakes", "Breakfast", 4, recipe.NutritionalInfo{})
+	pancakes.Allergens = []string{"eggs"}
+	porridge := newRecipe("Porridge", "Breakfast", 2, recipe.NutritionalInfo{})
+	curry := newRecipe("Curry", "Main Course", 4, recipe.NutritionalInfo{})
+	salad := newRecipe("Salad", "Salad", 2, recipe.NutritionalInfo{})
+	tacos := newRecipe("Tacos", "Dinner", 4, recipe.NutritionalInfo{})
+	risotto := newRecipe("Risotto", "", 4, recipe.NutritionalInfo{})
+	svc, repo, rs, userID := newTestService(pancakes, porridge, curry, salad, tacos, risotto)
+	ctx := context.Background()
+	p, _ := svc.Create(ctx, userID, CreateRequest{StartDate: monday, EndDate: monday.AddDays(2)})
+	svc.AddEntry(ctx, userID, p.ID, EntryRequest{Date: monday, Slot: SlotDinner, Text: "Dinner out"})
+
+	p, added, err := svc.AutoFill(ctx, userID, p.ID, AutoFillRequest{Servings: 2})
+	if err != nil {
+		t.Fatalf("auto-fill: %v", err)
+	}
+	if rs.search.ViewerID == nil || *rs.search.ViewerID != userID || !reflect.DeepEqual(rs.search.Dietary, []string{"vegetarian"}) {
+		t.Fatalf("expected a search for the user's diets, got %+v", rs.search)
+	}
+	if added != 8 || len(repo.entries) != 9 {
+		t.Fatalf("expected 8 meals added around the planned dinner, got %d", added)
+	}
+	var dinners []string
+	for _, e := range p.Entries {
+		switch {
+		case e.Text != "":
+			if e.Date != monday || e.Slot != SlotDinner {
+				t.Fatalf("expected the planned dinner to stay, got %+v", e)
+			}
+		case e.Slot == SlotBreakfast && e.Recipe.ID != porridge.ID:
+			t.Fatalf("expected only porridge for breakfast, got %s", e.Recipe.Title)
+		case e.Servings != 2:
+			t.Fatalf("expected 2 servings, got %+v", e)
+		case e.Slot == SlotDinner:
+			dinners = append(dinners, e.Recipe.Title)
+		}
+	}
+	if !reflect.DeepEqual(dinners, []string{"Curry", "Tacos"}) {
+		t.Fatalf("expected varied dinners, got %v", dinners)
+	}
+	if _, _, err := svc.AutoFill(ctx, userID, p.ID, AutoFillRequest{Slots: []string{"elevenses"}}); err == nil {
+		t.Fatal("expected an unknown slot to be rejected")
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
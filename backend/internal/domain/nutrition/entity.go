@@ -0,0 +1,20 @@
+// Package nutrition estimates the nutrition of recipes from a database of
+// foods imported from USDA FoodData Central.
+package nutrition
+
+// Food is an entry of the nutrient database. Nutrients are per 100 g: calories
+// in kcal, sodium in milligrams and the rest in grams.
+type Food struct {
+	ID            int64   `json:"id"` // FoodData Central fdc_id
+	Description   string  `json:"description"`
+	Calories      float64 `json:"calories"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Fat           float64 `json:"fat"`
+	Fiber         float64 `json:"fiber"`
+	Sugar         float64 `json:"sugar"`
+	Sodium        float64 `json:"sodium"`
+	// PortionGrams is the weight of one piece, such as one large egg, or 0
+	// when unknown.
+	PortionGrams float64 `json:"portion_grams"`
+}
@@ -0,0 +1,196 @@
+package nutrition
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"alchemorsel/backend/internal/pkg/units"
+)
+
+// FoodData Central nutrient ids. Foundation foods report energy under the
+// Atwater ids rather than 1008, and sugars under either total id.
+const (
+	nutrientEnergy         = 1008
+	nutrientEnergyGeneral  = 2047
+	nutrientEnergySpecific = 2048
+	nutrientProtein        = 1003
+	nutrientFat            = 1004
+	nutrientCarbohydrates  = 1005
+	nutrientFiber          = 1079
+	nutrientSugars         = 2000
+	nutrientSugarsNLEA     = 1063
+	nutrientSodium         = 1093
+)
+
+// importedTypes are the FoodData Central data types describing generic
+// foods. Branded foods are left out.
+var importedTypes = map[string]bool{
+	"foundation_food":   true,
+	"sr_legacy_food":    true,
+	"survey_fndds_food": true,
+}
+
+// ReadFDC reads foods from a FoodData Central CSV download: food.csv and
+// food_nutrient.csv, and food_portion.csv when present for the weight of one
+// piece of each food. Foods are returned in id order.
+func ReadFDC(fsys fs.FS) ([]*Food, error) {
+	foods := map[int64]*Food{}
+	err := readCSV(fsys, "food.csv", []string{"fdc_id", "data_type", "description"}, func(rec []string) error {
+		if !importedTypes[rec[1]] {
+			return nil
+		}
+		id, err := strconv.ParseInt(rec[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		foods[id] = &Food{ID: id, Description: strings.TrimSpace(rec[2])}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := map[int64]map[int]float64{}
+	err = readCSV(fsys, "food_nutrient.csv", []string{"fdc_id", "nutrient_id", "amount"}, func(rec []string) error {
+		id, err := strconv.ParseInt(rec[0], 10, 64)
+		if err != nil || foods[id] == nil {
+			return err
+		}
+		nutrient, err := strconv.Atoi(rec[1])
+		if err != nil || rec[2] == "" {
+			return err
+		}
+		amount, err := strconv.ParseFloat(rec[2], 64)
+		if err != nil {
+			return err
+		}
+		if amounts[id] == nil {
+			amounts[id] = map[int]float64{}
+		}
+		amounts[id][nutrient] = amount
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readCSV(fsys, "food_portion.csv", []string{"fdc_id", "amount", "modifier", "portion_description", "gram_weight"}, func(rec []string) error {
+		id, err := strconv.ParseInt(rec[0], 10, 64)
+		if err != nil || foods[id] == nil || foods[id].PortionGrams > 0 {
+			return err
+		}
+		grams, ok := pieceWeight(rec[1], rec[2], rec[3], rec[4])
+		if ok {
+			foods[id].PortionGrams = grams
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	out := make([]*Food, 0, len(foods))
+	for id, f := range foods {
+		n := amounts[id]
+		f.Calories = first(n, nutrientEnergy, nutrientEnergyGeneral, nutrientEnergySpecific)
+		f.Protein = n[nutrientProtein]
+		f.Fat = n[nutrientFat]
+		f.Carbohydrates = n[nutrientCarbohydrates]
+		f.Fiber = n[nutrientFiber]
+		f.Sugar = first(n, nutrientSugars, nutrientSugarsNLEA)
+		f.Sodium = n[nutrientSodium]
+		out = append(out, f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// pieceWeight returns the weight of one piece from a food portion, such as
+// "1 large" egg weighing 50 g. Portions measured in units such as cups are
+// not pieces.
+func pieceWeight(amount, modifier, description, gramWeight string) (float64, bool) {
+	label := strings.TrimSpace(modifier)
+	if label == "" {
+		label = strings.TrimSpace(description)
+	}
+	name, _, _ := strings.Cut(label, " ")
+	if _, ok := units.Lookup(name); ok || name == "" {
+		return 0, false
+	}
+	grams, err := strconv.ParseFloat(gramWeight, 64)
+	if err != nil || grams <= 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(amount, 64)
+	if err != nil || n <= 0 {
+		n = 1
+	}
+	return grams / n, true
+}
+
+// first returns the first of the nutrients present in n.
+func first(n map[int]float64, ids ...int) float64 {
+	for _, id := range ids {
+		if v, ok := n[id]; ok {
+			return v
+		}
+	}
+	return 0
+}
+
+// readCSV calls fn for each record of the named file with the values of the
+// given columns, which are located by the header row.
+func readCSV(fsys fs.FS, name string, columns []string, fn func([]string) error) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// Skip the byte order mark some downloads start with.
+	br := bufio.NewReader(f)
+	if c, _, err := br.ReadRune(); err == nil && c != '\ufeff' {
+		br.UnreadRune()
+	}
+	r := csv.NewReader(br)
+	r.ReuseRecord = true
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	index := make([]int, len(columns))
+	for i, col := range columns {
+		index[i] = -1
+		for j, h := range header {
+			if h == col {
+				index[i] = j
+			}
+		}
+		if index[i] < 0 {
+			return fmt.Errorf("%s: missing column %q", name, col)
+		}
+	}
+	values := make([]string, len(columns))
+	for line := 2; ; line++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for i, j := range index {
+			values[i] = rec[j]
+		}
+		if err := fn(values); err != nil {
+			return fmt.Errorf("%s line %d: %w", name, line, err)
+		}
+	}
+}
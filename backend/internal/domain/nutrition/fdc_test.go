@@ -0,0 +1,63 @@
+package nutrition
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadFDC(t *testing.T) {
+	fsys := fstest.MapFS{
+		"food.csv": {Data: []byte("\ufeff" + `"fdc_id","data_type","description","food_category_id","publication_date"
+"171287","sr_legacy_food","Egg, whole, raw, fresh","1","2019-04-01"
+"173430","sr_legacy_food","Butter, salted","1","2019-04-01"
+"2000001","branded_food","ACME BUTTER","","2021-01-01"
+"748967","foundation_food","Eggs, Grade A, Large, egg whole","1","2019-12-16"
+`)},
+		"food_nutrient.csv": {Data: []byte(`"id","fdc_id","nutrient_id","amount","data_points"
+"1","171287","1008","143",""
+"2","171287","1003","12.56",""
+"3","171287","1004","9.51",""
+"4","171287","1093","142",""
+"5","173430","1008","717",""
+"6","173430","1004","81.11",""
+"7","173430","2000","0.06",""
+"8","2000001","1008","700",""
+"9","748967","2047","148",""
+"10","748967","1063","0.2",""
+"11","748967","1079","",""
+`)},
+		"food_portion.csv": {Data: []byte(`"id","fdc_id","seq_num","amount","measure_unit_id","portion_description","modifier","gram_weight"
+"1","171287","1","1","9999","","cup (4.86 large eggs)","243"
+"2","171287","2","1","9999","","large","50"
+"3","171287","3","1","9999","","medium","44"
+"4","173430","1","1","9999","","tbsp","14.2"
+"5","173430","2","1","9999","","pat (1"" sq, 1/3"" high)","5"
+`)},
+	}
+
+	foods, err := ReadFDC(fsys)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := []*Food{
+		{ID: 171287, Description: "Egg, whole, raw, fresh", Calories: 143, Protein: 12.56, Fat: 9.51, Sodium: 142, PortionGrams: 50},
+		{ID: 173430, Description: "Butter, salted", Calories: 717, Fat: 81.11, Sugar: 0.06, PortionGrams: 5},
+		{ID: 748967, Description: "Eggs, Grade A, Large, egg whole", Calories: 148, Sugar: 0.2},
+	}
+	if !reflect.DeepEqual(foods, want) {
+		for _, f := range foods {
+			t.Logf("%+v", *f)
+		}
+		t.Fatalf("unexpected foods")
+	}
+
+	delete(fsys, "food_portion.csv")
+	if _, err := ReadFDC(fsys); err != nil {
+		t.Fatalf("expected food_portion.csv to be optional, got %v", err)
+	}
+	delete(fsys, "food_nutrient.csv")
+	if _, err := ReadFDC(fsys); err == nil {
+		t.Fatalf("expected an error without food_nutrient.csv")
+	}
+}
@@ -0,0 +1,157 @@
+package nutrition
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minScore is the lowest match score at which an ingredient is considered to
+// be the food.
+const minScore = 0.5
+
+// stopWords carry no meaning in either ingredient names or food
+// descriptions.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "or": true, "of": true, "the": true, "with": true,
+	"without": true, "for": true, "in": true, "to": true, "into": true, "at": true,
+}
+
+// descriptors describe how an ingredient is prepared or sized rather than
+// what it is.
+var descriptors = map[string]bool{
+	"chopped": true, "diced": true, "minced": true, "sliced": true, "grated": true,
+	"shredded": true, "crushed": true, "ground": true, "peeled": true, "cubed": true,
+	"halved": true, "quartered": true, "trimmed": true, "softened": true, "melted": true,
+	"beaten": true, "sifted": true, "packed": true, "finely": true, "roughly": true,
+	"thinly": true, "coarsely": true, "freshly": true, "large": true, "medium": true,
+	"small": true, "extra": true, "fresh": true, "optional": true, "divided": true,
+	"taste": true, "room": true, "temperature": true, "cold": true, "warm": true,
+	"hot": true, "about": true, "plus": true, "more": true, "needed": true,
+	"serving": true, "garnish": true, "cut": true, "piece": true,
+	"inch": true, "cm": true, "torn": true, "rinsed": true, "drained": true,
+}
+
+// neutralWords appear in food descriptions without distinguishing the food
+// from its variants: "Egg, whole, raw, fresh" is simply an egg.
+var neutralWords = map[string]bool{
+	"raw": true, "whole": true, "fresh": true, "plain": true, "regular": true,
+	"commercial": true, "nfs": true, "ns": true, "prepared": true, "all": true,
+	"type": true, "variety": true, "varieties": true, "form": true,
+}
+
+// phrases rewrites the ways food descriptions say what recipes call
+// unsalted.
+var phrases = strings.NewReplacer("without salt", "unsalted", "no salt added", "unsalted")
+
+// words splits text into lowercase, singular words, dropping stop words.
+func words(text string) []string {
+	fields := strings.FieldsFunc(phrases.Replace(strings.ToLower(text)), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if len(f) < 2 || stopWords[f] {
+			continue
+		}
+		out = append(out, singular(f))
+	}
+	return out
+}
+
+// ingredientWords returns the distinct words naming the food in an
+// ingredient, without notes in parentheses, preparation descriptors or
+// neutral words.
+func ingredientWords(name string) []string {
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		if j := strings.IndexByte(name[i:], ')'); j >= 0 {
+			name = name[:i] + " " + name[i+j+1:]
+		}
+	}
+	var out []string
+	seen := map[string]bool{}
+	for _, w := range words(name) {
+		if descriptors[w] || neutralWords[w] || seen[w] {
+			continue
+		}
+		seen[w] = true
+		out = append(out, w)
+	}
+	return out
+}
+
+// score rates how well a food description matches the ingredient words,
+// from 0 to 1. It rewards covering the ingredient's words, matching the main
+// food named before the first comma of the description, and descriptions
+// without unrelated words.
+func score(ingredient []string, description string) float64 {
+	if len(ingredient) == 0 {
+		return 0
+	}
+	have := map[string]bool{}
+	for _, w := range ingredient {
+		have[w] = true
+	}
+	head, _, _ := strings.Cut(description, ",")
+	headWords := words(head)
+	var food []string
+	for _, w := range words(description) {
+		if !neutralWords[w] {
+			food = append(food, w)
+		}
+	}
+	if len(food) == 0 || len(headWords) == 0 {
+		return 0
+	}
+
+	matched := map[string]bool{}
+	for _, w := range food {
+		if have[w] {
+			matched[w] = true
+		}
+	}
+	headMatched := 0
+	for _, w := range headWords {
+		if have[w] {
+			headMatched++
+		}
+	}
+	coverage := float64(len(matched)) / float64(len(ingredient))
+	headShare := float64(headMatched) / float64(len(headWords))
+	precision := float64(len(matched)) / float64(len(food))
+	return 0.6*coverage + 0.25*headShare + 0.15*precision
+}
+
+// bestMatch returns the best scoring food for the ingredient words and its
+// score, or nil when no food scores at least minScore. Ties go to the
+// shorter, more generic description.
+func bestMatch(ingredient []string, foods []*Food) (*Food, float64) {
+	var best *Food
+	bestScore := 0.0
+	for _, f := range foods {
+		s := score(ingredient, f.Description)
+		if s > bestScore || s == bestScore && best != nil && len(f.Description) < len(best.Description) {
+			best, bestScore = f, s
+		}
+	}
+	if bestScore < minScore {
+		return nil, 0
+	}
+	return best, bestScore
+}
+
+// singular makes a rough singular of an English plural so that "eggs" and
+// "egg" compare equal.
+func singular(w string) string {
+	switch {
+	case len(w) <= 3:
+		return w
+	case strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "oes"), strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "shes"),
+		strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "xes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us"):
+		return w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,69 @@
+package nutrition
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testFoods = []*Food{
+	{ID: 1, Description: "Egg, white, raw, fresh"},
+	{ID: 2, Description: "Egg, whole, raw, fresh"},
+	{ID: 3, Description: "Peanut butter, smooth style, with salt"},
+	{ID: 4, Description: "Butter, without salt"},
+	{ID: 5, Description: "Butter, salted"},
+	{ID: 6, Description: "Wheat flour, white, all-purpose, unenriched"},
+	{ID: 7, Description: "Wheat flour, whole-grain"},
+	{ID: 8, Description: "Chicken, broilers or fryers, thigh, meat and skin, raw"},
+	{ID: 9, Description: "Chicken, broilers or fryers, breast, meat only, raw"},
+	{ID: 10, Description: "Tomatoes, red, ripe, raw, year round average"},
+	{ID: 11, Description: "Onions, raw"},
+}
+
+func TestIngredientWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Eggs", []string{"egg"}},
+		{"all-purpose flour, sifted", []string{"purpose", "flour"}},
+		{"2 large tomatoes (about 1 lb), diced", []string{"tomato"}},
+		{"freshly ground black pepper", []string{"black", "pepper"}},
+		{"salt, to taste", []string{"salt"}},
+		{"chopped", nil},
+	}
+	for _, tt := range tests {
+		if got := ingredientWords(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ingredientWords(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBestMatch(t *testing.T) {
+	tests := []struct {
+		ingredient string
+		want       int64
+	}{
+		{"eggs", 2},
+		{"egg whites", 1},
+		{"unsalted butter", 4},
+		{"butter, softened", 5},
+		{"creamy peanut butter", 3},
+		{"all-purpose flour", 6},
+		{"whole-grain flour", 7},
+		{"boneless chicken thighs", 8},
+		{"chicken breast", 9},
+		{"ripe tomatoes, chopped", 10},
+		{"yellow onion", 11},
+		{"saffron", 0},
+	}
+	for _, tt := range tests {
+		food, score := bestMatch(ingredientWords(tt.ingredient), testFoods)
+		var got int64
+		if food != nil {
+			got = food.ID
+		}
+		if got != tt.want {
+			t.Errorf("bestMatch(%q) = %d (score %.2f), want %d", tt.ingredient, got, score, tt.want)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package nutrition
+
+import "context"
+
+// Repository defines persistence operations for the nutrient database.
+type Repository interface {
+	// Search returns foods whose description contains any of the words,
+	// best text matches first.
+	Search(ctx context.Context, words []string, limit int) ([]*Food, error)
+	// Save inserts foods, replacing existing foods with the same ids.
+	Save(ctx context.Context, foods []*Food) error
+}
@@ -0,0 +1,165 @@
+package nutrition
+
+import (
+	"context"
+	"io/fs"
+	"math"
+	"time"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/pkg/units"
+)
+
+// candidateLimit bounds the foods considered for each ingredient.
+const candidateLimit = 25
+
+// importBatchSize is the number of foods saved per repository call.
+const importBatchSize = 500
+
+// Confidence in the weight of an ingredient, by how it was found.
+const (
+	weighedConfidence   = 1   // given as a mass
+	densityConfidence   = 0.9 // a volume of an ingredient with a known density
+	volumeConfidence    = 0.6 // a volume assumed to weigh as much as water
+	portionConfidence   = 0.7 // a count of pieces of known weight
+	confidencePrecision = 100
+)
+
+// Service defines business logic for the nutrient database. It implements
+// recipe.NutritionCalculator.
+type Service interface {
+	// Calculate estimates the nutrition per serving of the ingredients.
+	// Optional ingredients and unmeasured ones such as "a pinch" are left
+	// out; ingredients without a matching food or a known weight are listed
+	// as unmatched.
+	Calculate(ctx context.Context, ingredients []recipe.Ingredient, servings int) (recipe.NutritionalInfo, *recipe.NutritionEstimate, error)
+	// Import loads foods from a FoodData Central CSV download and returns
+	// the number of foods imported.
+	Import(ctx context.Context, fsys fs.FS) (int, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+// NewService creates a nutrition service backed by the given repository.
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Calculate(ctx context.Context, ingredients []recipe.Ingredient, servings int) (recipe.NutritionalInfo, *recipe.NutritionEstimate, error) {
+	var total nutrients
+	var confidence float64
+	counted := 0
+	unmatched := []string{}
+	for _, ing := range ingredients {
+		if ing.Optional || ing.Amount <= 0 || units.Unmeasured(ing.Unit) {
+			continue
+		}
+		counted++
+		food, matchScore, err := s.match(ctx, ing.Name)
+		if err != nil {
+			return recipe.NutritionalInfo{}, nil, err
+		}
+		if food == nil {
+			unmatched = append(unmatched, ing.Name)
+			continue
+		}
+		grams, weightConfidence, ok := weigh(ing, food)
+		if !ok {
+			unmatched = append(unmatched, ing.Name)
+			continue
+		}
+		total.add(food, grams)
+		confidence += matchScore * weightConfidence
+	}
+	if counted > 0 {
+		confidence = math.Round(confidence/float64(counted)*confidencePrecision) / confidencePrecision
+	}
+	if servings <= 0 {
+		servings = 1
+	}
+	estimate := &recipe.NutritionEstimate{
+		Confidence: confidence,
+		Unmatched:  unmatched,
+		ComputedAt: time.Now().UTC(),
+	}
+	return total.perServing(servings), estimate, nil
+}
+
+// match finds the food best matching an ingredient name.
+func (s *service) match(ctx context.Context, name string) (*Food, float64, error) {
+	words := ingredientWords(name)
+	if len(words) == 0 {
+		return nil, 0, nil
+	}
+	foods, err := s.repo.Search(ctx, words, candidateLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+	food, score := bestMatch(words, foods)
+	return food, score, nil
+}
+
+// weigh returns the weight in grams of an ingredient and the confidence in
+// it. Volumes are weighed using the ingredient's density and counts using
+// the weight of one piece of the food.
+func weigh(ing recipe.Ingredient, food *Food) (float64, float64, bool) {
+	u, ok := units.Lookup(ing.Unit)
+	switch {
+	case ok && u.Dimension == units.Mass:
+		return ing.Amount * u.Base, weighedConfidence, true
+	case ok && u.Dimension == units.Volume:
+		ml := ing.Amount * u.Base
+		if d, ok := units.LookupDensity(ing.Name); ok {
+			return ml * d.GramsPerMl, densityConfidence, true
+		}
+		return ml, volumeConfidence, true
+	case !ok && food.PortionGrams > 0:
+		return ing.Amount * food.PortionGrams, portionConfidence, true
+	}
+	return 0, 0, false
+}
+
+func (s *service) Import(ctx context.Context, fsys fs.FS) (int, error) {
+	foods, err := ReadFDC(fsys)
+	if err != nil {
+		return 0, err
+	}
+	for start := 0; start < len(foods); start += importBatchSize {
+		end := min(start+importBatchSize, len(foods))
+		if err := s.repo.Save(ctx, foods[start:end]); err != nil {
+			return start, err
+		}
+	}
+	return len(foods), nil
+}
+
+// nutrients accumulates the nutrients of weighed foods.
+type nutrients struct {
+	calories, protein, carbohydrates, fat, fiber, sugar, sodium float64
+}
+
+func (n *nutrients) add(f *Food, grams float64) {
+	k := grams / 100
+	n.calories += f.Calories * k
+	n.protein += f.Protein * k
+	n.carbohydrates += f.Carbohydrates * k
+	n.fat += f.Fat * k
+	n.fiber += f.Fiber * k
+	n.sugar += f.Sugar * k
+	n.sodium += f.Sodium * k
+}
+
+func (n nutrients) perServing(servings int) recipe.NutritionalInfo {
+	per := func(v float64) int { return int(math.Round(v / float64(servings))) }
+	return recipe.NutritionalInfo{
+		Calories:      per(n.calories),
+		Protein:       per(n.protein),
+		Carbohydrates: per(n.carbohydrates),
+		Fat:           per(n.fat),
+		Fiber:         per(n.fiber),
+		Sugar:         per(n.sugar),
+		Sodium:        per(n.sodium),
+	}
+}
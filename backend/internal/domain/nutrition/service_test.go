@@ -0,0 +1,95 @@
+package nutrition
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+// fakeRepository searches foods in memory by description words.
+type fakeRepository struct {
+	foods []*Food
+	saved []int
+}
+
+func (f *fakeRepository) Search(ctx context.Context, words []string, limit int) ([]*Food, error) {
+	var out []*Food
+	for _, food := range f.foods {
+		desc := strings.ToLower(food.Description)
+		for _, w := range words {
+			if strings.Contains(desc, w) {
+				out = append(out, food)
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) Save(ctx context.Context, foods []*Food) error {
+	f.saved = append(f.saved, len(foods))
+	f.foods = append(f.foods, foods...)
+	return nil
+}
+
+func TestCalculate(t *testing.T) {
+	repo := &fakeRepository{foods: []*Food{
+		{ID: 1, Description: "Egg, whole, raw, fresh", Calories: 143, Protein: 12.6, Fat: 9.5, Sodium: 142, PortionGrams: 50},
+		{ID: 2, Description: "Butter, salted", Calories: 717, Fat: 81, Sodium: 643},
+		{ID: 3, Description: "Wheat flour, white, all-purpose, unenriched", Calories: 364, Protein: 10, Carbohydrates: 76, Fiber: 2.7, Sugar: 0.3},
+		{ID: 4, Description: "Onions, raw", Calories: 40, Carbohydrates: 9.3},
+	}}
+	svc := NewService(repo)
+
+	info, estimate, err := svc.Calculate(context.Background(), []recipe.Ingredient{
+		{Name: "eggs", Amount: 2},
+		{Name: "butter, melted", Amount: 100, Unit: "g"},
+		{Name: "all-purpose flour", Amount: 1, Unit: "cup"},
+		{Name: "onion", Amount: 1},
+		{Name: "saffron threads", Amount: 1, Unit: "g"},
+		{Name: "salt", Amount: 1, Unit: "pinch"},
+		{Name: "parsley", Amount: 2, Unit: "tbsp", Optional: true},
+	}, 2)
+	if err != nil {
+		t.Fatalf("calculate: %v", err)
+	}
+
+	// 100 g egg, 100 g butter and 120 g flour shared by two servings.
+	want := recipe.NutritionalInfo{Calories: 650, Protein: 12, Carbohydrates: 46, Fat: 45, Fiber: 2, Sugar: 0, Sodium: 393}
+	if info != want {
+		t.Fatalf("nutrition = %+v, want %+v", info, want)
+	}
+	if !reflect.DeepEqual(estimate.Unmatched, []string{"onion", "saffron threads"}) {
+		t.Fatalf("unexpected unmatched ingredients %v", estimate.Unmatched)
+	}
+	if estimate.Confidence <= 0.3 || estimate.Confidence >= 0.7 {
+		t.Fatalf("unexpected confidence %v", estimate.Confidence)
+	}
+
+	_, estimate, err = svc.Calculate(context.Background(), []recipe.Ingredient{{Name: "saffron", Amount: 1, Unit: "g"}}, 4)
+	if err != nil {
+		t.Fatalf("calculate: %v", err)
+	}
+	if estimate.Confidence != 0 {
+		t.Fatalf("expected no confidence without matches, got %v", estimate.Confidence)
+	}
+}
+
+func TestImport(t *testing.T) {
+	repo := &fakeRepository{}
+	fsys := fstest.MapFS{
+		"food.csv":          {Data: []byte("fdc_id,data_type,description\n1,sr_legacy_food,\"Onions, raw\"\n")},
+		"food_nutrient.csv": {Data: []byte("id,fdc_id,nutrient_id,amount\n1,1,1008,40\n")},
+	}
+	n, err := NewService(repo).Import(context.Background(), fsys)
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if n != 1 || len(repo.foods) != 1 || repo.foods[0].Calories != 40 {
+		t.Fatalf("unexpected import of %d foods: %+v", n, repo.foods)
+	}
+}
@@ -0,0 +1,63 @@
+package pantry
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/pkg/date"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+// Item is an ingredient the user has at home. A zero amount means the
+// quantity was not recorded, and a zero Expires that the item keeps.
+type Item struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Amount    float64   `json:"amount"`
+	Unit      string    `json:"unit"`
+	Expires   date.Date `json:"expires"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// expired reports whether the item went off before the given day.
+func (it *Item) expired(today date.Date) bool {
+	return !it.Expires.IsZero() && it.Expires.Before(today)
+}
+
+// expiresWithin reports whether the item expires at most days after the
+// given day, or already has.
+func (it *Item) expiresWithin(today date.Date, days int) bool {
+	return !it.Expires.IsZero() && it.Expires.Sub(today) <= days
+}
+
+// Match is a recipe ranked by how much of it the pantry covers. Need counts
+// the ingredients that have to come from the pantry, leaving out optional
+// ones and staples such as salt and water, and Have those it holds enough
+// of. Missing names the ingredients the pantry lacks and Short those it
+// holds too little of.
+type Match struct {
+	*recipe.Recipe
+	Coverage float64  `json:"coverage"`
+	Have     int      `json:"have"`
+	Need     int      `json:"need"`
+	Missing  []string `json:"missing"`
+	Short    []string `json:"short,omitempty"`
+	// UsesExpiring names the pantry items about to expire that the recipe
+	// would use up.
+	UsesExpiring []string `json:"uses_expiring,omitempty"`
+}
+
+// CookableResult holds a page of recipes the pantry can make and the
+// pantry items about to expire. Only the best matching candidates are
+// ranked; Truncated is set when more recipes use the pantry's items, in which
+// case Pagination counts the ranked ones.
+type CookableResult struct {
+	Recipes    []*Match        `json:"recipes"`
+	Pagination pagination.Meta `json:"pagination"`
+	Expiring   []*Item         `json:"expiring"`
+	Truncated  bool            `json:"truncated"`
+}
@@ -0,0 +1,245 @@
+package pantry
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/pkg/date"
+	"alchemorsel/backend/internal/pkg/units"
+)
+
+// staples are ingredients every kitchen is assumed to have. An ingredient
+// made only of staples and qualifiers, such as "kosher salt" or "cold
+// water", counts as always available.
+var staples = map[string]bool{"salt": true, "water": true, "pepper": true, "ice": true}
+
+var stapleQualifiers = map[string]bool{
+	"kosher": true, "sea": true, "table": true, "fine": true, "flaky": true, "black": true, "white": true,
+	"ground": true, "freshly": true, "fresh": true, "cracked": true, "cold": true, "warm": true, "hot": true,
+	"boiling": true, "lukewarm": true, "tap": true, "filtered": true, "and": true,
+	"to": true, "taste": true, "of": true,
+}
+
+// forms are words naming the form an ingredient is bought in. They are
+// dropped from the end of a name so that "garlic cloves" is matched by
+// "garlic".
+var forms = map[string]bool{
+	"clove": true, "sprig": true, "stalk": true, "head": true, "bunch": true,
+	"can": true, "jar": true, "cube": true, "fillet": true,
+}
+
+// grades are words that only grade or describe an ingredient without making
+// it a different one, so that "all-purpose flour" or "unsalted butter" in the
+// pantry covers "flour" or "butter", while "almond flour" and "peanut
+// butter" do not.
+var grades = map[string]bool{
+	"all": true, "purpose": true, "plain": true, "unbleached": true, "granulated": true,
+	"unsalted": true, "salted": true, "whole": true, "skim": true, "skimmed": true, "low": true, "fat": true,
+	"fresh": true, "organic": true, "free": true, "range": true, "large": true, "medium": true, "small": true,
+	"extra": true, "jumbo": true, "white": true, "yellow": true, "red": true,
+}
+
+// matcher compares recipes against the items of a pantry.
+type matcher struct {
+	items    []*Item
+	words    [][]string
+	expiring map[*Item]bool
+}
+
+// newMatcher prepares a pantry for matching. Expired items are left out,
+// and items expiring within days of today are noted so that recipes using
+// them up rank higher.
+func newMatcher(items []*Item, today date.Date, days int) *matcher {
+	m := &matcher{expiring: map[*Item]bool{}}
+	for _, it := range items {
+		if it.expired(today) {
+			continue
+		}
+		w := nameWords(it.Name)
+		if len(w) == 0 {
+			continue
+		}
+		m.items = append(m.items, it)
+		m.words = append(m.words, w)
+		if it.expiresWithin(today, days) {
+			m.expiring[it] = true
+		}
+	}
+	return m
+}
+
+// queryWords returns the distinct words of the pantry's items, for finding
+// the recipes that mention any of them.
+func (m *matcher) queryWords() []string {
+	var all []string
+	for _, w := range m.words {
+		all = append(all, w...)
+	}
+	slices.Sort(all)
+	return slices.Compact(all)
+}
+
+// match works out how much of the recipe the pantry covers.
+func (m *matcher) match(r *recipe.Recipe) *Match {
+	res := &Match{Recipe: r, Missing: []string{}}
+	for _, ing := range r.Ingredients {
+		iw := nameWords(ing.Name)
+		if ing.Optional || len(iw) == 0 || isStaple(iw) {
+			continue
+		}
+		res.Need++
+		found, short := false, false
+		for i, it := range m.items {
+			if !covers(m.words[i], iw) {
+				continue
+			}
+			if !enough(it, ing) {
+				short = true
+				continue
+			}
+			found = true
+			if m.expiring[it] && !slices.Contains(res.UsesExpiring, it.Name) {
+				res.UsesExpiring = append(res.UsesExpiring, it.Name)
+			}
+			break
+		}
+		switch {
+		case found:
+			res.Have++
+		case short:
+			res.Short = append(res.Short, ing.Name)
+		default:
+			res.Missing = append(res.Missing, ing.Name)
+		}
+	}
+	res.Coverage = 1
+	if res.Need > 0 {
+		res.Coverage = float64(res.Have) / float64(res.Need)
+	}
+	return res
+}
+
+// rank orders matches by coverage, then by the fewest ingredients to buy,
+// then by how many expiring items they use up, then by rating and title.
+func rank(matches []*Match) {
+	slices.SortStableFunc(matches, func(a, b *Match) int {
+		switch {
+		case a.Coverage != b.Coverage:
+			return compareDesc(a.Coverage, b.Coverage)
+		case len(a.Missing)+len(a.Short) != len(b.Missing)+len(b.Short):
+			return len(a.Missing) + len(a.Short) - len(b.Missing) - len(b.Short)
+		case len(a.UsesExpiring) != len(b.UsesExpiring):
+			return len(b.UsesExpiring) - len(a.UsesExpiring)
+		case a.RatingAverage != b.RatingAverage:
+			return compareDesc(a.RatingAverage, b.RatingAverage)
+		}
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	})
+}
+
+func compareDesc(a, b float64) int {
+	if a > b {
+		return -1
+	}
+	return 1
+}
+
+// covers reports whether a pantry item is the ingredient. The ingredient must
+// end with the item's name, so "onion" covers "red onions" while "chicken"
+// does not cover "chicken stock". An item with a longer name covers the
+// ingredient only when the extra words are grades: "all-purpose flour"
+// covers "flour", but "coconut milk" does not cover "milk".
+func covers(item, ingredient []string) bool {
+	if hasSuffix(ingredient, item) {
+		return true
+	}
+	if !hasSuffix(item, ingredient) {
+		return false
+	}
+	for _, w := range item[:len(item)-len(ingredient)] {
+		if !grades[w] {
+			return false
+		}
+	}
+	return true
+}
+
+func hasSuffix(s, suffix []string) bool {
+	return len(suffix) <= len(s) && slices.Equal(s[len(s)-len(suffix):], suffix)
+}
+
+// enough reports whether the pantry item holds at least the amount the
+// ingredient calls for. Quantities that cannot be compared, because one is
+// not recorded or the units differ in kind, are given the benefit of the
+// doubt.
+func enough(it *Item, ing recipe.Ingredient) bool {
+	if it.Amount <= 0 || ing.Amount <= 0 || units.Unmeasured(ing.Unit) {
+		return true
+	}
+	from, fromOK := units.Lookup(it.Unit)
+	to, toOK := units.Lookup(ing.Unit)
+	switch {
+	case fromOK && toOK:
+		have, ok := units.Convert(it.Amount, from, to)
+		return !ok || have >= ing.Amount*(1-1e-9)
+	case !fromOK && !toOK && slices.Equal(words(it.Unit), words(ing.Unit)):
+		return it.Amount >= ing.Amount*(1-1e-9)
+	}
+	return true
+}
+
+func isStaple(w []string) bool {
+	staple := false
+	for _, word := range w {
+		switch {
+		case staples[word]:
+			staple = true
+		case !stapleQualifiers[word]:
+			return false
+		}
+	}
+	return staple
+}
+
+// nameWords returns the words of an ingredient name that identify it,
+// leaving out notes after a comma or in parentheses and a trailing form
+// such as "cloves".
+func nameWords(name string) []string {
+	name, _, _ = strings.Cut(name, ",")
+	name, _, _ = strings.Cut(name, "(")
+	w := words(name)
+	if len(w) > 1 && forms[w[len(w)-1]] {
+		w = w[:len(w)-1]
+	}
+	return w
+}
+
+// words splits text into lower case singular words.
+func words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		fields[i] = singular(f)
+	}
+	return fields
+}
+
+// singular makes a rough singular of an English plural so that "eggs" in
+// the pantry covers "egg" in a recipe.
+func singular(w string) string {
+	switch {
+	case len(w) <= 3:
+		return w
+	case strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "oes"), strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "shes"),
+		strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "xes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us"):
+		return w[:len(w)-1]
+	}
+	return w
+}
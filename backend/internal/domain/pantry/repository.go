@@ -0,0 +1,19 @@
+package pantry
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository defines persistence operations for pantry items. Items are
+// always looked up within the pantry of their owner.
+type Repository interface {
+	Create(ctx context.Context, it *Item) error
+	GetByID(ctx context.Context, userID, id uuid.UUID) (*Item, error)
+	Update(ctx context.Context, it *Item) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// ListByUser returns the user's pantry ordered by name.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Item, error)
+	CountByUser(ctx context.Context, userID uuid.UUID) (int, error)
+}
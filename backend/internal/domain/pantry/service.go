@@ -0,0 +1,254 @@
+package pantry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/pkg/date"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/pagination"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// Limits on pantry items, counted in characters after sanitization.
+const (
+	MaxNameLength = 200
+	MaxUnitLength = 30
+	MaxAmount     = 100000
+	// MaxItems bounds the size of a pantry.
+	MaxItems = 300
+)
+
+const (
+	// DefaultExpiringDays is how many days ahead items count as about to
+	// expire.
+	DefaultExpiringDays = 3
+	MaxExpiringDays     = 60
+)
+
+// candidatePages bounds how many pages of candidate recipes are ranked when
+// looking for recipes the pantry can make. CookableResult.Truncated reports
+// when there were more.
+const candidatePages = 5
+
+var (
+	// ErrItemNotFound is returned when the user's pantry has no such item.
+	ErrItemNotFound = apperrors.New("pantry_item_not_found", "pantry item not found", 404)
+	// ErrPantryFull is returned when adding to a pantry holding MaxItems.
+	ErrPantryFull = apperrors.New("pantry_full", fmt.Sprintf("a pantry holds at most %d items", MaxItems), 422)
+)
+
+// Service defines business logic for the pantry.
+type Service interface {
+	// List returns the user's pantry ordered by name.
+	List(ctx context.Context, userID uuid.UUID) ([]*Item, error)
+	Add(ctx context.Context, userID uuid.UUID, req ItemRequest) (*Item, error)
+	// Update changes the set fields of an item.
+	Update(ctx context.Context, userID, id uuid.UUID, req UpdateRequest) (*Item, error)
+	Remove(ctx context.Context, userID, id uuid.UUID) error
+	// Expiring returns the items expiring within days, or already expired,
+	// soonest first. Zero days means DefaultExpiringDays.
+	Expiring(ctx context.Context, userID uuid.UUID, days int) ([]*Item, error)
+	// Cookable finds the recipes the user may see that use the pantry,
+	// ranked by how much of each the pantry covers.
+	Cookable(ctx context.Context, userID uuid.UUID, params CookableParams) (*CookableResult, error)
+}
+
+// ItemRequest describes an item added to the pantry.
+type ItemRequest struct {
+	Name    string
+	Amount  float64
+	Unit    string
+	Expires date.Date
+}
+
+// UpdateRequest changes the set fields of an item. A set but zero Expires
+// clears the expiry date.
+type UpdateRequest struct {
+	Name    *string
+	Amount  *float64
+	Unit    *string
+	Expires *date.Date
+}
+
+// CookableParams narrows the recipes Cookable considers. MaxMissing, when
+// set, drops recipes lacking more ingredients than that.
+type CookableParams struct {
+	Category   string
+	Dietary    []string
+	Tags       []string
+	MaxMissing *int
+	Pagination pagination.Params
+}
+
+type service struct {
+	repo    Repository
+	recipes recipe.Service
+}
+
+// NewService creates a pantry service. Recipes are searched through the
+// recipe service, so matches only include what the user may see and leave
+// out recipes declaring their allergies.
+func NewService(repo Repository, recipes recipe.Service) Service {
+	return &service{repo: repo, recipes: recipes}
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID) ([]*Item, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Add(ctx context.Context, userID uuid.UUID, req ItemRequest) (*Item, error) {
+	now := time.Now().UTC()
+	it := &Item{ID: uuid.New(), UserID: userID, Expires: req.Expires, CreatedAt: now, UpdatedAt: now}
+	var err error
+	if it.Name, err = validator.Text("name", req.Name, 1, MaxNameLength); err != nil {
+		return nil, err
+	}
+	if it.Amount, err = checkAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	if it.Unit, err = validator.Text("unit", req.Unit, 0, MaxUnitLength); err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxItems {
+		return nil, ErrPantryFull
+	}
+	if err := s.repo.Create(ctx, it); err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+func (s *service) Update(ctx context.Context, userID, id uuid.UUID, req UpdateRequest) (*Item, error) {
+	it, err := s.repo.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		if it.Name, err = validator.Text("name", *req.Name, 1, MaxNameLength); err != nil {
+			return nil, err
+		}
+	}
+	if req.Amount != nil {
+		if it.Amount, err = checkAmount(*req.Amount); err != nil {
+			return nil, err
+		}
+	}
+	if req.Unit != nil {
+		if it.Unit, err = validator.Text("unit", *req.Unit, 0, MaxUnitLength); err != nil {
+			return nil, err
+		}
+	}
+	if req.Expires != nil {
+		it.Expires = *req.Expires
+	}
+	it.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(ctx, it); err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+func (s *service) Remove(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, id)
+}
+
+func (s *service) Expiring(ctx context.Context, userID uuid.UUID, days int) ([]*Item, error) {
+	switch {
+	case days == 0:
+		days = DefaultExpiringDays
+	case days < 0 || days > MaxExpiringDays:
+		return nil, validator.InvalidField("days", fmt.Sprintf("days must be between 1 and %d", MaxExpiringDays))
+	}
+	items, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return expiring(items, date.Today(), days), nil
+}
+
+func (s *service) Cookable(ctx context.Context, userID uuid.UUID, params CookableParams) (*CookableResult, error) {
+	if params.MaxMissing != nil && *params.MaxMissing < 0 {
+		return nil, validator.InvalidField("max_missing", "max_missing must not be negative")
+	}
+	page := params.Pagination.Normalize()
+	items, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	today := date.Today()
+	m := newMatcher(items, today, DefaultExpiringDays)
+	res := &CookableResult{Recipes: []*Match{}, Expiring: expiring(items, today, DefaultExpiringDays)}
+
+	var matches []*Match
+	if words := m.queryWords(); len(words) > 0 {
+		// Candidates are the recipes using any pantry item as an
+		// ingredient, best matches first; they are then ranked by coverage.
+		search := recipe.SearchParams{
+			Mode:            recipe.ModeFullText,
+			Query:           strings.Join(words, " OR "),
+			IngredientWords: words,
+			Category:        params.Category,
+			Dietary:         params.Dietary,
+			Tags:            params.Tags,
+			ViewerID:        &userID,
+			Sort:            recipe.SortRelevance,
+			Order:           "desc",
+			Pagination:      pagination.Params{Page: 1, PerPage: pagination.MaxPerPage},
+		}
+		for ; search.Pagination.Page <= candidatePages; search.Pagination.Page++ {
+			found, err := s.recipes.Search(ctx, search)
+			if err != nil {
+				return nil, err
+			}
+			for _, hit := range found.Recipes {
+				match := m.match(hit.Recipe)
+				if match.Have == 0 || (params.MaxMissing != nil && len(match.Missing)+len(match.Short) > *params.MaxMissing) {
+					continue
+				}
+				matches = append(matches, match)
+			}
+			if !found.Pagination.HasNext {
+				break
+			}
+			res.Truncated = search.Pagination.Page == candidatePages
+		}
+	}
+	rank(matches)
+
+	res.Pagination = pagination.NewMeta(page, len(matches))
+	if start := page.Offset(); start < len(matches) {
+		res.Recipes = matches[start:min(start+page.PerPage, len(matches))]
+	}
+	return res, nil
+}
+
+// expiring returns the items expiring within days of today, soonest first.
+func expiring(items []*Item, today date.Date, days int) []*Item {
+	soon := []*Item{}
+	for _, it := range items {
+		if it.expiresWithin(today, days) {
+			soon = append(soon, it)
+		}
+	}
+	slices.SortStableFunc(soon, func(a, b *Item) int { return a.Expires.Sub(b.Expires) })
+	return soon
+}
+
+func checkAmount(amount float64) (float64, error) {
+	if math.IsNaN(amount) || amount < 0 || amount > MaxAmount {
+		return 0, validator.InvalidField("amount", fmt.Sprintf("amount must be between 0 and %d", MaxAmount))
+	}
+	return amount, nil
+}
@@ -0,0 +1,261 @@
+package pantry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/pkg/date"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+type fakeRepository struct {
+	Repository
+	items map[uuid.UUID]*Item
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{items: map[uuid.UUID]*Item{}}
+}
+
+func (f *fakeRepository) Create(ctx context.Context, it *Item) error {
+	copy := *it
+	f.items[it.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) GetByID(ctx context.Context, userID, id uuid.UUID) (*Item, error) {
+	if it, ok := f.items[id]; ok && it.UserID == userID {
+		copy := *it
+		return &copy, nil
+	}
+	return nil, ErrItemNotFound
+}
+
+func (f *fakeRepository) Update(ctx context.Context, it *Item) error {
+	copy := *it
+	f.items[it.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*Item, error) {
+	items := []*Item{}
+	for _, it := range f.items {
+		if it.UserID == userID {
+			copy := *it
+			items = append(items, &copy)
+		}
+	}
+	slices.SortFunc(items, func(a, b *Item) int { return strings.Compare(a.Name, b.Name) })
+	return items, nil
+}
+
+func (f *fakeRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	items, _ := f.ListByUser(ctx, userID)
+	return len(items), nil
+}
+
+// fakeRecipes implements the recipe.Service methods used by the pantry.
+// Search returns every recipe, two to a page, and records the query and
+// ingredient words.
+type fakeRecipes struct {
+	recipe.Service
+	recipes []*recipe.Recipe
+	queries []string
+	words   []string
+}
+
+func (f *fakeRecipes) Search(ctx context.Context, params recipe.SearchParams) (*recipe.SearchResult, error) {
+	f.queries = append(f.queries, params.Query)
+	f.words = params.IngredientWords
+	p := pagination.Params{Page: params.Pagination.Page, PerPage: 2}
+	res := &recipe.SearchResult{Pagination: pagination.NewMeta(p, len(f.recipes))}
+	for i := p.Offset(); i < len(f.recipes) && i < p.Offset()+p.PerPage; i++ {
+		res.Recipes = append(res.Recipes, &recipe.SearchHit{Recipe: f.recipes[i]})
+	}
+	return res, nil
+}
+
+func TestMatch(t *testing.T) {
+	today := date.New(2024, 6, 3)
+	items := []*Item{
+		{Name: "Red onions"},
+		{Name: "garlic", Expires: today.AddDays(1)},
+		{Name: "butter", Amount: 50, Unit: "g"},
+		{Name: "milk", Amount: 1, Unit: "cup", Expires: today.AddDays(-1)},
+		{Name: "eggs", Amount: 2},
+		{Name: "chicken"},
+	}
+	m := newMatcher(items, today, DefaultExpiringDays)
+	got := m.match(&recipe.Recipe{Ingredients: []recipe.Ingredient{
+		{Name: "onion, diced", Amount: 1},
+		{Name: "garlic cloves", Amount: 3},
+		{Name: "butter", Amount: 2, Unit: "tbsp"},
+		{Name: "milk", Amount: 0.5, Unit: "cup"},
+		{Name: "large eggs", Amount: 3},
+		{Name: "chicken stock", Amount: 1, Unit: "cup"},
+		{Name: "kosher salt and freshly ground black pepper", Unit: "to taste"},
+		{Name: "cold water", Amount: 2, Unit: "tbsp"},
+		{Name: "parsley", Optional: true},
+	}})
+
+	if got.Need != 6 || got.Have != 3 || got.Coverage != 0.5 {
+		t.Fatalf("have %d of %d (%v), want 3 of 6", got.Have, got.Need, got.Coverage)
+	}
+	if !slices.Equal(got.Missing, []string{"milk", "chicken stock"}) {
+		t.Fatalf("missing = %q", got.Missing)
+	}
+	if !slices.Equal(got.Short, []string{"large eggs"}) {
+		t.Fatalf("short = %q", got.Short)
+	}
+	if !slices.Equal(got.UsesExpiring, []string{"garlic"}) {
+		t.Fatalf("uses expiring = %q", got.UsesExpiring)
+	}
+}
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		item, ingredient string
+		want             bool
+	}{
+		{"onion", "red onions", true},
+		{"all-purpose flour", "flour", true},
+		{"unsalted butter", "butter", true},
+		{"whole milk", "milk", true},
+		{"chicken", "chicken stock", false},
+		{"peanut butter", "butter", false},
+		{"coconut milk", "milk", false},
+		{"almond flour", "flour", false},
+	}
+	for _, tt := range tests {
+		if got := covers(nameWords(tt.item), nameWords(tt.ingredient)); got != tt.want {
+			t.Errorf("covers(%q, %q) = %v, want %v", tt.item, tt.ingredient, got, tt.want)
+		}
+	}
+}
+
+func TestCookableRanksByCoverage(t *testing.T) {
+	repo := newFakeRepository()
+	userID := uuid.New()
+	today := date.Today()
+	for _, it := range []*Item{
+		{ID: uuid.New(), UserID: userID, Name: "pasta"},
+		{ID: uuid.New(), UserID: userID, Name: "tomatoes", Expires: today.AddDays(2)},
+		{ID: uuid.New(), UserID: userID, Name: "spinach", Expires: today.AddDays(-2)},
+		{ID: uuid.New(), UserID: uuid.New(), Name: "basil"},
+	} {
+		repo.items[it.ID] = it
+	}
+	recipes := &fakeRecipes{recipes: []*recipe.Recipe{
+		{Title: "Pasta with spinach", Ingredients: []recipe.Ingredient{{Name: "pasta"}, {Name: "spinach"}}},
+		{Title: "Tomato pasta", Ingredients: []recipe.Ingredient{{Name: "pasta"}, {Name: "tomatoes"}, {Name: "salt"}}},
+		{Title: "Basil soup", Ingredients: []recipe.Ingredient{{Name: "basil"}}},
+		{Title: "Plain pasta", Ingredients: []recipe.Ingredient{{Name: "pasta"}, {Name: "water"}, {Name: "basil", Optional: true}}},
+		{Title: "Pasta salad", Ingredients: []recipe.Ingredient{{Name: "pasta"}, {Name: "olives"}, {Name: "feta"}}},
+	}}
+	svc := NewService(repo, recipes)
+
+	res, err := svc.Cookable(context.Background(), userID, CookableParams{})
+	if err != nil {
+		t.Fatalf("cookable: %v", err)
+	}
+	if len(recipes.queries) != 3 || recipes.queries[0] != "pasta OR tomato" || !slices.Equal(recipes.words, []string{"pasta", "tomato"}) {
+		t.Fatalf("queries = %q with ingredient words %q", recipes.queries, recipes.words)
+	}
+	if res.Truncated {
+		t.Fatalf("expected every candidate to be ranked")
+	}
+	var titles []string
+	for _, m := range res.Recipes {
+		titles = append(titles, m.Title)
+	}
+	want := []string{"Tomato pasta", "Plain pasta", "Pasta with spinach", "Pasta salad"}
+	if !slices.Equal(titles, want) {
+		t.Fatalf("recipes = %q, want %q", titles, want)
+	}
+	if res.Pagination.Total != 4 || !slices.Equal(res.Recipes[2].Missing, []string{"spinach"}) {
+		t.Fatalf("unexpected result %+v", res.Recipes[2])
+	}
+	if len(res.Expiring) != 2 || res.Expiring[0].Name != "spinach" || res.Expiring[1].Name != "tomatoes" {
+		t.Fatalf("expiring = %+v", res.Expiring)
+	}
+
+	maxMissing := 1
+	res, err = svc.Cookable(context.Background(), userID, CookableParams{MaxMissing: &maxMissing})
+	if err != nil || res.Pagination.Total != 3 {
+		t.Fatalf("expected 3 recipes missing at most one ingredient, got %+v, %v", res, err)
+	}
+}
+
+func TestCookableReportsCandidateCap(t *testing.T) {
+	repo := newFakeRepository()
+	userID := uuid.New()
+	it := &Item{ID: uuid.New(), UserID: userID, Name: "rice"}
+	repo.items[it.ID] = it
+	recipes := &fakeRecipes{}
+	for range 2*candidatePages + 1 {
+		recipes.recipes = append(recipes.recipes, &recipe.Recipe{Title: "Rice", Ingredients: []recipe.Ingredient{{Name: "rice"}}})
+	}
+
+	res, err := NewService(repo, recipes).Cookable(context.Background(), userID, CookableParams{})
+	if err != nil || !res.Truncated || res.Pagination.Total != 2*candidatePages {
+		t.Fatalf("expected %d ranked recipes out of more, got %+v, %v", 2*candidatePages, res, err)
+	}
+}
+
+func TestAddUpdateAndExpiring(t *testing.T) {
+	repo := newFakeRepository()
+	svc := NewService(repo, &fakeRecipes{})
+	ctx := context.Background()
+	userID := uuid.New()
+	today := date.Today()
+
+	if _, err := svc.Add(ctx, userID, ItemRequest{Name: " "}); err == nil {
+		t.Fatal("expected an error for an empty name")
+	}
+	if _, err := svc.Add(ctx, userID, ItemRequest{Name: "rice", Amount: -1}); err == nil {
+		t.Fatal("expected an error for a negative amount")
+	}
+	rice, err := svc.Add(ctx, userID, ItemRequest{Name: "rice", Amount: 1, Unit: "kg"})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	yogurt, err := svc.Add(ctx, userID, ItemRequest{Name: "yogurt", Expires: today.AddDays(5)})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	soon, err := svc.Expiring(ctx, userID, 0)
+	if err != nil || len(soon) != 0 {
+		t.Fatalf("expected nothing expiring within 3 days, got %+v, %v", soon, err)
+	}
+	if soon, _ = svc.Expiring(ctx, userID, 7); len(soon) != 1 || soon[0].ID != yogurt.ID {
+		t.Fatalf("expected the yogurt to expire within a week, got %+v", soon)
+	}
+	if _, err := svc.Expiring(ctx, userID, MaxExpiringDays+1); err == nil {
+		t.Fatal("expected an error for too many days")
+	}
+
+	cleared := date.Date{}
+	amount := 0.5
+	updated, err := svc.Update(ctx, userID, yogurt.ID, UpdateRequest{Amount: &amount, Expires: &cleared})
+	if err != nil || updated.Amount != 0.5 || !updated.Expires.IsZero() || updated.Name != "yogurt" {
+		t.Fatalf("unexpected update %+v, %v", updated, err)
+	}
+	if _, err := svc.Update(ctx, uuid.New(), rice.ID, UpdateRequest{Amount: &amount}); !errors.Is(err, ErrItemNotFound) {
+		t.Fatalf("expected ErrItemNotFound for another user's item, got %v", err)
+	}
+
+	for i := len(repo.items); i < MaxItems; i++ {
+		it := &Item{ID: uuid.New(), UserID: userID, Name: "item"}
+		repo.items[it.ID] = it
+	}
+	if _, err := svc.Add(ctx, userID, ItemRequest{Name: "flour"}); !errors.Is(err, ErrPantryFull) {
+		t.Fatalf("expected ErrPantryFull, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package recipe
+
+import "strings"
+
+// Allergens known to the detector.
+const (
+	AllergenDairy     = "dairy"
+	AllergenEggs      = "eggs"
+	AllergenFish      = "fish"
+	AllergenShellfish = "shellfish"
+	AllergenTreeNuts  = "tree nuts"
+	AllergenPeanuts   = "peanuts"
+	AllergenWheat     = "wheat"
+	AllergenGluten    = "gluten"
+	AllergenSoy       = "soy"
+	AllergenSesame    = "sesame"
+)
+
+// allergenAliases maps other names for an allergen, as authors and users
+// write them, to the detector's name.
+var allergenAliases = map[string]string{
+	"milk":         AllergenDairy,
+	"lactose":      AllergenDairy,
+	"egg":          AllergenEggs,
+	"peanut":       AllergenPeanuts,
+	"tree nut":     AllergenTreeNuts,
+	"nut":          AllergenTreeNuts,
+	"nuts":         AllergenTreeNuts,
+	"soya":         AllergenSoy,
+	"soybean":      AllergenSoy,
+	"soybeans":     AllergenSoy,
+	"crustacean":   AllergenShellfish,
+	"crustaceans":  AllergenShellfish,
+	"mollusc":      AllergenShellfish,
+	"molluscs":     AllergenShellfish,
+	"sesame seed":  AllergenSesame,
+	"sesame seeds": AllergenSesame,
+}
+
+// ingredientRule recognizes ingredients whose names contain one of its
+// keywords as whole words. Phrases in except are removed from the name
+// first, so that "peanut butter" is not dairy, and names containing a phrase
+// in unless, such as "dairy-free", are never recognized.
+type ingredientRule struct {
+	name     string
+	keywords []string
+	except   []string
+	unless   []string
+}
+
+var (
+	wheatKeywords = []string{
+		"wheat", "flour", "bread", "breadcrumbs", "panko", "pasta", "spaghetti", "macaroni",
+		"penne", "fettuccine", "linguine", "lasagna", "orzo", "noodle", "couscous", "bulgur",
+		"semolina", "farro", "spelt", "durum", "seitan", "pastry", "phyllo", "filo", "pita",
+		"naan", "tortilla", "bagel", "croissant", "brioche", "baguette", "cracker", "biscuit",
+		"soy sauce", "teriyaki",
+	}
+	wheatExceptions = []string{
+		"rice flour", "almond flour", "coconut flour", "corn flour", "chickpea flour",
+		"gram flour", "tapioca flour", "potato flour", "buckwheat flour", "oat flour",
+		"cassava flour", "sorghum flour", "millet flour",
+		"rice noodle", "glass noodle", "corn tortilla", "rice pasta", "rice cracker",
+	}
+)
+
+// allergenRules lists the allergens in the order they are reported.
+var allergenRules = []ingredientRule{
+	{
+		name: AllergenDairy,
+		keywords: []string{
+			"milk", "butter", "cream", "cheese", "ghee", "yogurt", "yoghurt", "buttermilk",
+			"whey", "casein", "lactose", "curd", "kefir", "paneer", "ricotta", "mozzarella",
+			"parmesan", "cheddar", "feta", "mascarpone", "brie", "gouda", "custard",
+			"crème fraîche", "creme fraiche", "half and half", "queso", "béchamel",
+		},
+		except: []string{
+			"peanut butter", "almond butter", "cashew butter", "nut butter", "apple butter",
+			"cocoa butter", "shea butter", "sunflower butter", "coconut milk", "almond milk",
+			"oat milk", "soy milk", "rice milk", "cashew milk", "coconut cream",
+			"cream of tartar", "bean curd", "butter bean",
+		},
+		unless: []string{"dairy-free", "non-dairy", "vegan", "plant-based"},
+	},
+	{
+		name:     AllergenEggs,
+		keywords: []string{"egg", "eggs", "mayonnaise", "mayo", "meringue", "aioli", "albumen", "custard", "hollandaise"},
+		unless:   []string{"egg-free", "vegan"},
+	},
+	{
+		name: AllergenFish,
+		keywords: []string{
+			"fish", "salmon", "tuna", "cod", "anchovy", "anchovies", "sardine", "trout",
+			"halibut", "tilapia", "mackerel", "haddock", "herring", "pollock", "snapper",
+			"swordfish", "catfish", "sea bass", "mahi", "worcestershire",
+		},
+	},
+	{
+		name: AllergenShellfish,
+		keywords: []string{
+			"shellfish", "shrimp", "prawn", "crab", "lobster", "crayfish", "crawfish",
+			"langoustine", "scallop", "clam", "mussel", "oyster", "squid", "calamari", "octopus",
+		},
+		except: []string{"oyster mushroom", "crab apple"},
+	},
+	{
+		name: AllergenTreeNuts,
+		keywords: []string{
+			"almond", "walnut", "pecan", "cashew", "pistachio", "hazelnut", "macadamia",
+			"brazil nut", "pine nut", "chestnut", "praline", "marzipan", "frangipane",
+			"nutella", "gianduja", "nut",
+		},
+		except: []string{"water chestnut"},
+		unless: []string{"nut-free"},
+	},
+	{
+		name:     AllergenPeanuts,
+		keywords: []string{"peanut", "groundnut", "monkey nut", "satay"},
+	},
+	{
+		name:     AllergenWheat,
+		keywords: wheatKeywords,
+		except:   wheatExceptions,
+		unless:   []string{"gluten-free", "wheat-free"},
+	},
+	{
+		name:     AllergenGluten,
+		keywords: append([]string{"barley", "rye", "malt", "beer"}, wheatKeywords...),
+		except:   wheatExceptions,
+		unless:   []string{"gluten-free"},
+	},
+	{
+		name:     AllergenSoy,
+		keywords: []string{"soy", "soya", "soybean", "tofu", "tempeh", "edamame", "miso", "shoyu", "tamari", "natto", "bean curd"},
+		unless:   []string{"soy-free"},
+	},
+	{
+		name:     AllergenSesame,
+		keywords: []string{"sesame", "tahini", "hummus", "halva", "halvah", "benne", "gomasio"},
+	},
+}
+
+// DetectAllergens returns the allergens found in the ingredient names, in
+// the detector's order.
+func DetectAllergens(ingredients []Ingredient) []string {
+	found := []string{}
+	for _, rule := range allergenRules {
+		if len(rule.find(ingredients)) > 0 {
+			found = append(found, rule.name)
+		}
+	}
+	return found
+}
+
+// find returns the names of the ingredients the rule recognizes.
+func (rule ingredientRule) find(ingredients []Ingredient) []string {
+	var names []string
+	for _, ing := range ingredients {
+		if rule.matches(ing.Name) {
+			names = append(names, ing.Name)
+		}
+	}
+	return names
+}
+
+func (rule ingredientRule) matches(name string) bool {
+	text := textWords(name)
+	for _, phrase := range rule.unless {
+		if containsWords(text, phrase) {
+			return false
+		}
+	}
+	for _, phrase := range rule.except {
+		text = strings.ReplaceAll(text, textWords(phrase), " ")
+	}
+	for _, kw := range rule.keywords {
+		if containsWords(text, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeAllergens normalizes allergen labels like normalizeLabels and
+// maps other names for known allergens to the detector's names.
+func normalizeAllergens(labels []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, l := range normalizeLabels(labels) {
+		if alias, ok := allergenAliases[l]; ok {
+			l = alias
+		}
+		if !seen[l] {
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// applyAllergens sets the recipe's allergens to the declared ones together
+// with those detected in its ingredients, and records the detected allergens
+// the author did not declare.
+func applyAllergens(r *Recipe, declared []string) {
+	declared = normalizeAllergens(declared)
+	isDeclared := map[string]bool{}
+	for _, a := range declared {
+		isDeclared[a] = true
+	}
+	r.Allergens = declared
+	r.UndeclaredAllergens = []string{}
+	for _, a := range DetectAllergens(r.Ingredients) {
+		if !isDeclared[a] {
+			r.Allergens = append(r.Allergens, a)
+			r.UndeclaredAllergens = append(r.UndeclaredAllergens, a)
+		}
+	}
+}
+
+// declaredAllergens returns the allergens the author declared, leaving out
+// those that were only detected.
+func (r *Recipe) declaredAllergens() []string {
+	undeclared := map[string]bool{}
+	for _, a := range r.UndeclaredAllergens {
+		undeclared[a] = true
+	}
+	declared := []string{}
+	for _, a := range r.Allergens {
+		if !undeclared[a] {
+			declared = append(declared, a)
+		}
+	}
+	return declared
+}
@@ -0,0 +1,125 @@
+package recipe
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user/usertest"
+)
+
+func TestDetectAllergens(t *testing.T) {
+	tests := []struct {
+		ingredient string
+		want       []string
+	}{
+		{"unsalted butter", []string{AllergenDairy}},
+		{"ghee", []string{AllergenDairy}},
+		{"Parmesan, grated", []string{AllergenDairy}},
+		{"large eggs", []string{AllergenEggs}},
+		{"mayonnaise", []string{AllergenEggs}},
+		{"anchovies in oil", []string{AllergenFish}},
+		{"Worcestershire sauce", []string{AllergenFish}},
+		{"raw shrimp, peeled", []string{AllergenShellfish}},
+		{"toasted pine nuts", []string{AllergenTreeNuts}},
+		{"roasted peanuts", []string{AllergenPeanuts}},
+		{"creamy peanut butter", []string{AllergenPeanuts}},
+		{"all-purpose flour", []string{AllergenWheat, AllergenGluten}},
+		{"pearl barley", []string{AllergenGluten}},
+		{"soy sauce", []string{AllergenWheat, AllergenGluten, AllergenSoy}},
+		{"firm tofu", []string{AllergenSoy}},
+		{"tahini", []string{AllergenSesame}},
+		{"coconut milk", []string{}},
+		{"cream of tartar", []string{}},
+		{"dairy-free cheese", []string{}},
+		{"gluten-free spaghetti", []string{}},
+		{"almond flour", []string{AllergenTreeNuts}},
+		{"water chestnuts", []string{}},
+		{"oyster mushrooms", []string{}},
+		{"eggplant", []string{}},
+		{"nutmeg", []string{}},
+		{"butternut squash", []string{}},
+		{"buckwheat", []string{}},
+	}
+	for _, tt := range tests {
+		if got := DetectAllergens([]Ingredient{{Name: tt.ingredient}}); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("DetectAllergens(%q) = %v, want %v", tt.ingredient, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAllergens(t *testing.T) {
+	got := normalizeAllergens([]string{" Milk", "dairy", "Peanut", "soya", "Nuts", "celery"})
+	want := []string{AllergenDairy, AllergenPeanuts, AllergenSoy, AllergenTreeNuts, "celery"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("normalizeAllergens = %v, want %v", got, want)
+	}
+}
+
+func TestAllergensAreMergedWithDetectedOnes(t *testing.T) {
+	svc := NewService(&fakeRepository{}, usertest.NewUsers())
+	ctx := context.Background()
+	owner := uuid.New()
+
+	r, err := svc.Create(ctx, owner, CreateRequest{
+		Title:       "Pesto",
+		Ingredients: []Ingredient{{Name: "basil"}, {Name: "pine nuts"}, {Name: "parmesan"}},
+		Allergens:   []string{"Milk", "celery"},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if want := []string{AllergenDairy, "celery", AllergenTreeNuts}; !reflect.DeepEqual(r.Allergens, want) {
+		t.Fatalf("allergens = %v, want %v", r.Allergens, want)
+	}
+	if want := []string{AllergenTreeNuts}; !reflect.DeepEqual(r.UndeclaredAllergens, want) {
+		t.Fatalf("undeclared allergens = %v, want %v", r.UndeclaredAllergens, want)
+	}
+
+	// Removing the nuts drops the detected allergen but keeps the declared ones.
+	ingredients := []Ingredient{{Name: "basil"}, {Name: "parmesan"}}
+	r, err = svc.Update(ctx, owner, r.ID, UpdateRequest{Version: 1, Ingredients: &ingredients})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if want := []string{AllergenDairy, "celery"}; !reflect.DeepEqual(r.Allergens, want) || len(r.UndeclaredAllergens) != 0 {
+		t.Fatalf("allergens = %v, undeclared %v", r.Allergens, r.UndeclaredAllergens)
+	}
+
+	// Declaring a detected allergen clears the discrepancy.
+	ingredients = append(ingredients, Ingredient{Name: "walnuts"})
+	declared := []string{"dairy", "tree nuts"}
+	r, err = svc.Update(ctx, owner, r.ID, UpdateRequest{Version: 2, Ingredients: &ingredients, Allergens: &declared})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if want := []string{AllergenDairy, AllergenTreeNuts}; !reflect.DeepEqual(r.Allergens, want) || len(r.UndeclaredAllergens) != 0 {
+		t.Fatalf("allergens = %v, undeclared %v", r.Allergens, r.UndeclaredAllergens)
+	}
+}
+
+func TestRelabelUpdatesStoredLabels(t *testing.T) {
+	stale := &Recipe{ID: uuid.New(), Title: "Custard", Allergens: []string{"milk"},
+		Ingredients: []Ingredient{{Name: "whole milk"}, {Name: "eggs"}}}
+	diets := []string{DietVegetarian, DietVegan, DietPescatarian, DietDairyFree, DietNutFree}
+	current := &Recipe{ID: uuid.New(), Title: "Toast", Allergens: []string{AllergenWheat, AllergenGluten},
+		DietaryCategories: diets, UndeclaredDiets: diets, Ingredients: []Ingredient{{Name: "bread"}}}
+	repo := &fakeRepository{recipes: []*Recipe{stale, current}}
+
+	n, err := NewService(repo, usertest.NewUsers()).Relabel(context.Background())
+	if err != nil || n != 1 || len(repo.labeled) != 1 {
+		t.Fatalf("relabeled %d, %v; want only the custard", n, err)
+	}
+	got := repo.labeled[0]
+	if want := []string{AllergenDairy, AllergenEggs}; !reflect.DeepEqual(got.Allergens, want) {
+		t.Fatalf("allergens = %v, want %v", got.Allergens, want)
+	}
+	if want := []string{AllergenEggs}; !reflect.DeepEqual(got.UndeclaredAllergens, want) {
+		t.Fatalf("undeclared allergens = %v, want %v", got.UndeclaredAllergens, want)
+	}
+	if want := []string{DietVegetarian, DietPescatarian, DietGlutenFree, DietNutFree}; !reflect.DeepEqual(got.DietaryCategories, want) {
+		t.Fatalf("dietary categories = %v, want %v", got.DietaryCategories, want)
+	}
+}
@@ -0,0 +1,154 @@
+package recipe
+
+import (
+	"fmt"
+	"strings"
+
+	"alchemorsel/backend/internal/pkg/pdf"
+)
+
+// Layout of the printable recipe card, in points.
+const (
+	cardMargin  = 54
+	cardWidth   = pdf.LetterWidth - 2*cardMargin
+	cardTop     = pdf.LetterHeight - cardMargin
+	cardBottom  = cardMargin + 18 // room for the footer
+	cardIndent  = 16
+	cardBoxSize = 230
+)
+
+// cardWriter lays out text from the top of the page down, starting a new
+// page when the next block does not fit.
+type cardWriter struct {
+	doc   *pdf.Document
+	pages []*pdf.Page
+	page  *pdf.Page
+	y     float64
+}
+
+// need starts a new page unless height points fit above the bottom margin.
+func (w *cardWriter) need(height float64) {
+	if w.page == nil || w.y-height < cardBottom {
+		w.page = w.doc.AddPage()
+		w.pages = append(w.pages, w.page)
+		w.y = cardTop
+	}
+}
+
+// lines writes s wrapped to width, starting at x, with lead points between
+// baselines.
+func (w *cardWriter) lines(x, width float64, font pdf.Font, size, lead float64, s string) {
+	for _, line := range pdf.Wrap(s, font, size, width) {
+		w.need(lead)
+		w.y -= lead
+		w.page.Text(x, w.y, font, size, line)
+	}
+}
+
+// heading writes a section heading with a rule below it, keeping it on the
+// same page as the first line of the section.
+func (w *cardWriter) heading(s string) {
+	w.need(52)
+	w.y -= 30
+	w.page.Text(cardMargin, w.y, pdf.HelveticaBold, 13, s)
+	w.y -= 6
+	w.page.SetGray(0.6)
+	w.page.Line(cardMargin, w.y, cardMargin+cardWidth, w.y, 0.5)
+	w.page.SetGray(0)
+	w.y -= 4
+}
+
+// recipeCard lays out a recipe as a printable US Letter PDF: the title and
+// summary, the description, the ingredients as a bulleted list, numbered
+// instructions under their section headers and a box with the nutrition per
+// serving.
+func recipeCard(r *Recipe) []byte {
+	doc := pdf.New(pdf.LetterWidth, pdf.LetterHeight)
+	doc.SetTitle(r.Title)
+	w := &cardWriter{doc: doc}
+
+	w.lines(cardMargin, cardWidth, pdf.HelveticaBold, 22, 26, r.Title)
+	var meta []string
+	for _, s := range summary(r) {
+		meta = append(meta, s[0]+": "+s[1])
+	}
+	if len(meta) > 0 {
+		w.y -= 4
+		w.page.SetGray(0.35)
+		w.lines(cardMargin, cardWidth, pdf.Helvetica, 10, 14, strings.Join(meta, "   ·   "))
+		w.page.SetGray(0)
+	}
+	for _, l := range labels(r) {
+		w.lines(cardMargin, cardWidth, pdf.HelveticaOblique, 9, 12, l[0]+": "+l[1])
+	}
+	if r.Description != "" {
+		w.y -= 8
+		w.lines(cardMargin, cardWidth, pdf.Helvetica, 11, 15, r.Description)
+	}
+
+	w.heading("Ingredients")
+	for _, ing := range r.Ingredients {
+		w.need(14)
+		w.page.Text(cardMargin+4, w.y-14, pdf.Helvetica, 10.5, "•")
+		w.lines(cardMargin+cardIndent, cardWidth-cardIndent, pdf.Helvetica, 10.5, 14, FormatIngredient(ing))
+	}
+
+	w.heading("Instructions")
+	for i, step := range r.Instructions {
+		if step.Section != "" {
+			w.need(40)
+			w.y -= 8
+			w.lines(cardMargin, cardWidth, pdf.HelveticaBold, 11, 15, step.Section)
+		}
+		w.need(20)
+		w.y -= 6
+		w.page.Text(cardMargin, w.y-14, pdf.HelveticaBold, 10.5, fmt.Sprintf("%d.", i+1))
+		w.lines(cardMargin+cardIndent+4, cardWidth-cardIndent-4, pdf.Helvetica, 10.5, 14, FormatStep(step))
+	}
+
+	if rows := nutritionRows(r.NutritionalInfo); rows != nil {
+		w.nutritionBox(rows, nutritionSource(r))
+	}
+	w.footer(r.Title)
+	return doc.Bytes()
+}
+
+// nutritionBox draws the nutrition facts in a shaded box.
+func (w *cardWriter) nutritionBox(rows [][2]string, source string) {
+	height := 34 + float64(len(rows))*14
+	if source != "" {
+		height += 14
+	}
+	w.need(height + 24)
+	w.y -= 24
+	top := w.y
+	w.page.SetGray(0.93)
+	w.page.Rect(cardMargin, top-height, cardBoxSize, height)
+	w.page.SetGray(0)
+	w.page.Text(cardMargin+10, top-18, pdf.HelveticaBold, 11, "Nutrition per serving")
+	y := top - 20
+	for _, row := range rows {
+		y -= 14
+		w.page.Text(cardMargin+10, y, pdf.Helvetica, 10, row[0])
+		right := cardMargin + cardBoxSize - 10 - pdf.TextWidth(row[1], pdf.Helvetica, 10)
+		w.page.Text(right, y, pdf.Helvetica, 10, row[1])
+	}
+	if source != "" {
+		w.page.SetGray(0.35)
+		w.page.Text(cardMargin+10, y-14, pdf.HelveticaOblique, 8, source)
+		w.page.SetGray(0)
+	}
+	w.y = top - height
+}
+
+// footer numbers the pages of a card that runs over more than one page.
+func (w *cardWriter) footer(title string) {
+	if len(w.pages) < 2 {
+		return
+	}
+	for i, p := range w.pages {
+		p.SetGray(0.5)
+		p.Text(cardMargin, cardMargin, pdf.Helvetica, 8, fmt.Sprintf("%s — page %d of %d", title, i+1, len(w.pages)))
+		p.SetGray(0)
+	}
+}
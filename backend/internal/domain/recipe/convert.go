@@ -0,0 +1,42 @@
+package recipe
+
+import "alchemorsel/backend/internal/pkg/units"
+
+// ConvertRecipe expresses the recipe's ingredients and the temperatures of
+// its instructions in the given unit system. The ingredients as written are
+// kept in OriginalIngredients. Ingredients without a recognised unit are left
+// as they are.
+func ConvertRecipe(r *Recipe, system units.System) {
+	converted := make([]Ingredient, len(r.Ingredients))
+	for i, ing := range r.Ingredients {
+		converted[i] = convertIngredient(ing, system)
+	}
+	instructions := make([]Step, len(r.Instructions))
+	for i, step := range r.Instructions {
+		instructions[i] = convertStep(step, system)
+	}
+	r.OriginalIngredients = r.Ingredients
+	r.Ingredients = converted
+	r.Instructions = instructions
+	r.Units = system
+}
+
+func convertIngredient(ing Ingredient, system units.System) Ingredient {
+	if ing.Amount <= 0 || units.Unmeasured(ing.Unit) {
+		return ing
+	}
+	u, ok := units.Lookup(ing.Unit)
+	if !ok {
+		return ing
+	}
+	amount, to := units.ForSystem(ing.Amount, u, ing.Name, system)
+	if to == u {
+		return ing
+	}
+	if ing.AmountMax > 0 {
+		ing.AmountMax = units.Round(ing.AmountMax*amount/ing.Amount, to)
+	}
+	ing.Amount = units.Round(amount, to)
+	ing.Unit = to.Label(max(ing.Amount, ing.AmountMax))
+	return ing
+}
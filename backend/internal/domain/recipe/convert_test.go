@@ -0,0 +1,99 @@
+package recipe
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user"
+	"alchemorsel/backend/internal/domain/user/usertest"
+	"alchemorsel/backend/internal/pkg/units"
+)
+
+func TestConvertRecipe(t *testing.T) {
+	r := &Recipe{
+		Ingredients: []Ingredient{
+			{Name: "all-purpose flour", Amount: 2, Unit: "cups"},
+			{Name: "milk", Amount: 1, Unit: "cup"},
+			{Name: "butter", Amount: 4, Unit: "oz"},
+			{Name: "salt", Amount: 1, Unit: "pinch"},
+			{Name: "eggs", Amount: 2},
+		},
+		Instructions: []Step{
+			{Text: "Preheat the oven to 350°F."},
+			{Text: "Bake.", Duration: 30, Temperature: &Temperature{Value: 350, Unit: Fahrenheit}},
+		},
+	}
+	original := append([]Ingredient(nil), r.Ingredients...)
+
+	ConvertRecipe(r, units.Metric)
+
+	want := []Ingredient{
+		{Name: "all-purpose flour", Amount: 240, Unit: "g"},
+		{Name: "milk", Amount: 235, Unit: "ml"},
+		{Name: "butter", Amount: 115, Unit: "g"},
+		{Name: "salt", Amount: 1, Unit: "pinch"},
+		{Name: "eggs", Amount: 2},
+	}
+	if !reflect.DeepEqual(r.Ingredients, want) {
+		t.Fatalf("converted ingredients = %+v, want %+v", r.Ingredients, want)
+	}
+	if !reflect.DeepEqual(r.OriginalIngredients, original) {
+		t.Fatalf("original ingredients = %+v", r.OriginalIngredients)
+	}
+	if r.Instructions[0].Text != "Preheat the oven to 175°C (350°F)." || r.Units != units.Metric {
+		t.Fatalf("unexpected instructions %+v or units %q", r.Instructions[0], r.Units)
+	}
+	if temp := r.Instructions[1].Temperature; *temp != (Temperature{Value: 175, Unit: Celsius}) {
+		t.Fatalf("step temperature = %+v, want 175 C", temp)
+	}
+}
+
+func TestConvertUsesViewerPreference(t *testing.T) {
+	viewer := uuid.New()
+	id := uuid.New()
+	repo := &fakeRepository{recipes: []*Recipe{{
+		ID:          id,
+		UserID:      viewer,
+		Servings:    2,
+		IsPublic:    true,
+		Ingredients: []Ingredient{{Name: "sugar", Amount: 100, Unit: "g"}},
+	}}}
+	users := usertest.NewUsers(&user.User{ID: viewer, UnitSystem: "us"})
+	svc := NewService(repo, users)
+	ctx := context.Background()
+
+	r, err := svc.Convert(ctx, &viewer, id, "")
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if got := r.Ingredients[0]; got.Unit != "cup" || got.Amount != 0.5 || r.Units != units.US {
+		t.Fatalf("expected the stored preference to apply, got %+v in %q", got, r.Units)
+	}
+
+	r, err = svc.Convert(ctx, &viewer, id, units.Metric)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if got := r.Ingredients[0]; got.Unit != "g" || got.Amount != 100 {
+		t.Fatalf("expected the requested system to win, got %+v", got)
+	}
+
+	scaled, err := svc.Scale(ctx, &viewer, id, 20, "")
+	if err != nil {
+		t.Fatalf("scale: %v", err)
+	}
+	if got := scaled.Ingredients[0].Display; got != "5 cups" {
+		t.Fatalf("expected the scaled amount in US units, got %q", got)
+	}
+
+	r, err = svc.Convert(ctx, nil, id, "")
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if r.Units != "" || r.OriginalIngredients != nil {
+		t.Fatalf("expected anonymous viewers to see the recipe as written, got %q", r.Units)
+	}
+}
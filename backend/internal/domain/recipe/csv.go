@@ -0,0 +1,207 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvColumns maps the column names accepted in CSV headers, compared
+// without regard to case, underscores or hyphens, to the field they fill.
+var csvColumns = map[string]string{
+	"title": "title", "name": "title", "recipe": "title", "recipe name": "title",
+	"description": "description", "summary": "description",
+	"notes": "notes", "note": "notes",
+	"source": "source", "source url": "source", "url": "source",
+	"ingredients": "ingredients", "ingredient": "ingredients",
+	"instructions": "instructions", "directions": "instructions", "method": "instructions", "steps": "instructions",
+	"prep time": "prep_time", "prep": "prep_time", "preparation time": "prep_time",
+	"cook time": "cook_time", "cook": "cook_time", "cooking time": "cook_time",
+	"total time": "total_time", "time": "total_time",
+	"servings": "servings", "yield": "servings", "serves": "servings",
+	"category": "category", "course": "category",
+	"tags": "tags", "keywords": "tags", "categories": "tags",
+	"dietary categories": "dietary_categories", "diets": "dietary_categories", "diet": "dietary_categories",
+	"allergens": "allergens",
+	"image url": "image_url", "image": "image_url", "photo": "image_url", "photo url": "image_url",
+	"calories": "calories", "protein": "proteinContent", "carbohydrates": "carbohydrateContent",
+	"carbs": "carbohydrateContent", "fat": "fatContent", "fiber": "fiberContent",
+	"sugar": "sugarContent", "sodium": "sodiumContent",
+}
+
+// csvHeader is the header written on export.
+var csvHeader = []string{
+	"title", "description", "category", "servings", "prep_time", "cook_time", "ingredients",
+	"instructions", "tags", "dietary_categories", "allergens", "calories", "protein",
+	"carbohydrates", "fat", "fiber", "sugar", "sodium", "image_url",
+}
+
+// parseCSV reads recipes from a CSV file whose first row names the columns.
+// Commas, semicolons and tabs are accepted as separators. Ingredients and
+// instructions are one per line within their cell, or separated by
+// semicolons or vertical bars when the cell has a single line.
+func parseCSV(data []byte) ([]ImportItem, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = csvSeparator(data)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, unreadableFile(FormatCSV, "file has no header row")
+	}
+	fields := make([]string, len(header))
+	titled := false
+	for i, name := range header {
+		name = strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+			return r == ' ' || r == '_' || r == '-'
+		}), " ")
+		fields[i] = csvColumns[name]
+		titled = titled || fields[i] == "title"
+	}
+	if !titled {
+		return nil, unreadableFile(FormatCSV, "header has no title column")
+	}
+
+	var items []ImportItem
+	for len(items) <= MaxImportItems {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, unreadableFile(FormatCSV, err.Error())
+			}
+			items = append(items, ImportItem{Err: err})
+			continue
+		}
+		row := map[string]string{}
+		for i, value := range record {
+			if i < len(fields) && fields[i] != "" && strings.TrimSpace(value) != "" {
+				row[fields[i]] = strings.TrimSpace(value)
+			}
+		}
+		if len(row) == 0 {
+			continue
+		}
+		items = append(items, csvItem(row))
+	}
+	return items, nil
+}
+
+// csvSeparator picks the separator used most in the first line.
+func csvSeparator(data []byte) rune {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	best, count := ',', bytes.Count(line, []byte(","))
+	for _, sep := range []rune{';', '\t'} {
+		if n := bytes.Count(line, []byte(string(sep))); n > count {
+			best, count = sep, n
+		}
+	}
+	return best
+}
+
+func csvItem(row map[string]string) ImportItem {
+	req := CreateRequest{
+		Title:           row["title"],
+		Category:        row["category"],
+		IngredientLines: cellList(row["ingredients"]),
+		Instructions:    TextSteps(cellList(row["instructions"])),
+	}
+	item := ImportItem{Title: req.Title}
+	if req.Title == "" {
+		item.Err = errors.New("recipe has no title")
+		return item
+	}
+	var description []string
+	for _, s := range []string{row["description"], row["notes"]} {
+		if s != "" {
+			description = append(description, s)
+		}
+	}
+	if row["source"] != "" {
+		description = append(description, "Source: "+row["source"])
+	}
+	req.Description = strings.Join(description, "\n\n")
+
+	req.Servings = servings(row["servings"])
+	req.PrepTime, _ = parseMinutes(row["prep_time"])
+	req.CookTime, _ = parseMinutes(row["cook_time"])
+	if total, ok := parseMinutes(row["total_time"]); ok && req.PrepTime == 0 && req.CookTime == 0 {
+		req.CookTime = total
+	}
+	addTags(&req, strings.FieldsFunc(row["tags"], isListSeparator)...)
+	req.DietaryCategories = strings.FieldsFunc(row["dietary_categories"], isListSeparator)
+	req.Allergens = strings.FieldsFunc(row["allergens"], isListSeparator)
+
+	nutrients := map[string]any{}
+	for _, key := range []string{"calories", "proteinContent", "carbohydrateContent", "fatContent", "fiberContent", "sugarContent", "sodiumContent"} {
+		if v, ok := row[key]; ok {
+			nutrients[key] = v
+		}
+	}
+	nutrition(nutrients, &req.NutritionalInfo)
+	if url := row["image_url"]; url != "" {
+		req.ImageURL = &url
+	}
+	item.Request = req
+	return item
+}
+
+// cellList splits a cell holding a list, one item per line or, on a single
+// line, separated by semicolons or vertical bars.
+func cellList(s string) []string {
+	if !strings.Contains(s, "\n") {
+		s = strings.NewReplacer(";", "\n", "|", "\n").Replace(s)
+	}
+	return textLines(s)
+}
+
+func isListSeparator(r rune) bool {
+	return r == ',' || r == ';'
+}
+
+// recipesCSV writes recipes as CSV with the columns of csvHeader.
+func recipesCSV(recipes []*Recipe) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(csvHeader)
+	for _, r := range recipes {
+		var ingredients []string
+		for _, ing := range r.Ingredients {
+			ingredients = append(ingredients, FormatIngredient(ing))
+		}
+		var image string
+		if r.ImageURL != nil {
+			image = *r.ImageURL
+		}
+		// Unknown nutrition is left blank rather than written as zeros.
+		nutrients := make([]string, 7)
+		if n := r.NutritionalInfo; n != (NutritionalInfo{}) {
+			for i, v := range []int{n.Calories, n.Protein, n.Carbohydrates, n.Fat, n.Fiber, n.Sugar, n.Sodium} {
+				nutrients[i] = strconv.Itoa(v)
+			}
+		}
+		w.Write(append([]string{
+			r.Title, r.Description, r.Category, positive(r.Servings), positive(r.PrepTime), positive(r.CookTime),
+			strings.Join(ingredients, "\n"), strings.Join(stepLines(r.Instructions), "\n"), strings.Join(r.Tags, ", "),
+			strings.Join(r.DietaryCategories, ", "), strings.Join(r.Allergens, ", "),
+		}, append(nutrients, image)...))
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// positive writes n, or nothing when it is not positive.
+func positive(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
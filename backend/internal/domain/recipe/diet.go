@@ -0,0 +1,249 @@
+package recipe
+
+import "fmt"
+
+// Diets known to the classifier.
+const (
+	DietVegetarian  = "vegetarian"
+	DietVegan       = "vegan"
+	DietPescatarian = "pescatarian"
+	DietGlutenFree  = "gluten-free"
+	DietDairyFree   = "dairy-free"
+	DietNutFree     = "nut-free"
+	DietKeto        = "keto"
+	DietLowCarb     = "low-carb"
+	DietLowSodium   = "low-sodium"
+)
+
+// Nutrition thresholds per serving used by the classifier.
+const (
+	ketoMaxNetCarbs    = 10  // grams
+	ketoMinFatCalories = 0.6 // share of calories from fat
+	lowCarbMaxNetCarbs = 20  // grams
+	lowSodiumMax       = 140 // milligrams, as defined by the FDA
+)
+
+// dietAliases maps other spellings of a diet to the classifier's name.
+var dietAliases = map[string]string{
+	"veggie":       DietVegetarian,
+	"pescetarian":  DietPescatarian,
+	"gluten free":  DietGlutenFree,
+	"dairy free":   DietDairyFree,
+	"nut free":     DietNutFree,
+	"ketogenic":    DietKeto,
+	"low carb":     DietLowCarb,
+	"low sodium":   DietLowSodium,
+	"low-salt":     DietLowSodium,
+	"low salt":     DietLowSodium,
+	"plant-based":  DietVegan,
+	"plant based":  DietVegan,
+	"lacto-ovo":    DietVegetarian,
+	"non-dairy":    DietDairyFree,
+	"lactose-free": DietDairyFree,
+}
+
+// Ingredient groups excluded by diets, besides the allergens.
+var (
+	meatRule = ingredientRule{
+		name: "meat",
+		keywords: []string{
+			"meat", "beef", "steak", "veal", "pork", "bacon", "ham", "pancetta", "prosciutto",
+			"guanciale", "lard", "sausage", "chorizo", "salami", "pepperoni", "hot dog", "lamb",
+			"mutton", "goat", "venison", "rabbit", "chicken", "turkey", "duck", "goose", "quail",
+			"liver", "oxtail", "brisket", "mince", "meatball", "bone broth", "suet",
+			"foie gras", "pâté", "pate", "terrine", "mortadella", "bresaola", "capicola", "coppa",
+			"soppressata", "speck", "jamón", "jamon", "lardons", "pastrami", "bologna", "kielbasa",
+			"andouille", "bratwurst", "frankfurter", "jerky", "biltong", "bison", "boar", "elk",
+			"pheasant", "partridge", "pigeon", "squab", "tripe", "sweetbreads", "giblets", "offal",
+			"chicharron", "tallow", "schmaltz", "poultry",
+		},
+		except: []string{"goat cheese", "goat milk", "coconut meat", "crab meat", "lobster meat"},
+		unless: []string{"vegan", "vegetarian", "meatless", "plant-based", "veggie", "meat-free"},
+	}
+	gelatinRule = ingredientRule{
+		name:     "gelatin",
+		keywords: []string{"gelatin", "gelatine", "isinglass"},
+		unless:   []string{"vegan", "agar"},
+	}
+	honeyRule = ingredientRule{
+		name:     "honey",
+		keywords: []string{"honey", "honeycomb", "royal jelly"},
+	}
+)
+
+// dietRule defines a diet by the ingredient groups it excludes and, for
+// diets defined by macros, a check of the nutrition per serving that returns
+// the reasons the recipe fails it.
+type dietRule struct {
+	diet      string
+	excludes  []ingredientRule
+	nutrition func(NutritionalInfo) []string
+}
+
+// dietRules lists the diets in the order they are reported.
+var dietRules = []dietRule{
+	{diet: DietVegetarian, excludes: []ingredientRule{meatRule, gelatinRule, allergenRule(AllergenFish), allergenRule(AllergenShellfish)}},
+	{diet: DietVegan, excludes: []ingredientRule{
+		meatRule, gelatinRule, allergenRule(AllergenFish), allergenRule(AllergenShellfish),
+		allergenRule(AllergenDairy), allergenRule(AllergenEggs), honeyRule,
+	}},
+	{diet: DietPescatarian, excludes: []ingredientRule{meatRule, gelatinRule}},
+	{diet: DietGlutenFree, excludes: []ingredientRule{allergenRule(AllergenGluten)}},
+	{diet: DietDairyFree, excludes: []ingredientRule{allergenRule(AllergenDairy)}},
+	{diet: DietNutFree, excludes: []ingredientRule{allergenRule(AllergenTreeNuts), allergenRule(AllergenPeanuts)}},
+	{diet: DietKeto, nutrition: checkKeto},
+	{diet: DietLowCarb, nutrition: checkLowCarb},
+	{diet: DietLowSodium, nutrition: checkLowSodium},
+}
+
+// DietResult reports whether a recipe fits a diet and, if not, why. Declared
+// is set for diets the author declared, which stay among the recipe's
+// dietary categories even when the classifier does not confirm them.
+type DietResult struct {
+	Diet     string   `json:"diet"`
+	Eligible bool     `json:"eligible"`
+	Declared bool     `json:"declared,omitempty"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
+
+// declaredDietReason explains why a diet the classifier does not confirm is
+// still among the recipe's dietary categories.
+const declaredDietReason = "kept because the author declared it"
+
+// ClassifyDiets checks the recipe against every known diet.
+func ClassifyDiets(r *Recipe) []DietResult {
+	declared := map[string]bool{}
+	for _, d := range normalizeDiets(r.declaredDiets()) {
+		declared[d] = true
+	}
+	results := make([]DietResult, 0, len(dietRules))
+	for _, rule := range dietRules {
+		var reasons []string
+		for _, group := range rule.excludes {
+			for _, name := range group.find(r.Ingredients) {
+				reasons = append(reasons, fmt.Sprintf("%s contains %s", name, group.name))
+			}
+		}
+		if rule.nutrition != nil {
+			reasons = append(reasons, rule.nutrition(r.NutritionalInfo)...)
+		}
+		res := DietResult{Diet: rule.diet, Eligible: len(reasons) == 0, Declared: declared[rule.diet], Reasons: reasons}
+		if res.Declared && !res.Eligible {
+			res.Reasons = append(res.Reasons, declaredDietReason)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// applyDiets sets the recipe's dietary categories to the declared ones
+// together with the known diets it fits, and records the diets the author
+// did not declare. Declared diets are kept even when the classifier does not
+// confirm them, and labels it does not know, such as "halal", are kept as
+// entered.
+func applyDiets(r *Recipe, declared []string) {
+	r.DietaryCategories = normalizeDiets(declared)
+	r.UndeclaredDiets = []string{}
+	known := map[string]bool{}
+	categories := []string{}
+	for _, res := range ClassifyDiets(r) {
+		known[res.Diet] = true
+		if res.Declared || res.Eligible {
+			categories = append(categories, res.Diet)
+		}
+		if !res.Declared && res.Eligible {
+			r.UndeclaredDiets = append(r.UndeclaredDiets, res.Diet)
+		}
+	}
+	for _, label := range r.DietaryCategories {
+		if !known[label] {
+			categories = append(categories, label)
+		}
+	}
+	r.DietaryCategories = categories
+}
+
+// declaredDiets returns the dietary categories the author declared, leaving
+// out those that were only inferred by the classifier.
+func (r *Recipe) declaredDiets() []string {
+	undeclared := map[string]bool{}
+	for _, d := range r.UndeclaredDiets {
+		undeclared[d] = true
+	}
+	declared := []string{}
+	for _, d := range r.DietaryCategories {
+		if !undeclared[d] {
+			declared = append(declared, d)
+		}
+	}
+	return declared
+}
+
+// normalizeDiets normalizes diet labels like normalizeLabels and maps other
+// spellings of known diets to the classifier's names.
+func normalizeDiets(labels []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, l := range normalizeLabels(labels) {
+		if alias, ok := dietAliases[l]; ok {
+			l = alias
+		}
+		if !seen[l] {
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+func checkKeto(n NutritionalInfo) []string {
+	if n.Calories == 0 {
+		return []string{"nutrition is unknown"}
+	}
+	var reasons []string
+	if carbs := netCarbs(n); carbs > ketoMaxNetCarbs {
+		reasons = append(reasons, fmt.Sprintf("%d g net carbohydrates per serving exceeds %d g", carbs, ketoMaxNetCarbs))
+	}
+	if share := float64(n.Fat*9) / float64(n.Calories); share < ketoMinFatCalories {
+		reasons = append(reasons, fmt.Sprintf("%.0f%% of calories from fat is below %.0f%%", share*100, ketoMinFatCalories*100))
+	}
+	return reasons
+}
+
+func checkLowCarb(n NutritionalInfo) []string {
+	if n.Calories == 0 {
+		return []string{"nutrition is unknown"}
+	}
+	if carbs := netCarbs(n); carbs > lowCarbMaxNetCarbs {
+		return []string{fmt.Sprintf("%d g net carbohydrates per serving exceeds %d g", carbs, lowCarbMaxNetCarbs)}
+	}
+	return nil
+}
+
+func checkLowSodium(n NutritionalInfo) []string {
+	if n.Calories == 0 {
+		return []string{"nutrition is unknown"}
+	}
+	if n.Sodium > lowSodiumMax {
+		return []string{fmt.Sprintf("%d mg sodium per serving exceeds %d mg", n.Sodium, lowSodiumMax)}
+	}
+	return nil
+}
+
+// netCarbs returns the carbohydrates per serving that are not fiber.
+func netCarbs(n NutritionalInfo) int {
+	if n.Fiber > n.Carbohydrates {
+		return 0
+	}
+	return n.Carbohydrates - n.Fiber
+}
+
+// allergenRule returns the detector rule for the allergen.
+func allergenRule(name string) ingredientRule {
+	for _, rule := range allergenRules {
+		if rule.name == name {
+			return rule
+		}
+	}
+	panic("recipe: unknown allergen " + name)
+}
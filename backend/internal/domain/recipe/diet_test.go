@@ -0,0 +1,136 @@
+package recipe
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user/usertest"
+)
+
+func TestClassifyDiets(t *testing.T) {
+	tests := []struct {
+		name        string
+		ingredients []string
+		nutrition   NutritionalInfo
+		want        []string
+	}{
+		{
+			name:        "vegetable soup",
+			ingredients: []string{"carrots", "vegetable stock", "olive oil"},
+			nutrition:   NutritionalInfo{Calories: 120, Carbohydrates: 14, Fiber: 4, Fat: 6, Sodium: 90},
+			want:        []string{DietVegetarian, DietVegan, DietPescatarian, DietGlutenFree, DietDairyFree, DietNutFree, DietLowCarb, DietLowSodium},
+		},
+		{
+			name:        "salmon with butter",
+			ingredients: []string{"salmon fillet", "unsalted butter", "lemon"},
+			nutrition:   NutritionalInfo{Calories: 400, Carbohydrates: 2, Fat: 30, Protein: 30, Sodium: 300},
+			want:        []string{DietPescatarian, DietGlutenFree, DietNutFree, DietKeto, DietLowCarb},
+		},
+		{
+			name:        "pasta carbonara",
+			ingredients: []string{"spaghetti", "eggs", "pancetta", "parmesan"},
+			nutrition:   NutritionalInfo{Calories: 700, Carbohydrates: 80, Fiber: 3, Fat: 30, Sodium: 900},
+			want:        []string{DietNutFree},
+		},
+		{
+			name:        "vegan stand-ins",
+			ingredients: []string{"vegan sausages", "dairy-free cheese", "gluten-free pasta", "maple syrup"},
+			want:        []string{DietVegetarian, DietVegan, DietPescatarian, DietGlutenFree, DietDairyFree, DietNutFree},
+		},
+		{
+			name:        "cured meats",
+			ingredients: []string{"mortadella", "bresaola", "foie gras", "crackers"},
+			want:        []string{DietDairyFree, DietNutFree},
+		},
+		{
+			name:        "honey and gelatin",
+			ingredients: []string{"honey", "powdered gelatin", "greek yogurt"},
+			want:        []string{DietGlutenFree, DietNutFree},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Recipe{NutritionalInfo: tt.nutrition}
+			for _, name := range tt.ingredients {
+				r.Ingredients = append(r.Ingredients, Ingredient{Name: name})
+			}
+			got := []string{}
+			for _, res := range ClassifyDiets(r) {
+				if res.Eligible {
+					got = append(got, res.Diet)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("eligible diets = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyDietsExplainsFailures(t *testing.T) {
+	r := &Recipe{
+		Ingredients:     []Ingredient{{Name: "chicken thighs"}, {Name: "soy sauce"}, {Name: "honey"}},
+		NutritionalInfo: NutritionalInfo{Calories: 500, Carbohydrates: 30, Fat: 20, Sodium: 1200},
+	}
+	reasons := map[string][]string{}
+	for _, res := range ClassifyDiets(r) {
+		reasons[res.Diet] = res.Reasons
+	}
+	want := map[string][]string{
+		DietVegetarian:  {"chicken thighs contains meat"},
+		DietVegan:       {"chicken thighs contains meat", "honey contains honey"},
+		DietPescatarian: {"chicken thighs contains meat"},
+		DietGlutenFree:  {"soy sauce contains gluten"},
+		DietDairyFree:   nil,
+		DietNutFree:     nil,
+		DietKeto:        {"30 g net carbohydrates per serving exceeds 10 g", "36% of calories from fat is below 60%"},
+		DietLowCarb:     {"30 g net carbohydrates per serving exceeds 20 g"},
+		DietLowSodium:   {"1200 mg sodium per serving exceeds 140 mg"},
+	}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Fatalf("reasons = %v, want %v", reasons, want)
+	}
+}
+
+func TestDietaryCategoriesAreComputed(t *testing.T) {
+	svc := NewService(&fakeRepository{}, usertest.NewUsers())
+	ctx := context.Background()
+	owner := uuid.New()
+
+	r, err := svc.Create(ctx, owner, CreateRequest{
+		Title:             "Chili",
+		Ingredients:       []Ingredient{{Name: "kidney beans"}, {Name: "ground beef"}},
+		DietaryCategories: []string{"Vegan", "Halal", "gluten free"},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	want := []string{DietVegan, DietGlutenFree, DietDairyFree, DietNutFree, "halal"}
+	if !reflect.DeepEqual(r.DietaryCategories, want) {
+		t.Fatalf("dietary categories = %v, want %v", r.DietaryCategories, want)
+	}
+	if want := []string{DietDairyFree, DietNutFree}; !reflect.DeepEqual(r.UndeclaredDiets, want) {
+		t.Fatalf("undeclared diets = %v, want %v", r.UndeclaredDiets, want)
+	}
+	results, err := svc.Diets(ctx, &owner, r.ID)
+	if err != nil {
+		t.Fatalf("diets: %v", err)
+	}
+	vegan := results[1]
+	if vegan.Eligible || !vegan.Declared || !reflect.DeepEqual(vegan.Reasons, []string{"ground beef contains meat", declaredDietReason}) {
+		t.Fatalf("expected the declared vegan diet to be flagged, got %+v", vegan)
+	}
+
+	ingredients := []Ingredient{{Name: "kidney beans"}, {Name: "textured vegetable protein"}}
+	r, err = svc.Update(ctx, owner, r.ID, UpdateRequest{Version: 1, Ingredients: &ingredients})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	want = []string{DietVegetarian, DietVegan, DietPescatarian, DietGlutenFree, DietDairyFree, DietNutFree, "halal"}
+	if !reflect.DeepEqual(r.DietaryCategories, want) {
+		t.Fatalf("dietary categories = %v, want %v", r.DietaryCategories, want)
+	}
+}
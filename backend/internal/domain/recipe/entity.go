@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/pkg/units"
 )
 
 type Recipe struct {
@@ -12,27 +14,90 @@ type Recipe struct {
 	Title             string          `json:"title"`
 	Description       string          `json:"description"`
 	Ingredients       []Ingredient    `json:"ingredients"`
-	Instructions      []string        `json:"instructions"`
+	Instructions      []Step          `json:"instructions"`
 	PrepTime          int             `json:"prep_time"`
 	CookTime          int             `json:"cook_time"`
 	Servings          int             `json:"servings"`
 	Category          string          `json:"category"`
 	DietaryCategories []string        `json:"dietary_categories"`
 	Allergens         []string        `json:"allergens"`
+	Tags              []string        `json:"tags"`
 	NutritionalInfo   NutritionalInfo `json:"nutritional_info"`
 	ImageURL          *string         `json:"image_url,omitempty"`
 	IsPublic          bool            `json:"is_public"`
+	Version           int             `json:"version"` // incremented on every update
 	CreatedAt         time.Time       `json:"created_at"`
 	UpdatedAt         time.Time       `json:"updated_at"`
+	DeletedAt         *time.Time      `json:"deleted_at,omitempty"`
+	ForkedFrom        *ForkOrigin     `json:"forked_from,omitempty"`
+	RatingAverage     float64         `json:"rating_average"`
+	RatingCount       int             `json:"rating_count"`
+
+	// NutritionEstimate is set when NutritionalInfo was computed from the
+	// ingredients rather than entered by hand.
+	NutritionEstimate *NutritionEstimate `json:"nutrition_estimate,omitempty"`
+
+	// UndeclaredAllergens lists the allergens detected in the ingredients
+	// that the author did not declare. They are included in Allergens.
+	UndeclaredAllergens []string `json:"undeclared_allergens,omitempty"`
+
+	// UndeclaredDiets lists the diets the classifier found the recipe fits
+	// that the author did not declare. They are included in DietaryCategories.
+	UndeclaredDiets []string `json:"undeclared_diets,omitempty"`
+
+	// AllergenWarnings lists the recipe's allergens that conflict with the
+	// viewing user's allergies. It is computed per request and not persisted.
+	AllergenWarnings []string `json:"allergen_warnings,omitempty"`
+	// Units is the unit system the recipe was converted to for the viewer,
+	// and OriginalIngredients holds the ingredients as written. Both are
+	// computed per request and not persisted.
+	Units               units.System `json:"units,omitempty"`
+	OriginalIngredients []Ingredient `json:"original_ingredients,omitempty"`
+}
+
+// ForkOrigin attributes a fork to the recipe it was copied from. It is
+// recorded when the fork is made so that attribution is kept even if the
+// original later becomes private or is deleted.
+type ForkOrigin struct {
+	RecipeID       uuid.UUID `json:"recipe_id"`
+	Version        int       `json:"version"`
+	Title          string    `json:"title"`
+	AuthorID       uuid.UUID `json:"author_id"`
+	AuthorUsername string    `json:"author_username,omitempty"`
+}
+
+// Generation records a recipe generated for a user and what they asked
+// for, so that their generation history can inform recommendations.
+type Generation struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	RecipeID    uuid.UUID `json:"recipe_id"`
+	Style       string    `json:"style,omitempty"`
+	Ingredients []string  `json:"ingredients"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Ancestor is one step in a fork's ancestry. Recipe is nil when the ancestor
+// is no longer visible to the viewer, leaving only the attribution.
+type Ancestor struct {
+	Origin ForkOrigin `json:"origin"`
+	Recipe *Recipe    `json:"recipe,omitempty"`
 }
 
 type Ingredient struct {
-	Name     string  `json:"name"`
-	Amount   float64 `json:"amount"`
-	Unit     string  `json:"unit"`
-	Optional bool    `json:"optional"`
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+	// AmountMax is the upper end of a range such as "2-3 cups" and zero for
+	// a single amount.
+	AmountMax float64 `json:"amount_max,omitempty"`
+	Unit      string  `json:"unit"`
+	// Note holds preparation notes such as "finely chopped".
+	Note     string `json:"note,omitempty"`
+	Optional bool   `json:"optional"`
 }
 
+// NutritionalInfo is the nutrition of one serving: calories in kcal, sodium
+// in milligrams and the rest in grams.
 type NutritionalInfo struct {
 	Calories      int `json:"calories"`
 	Protein       int `json:"protein"`
@@ -42,3 +107,16 @@ type NutritionalInfo struct {
 	Sugar         int `json:"sugar"`
 	Sodium        int `json:"sodium"`
 }
+
+// Times returns the nutrition of the given number of servings.
+func (n NutritionalInfo) Times(servings int) NutritionalInfo {
+	return NutritionalInfo{
+		Calories:      n.Calories * servings,
+		Protein:       n.Protein * servings,
+		Carbohydrates: n.Carbohydrates * servings,
+		Fat:           n.Fat * servings,
+		Fiber:         n.Fiber * servings,
+		Sugar:         n.Sugar * servings,
+		Sodium:        n.Sodium * servings,
+	}
+}
@@ -0,0 +1,313 @@
+package recipe
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// Export formats accepted by Export, along with the formats of other recipe
+// managers. JSON-LD is the default.
+const (
+	FormatJSONLD   = "jsonld"
+	FormatMarkdown = "markdown"
+	FormatPDF      = "pdf"
+	FormatText     = "txt"
+)
+
+// exportFormat describes how a format is written. Formats with a bundle
+// function hold many recipes in one file; the others are packed into a zip
+// archive when several recipes are exported.
+type exportFormat struct {
+	ext, contentType string
+	render           func(*Recipe) []byte
+	bundle           func([]*Recipe) []byte
+	// bundleExt and bundleType override ext and contentType for bundles.
+	bundleExt, bundleType string
+}
+
+var exportFormats = map[string]exportFormat{
+	FormatJSONLD:   {ext: "jsonld", contentType: "application/ld+json", render: MarshalSchemaOrg},
+	FormatMarkdown: {ext: "md", contentType: "text/markdown; charset=utf-8", render: markdown},
+	FormatPDF:      {ext: "pdf", contentType: "application/pdf", render: recipeCard},
+	FormatText:     {ext: "txt", contentType: "text/plain; charset=utf-8", render: plainText},
+	FormatPaprika: {
+		ext: "paprikarecipe", contentType: "application/gzip", render: paprikaFile,
+		bundle: paprikaBundle, bundleExt: "paprikarecipes", bundleType: "application/zip",
+	},
+	FormatMealMaster: {ext: "mmf", contentType: "text/plain; charset=utf-8", render: single(mealMaster), bundle: mealMaster},
+	FormatCSV:        {ext: "csv", contentType: "text/csv; charset=utf-8", render: single(recipesCSV), bundle: recipesCSV},
+}
+
+// single adapts a bundle function to write one recipe.
+func single(bundle func([]*Recipe) []byte) func(*Recipe) []byte {
+	return func(r *Recipe) []byte { return bundle([]*Recipe{r}) }
+}
+
+// Document is an exported file.
+type Document struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Export renders a recipe as a file in the given format.
+func Export(r *Recipe, format string) (*Document, error) {
+	format, err := checkFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	f := exportFormats[format]
+	return &Document{Filename: fileSlug(r.Title) + "." + f.ext, ContentType: f.contentType, Data: f.render(r)}, nil
+}
+
+// checkFormat returns the export format to use, JSON-LD when format is
+// empty.
+func checkFormat(format string) (string, error) {
+	if format == "" {
+		return FormatJSONLD, nil
+	}
+	if _, ok := exportFormats[format]; !ok {
+		return "", validator.InvalidField("format", "format must be one of jsonld, markdown, pdf, txt, paprika, mealmaster or csv")
+	}
+	return format, nil
+}
+
+// ExportArchive writes recipes in one file named after name: a single file
+// for formats that hold many recipes, and otherwise a zip archive of the
+// files Export renders. Recipes with the same title are numbered.
+func ExportArchive(name string, recipes []*Recipe, format string) (*Document, error) {
+	format, err := checkFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	if f := exportFormats[format]; f.bundle != nil {
+		doc := &Document{Filename: fileSlug(name) + "." + f.ext, ContentType: f.contentType, Data: f.bundle(recipes)}
+		if f.bundleExt != "" {
+			doc.Filename, doc.ContentType = fileSlug(name)+"."+f.bundleExt, f.bundleType
+		}
+		return doc, nil
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	seen := map[string]int{}
+	for _, r := range recipes {
+		doc, err := Export(r, format)
+		if err != nil {
+			return nil, err
+		}
+		base, ext, _ := strings.Cut(doc.Filename, ".")
+		filename := uniqueName(seen, base) + "." + ext
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: filename, Method: zip.Deflate, Modified: r.UpdatedAt})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(doc.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return &Document{Filename: fileSlug(name) + ".zip", ContentType: "application/zip", Data: buf.Bytes()}, nil
+}
+
+// FormatIngredient writes an ingredient as a line of a recipe, such as
+// "2 cups flour, sifted (optional)" or "salt, to taste". ParseIngredient
+// reads it back.
+func FormatIngredient(ing Ingredient) string {
+	s := strings.TrimSpace(quantity(ing) + " " + ing.Name)
+	if ing.Amount <= 0 && tastePattern.MatchString(ing.Unit) {
+		s = ing.Name + ", " + ing.Unit
+	}
+	if ing.Note != "" {
+		s += ", " + ing.Note
+	}
+	if ing.Optional {
+		s += " (optional)"
+	}
+	return s
+}
+
+// uniqueName numbers the repeats of a name: "pancakes", "pancakes-2".
+func uniqueName(seen map[string]int, name string) string {
+	if seen[name]++; seen[name] > 1 {
+		return fmt.Sprintf("%s-%d", name, seen[name])
+	}
+	return name
+}
+
+// fileSlug turns a title into a file name.
+func fileSlug(title string) string {
+	slug := NormalizeTag(title)
+	if runes := []rune(slug); len(runes) > 80 {
+		slug = strings.TrimRight(string(runes[:80]), "-")
+	}
+	if slug == "" {
+		return "recipe"
+	}
+	return slug
+}
+
+// formatMinutes writes a duration such as "1 h 5 min".
+func formatMinutes(m int) string {
+	switch {
+	case m < 60:
+		return fmt.Sprintf("%d min", m)
+	case m%60 == 0:
+		return fmt.Sprintf("%d h", m/60)
+	default:
+		return fmt.Sprintf("%d h %d min", m/60, m%60)
+	}
+}
+
+// summary lists the servings and times of a recipe for the heading of an
+// export, as label and value pairs.
+func summary(r *Recipe) [][2]string {
+	var out [][2]string
+	if r.Servings > 0 {
+		out = append(out, [2]string{"Servings", strconv.Itoa(r.Servings)})
+	}
+	if r.PrepTime > 0 {
+		out = append(out, [2]string{"Prep", formatMinutes(r.PrepTime)})
+	}
+	if r.CookTime > 0 {
+		out = append(out, [2]string{"Cook", formatMinutes(r.CookTime)})
+	}
+	if r.PrepTime > 0 && r.CookTime > 0 {
+		out = append(out, [2]string{"Total", formatMinutes(r.PrepTime + r.CookTime)})
+	}
+	return out
+}
+
+// labels lists the category, diets, allergens and tags of a recipe, as label
+// and value pairs, leaving out those that are empty.
+func labels(r *Recipe) [][2]string {
+	var out [][2]string
+	for _, l := range [][2]string{
+		{"Category", r.Category},
+		{"Diets", strings.Join(r.DietaryCategories, ", ")},
+		{"Allergens", strings.Join(r.Allergens, ", ")},
+		{"Tags", strings.Join(r.Tags, ", ")},
+	} {
+		if l[1] != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// nutritionRows lists the nutrition of a serving, or nothing when it is
+// unknown.
+func nutritionRows(n NutritionalInfo) [][2]string {
+	if n == (NutritionalInfo{}) {
+		return nil
+	}
+	return [][2]string{
+		{"Calories", fmt.Sprintf("%d kcal", n.Calories)},
+		{"Protein", fmt.Sprintf("%d g", n.Protein)},
+		{"Carbohydrates", fmt.Sprintf("%d g", n.Carbohydrates)},
+		{"Fat", fmt.Sprintf("%d g", n.Fat)},
+		{"Fiber", fmt.Sprintf("%d g", n.Fiber)},
+		{"Sugar", fmt.Sprintf("%d g", n.Sugar)},
+		{"Sodium", fmt.Sprintf("%d mg", n.Sodium)},
+	}
+}
+
+// nutritionSource explains where estimated nutrition came from.
+func nutritionSource(r *Recipe) string {
+	if r.NutritionEstimate == nil {
+		return ""
+	}
+	return "Estimated from the ingredients."
+}
+
+func markdown(r *Recipe) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", r.Title)
+	if r.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", r.Description)
+	}
+	var meta []string
+	for _, s := range summary(r) {
+		meta = append(meta, fmt.Sprintf("**%s:** %s", s[0], s[1]))
+	}
+	if len(meta) > 0 {
+		fmt.Fprintf(&b, "%s\n\n", strings.Join(meta, " · "))
+	}
+	if l := labels(r); len(l) > 0 {
+		for _, s := range l {
+			fmt.Fprintf(&b, "- **%s:** %s\n", s[0], s[1])
+		}
+		b.WriteString("\n")
+	}
+	if r.ImageURL != nil && *r.ImageURL != "" {
+		fmt.Fprintf(&b, "![%s](%s)\n\n", r.Title, *r.ImageURL)
+	}
+	b.WriteString("## Ingredients\n\n")
+	for _, ing := range r.Ingredients {
+		fmt.Fprintf(&b, "- %s\n", FormatIngredient(ing))
+	}
+	b.WriteString("\n## Instructions\n")
+	for i, step := range r.Instructions {
+		if step.Section != "" || i == 0 {
+			b.WriteString("\n")
+		}
+		if step.Section != "" {
+			fmt.Fprintf(&b, "### %s\n\n", step.Section)
+		}
+		fmt.Fprintf(&b, "%d. %s\n", i+1, FormatStep(step))
+	}
+	if rows := nutritionRows(r.NutritionalInfo); rows != nil {
+		b.WriteString("\n## Nutrition per serving\n\n| Nutrient | Amount |\n| --- | ---: |\n")
+		for _, row := range rows {
+			fmt.Fprintf(&b, "| %s | %s |\n", row[0], row[1])
+		}
+		if src := nutritionSource(r); src != "" {
+			fmt.Fprintf(&b, "\n_%s_\n", src)
+		}
+	}
+	return []byte(b.String())
+}
+
+func plainText(r *Recipe) []byte {
+	var b strings.Builder
+	heading := func(s string) {
+		fmt.Fprintf(&b, "\n%s\n%s\n\n", s, strings.Repeat("-", len([]rune(s))))
+	}
+	fmt.Fprintf(&b, "%s\n%s\n\n", r.Title, strings.Repeat("=", len([]rune(r.Title))))
+	if r.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", r.Description)
+	}
+	for _, s := range append(summary(r), labels(r)...) {
+		fmt.Fprintf(&b, "%s: %s\n", s[0], s[1])
+	}
+	heading("Ingredients")
+	for _, ing := range r.Ingredients {
+		fmt.Fprintf(&b, "* %s\n", FormatIngredient(ing))
+	}
+	heading("Instructions")
+	for i, step := range r.Instructions {
+		if step.Section != "" {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "%s:\n", step.Section)
+		}
+		fmt.Fprintf(&b, "%d. %s\n", i+1, FormatStep(step))
+	}
+	if rows := nutritionRows(r.NutritionalInfo); rows != nil {
+		heading("Nutrition per serving")
+		for _, row := range rows {
+			fmt.Fprintf(&b, "%-15s %s\n", row[0], row[1])
+		}
+		if src := nutritionSource(r); src != "" {
+			fmt.Fprintf(&b, "\n%s\n", src)
+		}
+	}
+	return []byte(b.String())
+}
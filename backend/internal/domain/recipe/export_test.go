@@ -0,0 +1,174 @@
+package recipe
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user/usertest"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+func exportRecipe() *Recipe {
+	image := "https://example.com/soup.jpg"
+	return &Recipe{
+		Title:       "Tomato & Basil Soup",
+		Description: "A bright summer soup.",
+		Ingredients: []Ingredient{
+			{Name: "ripe tomatoes", Amount: 2, Unit: "lb", Note: "quartered"},
+			{Name: "olive oil", Amount: 1.5, AmountMax: 2, Unit: "tbsp"},
+			{Name: "basil leaves", Amount: 0.25, Unit: "cup", Optional: true},
+			{Name: "salt", Unit: "to taste"},
+		},
+		Instructions: []Step{
+			{Text: "Roast the tomatoes.", Duration: 45},
+			{Section: "To serve", Text: "Blend with the oil and basil.", Duration: 5},
+		},
+		PrepTime:          10,
+		CookTime:          65,
+		Servings:          4,
+		Category:          "Soup",
+		DietaryCategories: []string{DietVegan, DietGlutenFree},
+		Tags:              []string{"summer", "soup"},
+		NutritionalInfo:   NutritionalInfo{Calories: 180, Protein: 4, Carbohydrates: 14, Fat: 12, Fiber: 4, Sugar: 9, Sodium: 320},
+		ImageURL:          &image,
+	}
+}
+
+func TestFormatIngredient(t *testing.T) {
+	r := exportRecipe()
+	want := []string{
+		"2 lb ripe tomatoes, quartered",
+		"1 ½–2 tbsp olive oil",
+		"¼ cup basil leaves (optional)",
+		"salt, to taste",
+	}
+	for i, ing := range r.Ingredients {
+		if got := FormatIngredient(ing); got != want[i] {
+			t.Errorf("FormatIngredient(%+v) = %q, want %q", ing, got, want[i])
+		}
+	}
+}
+
+func TestSchemaOrgRoundTrip(t *testing.T) {
+	r := exportRecipe()
+	doc, err := Export(r, "")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if doc.Filename != "tomato-basil-soup.jsonld" || doc.ContentType != "application/ld+json" {
+		t.Fatalf("unexpected document %q, %q", doc.Filename, doc.ContentType)
+	}
+	res, err := ParseSchemaOrg(doc.Data)
+	if err != nil {
+		t.Fatalf("parse: %v\n%s", err, doc.Data)
+	}
+	req := res.Request
+	if req.Title != r.Title || req.Description != r.Description || req.PrepTime != 10 || req.CookTime != 65 ||
+		req.Servings != 4 || req.Category != "Soup" || *req.ImageURL != *r.ImageURL {
+		t.Fatalf("unexpected request %+v", req)
+	}
+	if !reflect.DeepEqual(req.Instructions, r.Instructions) || !reflect.DeepEqual(req.Tags, r.Tags) ||
+		req.NutritionalInfo != r.NutritionalInfo {
+		t.Fatalf("unexpected request %+v", req)
+	}
+	if want := []string{DietGlutenFree, DietVegan}; !reflect.DeepEqual(req.DietaryCategories, want) {
+		t.Fatalf("diets = %v, want %v", req.DietaryCategories, want)
+	}
+	if got := ParseIngredients(req.IngredientLines); !reflect.DeepEqual(got, r.Ingredients) {
+		t.Fatalf("ingredients =\n\t%+v\nwant\n\t%+v", got, r.Ingredients)
+	}
+	if len(res.Unmapped) != 0 {
+		t.Fatalf("unexpected unmapped properties %v", res.Unmapped)
+	}
+}
+
+func TestExportMarkdownAndText(t *testing.T) {
+	r := exportRecipe()
+	md, err := Export(r, FormatMarkdown)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	for _, want := range []string{
+		"# Tomato & Basil Soup\n",
+		"**Servings:** 4 · **Prep:** 10 min · **Cook:** 1 h 5 min · **Total:** 1 h 15 min\n",
+		"- **Diets:** vegan, gluten-free\n",
+		"- 2 lb ripe tomatoes, quartered\n",
+		"1. Roast the tomatoes. (45 min)\n\n### To serve\n\n2. Blend with the oil and basil. (5 min)\n",
+		"| Sodium | 320 mg |\n",
+	} {
+		if !strings.Contains(string(md.Data), want) {
+			t.Errorf("markdown is missing %q:\n%s", want, md.Data)
+		}
+	}
+
+	txt, err := Export(r, FormatText)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !strings.HasPrefix(string(txt.Data), "Tomato & Basil Soup\n===================\n") ||
+		!strings.Contains(string(txt.Data), "Calories        180 kcal\n") {
+		t.Errorf("unexpected text export:\n%s", txt.Data)
+	}
+}
+
+func TestExportPDF(t *testing.T) {
+	r := exportRecipe()
+	doc, err := Export(r, FormatPDF)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if !bytes.HasPrefix(doc.Data, []byte("%PDF-")) || !bytes.Contains(doc.Data, []byte("/Count 1")) {
+		t.Fatalf("expected a one page PDF")
+	}
+
+	// A long recipe runs onto further pages.
+	for i := 0; i < 60; i++ {
+		r.Instructions = append(r.Instructions, Step{Text: strings.Repeat("Stir the soup slowly and taste it. ", 4)})
+	}
+	doc, _ = Export(r, FormatPDF)
+	if bytes.Contains(doc.Data, []byte("/Count 1 ")) {
+		t.Fatalf("expected a long recipe to span several pages")
+	}
+}
+
+func TestExportRejectsUnknownFormat(t *testing.T) {
+	_, err := Export(exportRecipe(), "docx")
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Details["field"] != "format" {
+		t.Fatalf("expected invalid format, got %v", err)
+	}
+}
+
+func TestExportLibrary(t *testing.T) {
+	owner := uuid.New()
+	repo := &fakeRepository{recipes: []*Recipe{
+		{UserID: owner, Title: "Pancakes"},
+		{UserID: owner, Title: "Pancakes"},
+		{UserID: uuid.New(), Title: "Waffles"},
+	}}
+	doc, err := NewService(repo, usertest.NewUsers()).ExportLibrary(context.Background(), owner, FormatMarkdown)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if doc.Filename != "recipes.zip" || doc.ContentType != "application/zip" {
+		t.Fatalf("unexpected document %q, %q", doc.Filename, doc.ContentType)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(doc.Data), int64(len(doc.Data)))
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if want := []string{"pancakes.md", "pancakes-2.md"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,15 @@
+package recipe
+
+import "context"
+
+// Generator produces recipes using a language model. The returned embedding
+// describes the generated recipe in the model's vector space.
+type Generator interface {
+	Generate(ctx context.Context, req GenerateRequest) (*Recipe, []float64, error)
+}
+
+// Embedder converts free text into an embedding comparable with the
+// embeddings returned by a Generator.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float64, error)
+}
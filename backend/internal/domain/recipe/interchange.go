@@ -0,0 +1,192 @@
+package recipe
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// Formats of other recipe managers, read by ParseRecipes and written by
+// Export.
+const (
+	// FormatPaprika is Paprika's .paprikarecipes bundle, a zip archive of
+	// gzipped JSON recipes, or a single gzipped .paprikarecipe.
+	FormatPaprika = "paprika"
+	// FormatMealMaster is MealMaster's plain text format, which holds any
+	// number of recipes.
+	FormatMealMaster = "mealmaster"
+	// FormatCSV is a spreadsheet with a header row and a recipe per row.
+	FormatCSV = "csv"
+)
+
+// MaxImportItems bounds the number of recipes read from one file.
+const MaxImportItems = 5000
+
+// ImportItem is one recipe read from an import file. Err is set when the
+// recipe could not be read; Title then names it as well as the file allows.
+type ImportItem struct {
+	Title   string
+	Request CreateRequest
+	Err     error
+}
+
+// ParseRecipes reads the recipes of a file in one of the import formats,
+// detecting the format when it is empty. Recipes that cannot be read are
+// reported in their item; an error is returned only when the file as a
+// whole cannot be read.
+func ParseRecipes(format string, data []byte) ([]ImportItem, error) {
+	if format == "" {
+		format = DetectFormat(data)
+	}
+	var items []ImportItem
+	var err error
+	switch format {
+	case FormatPaprika:
+		items, err = parsePaprika(data)
+	case FormatMealMaster:
+		items, err = parseMealMaster(data)
+	case FormatCSV:
+		items, err = parseCSV(data)
+	default:
+		return nil, validator.InvalidField("format", "format must be one of paprika, mealmaster or csv")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, unreadableFile(format, "file contains no recipes")
+	}
+	if len(items) > MaxImportItems {
+		return nil, unreadableFile(format, "file contains more than "+strconv.Itoa(MaxImportItems)+" recipes")
+	}
+	return items, nil
+}
+
+// DetectFormat guesses the import format of a file from its content.
+func DetectFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")), bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		return FormatPaprika
+	case mealMasterHeader.Match(data):
+		return FormatMealMaster
+	}
+	return FormatCSV
+}
+
+// unreadableFile reports an import file that cannot be read at all.
+func unreadableFile(format, reason string) error {
+	return apperrors.NewWithDetails("unreadable_import_file", reason, 422, map[string]any{"format": format})
+}
+
+var (
+	clockPattern = regexp.MustCompile(`^(\d+):(\d{1,2})$`)
+	timePattern  = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(days?|d|hours?|hrs?|h|minutes?|mins?|m)`)
+	labelPattern = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z ]*?)\s*[:=-]?\s*(\d.*)$`)
+)
+
+// parseMinutes reads a duration written by people or other apps, such as
+// "1 hr 30 mins", "45 minutes", "1:30", "PT20M" or a bare number of minutes.
+func parseMinutes(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	if m, ok := parseDuration(s); ok {
+		return m, true
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return n, true
+	}
+	if m := clockPattern.FindStringSubmatch(s); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		mins, _ := strconv.Atoi(m[2])
+		return h*60 + mins, true
+	}
+	matches := timePattern.FindAllStringSubmatch(s, -1)
+	if matches == nil {
+		return 0, false
+	}
+	var minutes float64
+	for _, m := range matches {
+		n, _ := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64)
+		switch strings.ToLower(m[2])[0] {
+		case 'd':
+			n *= 24 * 60
+		case 'h':
+			n *= 60
+		}
+		minutes += n
+	}
+	return int(minutes + 0.5), true
+}
+
+// nutrientLabels maps the labels apps use in free-text nutrition to the
+// schema.org properties read by nutrition.
+var nutrientLabels = map[string]string{
+	"calories": "calories", "energy": "calories", "kcal": "calories",
+	"protein":       "proteinContent",
+	"carbohydrates": "carbohydrateContent", "carbohydrate": "carbohydrateContent",
+	"total carbohydrate": "carbohydrateContent", "total carbohydrates": "carbohydrateContent", "carbs": "carbohydrateContent",
+	"fat": "fatContent", "total fat": "fatContent",
+	"fiber": "fiberContent", "fibre": "fiberContent", "dietary fiber": "fiberContent",
+	"sugar": "sugarContent", "sugars": "sugarContent",
+	"sodium": "sodiumContent",
+}
+
+// nutritionText reads free-text nutrition such as "Calories: 320\nFat: 12 g",
+// with one nutrient per line or separated by commas. It reports whether any
+// value was read.
+func nutritionText(s string, info *NutritionalInfo) bool {
+	obj := map[string]any{}
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ',' || r == ';' }) {
+		m := labelPattern.FindStringSubmatch(part)
+		if m == nil {
+			continue
+		}
+		if key, ok := nutrientLabels[strings.ToLower(m[1])]; ok {
+			obj[key] = m[2]
+		}
+	}
+	return len(obj) > 0 && nutrition(obj, info)
+}
+
+// textLines splits text into trimmed, non-blank lines.
+func textLines(s string) []string {
+	var out []string
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// categoryAndTags uses the first of a list of categories as the recipe's
+// category, unless it has one, and the rest as tags.
+func categoryAndTags(req *CreateRequest, categories []string) {
+	for _, c := range categories {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if req.Category == "" {
+			req.Category = c
+			continue
+		}
+		addTags(req, c)
+	}
+}
+
+// addTags adds tags to a request as far as the tag limits allow.
+func addTags(req *CreateRequest, tags ...string) {
+	for _, t := range normalizeTags(tags) {
+		if len(req.Tags) < MaxTags && len([]rune(t)) <= MaxTagLength && !slices.Contains(req.Tags, t) {
+			req.Tags = append(req.Tags, t)
+		}
+	}
+}
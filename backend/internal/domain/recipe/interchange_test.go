@@ -0,0 +1,253 @@
+package recipe
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// importedRecipe creates the recipe an import item would, without the
+// service's derived fields.
+func importedRecipe(t *testing.T, item ImportItem) *Recipe {
+	t.Helper()
+	if item.Err != nil {
+		t.Fatalf("item %q: %v", item.Title, item.Err)
+	}
+	req := item.Request
+	return &Recipe{
+		Title: req.Title, Description: req.Description, PrepTime: req.PrepTime, CookTime: req.CookTime,
+		Servings: req.Servings, Category: req.Category, Tags: req.Tags, NutritionalInfo: req.NutritionalInfo,
+		ImageURL: req.ImageURL, Instructions: req.Instructions,
+		Ingredients: append(req.Ingredients, ParseIngredients(req.IngredientLines)...),
+	}
+}
+
+func TestInterchangeRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatPaprika, FormatMealMaster, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			first, second := exportRecipe(), exportRecipe()
+			second.Title = "Plain Rice"
+			second.Ingredients = []Ingredient{{
+				Name: "long grain rice", Amount: 1, Unit: "cup", Note: "rinsed well under cold running water until it runs clear",
+			}}
+			second.NutritionalInfo = NutritionalInfo{}
+			doc, err := ExportArchive("library", []*Recipe{first, second}, format)
+			if err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			if DetectFormat(doc.Data) != format {
+				t.Fatalf("DetectFormat = %q, want %q", DetectFormat(doc.Data), format)
+			}
+			items, err := ParseRecipes("", doc.Data)
+			if err != nil {
+				t.Fatalf("parse: %v\n%s", err, doc.Data)
+			}
+			if len(items) != 2 {
+				t.Fatalf("expected 2 recipes, got %d", len(items))
+			}
+			got := importedRecipe(t, items[0])
+			if !reflect.DeepEqual(got.Ingredients, first.Ingredients) {
+				t.Errorf("ingredients =\n\t%+v\nwant\n\t%+v", got.Ingredients, first.Ingredients)
+			}
+			// The formats keep steps as text.
+			if !reflect.DeepEqual(got.Instructions, TextSteps(stepLines(first.Instructions))) || got.Servings != 4 || got.Category != "Soup" ||
+				!reflect.DeepEqual(got.Tags, first.Tags) {
+				t.Errorf("unexpected recipe %+v", got)
+			}
+			// MealMaster has no place for times or nutrition.
+			if format != FormatMealMaster && (got.PrepTime != 10 || got.CookTime != 65 || got.NutritionalInfo != first.NutritionalInfo) {
+				t.Errorf("unexpected times or nutrition %+v", got)
+			}
+			rice := importedRecipe(t, items[1])
+			if rice.Title != "Plain Rice" || !reflect.DeepEqual(rice.Ingredients, second.Ingredients) {
+				t.Errorf("unexpected recipe %+v", rice)
+			}
+		})
+	}
+}
+
+func TestParsePaprika(t *testing.T) {
+	entry := func(json string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(json))
+		zw.Close()
+		return buf.Bytes()
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range map[string][]byte{
+		"Shakshuka.paprikarecipe": entry(`{
+			"name": "Shakshuka",
+			"description": "Eggs poached in spiced tomatoes.",
+			"notes": "Great with crusty bread.",
+			"source": "Grandma",
+			"ingredients": "For the sauce:\n2 tbsp olive oil\n1 (28 oz) can crushed tomatoes\n\n6 eggs",
+			"directions": "Simmer the sauce.\n\nCrack in the eggs and cover.",
+			"servings": "Serves 3",
+			"prep_time": "10 mins",
+			"cook_time": "1 hr 5 mins",
+			"categories": ["Breakfast", "Vegetarian"],
+			"nutritional_info": "Calories: 310\nProtein: 18g\nSodium: 0.6 g",
+			"image_url": "https://example.com/shakshuka.jpg",
+			"photo_data": "aGVsbG8="
+		}`),
+		"Broken.paprikarecipe": []byte("not gzip"),
+	} {
+		w, _ := zw.Create(name)
+		w.Write(data)
+	}
+	zw.Close()
+
+	items, err := ParseRecipes(FormatPaprika, buf.Bytes())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+	var ok, broken ImportItem
+	for _, item := range items {
+		if item.Err != nil {
+			broken = item
+		} else {
+			ok = item
+		}
+	}
+	if broken.Title != "Broken" || broken.Err == nil {
+		t.Fatalf("expected the broken recipe to be reported, got %+v", broken)
+	}
+	req := ok.Request
+	if req.Description != "Eggs poached in spiced tomatoes.\n\nGreat with crusty bread.\n\nSource: Grandma" {
+		t.Errorf("description = %q", req.Description)
+	}
+	if want := []string{"2 tbsp olive oil", "1 (28 oz) can crushed tomatoes", "6 eggs"}; !reflect.DeepEqual(req.IngredientLines, want) {
+		t.Errorf("ingredient lines = %q, want %q", req.IngredientLines, want)
+	}
+	if req.Servings != 3 || req.PrepTime != 10 || req.CookTime != 65 || req.Category != "Breakfast" ||
+		!reflect.DeepEqual(req.Tags, []string{"vegetarian"}) || len(req.Instructions) != 2 {
+		t.Errorf("unexpected request %+v", req)
+	}
+	if want := (NutritionalInfo{Calories: 310, Protein: 18, Sodium: 600}); req.NutritionalInfo != want {
+		t.Errorf("nutrition = %+v, want %+v", req.NutritionalInfo, want)
+	}
+}
+
+const mealMasterFile = `Some BBS header text
+
+MMMMM----- Recipe via Meal-Master (tm) v8.05
+
+      Title: Oatmeal Cookies
+ Categories: Cookies, Desserts
+      Yield: 36 servings
+
+      1 c  Butter; softened                    1 1/2 c  Flour
+    3/4 c  Brown sugar                         1    t  Baking soda
+      2    Eggs                                3    c  Rolled oats
+MMMMM--------------------------TOPPING-------------------------------
+      2 T  Coarse sugar, for sprinkling over the tops of
+           -the cookies before baking
+    1-2 pn Salt
+
+  Cream the butter and sugar, then beat in the eggs. Stir in the
+  flour, soda and oats.
+
+  Drop by spoonfuls onto a sheet and sprinkle with sugar.
+  Bake at 350F for 12 minutes.
+
+MMMMM
+
+MMMMM----- Recipe via Meal-Master (tm) v8.05
+
+ Categories: None
+
+      1 c  Water
+
+MMMMM
+`
+
+func TestParseMealMaster(t *testing.T) {
+	items, err := ParseRecipes("", []byte(mealMasterFile))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(items) != 2 || items[1].Err == nil {
+		t.Fatalf("expected a recipe and an untitled one, got %+v", items)
+	}
+	req := items[0].Request
+	if req.Title != "Oatmeal Cookies" || req.Servings != 36 || req.Category != "Cookies" ||
+		!reflect.DeepEqual(req.Tags, []string{"desserts"}) {
+		t.Errorf("unexpected request %+v", req)
+	}
+	want := []Ingredient{
+		{Name: "Butter; softened", Amount: 1, Unit: "cup"},
+		{Name: "Flour", Amount: 1.5, Unit: "cup"},
+		{Name: "Brown sugar", Amount: 0.75, Unit: "cup"},
+		{Name: "Baking soda", Amount: 1, Unit: "tsp"},
+		{Name: "Eggs", Amount: 2},
+		{Name: "Rolled oats", Amount: 3, Unit: "cup"},
+		{Name: "Coarse sugar", Amount: 2, Unit: "tbsp", Note: "for sprinkling over the tops of the cookies before baking"},
+		{Name: "Salt", Amount: 1, AmountMax: 2, Unit: "pinch"},
+	}
+	if !reflect.DeepEqual(req.Ingredients, want) {
+		t.Errorf("ingredients =\n\t%+v\nwant\n\t%+v", req.Ingredients, want)
+	}
+	if len(req.Instructions) != 2 || !strings.HasPrefix(req.Instructions[1].Text, "Drop by spoonfuls") {
+		t.Errorf("instructions = %+v", req.Instructions)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	data := "\ufeffName;Ingredients;Directions;Total Time;Yield;Keywords;Carbs;Unknown\n" +
+		"Lemonade;1 cup lemon juice|1 cup sugar|6 cups water;Stir.|Chill.;20 minutes;6;drinks, summer;40;x\n" +
+		";1 egg;Boil.;;;;;\n"
+	items, err := ParseRecipes(FormatCSV, []byte(data))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(items) != 2 || items[1].Err == nil {
+		t.Fatalf("expected a recipe and an untitled one, got %+v", items)
+	}
+	req := items[0].Request
+	if req.Title != "Lemonade" || req.CookTime != 20 || req.Servings != 6 || req.NutritionalInfo.Carbohydrates != 40 ||
+		!reflect.DeepEqual(req.Tags, []string{"drinks", "summer"}) || !reflect.DeepEqual(req.Instructions, TextSteps([]string{"Stir.", "Chill."})) ||
+		len(req.IngredientLines) != 3 {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	_, err = ParseRecipes(FormatCSV, []byte("ingredients,instructions\n1 egg,Boil.\n"))
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Code != "unreadable_import_file" {
+		t.Fatalf("expected a CSV without titles to be rejected, got %v", err)
+	}
+}
+
+func TestParseMinutes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"45", 45, true},
+		{"45 minutes", 45, true},
+		{"1 hr 30 mins", 90, true},
+		{"1.5 hours", 90, true},
+		{"2h15m", 135, true},
+		{"1:05", 65, true},
+		{"PT20M", 20, true},
+		{"overnight", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseMinutes(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseMinutes(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
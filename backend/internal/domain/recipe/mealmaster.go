@@ -0,0 +1,266 @@
+package recipe
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"alchemorsel/backend/internal/pkg/units"
+)
+
+var (
+	mealMasterHeader  = regexp.MustCompile(`(?im)^(?:MMMMM|-----).*meal-master`)
+	mealMasterEnd     = regexp.MustCompile(`^(?:MMMMM|-----)\s*$`)
+	mealMasterField   = regexp.MustCompile(`(?i)^\s*(title|categories|yield|servings)\s*:\s*(.*)$`)
+	mealMasterSection = regexp.MustCompile(`^\s*(?:MMMMM|---)`)
+	mealMasterAmount  = regexp.MustCompile(`^[\d/. -]*$`)
+	stepNumberPattern = regexp.MustCompile(`^\d+[.)]\s`)
+)
+
+// mealMasterUnits maps MealMaster's two-letter unit codes to units. The
+// size codes become part of the name.
+var mealMasterUnits = map[string]string{
+	"t": "tsp", "ts": "tsp", "T": "tbsp", "tb": "tbsp", "c": "cup", "pt": "pint", "qt": "quart",
+	"ga": "gallon", "fl": "fl oz", "oz": "oz", "lb": "lb", "ml": "ml", "cl": "cl", "dl": "dl",
+	"l": "l", "mg": "mg", "cg": "cg", "dg": "dg", "g": "g", "kg": "kg", "pn": "pinch",
+	"ds": "dash", "dr": "drop", "cn": "can", "pk": "package", "ct": "carton", "bn": "bunch",
+	"sl": "slice", "ea": "", "x": "", "sm": "small", "md": "medium", "lg": "large",
+}
+
+// mealMasterCodes are the codes written for units on export.
+var mealMasterCodes = map[string]string{
+	"tsp": "t", "tbsp": "T", "cup": "c", "pint": "pt", "quart": "qt", "gallon": "ga",
+	"fl oz": "fl", "oz": "oz", "lb": "lb", "ml": "ml", "l": "l", "g": "g", "kg": "kg",
+	"cl": "cl", "dl": "dl", "mg": "mg", "pinch": "pn", "dash": "ds", "drop": "dr", "can": "cn", "package": "pk", "carton": "ct",
+	"bunch": "bn", "slice": "sl",
+}
+
+// parseMealMaster reads every MealMaster recipe in a text file. Recipes
+// start with a "Recipe via Meal-Master" line and end with a line of M's or
+// dashes.
+func parseMealMaster(data []byte) ([]ImportItem, error) {
+	var items []ImportItem
+	var block []string
+	in := false
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		switch {
+		case mealMasterHeader.MatchString(line):
+			if in {
+				items = append(items, mealMasterItem(block))
+			}
+			in, block = true, nil
+		case in && mealMasterEnd.MatchString(line):
+			items = append(items, mealMasterItem(block))
+			in, block = false, nil
+		case in:
+			block = append(block, strings.TrimRight(line, " \t"))
+		}
+	}
+	if in {
+		items = append(items, mealMasterItem(block))
+	}
+	return items, nil
+}
+
+// mealMasterItem reads one recipe: header fields, ingredients up to the
+// first blank line and then directions, one step per paragraph.
+func mealMasterItem(block []string) ImportItem {
+	var req CreateRequest
+	i := 0
+	for ; i < len(block); i++ {
+		if strings.TrimSpace(block[i]) == "" {
+			continue
+		}
+		m := mealMasterField.FindStringSubmatch(block[i])
+		if m == nil {
+			break
+		}
+		value := strings.TrimSpace(m[2])
+		switch strings.ToLower(m[1]) {
+		case "title":
+			req.Title = value
+		case "categories":
+			if !strings.EqualFold(value, "none") {
+				categoryAndTags(&req, strings.Split(value, ","))
+			}
+		default:
+			req.Servings = servings(value)
+		}
+	}
+
+	var lines []mealMasterLine
+	for ; i < len(block) && strings.TrimSpace(block[i]) != ""; i++ {
+		if mealMasterSection.MatchString(block[i]) {
+			continue
+		}
+		for _, col := range mealMasterColumns(block[i]) {
+			l := splitMealMaster(col)
+			if l.amount == "" && l.code == "" && strings.HasPrefix(l.name, "-") && len(lines) > 0 {
+				lines[len(lines)-1].name += " " + strings.TrimSpace(l.name[1:])
+				continue
+			}
+			lines = append(lines, l)
+		}
+	}
+	for _, l := range lines {
+		if ing := l.ingredient(); ing.Name != "" {
+			req.Ingredients = append(req.Ingredients, ing)
+		}
+	}
+
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			req.Instructions = append(req.Instructions, Step{Text: strings.Join(para, " ")})
+			para = nil
+		}
+	}
+	for ; i < len(block); i++ {
+		line := strings.TrimSpace(block[i])
+		if line == "" || stepNumberPattern.MatchString(line) {
+			flush()
+		}
+		if line != "" {
+			para = append(para, line)
+		}
+	}
+	flush()
+
+	item := ImportItem{Title: req.Title, Request: req}
+	if req.Title == "" {
+		item.Err = fmt.Errorf("recipe has no title")
+	}
+	return item
+}
+
+// mealMasterColumns splits an ingredient line laid out in two columns, the
+// second starting at column 42.
+func mealMasterColumns(line string) []string {
+	r := []rune(line)
+	if len(r) > 52 && strings.TrimSpace(string(r[39:41])) == "" {
+		second := r[41:]
+		if mealMasterAmount.MatchString(string(second[:7])) && second[7] == ' ' {
+			return []string{string(r[:41]), string(second)}
+		}
+	}
+	return []string{line}
+}
+
+// mealMasterLine is an ingredient laid out as a seven character amount, a
+// two letter unit code and the name. Lines not laid out in columns are
+// kept whole in name.
+type mealMasterLine struct {
+	amount, code, name string
+}
+
+func splitMealMaster(col string) mealMasterLine {
+	r := []rune(col)
+	for len(r) < 11 {
+		r = append(r, ' ')
+	}
+	l := mealMasterLine{
+		amount: strings.TrimSpace(string(r[:7])),
+		code:   strings.TrimSpace(string(r[8:10])),
+		name:   strings.TrimSpace(string(r[10:])),
+	}
+	if !mealMasterAmount.MatchString(l.amount) || r[7] != ' ' || r[10] != ' ' {
+		return mealMasterLine{name: strings.TrimSpace(col)}
+	}
+	return l
+}
+
+func (l mealMasterLine) ingredient() Ingredient {
+	unit, known := mealMasterUnits[l.code]
+	if !known || unit == "" {
+		// Counted ingredients may name their unit, as in "2  cloves garlic".
+		return ParseIngredient(strings.Join(strings.Fields(l.amount+" "+l.code+" "+l.name), " "))
+	}
+	ing := ParseIngredient(l.name)
+	var a Ingredient
+	a.parseAmount(strings.ReplaceAll(l.amount, "-", " - "))
+	ing.Amount, ing.AmountMax = a.Amount, a.AmountMax
+	switch unit {
+	case "small", "medium", "large":
+		ing.Name = unit + " " + ing.Name
+	default:
+		ing.Unit = unit
+	}
+	return ing
+}
+
+// mealMaster writes recipes in MealMaster format. MealMaster has no place
+// for a description, times or nutrition, so they are left out.
+func mealMaster(recipes []*Recipe) []byte {
+	var b strings.Builder
+	for _, r := range recipes {
+		b.WriteString("MMMMM----- Recipe via Meal-Master (tm) v8.05\n\n")
+		fmt.Fprintf(&b, "      Title: %s\n", r.Title)
+		categories := r.Tags
+		if r.Category != "" {
+			categories = append([]string{r.Category}, r.Tags...)
+		}
+		if len(categories) == 0 {
+			categories = []string{"None"}
+		}
+		fmt.Fprintf(&b, " Categories: %s\n", strings.Join(categories, ", "))
+		if r.Servings > 0 {
+			fmt.Fprintf(&b, "   Servings: %d\n", r.Servings)
+		}
+		b.WriteString("\n")
+		for _, ing := range r.Ingredients {
+			writeMealMasterIngredient(&b, ing)
+		}
+		for _, step := range stepLines(r.Instructions) {
+			b.WriteString("\n")
+			for _, line := range wrapText(step, 70) {
+				fmt.Fprintf(&b, "  %s\n", line)
+			}
+		}
+		b.WriteString("\nMMMMM\n\n")
+	}
+	return []byte(b.String())
+}
+
+// writeMealMasterIngredient writes an ingredient in columns, continuing long
+// names on further lines.
+func writeMealMasterIngredient(b *strings.Builder, ing Ingredient) {
+	var amount, code string
+	if c, ok := mealMasterCodes[ing.Unit]; ing.Amount > 0 && (ok || !units.Unmeasured(ing.Unit)) {
+		amount = asciiAmount(ing.Amount)
+		if ing.AmountMax > ing.Amount {
+			amount += "-" + asciiAmount(ing.AmountMax)
+		}
+		ing.Amount, ing.AmountMax = 0, 0
+		if ok {
+			code, ing.Unit = c, ""
+		}
+	}
+	for i, line := range wrapText(FormatIngredient(ing), 56) {
+		if i > 0 {
+			amount, code, line = "", "", "-"+line
+		}
+		fmt.Fprintf(b, "%7s %-2s %s\n", amount, code, line)
+	}
+}
+
+// asciiAmount writes an amount with ASCII fractions: "1 1/2".
+func asciiAmount(x float64) string {
+	return strings.Join(strings.Fields(fractionGlyphs.Replace(units.FormatAmount(x))), " ")
+}
+
+// wrapText breaks s into lines of at most width characters between words.
+func wrapText(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,44 @@
+package recipe
+
+import (
+	"context"
+	"time"
+)
+
+// NutritionCalculator estimates the nutrition of a recipe from its
+// ingredients.
+type NutritionCalculator interface {
+	// Calculate returns the nutrition per serving of the ingredients and how
+	// it was estimated.
+	Calculate(ctx context.Context, ingredients []Ingredient, servings int) (NutritionalInfo, *NutritionEstimate, error)
+}
+
+// NutritionEstimate describes how a recipe's nutritional information was
+// computed from its ingredients.
+type NutritionEstimate struct {
+	// Confidence ranges from 0, when no ingredient could be matched to a
+	// food, to 1 when every ingredient was matched exactly and weighed.
+	Confidence float64 `json:"confidence"`
+	// Unmatched lists the ingredients left out of the estimate because no
+	// food or weight was found for them.
+	Unmatched  []string  `json:"unmatched_ingredients"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// estimateNutrition replaces the recipe's nutritional information with an
+// estimate from its ingredients when a calculator is configured. Recipes
+// whose ingredients match no food keep the nutrition they were given.
+func (s *service) estimateNutrition(ctx context.Context, r *Recipe) error {
+	if s.nutrition == nil {
+		return nil
+	}
+	info, estimate, err := s.nutrition.Calculate(ctx, r.Ingredients, r.Servings)
+	if err != nil {
+		return err
+	}
+	r.NutritionEstimate = estimate
+	if estimate.Confidence > 0 {
+		r.NutritionalInfo = info
+	}
+	return nil
+}
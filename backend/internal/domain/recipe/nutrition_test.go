@@ -0,0 +1,82 @@
+package recipe
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user/usertest"
+)
+
+// fakeCalculator counts the ingredients it is given as calories.
+type fakeCalculator struct {
+	calls int
+}
+
+func (f *fakeCalculator) Calculate(ctx context.Context, ingredients []Ingredient, servings int) (NutritionalInfo, *NutritionEstimate, error) {
+	f.calls++
+	estimate := &NutritionEstimate{Unmatched: []string{}}
+	if len(ingredients) > 0 {
+		estimate.Confidence = 1
+	}
+	return NutritionalInfo{Calories: len(ingredients)}, estimate, nil
+}
+
+func TestNutritionIsRecomputedWhenIngredientsChange(t *testing.T) {
+	calc := &fakeCalculator{}
+	repo := &fakeRepository{}
+	svc := NewService(repo, usertest.NewUsers(), WithNutrition(calc))
+	ctx := context.Background()
+	owner := uuid.New()
+
+	r, err := svc.Create(ctx, owner, CreateRequest{
+		Title:           "Toast",
+		Servings:        1,
+		Ingredients:     []Ingredient{{Name: "bread", Amount: 1}},
+		NutritionalInfo: NutritionalInfo{Calories: 900},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if r.NutritionalInfo.Calories != 1 || r.NutritionEstimate == nil || calc.calls != 1 {
+		t.Fatalf("expected computed nutrition on create, got %+v", r.NutritionalInfo)
+	}
+
+	title := "Buttered toast"
+	if _, err := svc.Update(ctx, owner, r.ID, UpdateRequest{Version: 1, Title: &title}); err != nil {
+		t.Fatalf("update title: %v", err)
+	}
+	if calc.calls != 1 {
+		t.Fatalf("expected no recomputation when ingredients are unchanged")
+	}
+
+	ingredients := []Ingredient{{Name: "bread", Amount: 1}, {Name: "butter", Amount: 10, Unit: "g"}}
+	updated, err := svc.Update(ctx, owner, r.ID, UpdateRequest{Version: 2, Ingredients: &ingredients})
+	if err != nil {
+		t.Fatalf("update ingredients: %v", err)
+	}
+	if calc.calls != 2 || updated.NutritionalInfo.Calories != 2 {
+		t.Fatalf("expected recomputation when ingredients change, got %+v", updated.NutritionalInfo)
+	}
+
+	manual := NutritionalInfo{Calories: 250}
+	updated, err = svc.Update(ctx, owner, r.ID, UpdateRequest{Version: 3, NutritionalInfo: &manual})
+	if err != nil {
+		t.Fatalf("update nutrition: %v", err)
+	}
+	if updated.NutritionalInfo.Calories != 250 || updated.NutritionEstimate != nil {
+		t.Fatalf("expected hand-entered nutrition to replace the estimate, got %+v", updated)
+	}
+}
+
+func TestNutritionKeepsGivenValuesWithoutMatches(t *testing.T) {
+	svc := NewService(&fakeRepository{}, usertest.NewUsers(), WithNutrition(&fakeCalculator{}))
+	r, err := svc.Create(context.Background(), uuid.New(), CreateRequest{Title: "Water", NutritionalInfo: NutritionalInfo{Calories: 5}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if r.NutritionalInfo.Calories != 5 || r.NutritionEstimate == nil || r.NutritionEstimate.Confidence != 0 {
+		t.Fatalf("expected the given nutrition to be kept, got %+v %+v", r.NutritionalInfo, r.NutritionEstimate)
+	}
+}
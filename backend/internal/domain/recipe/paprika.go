@@ -0,0 +1,233 @@
+package recipe
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxPaprikaRecipeSize bounds a decompressed Paprika recipe, which may embed
+// a photo.
+const maxPaprikaRecipeSize = 20 << 20
+
+// paprikaRecipe is a recipe as Paprika exports it. Times, servings and
+// nutrition are free text.
+type paprikaRecipe struct {
+	UID             string   `json:"uid"`
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	Ingredients     string   `json:"ingredients"`
+	Directions      string   `json:"directions"`
+	Notes           string   `json:"notes"`
+	NutritionalInfo string   `json:"nutritional_info"`
+	Servings        string   `json:"servings"`
+	PrepTime        string   `json:"prep_time"`
+	CookTime        string   `json:"cook_time"`
+	TotalTime       string   `json:"total_time"`
+	Difficulty      string   `json:"difficulty"`
+	Source          string   `json:"source"`
+	SourceURL       string   `json:"source_url"`
+	ImageURL        string   `json:"image_url"`
+	Categories      []string `json:"categories"`
+	Rating          int      `json:"rating"`
+	Created         string   `json:"created"`
+	Hash            string   `json:"hash"`
+}
+
+// parsePaprika reads a .paprikarecipes bundle or a single .paprikarecipe.
+func parsePaprika(data []byte) ([]ImportItem, error) {
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		recipes, err := decodePaprika(bytes.NewReader(data))
+		if err != nil {
+			return nil, unreadableFile(FormatPaprika, err.Error())
+		}
+		var items []ImportItem
+		for _, p := range recipes {
+			items = append(items, p.item())
+		}
+		return items, nil
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, unreadableFile(FormatPaprika, "file is not a Paprika export")
+	}
+	var items []ImportItem
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(path.Base(f.Name), ".paprikarecipe")
+		rc, err := f.Open()
+		if err != nil {
+			items = append(items, ImportItem{Title: name, Err: err})
+			continue
+		}
+		recipes, err := decodePaprika(rc)
+		rc.Close()
+		if err != nil {
+			items = append(items, ImportItem{Title: name, Err: err})
+			continue
+		}
+		for _, p := range recipes {
+			items = append(items, p.item())
+		}
+		if len(items) > MaxImportItems {
+			break
+		}
+	}
+	return items, nil
+}
+
+// decodePaprika reads gzipped, or plain, JSON holding one recipe or a list.
+func decodePaprika(r io.Reader) ([]paprikaRecipe, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); string(magic) == "\x1f\x8b" {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, errors.New("recipe is not gzip compressed")
+		}
+		defer zr.Close()
+		r = zr
+	} else {
+		r = br
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxPaprikaRecipeSize+1))
+	if err != nil {
+		return nil, errors.New("recipe could not be decompressed")
+	}
+	if len(data) > maxPaprikaRecipeSize {
+		return nil, errors.New("recipe is too large")
+	}
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var list []paprikaRecipe
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, errors.New("recipe is not valid JSON")
+		}
+		return list, nil
+	}
+	var p paprikaRecipe
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, errors.New("recipe is not valid JSON")
+	}
+	return []paprikaRecipe{p}, nil
+}
+
+// item maps a Paprika recipe onto a create request. Notes and the source are
+// kept at the end of the description.
+func (p paprikaRecipe) item() ImportItem {
+	item := ImportItem{Title: strings.TrimSpace(p.Name)}
+	if item.Title == "" {
+		item.Err = errors.New("recipe has no name")
+		return item
+	}
+	req := CreateRequest{Title: item.Title, Instructions: TextSteps(textLines(p.Directions))}
+	var description []string
+	for _, s := range []string{p.Description, p.Notes} {
+		if s = strings.TrimSpace(s); s != "" {
+			description = append(description, s)
+		}
+	}
+	if source := strings.TrimSpace(strings.Join(strings.Fields(p.Source+" "+p.SourceURL), " ")); source != "" {
+		description = append(description, "Source: "+source)
+	}
+	req.Description = strings.Join(description, "\n\n")
+	for _, line := range textLines(p.Ingredients) {
+		// Paprika keeps section headings such as "For the sauce:" among the
+		// ingredients.
+		if !strings.HasSuffix(line, ":") {
+			req.IngredientLines = append(req.IngredientLines, line)
+		}
+	}
+	req.Servings = servings(p.Servings)
+	req.PrepTime, _ = parseMinutes(p.PrepTime)
+	req.CookTime, _ = parseMinutes(p.CookTime)
+	if total, ok := parseMinutes(p.TotalTime); ok && req.PrepTime == 0 && req.CookTime == 0 {
+		req.CookTime = total
+	}
+	categoryAndTags(&req, p.Categories)
+	nutritionText(p.NutritionalInfo, &req.NutritionalInfo)
+	if url := strings.TrimSpace(p.ImageURL); strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		req.ImageURL = &url
+	}
+	item.Request = req
+	return item
+}
+
+// paprikaFromRecipe converts a recipe to Paprika's JSON layout.
+func paprikaFromRecipe(r *Recipe) paprikaRecipe {
+	p := paprikaRecipe{
+		UID:         strings.ToUpper(r.ID.String()),
+		Name:        r.Title,
+		Description: r.Description,
+		Directions:  strings.Join(stepLines(r.Instructions), "\n"),
+		Categories:  []string{},
+		Created:     r.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	var ingredients, nutrition []string
+	for _, ing := range r.Ingredients {
+		ingredients = append(ingredients, FormatIngredient(ing))
+	}
+	p.Ingredients = strings.Join(ingredients, "\n")
+	for _, row := range nutritionRows(r.NutritionalInfo) {
+		nutrition = append(nutrition, row[0]+": "+row[1])
+	}
+	p.NutritionalInfo = strings.Join(nutrition, "\n")
+	if r.Servings > 0 {
+		p.Servings = strconv.Itoa(r.Servings)
+	}
+	if r.PrepTime > 0 {
+		p.PrepTime = formatMinutes(r.PrepTime)
+	}
+	if r.CookTime > 0 {
+		p.CookTime = formatMinutes(r.CookTime)
+	}
+	if r.PrepTime+r.CookTime > 0 {
+		p.TotalTime = formatMinutes(r.PrepTime + r.CookTime)
+	}
+	if r.Category != "" {
+		p.Categories = append(p.Categories, r.Category)
+	}
+	p.Categories = append(p.Categories, r.Tags...)
+	if r.ImageURL != nil {
+		p.ImageURL = *r.ImageURL
+	}
+	content, _ := json.Marshal(p)
+	sum := sha256.Sum256(content)
+	p.Hash = strings.ToUpper(hex.EncodeToString(sum[:]))
+	return p
+}
+
+// paprikaFile writes a recipe as a gzipped .paprikarecipe.
+func paprikaFile(r *Recipe) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	json.NewEncoder(zw).Encode(paprikaFromRecipe(r))
+	zw.Close()
+	return buf.Bytes()
+}
+
+// paprikaBundle writes recipes as a .paprikarecipes bundle.
+func paprikaBundle(recipes []*Recipe) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	seen := map[string]int{}
+	for _, r := range recipes {
+		name := uniqueName(seen, fileSlug(r.Title))
+		// The recipes are compressed already.
+		w, _ := zw.CreateHeader(&zip.FileHeader{Name: name + ".paprikarecipe", Method: zip.Store, Modified: r.UpdatedAt})
+		w.Write(paprikaFile(r))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
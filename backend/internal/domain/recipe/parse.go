@@ -0,0 +1,230 @@
+package recipe
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"alchemorsel/backend/internal/pkg/units"
+)
+
+// fractionGlyphs spells out unicode fractions so that "1½" reads as "1 1/2".
+var fractionGlyphs = strings.NewReplacer(
+	"½", " 1/2", "⅓", " 1/3", "⅔", " 2/3", "¼", " 1/4", "¾", " 3/4",
+	"⅕", " 1/5", "⅖", " 2/5", "⅗", " 3/5", "⅘", " 4/5", "⅙", " 1/6", "⅚", " 5/6",
+	"⅛", " 1/8", "⅜", " 3/8", "⅝", " 5/8", "⅞", " 7/8", "⁄", "/",
+)
+
+const numberPattern = `\d+\s+\d+/\d+|\d+/\d+|\d*\.\d+|\d+`
+
+var (
+	bulletPattern   = regexp.MustCompile(`^[-*•·]+\s*`)
+	gluedPattern    = regexp.MustCompile(`(\d)([a-zA-Z])`)
+	quantityPattern = regexp.MustCompile(`^(` + numberPattern + `)(?:\s*(?:-|–|—|to|or)\s*(` + numberPattern + `))?(?:\s+|$)`)
+	// sizePattern reads a package size such as "(14 oz)", "(14-ounce)" or
+	// "14-ounce" following the count.
+	sizePattern     = regexp.MustCompile(`^(?:\(\s*(` + numberPattern + `)[\s-]*([a-zA-Z. ]+?)\s*\)|(` + numberPattern + `)-([a-zA-Z.]+))\s*`)
+	optionalPattern = regexp.MustCompile(`(?i)\(\s*optional\s*\)|,?\s*\(?\boptional\b\)?:?|,?\s*\bif desired\b`)
+	tastePattern    = regexp.MustCompile(`(?i),?\s*\b(to taste|as needed|as required)\b`)
+	notePattern     = regexp.MustCompile(`\(([^)]*)\)`)
+	spacePattern    = regexp.MustCompile(`\s+`)
+)
+
+// wordNumbers are amounts written as words at the start of a line.
+var wordNumbers = map[string]float64{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6,
+	"seven": 7, "eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12,
+	"half": 0.5, "dozen": 12, "a couple": 2, "a couple of": 2, "a dozen": 12, "half a": 0.5,
+}
+
+// vague words make a leading "a" an indefinite amount, as in "a few".
+var vague = map[string]bool{"few": true, "little": true, "bit": true, "touch": true}
+
+// countUnits are containers and pieces ingredients are counted in, keyed by
+// every spelling and mapped to the singular.
+var countUnits = map[string]string{
+	"can": "can", "cans": "can", "tin": "tin", "tins": "tin", "jar": "jar", "jars": "jar",
+	"package": "package", "packages": "package", "pkg": "package", "pkgs": "package",
+	"packet": "packet", "packets": "packet", "box": "box", "boxes": "box", "bag": "bag",
+	"bags": "bag", "bottle": "bottle", "bottles": "bottle", "carton": "carton", "cartons": "carton",
+	"container": "container", "containers": "container", "stick": "stick", "sticks": "stick",
+	"clove": "clove", "cloves": "clove", "slice": "slice", "slices": "slice", "sprig": "sprig",
+	"sprigs": "sprig", "bunch": "bunch", "bunches": "bunch", "head": "head", "heads": "head",
+	"handful": "handful", "handfuls": "handful", "piece": "piece", "pieces": "piece",
+	"knob": "knob", "knobs": "knob", "sheet": "sheet", "sheets": "sheet", "stalk": "stalk",
+	"stalks": "stalk", "ear": "ear", "ears": "ear", "envelope": "envelope", "envelopes": "envelope",
+}
+
+// ParseIngredient reads an ingredient line as people write it, such as
+// "2 1/2 cups all-purpose flour, sifted" or "1 (14 oz) can tomatoes". It
+// recognizes amounts written with fractions, unicode fractions, words and
+// ranges, units, a package size, preparation notes after a comma or in
+// parentheses, and the "optional" marker. Text it cannot place is kept in
+// the name.
+func ParseIngredient(line string) Ingredient {
+	var ing Ingredient
+	// "250g" is read as "250 g".
+	s := gluedPattern.ReplaceAllString(fractionGlyphs.Replace(line), "$1 $2")
+	s = spacePattern.ReplaceAllString(s, " ")
+	s = strings.TrimSpace(bulletPattern.ReplaceAllString(strings.TrimSpace(s), ""))
+	s = strings.TrimSuffix(s, ".")
+
+	if optionalPattern.MatchString(s) {
+		ing.Optional = true
+		s = strings.TrimSpace(optionalPattern.ReplaceAllString(s, ""))
+	}
+	var notes []string
+
+	s = ing.parseAmount(s)
+	counted := ing.Amount > 0
+	if m := sizePattern.FindStringSubmatch(s); counted && m != nil {
+		amount, unit := m[1], m[2]
+		if amount == "" {
+			amount, unit = m[3], m[4]
+		}
+		if u, ok := units.Lookup(unit); ok {
+			size := parseNumber(amount)
+			rest := s[len(m[0]):]
+			if container, after, ok := countUnit(rest); ok {
+				// Measure packaged ingredients by their size so that they can
+				// be scaled, converted and weighed.
+				ing.Amount *= size
+				ing.AmountMax *= size
+				ing.Unit = u.Symbol
+				notes = append(notes, units.FormatAmount(size)+" "+u.Symbol+" "+container)
+				s = after
+			} else {
+				notes = append(notes, units.FormatAmount(size)+" "+u.Symbol+" each")
+				s = rest
+			}
+		}
+	}
+	if ing.Unit == "" {
+		s = ing.parseUnit(s)
+	}
+
+	if ing.Amount == 0 && ing.Unit == "" {
+		if m := tastePattern.FindStringSubmatch(s); m != nil {
+			ing.Unit = strings.ToLower(m[1])
+			s = strings.TrimSpace(tastePattern.ReplaceAllString(s, ""))
+		}
+	}
+
+	for _, m := range notePattern.FindAllStringSubmatch(s, -1) {
+		if note := strings.TrimSpace(m[1]); note != "" {
+			notes = append(notes, note)
+		}
+	}
+	s = notePattern.ReplaceAllString(s, "")
+	if name, note, ok := strings.Cut(s, ","); ok {
+		s = name
+		if note = strings.Trim(strings.TrimSpace(note), ",;. "); note != "" {
+			notes = append(notes, spacePattern.ReplaceAllString(note, " "))
+		}
+	}
+	ing.Name = strings.Trim(spacePattern.ReplaceAllString(s, " "), " ,;:-")
+	ing.Note = strings.Join(notes, "; ")
+
+	// A count unit with nothing after it, as in "4 cloves", is the name.
+	if ing.Name == "" && countUnits[strings.ToLower(ing.Unit)] != "" {
+		ing.Name, ing.Unit = ing.Unit, ""
+	}
+	return ing
+}
+
+// ParseIngredients parses each non-blank line with ParseIngredient.
+func ParseIngredients(lines []string) []Ingredient {
+	out := []Ingredient{}
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		out = append(out, ParseIngredient(line))
+	}
+	return out
+}
+
+// parseAmount reads a leading amount or range into ing and returns the rest
+// of the line.
+func (ing *Ingredient) parseAmount(s string) string {
+	if m := quantityPattern.FindStringSubmatch(s); m != nil {
+		ing.Amount = parseNumber(m[1])
+		if m[2] != "" {
+			if max := parseNumber(m[2]); max > ing.Amount {
+				ing.AmountMax = max
+			}
+		}
+		return strings.TrimSpace(s[len(m[0]):])
+	}
+	lower := strings.ToLower(s)
+	for _, n := range []int{3, 2, 1} {
+		words := strings.SplitN(lower, " ", n+1)
+		if len(words) <= n {
+			continue
+		}
+		next, _, _ := strings.Cut(words[n], " ")
+		if v, ok := wordNumbers[strings.Join(words[:n], " ")]; ok && !vague[next] {
+			ing.Amount = v
+			return strings.TrimSpace(s[len(strings.Join(words[:n], " ")):])
+		}
+	}
+	return s
+}
+
+// parseUnit reads a unit following the amount into ing and returns the rest
+// of the line. Measured units need an amount; units such as "pinch" do not.
+func (ing *Ingredient) parseUnit(s string) string {
+	words := strings.Fields(s)
+	for _, n := range []int{2, 1} {
+		if len(words) < n {
+			continue
+		}
+		word := strings.Join(words[:n], " ")
+		rest := strings.Join(words[n:], " ")
+		var unit string
+		if u, ok := units.Lookup(word); ok && ing.Amount > 0 {
+			unit = u.Symbol
+		} else if units.Unmeasured(strings.TrimSuffix(word, ".")) {
+			unit = strings.ToLower(strings.TrimSuffix(word, "."))
+		} else if c, _, ok := countUnit(word); ok && ing.Amount > 0 && n == 1 {
+			unit = c
+		}
+		if unit == "" {
+			continue
+		}
+		ing.Unit = unit
+		rest = strings.TrimPrefix(rest, "of ")
+		if rest == "of" {
+			rest = ""
+		}
+		return rest
+	}
+	return s
+}
+
+// countUnit reads a count unit at the start of s, returning its singular and
+// the rest of s.
+func countUnit(s string) (unit, rest string, ok bool) {
+	word, rest, _ := strings.Cut(strings.TrimSpace(s), " ")
+	unit, ok = countUnits[strings.ToLower(strings.TrimSuffix(word, "."))]
+	rest = strings.TrimPrefix(strings.TrimSpace(rest), "of ")
+	return unit, rest, ok
+}
+
+// parseNumber reads an integer, decimal, fraction or mixed number.
+func parseNumber(s string) float64 {
+	var total float64
+	for _, part := range strings.Fields(s) {
+		if num, den, ok := strings.Cut(part, "/"); ok {
+			n, _ := strconv.ParseFloat(num, 64)
+			d, _ := strconv.ParseFloat(den, 64)
+			if d != 0 {
+				total += n / d
+			}
+			continue
+		}
+		v, _ := strconv.ParseFloat(part, 64)
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,163 @@
+package recipe
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user/usertest"
+)
+
+func TestParseIngredient(t *testing.T) {
+	tests := []struct {
+		line string
+		want Ingredient
+	}{
+		// Plain amounts and units.
+		{"3 tablespoons olive oil", Ingredient{Name: "olive oil", Amount: 3, Unit: "tbsp"}},
+		{"1 cup sugar", Ingredient{Name: "sugar", Amount: 1, Unit: "cup"}},
+		{"2 Cups milk", Ingredient{Name: "milk", Amount: 2, Unit: "cup"}},
+		{"1 tsp. vanilla extract", Ingredient{Name: "vanilla extract", Amount: 1, Unit: "tsp"}},
+		{"1 T butter", Ingredient{Name: "butter", Amount: 1, Unit: "tbsp"}},
+		{"1 t baking soda", Ingredient{Name: "baking soda", Amount: 1, Unit: "tsp"}},
+		{"500 g spaghetti", Ingredient{Name: "spaghetti", Amount: 500, Unit: "g"}},
+		{"250g butter", Ingredient{Name: "butter", Amount: 250, Unit: "g"}},
+		{"1 kg potatoes", Ingredient{Name: "potatoes", Amount: 1, Unit: "kg"}},
+		{"2 lbs. ground beef", Ingredient{Name: "ground beef", Amount: 2, Unit: "lb"}},
+		{"8 oz cream cheese", Ingredient{Name: "cream cheese", Amount: 8, Unit: "oz"}},
+		{"2 fl oz dark rum", Ingredient{Name: "dark rum", Amount: 2, Unit: "fl oz"}},
+		{"1 fl. oz lime juice", Ingredient{Name: "lime juice", Amount: 1, Unit: "fl oz"}},
+		{"1.5 l chicken stock", Ingredient{Name: "chicken stock", Amount: 1.5, Unit: "l"}},
+		{"200 ml double cream", Ingredient{Name: "double cream", Amount: 200, Unit: "ml"}},
+		{"1 quart buttermilk", Ingredient{Name: "buttermilk", Amount: 1, Unit: "quart"}},
+		{"1 cup of rice", Ingredient{Name: "rice", Amount: 1, Unit: "cup"}},
+
+		// Fractions and decimals.
+		{"2 1/2 cups all-purpose flour, sifted", Ingredient{Name: "all-purpose flour", Amount: 2.5, Unit: "cup", Note: "sifted"}},
+		{"1/2 tsp salt", Ingredient{Name: "salt", Amount: 0.5, Unit: "tsp"}},
+		{"3/4 cup brown sugar, packed", Ingredient{Name: "brown sugar", Amount: 0.75, Unit: "cup", Note: "packed"}},
+		{"1½ cups milk", Ingredient{Name: "milk", Amount: 1.5, Unit: "cup"}},
+		{"1 ½ cups milk", Ingredient{Name: "milk", Amount: 1.5, Unit: "cup"}},
+		{"½ teaspoon ground cinnamon", Ingredient{Name: "ground cinnamon", Amount: 0.5, Unit: "tsp"}},
+		{"¼ cup chopped parsley", Ingredient{Name: "chopped parsley", Amount: 0.25, Unit: "cup"}},
+		{"⅓ cup honey", Ingredient{Name: "honey", Amount: 1.0 / 3, Unit: "cup"}},
+		{"2¾ cups water", Ingredient{Name: "water", Amount: 2.75, Unit: "cup"}},
+		{"1⁄2 cup oats", Ingredient{Name: "oats", Amount: 0.5, Unit: "cup"}},
+		{".5 oz gin", Ingredient{Name: "gin", Amount: 0.5, Unit: "oz"}},
+		{"0.25 tsp cayenne", Ingredient{Name: "cayenne", Amount: 0.25, Unit: "tsp"}},
+
+		// Ranges.
+		{"2-3 cloves garlic, minced", Ingredient{Name: "garlic", Amount: 2, AmountMax: 3, Unit: "clove", Note: "minced"}},
+		{"2 - 3 tbsp soy sauce", Ingredient{Name: "soy sauce", Amount: 2, AmountMax: 3, Unit: "tbsp"}},
+		{"1 to 2 tsp chili flakes", Ingredient{Name: "chili flakes", Amount: 1, AmountMax: 2, Unit: "tsp"}},
+		{"1–2 jalapeños", Ingredient{Name: "jalapeños", Amount: 1, AmountMax: 2}},
+		{"1 or 2 limes", Ingredient{Name: "limes", Amount: 1, AmountMax: 2}},
+		{"1 1/2-2 lbs chicken thighs", Ingredient{Name: "chicken thighs", Amount: 1.5, AmountMax: 2, Unit: "lb"}},
+		{"½-1 cup stock", Ingredient{Name: "stock", Amount: 0.5, AmountMax: 1, Unit: "cup"}},
+		{"10-12 cherry tomatoes, halved", Ingredient{Name: "cherry tomatoes", Amount: 10, AmountMax: 12, Note: "halved"}},
+
+		// Amounts written as words.
+		{"a pinch of salt", Ingredient{Name: "salt", Amount: 1, Unit: "pinch"}},
+		{"An egg", Ingredient{Name: "egg", Amount: 1}},
+		{"one onion, diced", Ingredient{Name: "onion", Amount: 1, Note: "diced"}},
+		{"Two carrots", Ingredient{Name: "carrots", Amount: 2}},
+		{"a couple of bay leaves", Ingredient{Name: "bay leaves", Amount: 2}},
+		{"half a lemon, juiced", Ingredient{Name: "lemon", Amount: 0.5, Note: "juiced"}},
+		{"a dozen eggs", Ingredient{Name: "eggs", Amount: 12}},
+		{"a few sprigs of thyme", Ingredient{Name: "a few sprigs of thyme"}},
+
+		// Count units and package sizes.
+		{"1 (14 oz) can tomatoes", Ingredient{Name: "tomatoes", Amount: 14, Unit: "oz", Note: "14 oz can"}},
+		{"2 (15-ounce) cans black beans, drained and rinsed", Ingredient{Name: "black beans", Amount: 30, Unit: "oz", Note: "15 oz can; drained and rinsed"}},
+		{"1 (28 oz.) can crushed tomatoes", Ingredient{Name: "crushed tomatoes", Amount: 28, Unit: "oz", Note: "28 oz can"}},
+		{"One 14-ounce can coconut milk", Ingredient{Name: "coconut milk", Amount: 14, Unit: "oz", Note: "14 oz can"}},
+		{"1 (.25 oz) package active dry yeast", Ingredient{Name: "active dry yeast", Amount: 0.25, Unit: "oz", Note: "¼ oz package"}},
+		{"1 (400g) tin chickpeas", Ingredient{Name: "chickpeas", Amount: 400, Unit: "g", Note: "400 g tin"}},
+		{"2 (5 oz) salmon fillets", Ingredient{Name: "salmon fillets", Amount: 2, Note: "5 oz each"}},
+		{"2 cans (15 oz each) kidney beans", Ingredient{Name: "kidney beans", Amount: 2, Unit: "can", Note: "15 oz each"}},
+		{"3 cloves garlic", Ingredient{Name: "garlic", Amount: 3, Unit: "clove"}},
+		{"4 cloves", Ingredient{Name: "clove", Amount: 4}},
+		{"1/4 tsp ground cloves", Ingredient{Name: "ground cloves", Amount: 0.25, Unit: "tsp"}},
+		{"2 sticks unsalted butter, softened", Ingredient{Name: "unsalted butter", Amount: 2, Unit: "stick", Note: "softened"}},
+		{"1 bunch cilantro", Ingredient{Name: "cilantro", Amount: 1, Unit: "bunch"}},
+		{"2 sprigs rosemary", Ingredient{Name: "rosemary", Amount: 2, Unit: "sprig"}},
+		{"1 head of cauliflower", Ingredient{Name: "cauliflower", Amount: 1, Unit: "head"}},
+		{"4 slices bacon, chopped", Ingredient{Name: "bacon", Amount: 4, Unit: "slice", Note: "chopped"}},
+		{"2 stalks celery", Ingredient{Name: "celery", Amount: 2, Unit: "stalk"}},
+		{"1 handful basil leaves", Ingredient{Name: "basil leaves", Amount: 1, Unit: "handful"}},
+
+		// Notes.
+		{"1 cup (2 sticks) butter, softened", Ingredient{Name: "butter", Amount: 1, Unit: "cup", Note: "2 sticks; softened"}},
+		{"2 large tomatoes (about 1 lb), diced", Ingredient{Name: "large tomatoes", Amount: 2, Note: "about 1 lb; diced"}},
+		{"1 onion, finely chopped", Ingredient{Name: "onion", Amount: 1, Note: "finely chopped"}},
+		{"3 eggs, lightly beaten, at room temperature", Ingredient{Name: "eggs", Amount: 3, Note: "lightly beaten, at room temperature"}},
+		{"1 lb shrimp (peeled and deveined)", Ingredient{Name: "shrimp", Amount: 1, Unit: "lb", Note: "peeled and deveined"}},
+		{"2 large eggs", Ingredient{Name: "large eggs", Amount: 2}},
+		{"1 egg yolk", Ingredient{Name: "egg yolk", Amount: 1}},
+
+		// Optional ingredients.
+		{"1/4 cup chopped walnuts (optional)", Ingredient{Name: "chopped walnuts", Amount: 0.25, Unit: "cup", Optional: true}},
+		{"1 tsp red pepper flakes, optional", Ingredient{Name: "red pepper flakes", Amount: 1, Unit: "tsp", Optional: true}},
+		{"Optional: 2 tbsp capers", Ingredient{Name: "capers", Amount: 2, Unit: "tbsp", Optional: true}},
+		{"fresh parsley, for garnish (optional)", Ingredient{Name: "fresh parsley", Note: "for garnish", Optional: true}},
+		{"1 tbsp sesame seeds, if desired", Ingredient{Name: "sesame seeds", Amount: 1, Unit: "tbsp", Optional: true}},
+		{"2 tbsp butter (Optional)", Ingredient{Name: "butter", Amount: 2, Unit: "tbsp", Optional: true}},
+
+		// Amounts that are not measured.
+		{"salt and pepper to taste", Ingredient{Name: "salt and pepper", Unit: "to taste"}},
+		{"Salt, to taste", Ingredient{Name: "Salt", Unit: "to taste"}},
+		{"olive oil, as needed", Ingredient{Name: "olive oil", Unit: "as needed"}},
+		{"pinch of nutmeg", Ingredient{Name: "nutmeg", Unit: "pinch"}},
+		{"dash of hot sauce", Ingredient{Name: "hot sauce", Unit: "dash"}},
+		{"1 tsp salt, or to taste", Ingredient{Name: "salt", Amount: 1, Unit: "tsp", Note: "or to taste"}},
+		{"2 pinches saffron", Ingredient{Name: "saffron", Amount: 2, Unit: "pinches"}},
+
+		// Lines without an amount.
+		{"Salt", Ingredient{Name: "Salt"}},
+		{"Juice of 1 lemon", Ingredient{Name: "Juice of 1 lemon"}},
+		{"cup of coffee", Ingredient{Name: "cup of coffee"}},
+		{"3-inch piece ginger", Ingredient{Name: "3-inch piece ginger"}},
+		{"Arugula", Ingredient{Name: "Arugula"}},
+
+		// Formatting noise.
+		{"- 2 cups sugar", Ingredient{Name: "sugar", Amount: 2, Unit: "cup"}},
+		{"• 1 tbsp honey", Ingredient{Name: "honey", Amount: 1, Unit: "tbsp"}},
+		{"  3   eggs.  ", Ingredient{Name: "eggs", Amount: 3}},
+		{"* 4 cups  baby spinach", Ingredient{Name: "baby spinach", Amount: 4, Unit: "cup"}},
+	}
+	for _, tt := range tests {
+		if got := ParseIngredient(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseIngredient(%q) =\n\t%+v\nwant\n\t%+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseIngredientsSkipsBlankLines(t *testing.T) {
+	got := ParseIngredients([]string{"2 eggs", "", "   ", "1 cup milk"})
+	want := []Ingredient{{Name: "eggs", Amount: 2}, {Name: "milk", Amount: 1, Unit: "cup"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseIngredients = %+v, want %+v", got, want)
+	}
+}
+
+func TestCreateParsesIngredientLines(t *testing.T) {
+	svc := NewService(&fakeRepository{}, usertest.NewUsers())
+	r, err := svc.Create(context.Background(), uuid.New(), CreateRequest{
+		Title:           "Omelette",
+		Ingredients:     []Ingredient{{Name: "eggs", Amount: 3}},
+		IngredientLines: []string{"1 tbsp butter", "", "chives, snipped (optional)"},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	want := []Ingredient{
+		{Name: "eggs", Amount: 3},
+		{Name: "butter", Amount: 1, Unit: "tbsp"},
+		{Name: "chives", Note: "snipped", Optional: true},
+	}
+	if !reflect.DeepEqual(r.Ingredients, want) {
+		t.Fatalf("ingredients = %+v, want %+v", r.Ingredients, want)
+	}
+}
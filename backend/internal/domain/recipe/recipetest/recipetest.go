@@ -0,0 +1,45 @@
+// Package recipetest provides an in-memory recipe service for testing the
+// services built on recipes.
+package recipetest
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// Recipes implements recipe.Service lookups over a set of recipes. Calls to
+// any other method panic through the nil embedded interface; tests needing
+// them embed Recipes in a fake of their own.
+type Recipes struct {
+	recipe.Service
+	ByID map[uuid.UUID]*recipe.Recipe
+}
+
+// NewRecipes returns a service holding the given recipes.
+func NewRecipes(recipes ...*recipe.Recipe) *Recipes {
+	f := &Recipes{ByID: map[uuid.UUID]*recipe.Recipe{}}
+	f.Add(recipes...)
+	return f
+}
+
+// Add stores the given recipes, replacing those with the same ids.
+func (f *Recipes) Add(recipes ...*recipe.Recipe) {
+	for _, r := range recipes {
+		f.ByID[r.ID] = r
+	}
+}
+
+// Get returns a copy of a recipe visible to the viewer. Like the real
+// service, it reports other users' private recipes as missing.
+func (f *Recipes) Get(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) (*recipe.Recipe, error) {
+	r, ok := f.ByID[id]
+	if !ok || (!r.IsPublic && (viewerID == nil || *viewerID != r.UserID)) {
+		return nil, apperrors.ErrRecipeNotFound
+	}
+	copy := *r
+	return &copy, nil
+}
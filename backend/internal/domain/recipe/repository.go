@@ -4,20 +4,147 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+// Sort keys accepted by SearchParams.Sort.
+const (
+	SortRelevance = "relevance"
+	SortCreatedAt = "created_at"
+	SortTitle     = "title"
+	SortPrepTime  = "prep_time"
+	// SortRating orders by average rating, SortRatingCount by the number
+	// of ratings.
+	SortRating      = "rating"
+	SortRatingCount = "rating_count"
 )
 
+// Search modes accepted by SearchParams.Mode.
+const (
+	ModeFullText = "fulltext"
+	ModeSemantic = "semantic"
+	ModeHybrid   = "hybrid"
+)
+
+// DefaultSemanticWeight is the share of the vector score in hybrid ranking.
+const DefaultSemanticWeight = 0.5
+
 // SearchParams represents parameters for recipe search.
-type SearchParams struct{}
+//
+// Query accepts plain words, "quoted phrases", prefix terms ending in '*',
+// negated terms starting with '-' and the OR keyword.
+//
+// In semantic and hybrid mode results are ranked by the cosine similarity of
+// their embedding to Embedding, which the service derives from Query or from
+// the stored embedding of SimilarTo. Hybrid mode blends that score with the
+// full-text rank using SemanticWeight.
+//
+// Tags restricts results to recipes carrying all of the given tags, and
+// IngredientWords to recipes with an ingredient whose name contains any of
+// the given words.
+//
+// Allergies holds the viewer's allergies and is filled in by the service.
+// Recipes declaring any of them are removed from the results unless
+// DisableAllergenFilter is set.
+type SearchParams struct {
+	Mode                  string
+	Query                 string
+	SimilarTo             *uuid.UUID
+	Embedding             []float64
+	SemanticWeight        float64
+	Category              string
+	Dietary               []string
+	Exclude               []string
+	Tags                  []string
+	IngredientWords       []string
+	AuthorID              *uuid.UUID
+	ViewerID              *uuid.UUID
+	Favorites             bool
+	Allergies             []string
+	DisableAllergenFilter bool
+	Sort                  string
+	Order                 string
+	Pagination            pagination.Params
+}
 
-// SearchResult is a placeholder for search results.
-type SearchResult struct{}
+// SearchHit is a single ranked search result.
+type SearchHit struct {
+	*Recipe
+	Rank       float64     `json:"rank,omitempty"`
+	Highlights *Highlights `json:"highlights,omitempty"`
+}
+
+// Highlights holds text fragments with matched terms wrapped in <mark> tags.
+type Highlights struct {
+	Title   string `json:"title"`
+	Snippet string `json:"snippet"`
+}
+
+// AllergenFilter reports how the viewer's allergies affected a search.
+type AllergenFilter struct {
+	Enabled   bool     `json:"enabled"`
+	Allergies []string `json:"allergies"`
+	// Hidden is the number of matching recipes removed because they declare
+	// one of the allergies.
+	Hidden int `json:"hidden"`
+}
+
+// SearchResult holds a page of search hits.
+type SearchResult struct {
+	Recipes        []*SearchHit    `json:"recipes"`
+	Pagination     pagination.Meta `json:"pagination"`
+	AllergenFilter *AllergenFilter `json:"allergen_filter,omitempty"`
+}
 
 // Repository defines persistence operations for recipes.
 type Repository interface {
 	Create(ctx context.Context, r *Recipe) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Recipe, error)
+	// Update persists r when the stored version equals r.Version and then
+	// increments r.Version. A stale version yields ErrVersionConflict.
+	// Create and Update record a revision of the stored recipe; revertedFrom
+	// is the restored version when the update is a revert and 0 otherwise.
+	// Update leaves the image alone, which only UpdateImage changes.
+	Update(ctx context.Context, r *Recipe, revertedFrom int) error
+	// UpdateImage sets the recipe's image, clearing it when url is nil,
+	// without recording a revision or changing its version.
+	UpdateImage(ctx context.Context, id uuid.UUID, url *string) error
+	// SoftDelete moves the recipe to the trash if version matches the stored
+	// version.
+	SoftDelete(ctx context.Context, id uuid.UUID, version int) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	GetTrashed(ctx context.Context, id uuid.UUID) (*Recipe, error)
+	ListTrash(ctx context.Context, userID uuid.UUID) ([]*Recipe, error)
+	// ListByUser returns all of the user's live recipes, newest first.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Recipe, error)
+	// ListRevisions returns the recipe's revisions, newest first and without
+	// snapshots.
+	ListRevisions(ctx context.Context, recipeID uuid.UUID) ([]*Revision, error)
+	GetRevision(ctx context.Context, recipeID uuid.UUID, version int) (*Revision, error)
+	// ListForks returns the live direct forks of a recipe that are public or
+	// owned by the viewer, newest first.
+	ListForks(ctx context.Context, id uuid.UUID, viewerID *uuid.UUID) ([]*Recipe, error)
+	// ListAncestors follows the fork chain of a recipe and returns the
+	// ancestors that still exist, including private and deleted ones,
+	// nearest first.
+	ListAncestors(ctx context.Context, id uuid.UUID) ([]*Recipe, error)
 	Search(ctx context.Context, params SearchParams) (*SearchResult, error)
+	// ListTags returns the tags of public recipes starting with prefix,
+	// most used first.
+	ListTags(ctx context.Context, prefix string, limit int) ([]TagCount, error)
 	GetUserFavorites(ctx context.Context, userID uuid.UUID) ([]*Recipe, error)
 	AddFavorite(ctx context.Context, userID, recipeID uuid.UUID) error
 	RemoveFavorite(ctx context.Context, userID, recipeID uuid.UUID) error
+	SaveEmbedding(ctx context.Context, recipeID uuid.UUID, embedding []float64) error
+	// GetEmbedding returns nil when the recipe has no stored embedding.
+	GetEmbedding(ctx context.Context, recipeID uuid.UUID) ([]float64, error)
+	RecordGeneration(ctx context.Context, g *Generation) error
+	// ListAfter returns up to limit stored recipes, including trashed ones,
+	// whose ids follow after in id order, so that all recipes can be walked
+	// in batches starting from uuid.Nil.
+	ListAfter(ctx context.Context, after uuid.UUID, limit int) ([]*Recipe, error)
+	// UpdateLabels stores the recipe's allergens and dietary categories
+	// without recording a revision or changing its version.
+	UpdateLabels(ctx context.Context, r *Recipe) error
 }
@@ -0,0 +1,201 @@
+package recipe
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Revision is an immutable snapshot of a recipe taken each time it is
+// created or updated. Its version matches the recipe version it captures.
+type Revision struct {
+	RecipeID uuid.UUID `json:"recipe_id"`
+	Version  int       `json:"version"`
+	AuthorID uuid.UUID `json:"author_id"`
+	// RevertedFrom is the version restored by this revision, if it was
+	// created by a revert.
+	RevertedFrom *int      `json:"reverted_from,omitempty"`
+	Snapshot     *Recipe   `json:"snapshot,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Change types reported in a Diff.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Diff describes the differences between two revisions of a recipe.
+type Diff struct {
+	From        int                `json:"from"`
+	To          int                `json:"to"`
+	Fields      []FieldChange      `json:"fields"`
+	Ingredients []IngredientChange `json:"ingredients"`
+	Steps       []StepChange       `json:"steps"`
+}
+
+// FieldChange is a change to a single recipe attribute.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// IngredientChange is an ingredient added, removed or changed between
+// revisions. Ingredients are matched by name, ignoring case.
+type IngredientChange struct {
+	Type string      `json:"type"`
+	Name string      `json:"name"`
+	From *Ingredient `json:"from,omitempty"`
+	To   *Ingredient `json:"to,omitempty"`
+}
+
+// StepChange is an instruction step added, removed or edited between
+// revisions. Steps are numbered from 1 in the revision they belong to.
+type StepChange struct {
+	Type     string `json:"type"`
+	FromStep int    `json:"from_step,omitempty"`
+	ToStep   int    `json:"to_step,omitempty"`
+	From     *Step  `json:"from,omitempty"`
+	To       *Step  `json:"to,omitempty"`
+}
+
+// DiffRecipes compares two snapshots of the same recipe.
+func DiffRecipes(from, to *Recipe) *Diff {
+	return &Diff{
+		From:        from.Version,
+		To:          to.Version,
+		Fields:      diffFields(from, to),
+		Ingredients: diffIngredients(from.Ingredients, to.Ingredients),
+		Steps:       diffSteps(from.Instructions, to.Instructions),
+	}
+}
+
+func diffFields(from, to *Recipe) []FieldChange {
+	pairs := []struct {
+		field    string
+		from, to any
+	}{
+		{"title", from.Title, to.Title},
+		{"description", from.Description, to.Description},
+		{"prep_time", from.PrepTime, to.PrepTime},
+		{"cook_time", from.CookTime, to.CookTime},
+		{"servings", from.Servings, to.Servings},
+		{"category", from.Category, to.Category},
+		{"dietary_categories", nonNilLabels(from.DietaryCategories), nonNilLabels(to.DietaryCategories)},
+		{"allergens", nonNilLabels(from.Allergens), nonNilLabels(to.Allergens)},
+		{"tags", nonNilLabels(from.Tags), nonNilLabels(to.Tags)},
+		{"nutritional_info", from.NutritionalInfo, to.NutritionalInfo},
+		{"image_url", from.ImageURL, to.ImageURL},
+		{"is_public", from.IsPublic, to.IsPublic},
+	}
+	changes := []FieldChange{}
+	for _, p := range pairs {
+		if !reflect.DeepEqual(p.from, p.to) {
+			changes = append(changes, FieldChange{Field: p.field, From: p.from, To: p.to})
+		}
+	}
+	return changes
+}
+
+// diffIngredients matches ingredients by name. Repeated names are paired in
+// the order they appear.
+func diffIngredients(from, to []Ingredient) []IngredientChange {
+	remaining := map[string][]int{}
+	for i, ing := range from {
+		key := ingredientKey(ing)
+		remaining[key] = append(remaining[key], i)
+	}
+	matched := make([]bool, len(from))
+
+	changes := []IngredientChange{}
+	var added []IngredientChange
+	for _, ing := range to {
+		key := ingredientKey(ing)
+		if idx := remaining[key]; len(idx) > 0 {
+			remaining[key] = idx[1:]
+			matched[idx[0]] = true
+			old := from[idx[0]]
+			if old != ing {
+				changes = append(changes, IngredientChange{Type: ChangeChanged, Name: ing.Name, From: &old, To: &ing})
+			}
+			continue
+		}
+		added = append(added, IngredientChange{Type: ChangeAdded, Name: ing.Name, To: &ing})
+	}
+	for i, ing := range from {
+		if !matched[i] {
+			changes = append(changes, IngredientChange{Type: ChangeRemoved, Name: ing.Name, From: &ing})
+		}
+	}
+	return append(changes, added...)
+}
+
+func ingredientKey(ing Ingredient) string {
+	return strings.ToLower(strings.TrimSpace(ing.Name))
+}
+
+// diffSteps aligns the two step lists on their longest common subsequence.
+// Within each unaligned stretch, removed and added steps are paired up as
+// edits and the remainder reported as removals or additions.
+func diffSteps(from, to []Step) []StepChange {
+	n, m := len(from), len(to)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if from[i].equal(to[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	changes := []StepChange{}
+	var removed, added []int
+	flush := func() {
+		paired := min(len(removed), len(added))
+		for k := 0; k < paired; k++ {
+			i, j := removed[k], added[k]
+			changes = append(changes, StepChange{Type: ChangeChanged, FromStep: i + 1, ToStep: j + 1, From: &from[i], To: &to[j]})
+		}
+		for _, i := range removed[paired:] {
+			changes = append(changes, StepChange{Type: ChangeRemoved, FromStep: i + 1, From: &from[i]})
+		}
+		for _, j := range added[paired:] {
+			changes = append(changes, StepChange{Type: ChangeAdded, ToStep: j + 1, To: &to[j]})
+		}
+		removed, added = removed[:0], added[:0]
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && from[i].equal(to[j]):
+			flush()
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] >= lcs[i+1][j]):
+			added = append(added, j)
+			j++
+		default:
+			removed = append(removed, i)
+			i++
+		}
+	}
+	flush()
+	return changes
+}
+
+func nonNilLabels(labels []string) []string {
+	if labels == nil {
+		return []string{}
+	}
+	return labels
+}
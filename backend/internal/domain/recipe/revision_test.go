@@ -0,0 +1,122 @@
+package recipe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSteps(t *testing.T) {
+	step := func(text string) *Step { return &Step{Text: text} }
+	tests := []struct {
+		name     string
+		from, to []string
+		want     []StepChange
+	}{
+		{
+			name: "unchanged",
+			from: []string{"a", "b"},
+			to:   []string{"a", "b"},
+			want: []StepChange{},
+		},
+		{
+			name: "appended",
+			from: []string{"a"},
+			to:   []string{"a", "b"},
+			want: []StepChange{{Type: ChangeAdded, ToStep: 2, To: step("b")}},
+		},
+		{
+			name: "removed from the middle",
+			from: []string{"a", "b", "c"},
+			to:   []string{"a", "c"},
+			want: []StepChange{{Type: ChangeRemoved, FromStep: 2, From: step("b")}},
+		},
+		{
+			name: "edited",
+			from: []string{"a", "bake 20 min", "c"},
+			to:   []string{"a", "bake 25 min", "c"},
+			want: []StepChange{{Type: ChangeChanged, FromStep: 2, ToStep: 2, From: step("bake 20 min"), To: step("bake 25 min")}},
+		},
+		{
+			name: "edited and inserted",
+			from: []string{"a", "b"},
+			to:   []string{"x", "y", "b"},
+			want: []StepChange{
+				{Type: ChangeChanged, FromStep: 1, ToStep: 1, From: step("a"), To: step("x")},
+				{Type: ChangeAdded, ToStep: 2, To: step("y")},
+			},
+		},
+		{
+			name: "all new",
+			from: nil,
+			to:   []string{"a"},
+			want: []StepChange{{Type: ChangeAdded, ToStep: 1, To: step("a")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffSteps(TextSteps(tt.from), TextSteps(tt.to))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("diffSteps() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffIngredients(t *testing.T) {
+	from := []Ingredient{
+		{Name: "Flour", Amount: 200, Unit: "g"},
+		{Name: "salt", Amount: 1, Unit: "tsp"},
+		{Name: "egg", Amount: 1},
+		{Name: "egg", Amount: 1},
+	}
+	to := []Ingredient{
+		{Name: "flour", Amount: 250, Unit: "g"},
+		{Name: "egg", Amount: 1},
+		{Name: "butter", Amount: 50, Unit: "g"},
+	}
+	got := diffIngredients(from, to)
+
+	want := []struct{ typ, name string }{
+		{ChangeChanged, "flour"},
+		{ChangeRemoved, "salt"},
+		{ChangeRemoved, "egg"},
+		{ChangeAdded, "butter"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Type != w.typ || got[i].Name != w.name {
+			t.Errorf("change %d = %s %s, want %s %s", i, got[i].Type, got[i].Name, w.typ, w.name)
+		}
+	}
+	if got[0].From.Amount != 200 || got[0].To.Amount != 250 {
+		t.Errorf("unexpected amounts in %+v -> %+v", got[0].From, got[0].To)
+	}
+}
+
+func TestDiffRecipesFields(t *testing.T) {
+	from := &Recipe{Version: 1, Title: "Soup", Servings: 2, Allergens: nil}
+	to := &Recipe{Version: 3, Title: "Soup", Servings: 4, Allergens: []string{}}
+	d := DiffRecipes(from, to)
+	if d.From != 1 || d.To != 3 {
+		t.Fatalf("unexpected versions %d..%d", d.From, d.To)
+	}
+	want := []FieldChange{{Field: "servings", From: 2, To: 4}}
+	if !reflect.DeepEqual(d.Fields, want) {
+		t.Fatalf("fields = %+v, want %+v", d.Fields, want)
+	}
+}
+
+func TestDiffStepsComparesStructure(t *testing.T) {
+	from := []Step{{Text: "Bake.", Duration: 20}}
+	to := []Step{{Text: "Bake.", Duration: 25, Temperature: &Temperature{Value: 180, Unit: Celsius}}}
+	got := diffSteps(from, to)
+	if len(got) != 1 || got[0].Type != ChangeChanged || got[0].From.Duration != 20 || got[0].To.Duration != 25 {
+		t.Fatalf("diffSteps() = %+v, want the step changed", got)
+	}
+	same := []Step{{Text: "Bake.", Duration: 25, Temperature: &Temperature{Value: 180, Unit: Celsius}}}
+	if got := diffSteps(to, same); len(got) != 0 {
+		t.Fatalf("expected equal steps to match, got %+v", got)
+	}
+}
@@ -0,0 +1,114 @@
+package recipe
+
+import (
+	"fmt"
+	"strings"
+
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/units"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// MaxServings bounds the number of servings a recipe can be scaled to.
+const MaxServings = 1000
+
+// ErrServingsUnknown is returned when scaling a recipe that does not declare
+// how many servings it makes.
+var ErrServingsUnknown = apperrors.New("servings_unknown", "recipe does not declare its servings", 422)
+
+// ScaledRecipe is a recipe rescaled to a different number of servings. Its
+// ingredients replace those of the embedded recipe, whose nutritional
+// information stays per serving.
+type ScaledRecipe struct {
+	*Recipe
+	Ingredients      []ScaledIngredient `json:"ingredients"`
+	OriginalServings int                `json:"original_servings"`
+	ScaleFactor      float64            `json:"scale_factor"`
+	// TotalNutrition is the nutrition of all the scaled servings together.
+	TotalNutrition NutritionalInfo `json:"total_nutrition"`
+}
+
+// ScaledIngredient is an ingredient with its rescaled amount.
+type ScaledIngredient struct {
+	Ingredient
+	// Display is the amount and unit as a cook would write them, such as
+	// "1 ⅓ cups".
+	Display string `json:"display"`
+	// Fixed reports ingredients that were not scaled because they have no
+	// amount or an unmeasured one such as "1 pinch".
+	Fixed bool `json:"fixed,omitempty"`
+}
+
+// ScaleRecipe rescales a recipe to the given number of servings. Amounts are
+// rounded to cooking fractions, or to round metric values, and moved to a
+// larger or smaller unit when that reads better, staying within the unit
+// system of a converted recipe. Nutritional information is per serving and
+// so is unchanged; the total for the new servings is added alongside it.
+func ScaleRecipe(r *Recipe, servings int) (*ScaledRecipe, error) {
+	if servings < 1 || servings > MaxServings {
+		return nil, validator.InvalidField("servings", fmt.Sprintf("servings must be between 1 and %d", MaxServings))
+	}
+	if r.Servings <= 0 {
+		return nil, ErrServingsUnknown
+	}
+	factor := float64(servings) / float64(r.Servings)
+
+	scaled := *r
+	scaled.Servings = servings
+	ingredients := make([]ScaledIngredient, len(r.Ingredients))
+	for i, ing := range r.Ingredients {
+		ingredients[i] = scaleIngredient(ing, factor, r.Units)
+	}
+	scaled.Ingredients = nil
+	return &ScaledRecipe{
+		Recipe:           &scaled,
+		Ingredients:      ingredients,
+		OriginalServings: r.Servings,
+		ScaleFactor:      factor,
+		TotalNutrition:   r.NutritionalInfo.Times(servings),
+	}, nil
+}
+
+func scaleIngredient(ing Ingredient, factor float64, system units.System) ScaledIngredient {
+	if ing.Amount <= 0 || units.Unmeasured(ing.Unit) {
+		return ScaledIngredient{Ingredient: ing, Display: quantity(ing), Fixed: true}
+	}
+	amount := ing.Amount * factor
+	u, ok := units.Lookup(ing.Unit)
+	if !ok {
+		ing.Amount = units.RoundFraction(amount)
+		if ing.AmountMax > 0 {
+			ing.AmountMax = units.RoundFraction(ing.AmountMax * factor)
+		}
+		return ScaledIngredient{Ingredient: ing, Display: quantity(ing)}
+	}
+	best := u
+	if system != "" {
+		amount, best = units.ForSystem(amount, u, ing.Name, system)
+	} else {
+		amount, best = units.Best(amount, u)
+	}
+	if ing.AmountMax > 0 {
+		// The upper end of a range moves to the same unit as the amount.
+		ing.AmountMax = units.Round(ing.AmountMax*amount/ing.Amount, best)
+	}
+	ing.Amount = units.Round(amount, best)
+	// Keep the cook's own spelling of the unit unless it has to change.
+	if best != u || ing.Unit == u.Symbol || ing.Unit == u.Label(2) {
+		ing.Unit = best.Label(max(ing.Amount, ing.AmountMax))
+	}
+	return ScaledIngredient{Ingredient: ing, Display: quantity(ing)}
+}
+
+// quantity formats an ingredient's amount or range and unit; a missing
+// amount leaves the unit.
+func quantity(ing Ingredient) string {
+	if ing.Amount <= 0 {
+		return ing.Unit
+	}
+	amount := units.FormatAmount(ing.Amount)
+	if ing.AmountMax > ing.Amount {
+		amount += "–" + units.FormatAmount(ing.AmountMax)
+	}
+	return strings.TrimSpace(amount + " " + ing.Unit)
+}
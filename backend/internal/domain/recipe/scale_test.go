@@ -0,0 +1,90 @@
+package recipe
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScaleRecipe(t *testing.T) {
+	r := &Recipe{
+		Title:    "Pancakes",
+		Servings: 4,
+		Ingredients: []Ingredient{
+			{Name: "flour", Amount: 1.5, Unit: "cups"},
+			{Name: "sugar", Amount: 2, Unit: "tsp"},
+			{Name: "salt", Amount: 1, Unit: "pinch"},
+			{Name: "milk", Amount: 300, Unit: "ml"},
+			{Name: "eggs", Amount: 2},
+			{Name: "butter", Amount: 2, Unit: "tbsp"},
+			{Name: "maple syrup", Unit: "to taste"},
+		},
+		NutritionalInfo: NutritionalInfo{Calories: 300, Protein: 8, Sodium: 5},
+	}
+
+	scaled, err := ScaleRecipe(r, 6)
+	if err != nil {
+		t.Fatalf("scale: %v", err)
+	}
+	if scaled.Servings != 6 || scaled.OriginalServings != 4 || scaled.ScaleFactor != 1.5 {
+		t.Fatalf("unexpected servings %d/%d factor %v", scaled.Servings, scaled.OriginalServings, scaled.ScaleFactor)
+	}
+	want := []struct {
+		display string
+		fixed   bool
+	}{
+		{"2 ¼ cups", false},
+		{"1 tbsp", false},
+		{"1 pinch", true},
+		{"450 ml", false},
+		{"3", false},
+		{"3 tbsp", false},
+		{"to taste", true},
+	}
+	for i, w := range want {
+		got := scaled.Ingredients[i]
+		if got.Display != w.display || got.Fixed != w.fixed {
+			t.Errorf("%s = %q fixed=%v, want %q fixed=%v", got.Name, got.Display, got.Fixed, w.display, w.fixed)
+		}
+	}
+	if scaled.NutritionalInfo != r.NutritionalInfo {
+		t.Fatalf("expected nutrition per serving to be unchanged, got %+v", scaled.NutritionalInfo)
+	}
+	if n := scaled.TotalNutrition; n.Calories != 1800 || n.Protein != 48 || n.Sodium != 30 {
+		t.Fatalf("unexpected total nutrition %+v", n)
+	}
+	if r.Servings != 4 || r.Ingredients[0].Amount != 1.5 || r.NutritionalInfo.Calories != 300 {
+		t.Fatalf("original recipe was modified")
+	}
+}
+
+func TestScaleRecipeDown(t *testing.T) {
+	r := &Recipe{
+		Servings: 8,
+		Ingredients: []Ingredient{
+			{Name: "stock", Amount: 2, Unit: "l"},
+			{Name: "oil", Amount: 0.25, Unit: "cup"},
+			{Name: "garlic", Amount: 3, Unit: "cloves"},
+			{Name: "water", Amount: 4, AmountMax: 6, Unit: "cups"},
+		},
+	}
+	scaled, err := ScaleRecipe(r, 2)
+	if err != nil {
+		t.Fatalf("scale: %v", err)
+	}
+	for i, want := range []string{"500 ml", "1 tbsp", "¾ cloves", "1–1 ½ cups"} {
+		if got := scaled.Ingredients[i].Display; got != want {
+			t.Errorf("ingredient %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestScaleRecipeErrors(t *testing.T) {
+	if _, err := ScaleRecipe(&Recipe{}, 2); !errors.Is(err, ErrServingsUnknown) {
+		t.Fatalf("expected ErrServingsUnknown, got %v", err)
+	}
+	for _, servings := range []int{0, -1, MaxServings + 1} {
+		if _, err := ScaleRecipe(&Recipe{Servings: 2}, servings); err == nil {
+			t.Errorf("expected error for %d servings", servings)
+		}
+	}
+}
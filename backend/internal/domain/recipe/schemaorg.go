@@ -0,0 +1,549 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// ErrNoRecipeInDocument is returned when an imported document contains no
+// schema.org Recipe.
+var ErrNoRecipeInDocument = apperrors.New("no_recipe_in_document", "document contains no schema.org recipe", 422)
+
+// ImportResult is a recipe read from a schema.org document. Unmapped lists
+// the properties of the schema.org Recipe that could not be carried over, in
+// whole or in part.
+type ImportResult struct {
+	Request  CreateRequest
+	Unmapped []string
+}
+
+var (
+	jsonLDPattern   = regexp.MustCompile(`(?is)<script[^>]*type\s*=\s*["']?application/ld\+json["']?[^>]*>(.*?)</script>`)
+	tagPattern      = regexp.MustCompile(`<[^>]*>`)
+	durationPattern = regexp.MustCompile(`^P(?:(\d+(?:\.\d+)?)D)?(?:T(?:(\d+(?:\.\d+)?)H)?(?:(\d+(?:\.\d+)?)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+	integerPattern  = regexp.MustCompile(`\d+`)
+	measurePattern  = regexp.MustCompile(`(\d+(?:[.,]\d+)?)\s*(mg|g|kcal|cal)?`)
+)
+
+// schemaDiets maps schema.org RestrictedDiet values to dietary categories.
+var schemaDiets = map[string]string{
+	"GlutenFreeDiet": DietGlutenFree,
+	"VeganDiet":      DietVegan,
+	"VegetarianDiet": DietVegetarian,
+	"LowSaltDiet":    DietLowSodium,
+	"LowLactoseDiet": DietDairyFree,
+	"HalalDiet":      "halal",
+	"KosherDiet":     "kosher",
+	"HinduDiet":      "hindu",
+	"DiabeticDiet":   "diabetic",
+	"LowCalorieDiet": "low-calorie",
+	"LowFatDiet":     "low-fat",
+}
+
+// ignoredProperties are Recipe properties that describe the page rather
+// than the recipe and are not reported as unmapped.
+var ignoredProperties = map[string]bool{
+	"url": true, "mainEntityOfPage": true, "isPartOf": true, "publisher": true,
+	"inLanguage": true, "thumbnailUrl": true,
+}
+
+// ParseSchemaOrg reads the schema.org Recipe from a JSON-LD document or from
+// the JSON-LD scripts of an HTML page. Recipes nested in @graph, in lists or
+// under mainEntity are found as well. Ingredient lines are left in
+// IngredientLines for the ingredient parser.
+func ParseSchemaOrg(data []byte) (*ImportResult, error) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\ufeff")))
+	var docs [][]byte
+	if len(data) > 0 && (data[0] == '{' || data[0] == '[') {
+		docs = [][]byte{data}
+	} else {
+		for _, m := range jsonLDPattern.FindAllSubmatch(data, -1) {
+			docs = append(docs, m[1])
+		}
+	}
+	for _, doc := range docs {
+		var v any
+		if err := json.Unmarshal(doc, &v); err != nil {
+			// Pages often carry broken JSON-LD for other things.
+			continue
+		}
+		if obj := findRecipe(v); obj != nil {
+			return mapRecipe(obj), nil
+		}
+	}
+	return nil, ErrNoRecipeInDocument
+}
+
+// findRecipe searches a JSON-LD value for an object typed as a Recipe.
+func findRecipe(v any) map[string]any {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if obj := findRecipe(item); obj != nil {
+				return obj
+			}
+		}
+	case map[string]any:
+		if isType(v["@type"], "Recipe") {
+			return v
+		}
+		for _, key := range []string{"@graph", "mainEntity", "itemListElement", "item"} {
+			if obj := findRecipe(v[key]); obj != nil {
+				return obj
+			}
+		}
+	}
+	return nil
+}
+
+// isType reports whether a @type value, a name or a list of names, includes
+// the schema.org type name.
+func isType(t any, name string) bool {
+	switch t := t.(type) {
+	case string:
+		t = strings.TrimPrefix(strings.TrimPrefix(t, "http://schema.org/"), "https://schema.org/")
+		return strings.TrimPrefix(t, "schema:") == name
+	case []any:
+		for _, item := range t {
+			if isType(item, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mapRecipe maps the properties of a schema.org Recipe onto a create
+// request. IsPublic is left false so that imported recipes start private.
+func mapRecipe(obj map[string]any) *ImportResult {
+	res := &ImportResult{}
+	req := &res.Request
+	unmapped := map[string]bool{}
+	handled := map[string]bool{}
+	fail := func(key string) { unmapped[key] = true }
+	use := func(key string) (any, bool) {
+		handled[key] = true
+		v, ok := obj[key]
+		return v, ok && v != nil
+	}
+
+	if v, ok := use("name"); ok {
+		req.Title = firstText(v)
+	}
+	if v, ok := use("description"); ok {
+		req.Description = firstText(v)
+	}
+	if v, ok := use("recipeIngredient"); ok {
+		req.IngredientLines = texts(v)
+	}
+	// ingredients is the deprecated name of recipeIngredient.
+	if v, ok := use("ingredients"); ok && len(req.IngredientLines) == 0 {
+		req.IngredientLines = texts(v)
+	}
+	if v, ok := use("recipeInstructions"); ok {
+		req.Instructions = instructions(v)
+		if len(req.Instructions) == 0 {
+			fail("recipeInstructions")
+		}
+	}
+	for key, dest := range map[string]*int{"prepTime": &req.PrepTime, "cookTime": &req.CookTime} {
+		if v, ok := use(key); ok {
+			minutes, ok := parseDuration(firstText(v))
+			if !ok {
+				fail(key)
+			}
+			*dest = minutes
+		}
+	}
+	if v, ok := use("totalTime"); ok {
+		minutes, ok := parseDuration(firstText(v))
+		switch {
+		case !ok:
+			fail("totalTime")
+		case req.PrepTime == 0 && req.CookTime == 0:
+			// Without a breakdown the whole time is counted as cooking.
+			req.CookTime = minutes
+		case minutes > req.PrepTime+req.CookTime:
+			// Resting or marinating time has nowhere to go.
+			fail("totalTime")
+		}
+	}
+	if v, ok := use("recipeYield"); ok {
+		if req.Servings = servings(v); req.Servings == 0 {
+			fail("recipeYield")
+		}
+	}
+	if v, ok := use("recipeCategory"); ok {
+		categories := texts(v)
+		if len(categories) > 0 {
+			req.Category = categories[0]
+		}
+		if len(categories) > 1 {
+			fail("recipeCategory")
+		}
+	}
+	var keywords []string
+	if v, ok := use("keywords"); ok {
+		for _, k := range texts(v) {
+			keywords = append(keywords, strings.Split(k, ",")...)
+		}
+	}
+	if v, ok := use("recipeCuisine"); ok {
+		keywords = append(texts(v), keywords...)
+	}
+	for _, k := range normalizeTags(keywords) {
+		if len(req.Tags) == MaxTags || len([]rune(k)) > MaxTagLength {
+			fail("keywords")
+			continue
+		}
+		req.Tags = append(req.Tags, k)
+	}
+	if v, ok := use("suitableForDiet"); ok {
+		for _, d := range texts(v) {
+			name := d[strings.LastIndexAny(d, "/:")+1:]
+			if diet, ok := schemaDiets[name]; ok {
+				req.DietaryCategories = append(req.DietaryCategories, diet)
+			} else {
+				fail("suitableForDiet")
+			}
+		}
+	}
+	if v, ok := use("nutrition"); ok {
+		if !nutrition(v, &req.NutritionalInfo) {
+			fail("nutrition")
+		}
+	}
+	if v, ok := use("image"); ok {
+		if url := imageURL(v); url != "" {
+			req.ImageURL = &url
+		} else {
+			fail("image")
+		}
+	}
+
+	for key := range obj {
+		if !handled[key] && !strings.HasPrefix(key, "@") && !ignoredProperties[key] {
+			fail(key)
+		}
+	}
+	for key := range unmapped {
+		res.Unmapped = append(res.Unmapped, key)
+	}
+	sort.Strings(res.Unmapped)
+	return res
+}
+
+// texts returns the text of a value that may be a string, a number, a list
+// or an object with a name or text.
+func texts(v any) []string {
+	var out []string
+	switch v := v.(type) {
+	case string:
+		if s := cleanText(v); s != "" {
+			out = append(out, s)
+		}
+	case float64:
+		out = append(out, strconv.FormatFloat(v, 'f', -1, 64))
+	case []any:
+		for _, item := range v {
+			out = append(out, texts(item)...)
+		}
+	case map[string]any:
+		for _, key := range []string{"text", "name", "@id"} {
+			if s, ok := v[key].(string); ok && cleanText(s) != "" {
+				return []string{cleanText(s)}
+			}
+		}
+	}
+	return out
+}
+
+func firstText(v any) string {
+	if t := texts(v); len(t) > 0 {
+		return t[0]
+	}
+	return ""
+}
+
+// cleanText unescapes HTML entities, drops tags and collapses whitespace.
+func cleanText(s string) string {
+	s = html.UnescapeString(tagPattern.ReplaceAllString(s, " "))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// instructions flattens recipeInstructions, which may be a block of text, a
+// list of strings, HowToStep objects or HowToSection objects containing
+// steps. A section's name becomes the section of its first step, and a
+// step's timeRequired, performTime or totalTime its duration.
+func instructions(v any) []Step {
+	var out []Step
+	switch v := v.(type) {
+	case string:
+		for _, line := range strings.Split(html.UnescapeString(v), "\n") {
+			if line = cleanText(line); line != "" {
+				out = append(out, Step{Text: line})
+			}
+		}
+	case []any:
+		for _, item := range v {
+			out = append(out, instructions(item)...)
+		}
+	case map[string]any:
+		if steps, ok := v["itemListElement"]; ok {
+			out = instructions(steps)
+			if name, _ := v["name"].(string); len(out) > 0 && out[0].Section == "" && isType(v["@type"], "HowToSection") {
+				out[0].Section = cleanText(name)
+			}
+			return out
+		}
+		for _, text := range texts(v) {
+			step := Step{Text: text}
+			for _, key := range []string{"timeRequired", "performTime", "totalTime"} {
+				if s, ok := v[key].(string); ok {
+					if d, ok := parseDuration(s); ok {
+						step.Duration = d
+						break
+					}
+				}
+			}
+			out = append(out, step)
+		}
+	}
+	return out
+}
+
+// parseDuration reads an ISO 8601 duration such as "PT1H30M" as minutes.
+func parseDuration(s string) (int, bool) {
+	m := durationPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil || s == "" {
+		return 0, false
+	}
+	var minutes float64
+	for i, scale := range []float64{24 * 60, 60, 1, 1.0 / 60} {
+		if m[i+1] != "" {
+			n, _ := strconv.ParseFloat(m[i+1], 64)
+			minutes += n * scale
+		}
+	}
+	return int(math.Round(minutes)), true
+}
+
+// servings reads the number of servings from a recipeYield such as 4,
+// "4 servings" or ["4", "4 servings"].
+func servings(v any) int {
+	for _, t := range texts(v) {
+		if m := integerPattern.FindString(t); m != "" {
+			n, _ := strconv.Atoi(m)
+			if n > 0 && n <= MaxServings {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// nutrition reads a NutritionInformation object, whose values are texts such
+// as "240 calories" or "300 mg". It reports whether any value was read.
+func nutrition(v any, info *NutritionalInfo) bool {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	read := false
+	for key, dest := range map[string]*int{
+		"calories":            &info.Calories,
+		"proteinContent":      &info.Protein,
+		"carbohydrateContent": &info.Carbohydrates,
+		"fatContent":          &info.Fat,
+		"fiberContent":        &info.Fiber,
+		"sugarContent":        &info.Sugar,
+		"sodiumContent":       &info.Sodium,
+	} {
+		m := measurePattern.FindStringSubmatch(firstText(obj[key]))
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64)
+		switch {
+		case key == "sodiumContent" && m[2] == "g":
+			n *= 1000
+		case key != "sodiumContent" && key != "calories" && m[2] == "mg":
+			n /= 1000
+		}
+		*dest = int(math.Round(n))
+		read = true
+	}
+	return read
+}
+
+// imageURL returns the first URL of an image given as a URL, a list or an
+// ImageObject.
+func imageURL(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []any:
+		for _, item := range v {
+			if url := imageURL(item); url != "" {
+				return url
+			}
+		}
+	case map[string]any:
+		for _, key := range []string{"url", "contentUrl", "@id"} {
+			if url, ok := v[key].(string); ok && url != "" {
+				return strings.TrimSpace(url)
+			}
+		}
+	}
+	return ""
+}
+
+// schemaRecipe is the schema.org Recipe written by MarshalSchemaOrg.
+type schemaRecipe struct {
+	Context            string           `json:"@context"`
+	Type               string           `json:"@type"`
+	Name               string           `json:"name"`
+	Description        string           `json:"description,omitempty"`
+	Image              string           `json:"image,omitempty"`
+	PrepTime           string           `json:"prepTime,omitempty"`
+	CookTime           string           `json:"cookTime,omitempty"`
+	TotalTime          string           `json:"totalTime,omitempty"`
+	RecipeYield        string           `json:"recipeYield,omitempty"`
+	RecipeCategory     string           `json:"recipeCategory,omitempty"`
+	Keywords           string           `json:"keywords,omitempty"`
+	SuitableForDiet    []string         `json:"suitableForDiet,omitempty"`
+	RecipeIngredient   []string         `json:"recipeIngredient"`
+	RecipeInstructions []any            `json:"recipeInstructions"`
+	Nutrition          *schemaNutrition `json:"nutrition,omitempty"`
+	AggregateRating    *schemaRating    `json:"aggregateRating,omitempty"`
+}
+
+type schemaStep struct {
+	Type         string `json:"@type"`
+	Text         string `json:"text"`
+	TimeRequired string `json:"timeRequired,omitempty"`
+}
+
+type schemaSection struct {
+	Type            string       `json:"@type"`
+	Name            string       `json:"name"`
+	ItemListElement []schemaStep `json:"itemListElement"`
+}
+
+type schemaNutrition struct {
+	Type                string `json:"@type"`
+	Calories            string `json:"calories"`
+	ProteinContent      string `json:"proteinContent"`
+	CarbohydrateContent string `json:"carbohydrateContent"`
+	FatContent          string `json:"fatContent"`
+	FiberContent        string `json:"fiberContent"`
+	SugarContent        string `json:"sugarContent"`
+	SodiumContent       string `json:"sodiumContent"`
+}
+
+type schemaRating struct {
+	Type        string  `json:"@type"`
+	RatingValue float64 `json:"ratingValue"`
+	RatingCount int     `json:"ratingCount"`
+}
+
+// MarshalSchemaOrg writes a recipe as a schema.org Recipe in JSON-LD, the
+// format ParseSchemaOrg reads.
+func MarshalSchemaOrg(r *Recipe) []byte {
+	out := schemaRecipe{
+		Context:            "https://schema.org",
+		Type:               "Recipe",
+		Name:               r.Title,
+		Description:        r.Description,
+		RecipeCategory:     r.Category,
+		Keywords:           strings.Join(r.Tags, ", "),
+		RecipeIngredient:   []string{},
+		RecipeInstructions: []any{},
+	}
+	if r.ImageURL != nil {
+		out.Image = *r.ImageURL
+	}
+	if r.PrepTime > 0 {
+		out.PrepTime = isoDuration(r.PrepTime)
+	}
+	if r.CookTime > 0 {
+		out.CookTime = isoDuration(r.CookTime)
+	}
+	if r.PrepTime+r.CookTime > 0 {
+		out.TotalTime = isoDuration(r.PrepTime + r.CookTime)
+	}
+	if r.Servings > 0 {
+		out.RecipeYield = fmt.Sprintf("%d servings", r.Servings)
+	}
+	for _, d := range r.DietaryCategories {
+		for name, diet := range schemaDiets {
+			if diet == d {
+				out.SuitableForDiet = append(out.SuitableForDiet, "https://schema.org/"+name)
+			}
+		}
+	}
+	sort.Strings(out.SuitableForDiet)
+	for _, ing := range r.Ingredients {
+		out.RecipeIngredient = append(out.RecipeIngredient, FormatIngredient(ing))
+	}
+	// Steps before the first section are listed on their own, the rest in
+	// a HowToSection per section.
+	var section *schemaSection
+	for _, step := range r.Instructions {
+		s := schemaStep{Type: "HowToStep", Text: step.Text}
+		if step.Duration > 0 {
+			s.TimeRequired = isoDuration(step.Duration)
+		}
+		if step.Section != "" {
+			section = &schemaSection{Type: "HowToSection", Name: step.Section}
+			out.RecipeInstructions = append(out.RecipeInstructions, section)
+		}
+		if section != nil {
+			section.ItemListElement = append(section.ItemListElement, s)
+		} else {
+			out.RecipeInstructions = append(out.RecipeInstructions, s)
+		}
+	}
+	if n := r.NutritionalInfo; n != (NutritionalInfo{}) {
+		out.Nutrition = &schemaNutrition{
+			Type:                "NutritionInformation",
+			Calories:            fmt.Sprintf("%d calories", n.Calories),
+			ProteinContent:      fmt.Sprintf("%d g", n.Protein),
+			CarbohydrateContent: fmt.Sprintf("%d g", n.Carbohydrates),
+			FatContent:          fmt.Sprintf("%d g", n.Fat),
+			FiberContent:        fmt.Sprintf("%d g", n.Fiber),
+			SugarContent:        fmt.Sprintf("%d g", n.Sugar),
+			SodiumContent:       fmt.Sprintf("%d mg", n.Sodium),
+		}
+	}
+	if r.RatingCount > 0 {
+		out.AggregateRating = &schemaRating{Type: "AggregateRating", RatingValue: r.RatingAverage, RatingCount: r.RatingCount}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(out)
+	return buf.Bytes()
+}
+
+// isoDuration writes minutes as an ISO 8601 duration such as "PT1H5M".
+func isoDuration(m int) string {
+	s := "PT"
+	if m >= 60 {
+		s += strconv.Itoa(m/60) + "H"
+	}
+	if m%60 > 0 || m == 0 {
+		s += strconv.Itoa(m%60) + "M"
+	}
+	return s
+}
@@ -0,0 +1,175 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user/usertest"
+)
+
+const blogPage = `<!DOCTYPE html>
+<html><head>
+<title>Weeknight Chili | A Food Blog</title>
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "BreadcrumbList", broken</script>
+<script type="application/ld+json">
+{
+  "@context": "https://schema.org",
+  "@graph": [
+    {"@type": "WebSite", "@id": "https://example.com/#website", "name": "A Food Blog"},
+    {"@type": "WebPage", "@id": "https://example.com/chili/"},
+    {
+      "@type": ["Recipe", "NewsArticle"],
+      "name": "Weeknight Chili &amp; Cornbread",
+      "description": "<p>A quick, <b>smoky</b> chili.</p>",
+      "author": {"@type": "Person", "name": "Sam"},
+      "datePublished": "2024-01-05",
+      "image": [{"@type": "ImageObject", "url": "https://example.com/chili.jpg"}],
+      "recipeYield": ["4", "4 servings"],
+      "prepTime": "PT15M",
+      "cookTime": "PT1H5M",
+      "totalTime": "PT1H20M",
+      "recipeCategory": "Main Course",
+      "recipeCuisine": "Tex-Mex",
+      "keywords": "chili, weeknight, one pot",
+      "suitableForDiet": "https://schema.org/GlutenFreeDiet",
+      "recipeIngredient": [
+        "1 lb ground beef",
+        "1 (14 oz) can diced tomatoes",
+        "2 tbsp chili powder",
+        "salt, to taste"
+      ],
+      "recipeInstructions": [
+        {"@type": "HowToSection", "name": "Chili", "itemListElement": [
+          {"@type": "HowToStep", "text": "Brown the beef."},
+          {"@type": "HowToStep", "text": "Add the tomatoes and chili powder, then simmer for 1 hour.", "timeRequired": "PT1H"}
+        ]},
+        {"@type": "HowToStep", "text": "Season with salt."}
+      ],
+      "nutrition": {"@type": "NutritionInformation", "calories": "420 calories", "proteinContent": "28 g", "fatContent": "22g", "sodiumContent": "0.9 g"},
+      "aggregateRating": {"@type": "AggregateRating", "ratingValue": "4.8", "ratingCount": "120"},
+      "url": "https://example.com/chili/"
+    }
+  ]
+}
+</script>
+</head><body><h1>Weeknight Chili</h1></body></html>`
+
+func TestParseSchemaOrgFromHTML(t *testing.T) {
+	res, err := ParseSchemaOrg([]byte(blogPage))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	image := "https://example.com/chili.jpg"
+	want := CreateRequest{
+		Title:       "Weeknight Chili & Cornbread",
+		Description: "A quick, smoky chili.",
+		IngredientLines: []string{
+			"1 lb ground beef", "1 (14 oz) can diced tomatoes", "2 tbsp chili powder", "salt, to taste",
+		},
+		Instructions: []Step{
+			{Section: "Chili", Text: "Brown the beef."},
+			{Text: "Add the tomatoes and chili powder, then simmer for 1 hour.", Duration: 60},
+			{Text: "Season with salt."},
+		},
+		PrepTime:          15,
+		CookTime:          65,
+		Servings:          4,
+		Category:          "Main Course",
+		Tags:              []string{"tex-mex", "chili", "weeknight", "one-pot"},
+		DietaryCategories: []string{DietGlutenFree},
+		NutritionalInfo:   NutritionalInfo{Calories: 420, Protein: 28, Fat: 22, Sodium: 900},
+		ImageURL:          &image,
+	}
+	if !reflect.DeepEqual(res.Request, want) {
+		t.Fatalf("request =\n\t%+v\nwant\n\t%+v", res.Request, want)
+	}
+	if want := []string{"aggregateRating", "author", "datePublished"}; !reflect.DeepEqual(res.Unmapped, want) {
+		t.Fatalf("unmapped = %v, want %v", res.Unmapped, want)
+	}
+}
+
+func TestParseSchemaOrgFromJSONLD(t *testing.T) {
+	doc := `{
+		"@context": "http://schema.org",
+		"@type": "Recipe",
+		"name": "Flatbread",
+		"recipeYield": "Makes a lot",
+		"totalTime": "PT2H30M",
+		"recipeCategory": ["Bread", "Side"],
+		"recipeInstructions": "Mix the dough.\nRest for two hours.\n\nBake.",
+		"ingredients": ["500 g flour", "300 ml water"],
+		"video": {"@type": "VideoObject"}
+	}`
+	res, err := ParseSchemaOrg([]byte(doc))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	req := res.Request
+	if req.Title != "Flatbread" || req.CookTime != 150 || req.Servings != 0 || req.Category != "Bread" {
+		t.Fatalf("unexpected request %+v", req)
+	}
+	if !reflect.DeepEqual(req.Instructions, TextSteps([]string{"Mix the dough.", "Rest for two hours.", "Bake."})) {
+		t.Fatalf("unexpected instructions %+v", req.Instructions)
+	}
+	if !reflect.DeepEqual(req.IngredientLines, []string{"500 g flour", "300 ml water"}) {
+		t.Fatalf("unexpected ingredient lines %q", req.IngredientLines)
+	}
+	if want := []string{"recipeCategory", "recipeYield", "video"}; !reflect.DeepEqual(res.Unmapped, want) {
+		t.Fatalf("unmapped = %v, want %v", res.Unmapped, want)
+	}
+}
+
+func TestParseSchemaOrgWithoutRecipe(t *testing.T) {
+	for _, doc := range []string{
+		`<html><body>No structured data here.</body></html>`,
+		`{"@type": "Article", "name": "Not a recipe"}`,
+		`{broken`,
+	} {
+		if _, err := ParseSchemaOrg([]byte(doc)); !errors.Is(err, ErrNoRecipeInDocument) {
+			t.Errorf("ParseSchemaOrg(%q) error = %v, want ErrNoRecipeInDocument", doc, err)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{"PT15M", 15, true},
+		{"PT1H30M", 90, true},
+		{"P0DT0H35M", 35, true},
+		{"PT0.5H", 30, true},
+		{"PT90S", 2, true},
+		{"P1D", 1440, true},
+		{" pt20m ", 20, true},
+		{"20 minutes", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseDuration(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseDuration(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestImportCreatesPrivateRecipe(t *testing.T) {
+	svc := NewService(&fakeRepository{}, usertest.NewUsers())
+	r, unmapped, err := svc.Import(context.Background(), uuid.New(), []byte(blogPage))
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if r.IsPublic || len(unmapped) != 3 {
+		t.Fatalf("unexpected import %+v, unmapped %v", r, unmapped)
+	}
+	tomatoes := r.Ingredients[1]
+	if tomatoes.Name != "diced tomatoes" || tomatoes.Amount != 14 || tomatoes.Unit != "oz" {
+		t.Fatalf("expected ingredient lines to be parsed, got %+v", r.Ingredients)
+	}
+}
@@ -2,32 +2,177 @@ package recipe
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/logger"
+	"alchemorsel/backend/internal/pkg/pagination"
+	"alchemorsel/backend/internal/pkg/units"
+	"alchemorsel/backend/internal/pkg/validator"
 )
 
 // Service defines business logic for recipes.
 type Service interface {
-	Create(ctx context.Context, req CreateRequest) (*Recipe, error)
-	Get(ctx context.Context, id uuid.UUID) (*Recipe, error)
+	Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*Recipe, error)
+	// Import creates a private recipe from a schema.org JSON-LD document or
+	// an HTML page embedding one, and returns the schema.org properties that
+	// could not be carried over.
+	Import(ctx context.Context, userID uuid.UUID, document []byte) (*Recipe, []string, error)
+	// Get returns a recipe visible to the viewer, which may be nil for
+	// anonymous requests.
+	Get(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) (*Recipe, error)
+	// Convert returns a recipe visible to the viewer with its ingredients in
+	// the given unit system, or in the viewer's preferred system when system
+	// is empty. Without either the recipe is returned as written.
+	Convert(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, system units.System) (*Recipe, error)
+	// Scale returns a recipe visible to the viewer rescaled to the given
+	// number of servings, converted as by Convert.
+	Scale(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, servings int, system units.System) (*ScaledRecipe, error)
+	Update(ctx context.Context, userID, id uuid.UUID, req UpdateRequest) (*Recipe, error)
+	// SetImage sets the image of the user's recipe, clearing it when url is
+	// empty. Unlike Update it records no revision and leaves the version
+	// alone, so that galleries can keep the recipe's cover in step.
+	SetImage(ctx context.Context, userID, id uuid.UUID, url string) error
+	// Delete moves a recipe to its owner's trash. version is the version the
+	// client last read, as in UpdateRequest.
+	Delete(ctx context.Context, userID, id uuid.UUID, version int) error
+	Restore(ctx context.Context, userID, id uuid.UUID) (*Recipe, error)
+	ListTrash(ctx context.Context, userID uuid.UUID) ([]*Recipe, error)
+	// ListByUser returns all of the user's recipes, newest first.
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Recipe, error)
+	// ListRevisions, GetRevision and DiffRevisions expose the history of a
+	// recipe to anyone who may view it.
+	ListRevisions(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) ([]*Revision, error)
+	GetRevision(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, version int) (*Revision, error)
+	DiffRevisions(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, from, to int) (*Diff, error)
+	// Revert stores the content of an earlier revision as a new revision.
+	// expectedVersion is the version the client last read, as in UpdateRequest.
+	Revert(ctx context.Context, userID, id uuid.UUID, version, expectedVersion int) (*Recipe, error)
+	// Fork copies a recipe visible to the user into the user's account as a
+	// private recipe attributed to the original.
+	Fork(ctx context.Context, userID, id uuid.UUID) (*Recipe, error)
+	ListForks(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) ([]*Recipe, error)
+	// Ancestry returns the chain of recipes a fork descends from, nearest
+	// first.
+	Ancestry(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) ([]*Ancestor, error)
 	Search(ctx context.Context, params SearchParams) (*SearchResult, error)
-	Generate(ctx context.Context, req GenerateRequest) (*Recipe, error)
+	// ListTags returns popular tags, optionally restricted to those starting
+	// with prefix for autocompletion.
+	ListTags(ctx context.Context, prefix string, limit int) ([]TagCount, error)
+	// SuggestTags proposes tags for a recipe from its title and ingredients.
+	SuggestTags(ctx context.Context, req SuggestRequest) ([]string, error)
+	Generate(ctx context.Context, userID uuid.UUID, req GenerateRequest) (*Recipe, error)
 	AddFavorite(ctx context.Context, userID, recipeID uuid.UUID) error
 	RemoveFavorite(ctx context.Context, userID, recipeID uuid.UUID) error
 	GetFavorites(ctx context.Context, userID uuid.UUID) ([]*Recipe, error)
+	// FlagAllergens sets the allergen warnings of recipes listed to the
+	// viewer, as Get does for a single recipe. Anonymous viewers get none.
+	FlagAllergens(ctx context.Context, viewerID *uuid.UUID, recipes []*Recipe) error
+	// Diets checks a recipe visible to the viewer against every known diet,
+	// explaining why it fails those it does not fit.
+	Diets(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) ([]DietResult, error)
+	// Export renders a recipe visible to the viewer as a file in one of the
+	// export formats.
+	Export(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, format string) (*Document, error)
+	// ExportLibrary packs all of the user's recipes into a zip archive of
+	// files in one of the export formats.
+	ExportLibrary(ctx context.Context, userID uuid.UUID, format string) (*Document, error)
+	// Relabel brings the allergens and dietary categories of every stored
+	// recipe up to date with the current normalization, detection and diet
+	// rules, and returns how many recipes changed. It is meant to run once after the rules change.
+	Relabel(ctx context.Context) (int, error)
 }
 
 type CreateRequest struct {
 	Title             string
 	Description       string
 	Ingredients       []Ingredient
-	Instructions      []string
+	Instructions      []Step
 	PrepTime          int
 	CookTime          int
 	Servings          int
 	Category          string
 	DietaryCategories []string
 	Allergens         []string
+	Tags              []string
+	NutritionalInfo   NutritionalInfo
+	ImageURL          *string
+	IsPublic          bool
+
+	// IngredientLines are ingredients as free text, one per line, parsed
+	// with ParseIngredient and added after Ingredients.
+	IngredientLines []string
+}
+
+// UpdateRequest describes changes to a recipe. Nil fields are left unchanged,
+// so a full replacement sets all of them. Version is the version the client
+// last read; the update fails with ErrVersionConflict if it is stale.
+type UpdateRequest struct {
+	Version           int
+	Title             *string
+	Description       *string
+	Ingredients       *[]Ingredient
+	Instructions      *[]Step
+	PrepTime          *int
+	CookTime          *int
+	Servings          *int
+	Category          *string
+	DietaryCategories *[]string
+	Allergens         *[]string
+	Tags              *[]string
+	NutritionalInfo   *NutritionalInfo
+	IsPublic          *bool
+}
+
+// apply copies the set fields onto r. The image is not among them: it is the
+// cover of the recipe's gallery and changes only through SetImage.
+func (req UpdateRequest) apply(r *Recipe) {
+	if req.Title != nil {
+		r.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		r.Description = *req.Description
+	}
+	if req.Ingredients != nil {
+		r.Ingredients = *req.Ingredients
+	}
+	if req.Instructions != nil {
+		r.Instructions = sanitizeSteps(*req.Instructions)
+	}
+	if req.PrepTime != nil {
+		r.PrepTime = *req.PrepTime
+	}
+	if req.CookTime != nil {
+		r.CookTime = *req.CookTime
+	}
+	if req.Servings != nil {
+		r.Servings = *req.Servings
+	}
+	if req.Category != nil {
+		r.Category = *req.Category
+	}
+	if req.DietaryCategories != nil {
+		r.DietaryCategories = normalizeDiets(*req.DietaryCategories)
+	}
+	if req.Allergens != nil {
+		r.Allergens = normalizeAllergens(*req.Allergens)
+	}
+	if req.Tags != nil {
+		r.Tags = normalizeTags(*req.Tags)
+	}
+	if req.NutritionalInfo != nil {
+		r.NutritionalInfo = *req.NutritionalInfo
+	}
+	if req.IsPublic != nil {
+		r.IsPublic = *req.IsPublic
+	}
 }
 
 type GenerateRequest struct {
@@ -37,3 +182,790 @@ type GenerateRequest struct {
 	Servings     int
 	CustomPrompt string
 }
+
+var (
+	// ErrGenerationUnavailable is returned when no recipe generator is configured.
+	ErrGenerationUnavailable = apperrors.New("generation_unavailable", "recipe generation is not available", 503)
+	// ErrSemanticSearchUnavailable is returned when a text query needs an
+	// embedding but no embedder is configured.
+	ErrSemanticSearchUnavailable = apperrors.New("semantic_search_unavailable", "semantic search is not available", 503)
+	// ErrEmbeddingNotFound is returned when a recipe used as a similarity
+	// reference has no stored embedding.
+	ErrEmbeddingNotFound = apperrors.New("embedding_not_found", "recipe has no embedding", 422)
+	// ErrVersionConflict is returned when a recipe was modified after the
+	// client read it.
+	ErrVersionConflict = apperrors.New("version_conflict", "recipe has been modified by someone else", 409)
+	// ErrRevisionNotFound is returned when a recipe has no revision with the
+	// requested version.
+	ErrRevisionNotFound = apperrors.New("revision_not_found", "revision not found", 404)
+)
+
+var sortKeys = map[string]bool{
+	SortRelevance:   true,
+	SortCreatedAt:   true,
+	SortTitle:       true,
+	SortPrepTime:    true,
+	SortRating:      true,
+	SortRatingCount: true,
+}
+
+type service struct {
+	repo      Repository
+	users     user.Repository
+	generator Generator
+	embedder  Embedder
+	nutrition NutritionCalculator
+}
+
+// Option configures optional service dependencies.
+type Option func(*service)
+
+// WithGenerator enables LLM recipe generation.
+func WithGenerator(g Generator) Option {
+	return func(s *service) { s.generator = g }
+}
+
+// WithEmbedder enables embedding of created recipes and semantic text queries.
+func WithEmbedder(e Embedder) Option {
+	return func(s *service) { s.embedder = e }
+}
+
+// WithNutrition enables computing nutritional information from ingredients
+// whenever they change.
+func WithNutrition(c NutritionCalculator) Option {
+	return func(s *service) { s.nutrition = c }
+}
+
+// NewService creates a recipe service backed by the given repositories.
+func NewService(repo Repository, users user.Repository, opts ...Option) Service {
+	s := &service{repo: repo, users: users}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *service) Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*Recipe, error) {
+	now := time.Now().UTC()
+	r := &Recipe{
+		ID:                uuid.New(),
+		UserID:            userID,
+		Title:             strings.TrimSpace(req.Title),
+		Description:       req.Description,
+		Ingredients:       slices.Concat(req.Ingredients, ParseIngredients(req.IngredientLines)),
+		Instructions:      sanitizeSteps(req.Instructions),
+		PrepTime:          req.PrepTime,
+		CookTime:          req.CookTime,
+		Servings:          req.Servings,
+		Category:          req.Category,
+		DietaryCategories: normalizeDiets(req.DietaryCategories),
+		Tags:              normalizeTags(req.Tags),
+		NutritionalInfo:   req.NutritionalInfo,
+		ImageURL:          req.ImageURL,
+		IsPublic:          req.IsPublic,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if err := validate(r); err != nil {
+		return nil, err
+	}
+	applyAllergens(r, req.Allergens)
+	if err := s.estimateNutrition(ctx, r); err != nil {
+		return nil, err
+	}
+	applyDiets(r, r.DietaryCategories)
+	if err := s.repo.Create(ctx, r); err != nil {
+		return nil, err
+	}
+	s.embed(ctx, r)
+	return r, nil
+}
+
+func (s *service) Import(ctx context.Context, userID uuid.UUID, document []byte) (*Recipe, []string, error) {
+	res, err := ParseSchemaOrg(document)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := s.Create(ctx, userID, res.Request)
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, res.Unmapped, nil
+}
+
+func (s *service) Get(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) (*Recipe, error) {
+	r, err := s.getVisible(ctx, viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.FlagAllergens(ctx, viewerID, []*Recipe{r}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *service) Convert(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, system units.System) (*Recipe, error) {
+	r, err := s.Get(ctx, viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if system == "" && viewerID != nil {
+		if system, err = s.viewerUnits(ctx, *viewerID); err != nil {
+			return nil, err
+		}
+	}
+	if system != "" {
+		ConvertRecipe(r, system)
+	}
+	return r, nil
+}
+
+func (s *service) Scale(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, servings int, system units.System) (*ScaledRecipe, error) {
+	r, err := s.Convert(ctx, viewerID, id, system)
+	if err != nil {
+		return nil, err
+	}
+	return ScaleRecipe(r, servings)
+}
+
+func (s *service) Update(ctx context.Context, userID, id uuid.UUID, req UpdateRequest) (*Recipe, error) {
+	if req.Version <= 0 {
+		return nil, apperrors.ErrPreconditionRequired
+	}
+	r, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, servings := r.Ingredients, r.Servings
+	declared, diets := r.declaredAllergens(), r.declaredDiets()
+	if req.Allergens != nil {
+		declared = *req.Allergens
+	}
+	if req.DietaryCategories != nil {
+		diets = *req.DietaryCategories
+	}
+	req.apply(r)
+	if err := validate(r); err != nil {
+		return nil, err
+	}
+	applyAllergens(r, declared)
+	switch {
+	case !reflect.DeepEqual(r.Ingredients, ingredients) || r.Servings != servings:
+		if err := s.estimateNutrition(ctx, r); err != nil {
+			return nil, err
+		}
+	case req.NutritionalInfo != nil:
+		// Nutrition entered by hand replaces the estimate.
+		r.NutritionEstimate = nil
+	}
+	applyDiets(r, diets)
+	r.Version = req.Version
+	r.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(ctx, r, 0); err != nil {
+		return nil, err
+	}
+	s.embed(ctx, r)
+	return r, nil
+}
+
+func (s *service) SetImage(ctx context.Context, userID, id uuid.UUID, url string) error {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return err
+	}
+	if url == "" {
+		return s.repo.UpdateImage(ctx, id, nil)
+	}
+	return s.repo.UpdateImage(ctx, id, &url)
+}
+
+func (s *service) Delete(ctx context.Context, userID, id uuid.UUID, version int) error {
+	if version <= 0 {
+		return apperrors.ErrPreconditionRequired
+	}
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.SoftDelete(ctx, id, version)
+}
+
+func (s *service) Restore(ctx context.Context, userID, id uuid.UUID) (*Recipe, error) {
+	r, err := s.repo.GetTrashed(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.UserID != userID {
+		return nil, apperrors.ErrRecipeNotFound
+	}
+	if err := s.repo.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *service) ListTrash(ctx context.Context, userID uuid.UUID) ([]*Recipe, error) {
+	return s.repo.ListTrash(ctx, userID)
+}
+
+func (s *service) ListByUser(ctx context.Context, userID uuid.UUID) ([]*Recipe, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) ListRevisions(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) ([]*Revision, error) {
+	if _, err := s.getVisible(ctx, viewerID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRevisions(ctx, id)
+}
+
+func (s *service) GetRevision(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, version int) (*Revision, error) {
+	if _, err := s.getVisible(ctx, viewerID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetRevision(ctx, id, version)
+}
+
+func (s *service) DiffRevisions(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, from, to int) (*Diff, error) {
+	if _, err := s.getVisible(ctx, viewerID, id); err != nil {
+		return nil, err
+	}
+	older, err := s.repo.GetRevision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+	newer, err := s.repo.GetRevision(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+	return DiffRecipes(older.Snapshot, newer.Snapshot), nil
+}
+
+// Revert copies the content of the given revision onto the recipe. The
+// recipe's visibility is kept so that a revert never publishes or hides it.
+func (s *service) Revert(ctx context.Context, userID, id uuid.UUID, version, expectedVersion int) (*Recipe, error) {
+	if expectedVersion <= 0 {
+		return nil, apperrors.ErrPreconditionRequired
+	}
+	r, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	rev, err := s.repo.GetRevision(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	old := rev.Snapshot
+	r.Title = old.Title
+	r.Description = old.Description
+	r.Ingredients = old.Ingredients
+	r.Instructions = old.Instructions
+	r.PrepTime = old.PrepTime
+	r.CookTime = old.CookTime
+	r.Servings = old.Servings
+	r.Category = old.Category
+	r.DietaryCategories = old.DietaryCategories
+	r.Tags = old.Tags
+	r.NutritionalInfo = old.NutritionalInfo
+	r.NutritionEstimate = old.NutritionEstimate
+	if err := validate(r); err != nil {
+		return nil, err
+	}
+	applyAllergens(r, old.declaredAllergens())
+	applyDiets(r, old.declaredDiets())
+	r.Version = expectedVersion
+	r.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(ctx, r, version); err != nil {
+		return nil, err
+	}
+	s.embed(ctx, r)
+	return r, nil
+}
+
+func (s *service) Fork(ctx context.Context, userID, id uuid.UUID) (*Recipe, error) {
+	src, err := s.getVisible(ctx, &userID, id)
+	if err != nil {
+		return nil, err
+	}
+	origin := &ForkOrigin{RecipeID: src.ID, Version: src.Version, Title: src.Title, AuthorID: src.UserID}
+	author, err := s.users.GetByID(ctx, src.UserID)
+	switch {
+	case err == nil:
+		origin.AuthorUsername = author.Username
+	case err != apperrors.ErrUserNotFound:
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	fork := *src
+	fork.ID = uuid.New()
+	fork.UserID = userID
+	fork.IsPublic = false
+	fork.Version = 0
+	fork.ForkedFrom = origin
+	fork.CreatedAt = now
+	fork.UpdatedAt = now
+	fork.DeletedAt = nil
+	fork.AllergenWarnings = nil
+	// The fork starts without reviews, and the allergens and diets it copies
+	// count as declared by its author.
+	fork.RatingAverage = 0
+	fork.RatingCount = 0
+	fork.UndeclaredAllergens = nil
+	fork.UndeclaredDiets = nil
+	// The image lives in the original's gallery, which goes away with it.
+	fork.ImageURL = nil
+	if err := s.repo.Create(ctx, &fork); err != nil {
+		return nil, err
+	}
+	s.embed(ctx, &fork)
+	return &fork, nil
+}
+
+func (s *service) ListForks(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) ([]*Recipe, error) {
+	if _, err := s.getVisible(ctx, viewerID, id); err != nil {
+		return nil, err
+	}
+	forks, err := s.repo.ListForks(ctx, id, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.FlagAllergens(ctx, viewerID, forks); err != nil {
+		return nil, err
+	}
+	return forks, nil
+}
+
+func (s *service) Ancestry(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) ([]*Ancestor, error) {
+	r, err := s.getVisible(ctx, viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := s.repo.ListAncestors(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*Recipe, len(ancestors))
+	for _, a := range ancestors {
+		byID[a.ID] = a
+	}
+
+	chain := []*Ancestor{}
+	for cur := r; cur.ForkedFrom != nil; {
+		step := &Ancestor{Origin: *cur.ForkedFrom}
+		chain = append(chain, step)
+		parent, ok := byID[cur.ForkedFrom.RecipeID]
+		if !ok {
+			break
+		}
+		if parent.DeletedAt == nil && visibleTo(parent, viewerID) {
+			step.Recipe = parent
+		}
+		delete(byID, parent.ID)
+		cur = parent
+	}
+	return chain, nil
+}
+
+// getVisible loads a recipe the viewer may read. Private recipes of other
+// users are reported as missing.
+func (s *service) getVisible(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) (*Recipe, error) {
+	r, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !visibleTo(r, viewerID) {
+		return nil, apperrors.ErrRecipeNotFound
+	}
+	return r, nil
+}
+
+// visibleTo reports whether the viewer, which may be nil, may read r.
+func visibleTo(r *Recipe, viewerID *uuid.UUID) bool {
+	return r.IsPublic || (viewerID != nil && *viewerID == r.UserID)
+}
+
+// getOwned loads a recipe the user is about to modify. Private recipes of
+// other users are reported as missing rather than forbidden.
+func (s *service) getOwned(ctx context.Context, userID, id uuid.UUID) (*Recipe, error) {
+	r, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.UserID != userID {
+		if !r.IsPublic {
+			return nil, apperrors.ErrRecipeNotFound
+		}
+		return nil, apperrors.ErrForbidden
+	}
+	return r, nil
+}
+
+func (s *service) Search(ctx context.Context, params SearchParams) (*SearchResult, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	params.Pagination = params.Pagination.Normalize()
+	params.Dietary = normalizeDiets(params.Dietary)
+	params.Exclude = normalizeLabels(params.Exclude)
+	params.Tags = normalizeTags(params.Tags)
+
+	switch params.Mode {
+	case "", ModeFullText:
+		params.Mode = ModeFullText
+	case ModeSemantic, ModeHybrid:
+		if params.SemanticWeight < 0 || params.SemanticWeight > 1 {
+			return nil, validator.InvalidField("semantic_weight", "semantic_weight must be between 0 and 1")
+		}
+		if err := s.resolveEmbedding(ctx, &params); err != nil {
+			return nil, err
+		}
+		params.Sort = SortRelevance
+		params.Order = "desc"
+	default:
+		return nil, validator.InvalidField("mode", "unsupported search mode")
+	}
+
+	if params.Sort == "" {
+		params.Sort = SortCreatedAt
+		if params.Query != "" {
+			params.Sort = SortRelevance
+		}
+	}
+	if !sortKeys[params.Sort] {
+		return nil, validator.InvalidField("sort", "unsupported sort field")
+	}
+	switch params.Order {
+	case "":
+		params.Order = "desc"
+		if params.Sort == SortTitle || params.Sort == SortPrepTime {
+			params.Order = "asc"
+		}
+	case "asc", "desc":
+	default:
+		return nil, validator.InvalidField("order", "order must be asc or desc")
+	}
+	if params.Favorites && params.ViewerID == nil {
+		return nil, apperrors.ErrUnauthorized
+	}
+
+	params.Allergies = nil
+	if params.Favorites {
+		// Like GetFavorites, favorites are flagged rather than hidden.
+		params.DisableAllergenFilter = true
+	}
+	if params.ViewerID != nil {
+		allergies, err := s.viewerAllergies(ctx, *params.ViewerID)
+		if err != nil {
+			return nil, err
+		}
+		params.Allergies = allergies
+	}
+
+	res, err := s.repo.Search(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range res.Recipes {
+		flagAllergens(hit.Recipe, params.Allergies)
+	}
+	return res, nil
+}
+
+func (s *service) ListTags(ctx context.Context, prefix string, limit int) ([]TagCount, error) {
+	return s.repo.ListTags(ctx, NormalizeTag(prefix), pagination.NormalizeLimit(limit))
+}
+
+func (s *service) SuggestTags(ctx context.Context, req SuggestRequest) ([]string, error) {
+	switch {
+	case req.Limit == 0:
+		req.Limit = DefaultSuggestions
+	case req.Limit < 0 || req.Limit > MaxSuggestions:
+		return nil, validator.InvalidField("limit", fmt.Sprintf("limit must be between 1 and %d", MaxSuggestions))
+	}
+	popular, err := s.repo.ListTags(ctx, "", suggestionTagsCount)
+	if err != nil {
+		return nil, err
+	}
+	return suggestTags(req, popular), nil
+}
+
+func (s *service) Generate(ctx context.Context, userID uuid.UUID, req GenerateRequest) (*Recipe, error) {
+	if s.generator == nil {
+		return nil, ErrGenerationUnavailable
+	}
+	r, embedding, err := s.generator.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	r.ID = uuid.New()
+	r.UserID = userID
+	r.CreatedAt = now
+	r.UpdatedAt = now
+	r.Instructions = sanitizeSteps(r.Instructions)
+	if err := validate(r); err != nil {
+		return nil, err
+	}
+	applyAllergens(r, r.Allergens)
+	if err := s.estimateNutrition(ctx, r); err != nil {
+		return nil, err
+	}
+	applyDiets(r, r.DietaryCategories)
+	if err := s.repo.Create(ctx, r); err != nil {
+		return nil, err
+	}
+	if len(embedding) > 0 {
+		// As in embed, the recipe is already saved, so failing here would
+		// only make clients retry and create duplicates.
+		if err := s.repo.SaveEmbedding(ctx, r.ID, embedding); err != nil {
+			logger.FromContext(ctx).Warnw("failed to store recipe embedding", "recipe_id", r.ID, "error", err)
+		}
+	} else {
+		s.embed(ctx, r)
+	}
+	g := &Generation{
+		ID:          uuid.New(),
+		UserID:      userID,
+		RecipeID:    r.ID,
+		Style:       req.Style,
+		Ingredients: req.Ingredients,
+		CreatedAt:   now,
+	}
+	if err := s.repo.RecordGeneration(ctx, g); err != nil {
+		// The history only informs recommendations, so the recipe is
+		// returned even when it cannot be recorded.
+		logger.FromContext(ctx).Warnw("failed to record recipe generation", "recipe_id", r.ID, "error", err)
+	}
+	return r, nil
+}
+
+func (s *service) AddFavorite(ctx context.Context, userID, recipeID uuid.UUID) error {
+	if _, err := s.getVisible(ctx, &userID, recipeID); err != nil {
+		return err
+	}
+	return s.repo.AddFavorite(ctx, userID, recipeID)
+}
+
+func (s *service) RemoveFavorite(ctx context.Context, userID, recipeID uuid.UUID) error {
+	return s.repo.RemoveFavorite(ctx, userID, recipeID)
+}
+
+// GetFavorites returns the user's favorites that are still visible to them.
+// Favorites are never hidden for allergies, but recipes conflicting with the
+// user's allergies carry allergen warnings.
+func (s *service) GetFavorites(ctx context.Context, userID uuid.UUID) ([]*Recipe, error) {
+	recipes, err := s.repo.GetUserFavorites(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.FlagAllergens(ctx, &userID, recipes); err != nil {
+		return nil, err
+	}
+	return recipes, nil
+}
+
+func (s *service) FlagAllergens(ctx context.Context, viewerID *uuid.UUID, recipes []*Recipe) error {
+	if viewerID == nil {
+		return nil
+	}
+	allergies, err := s.viewerAllergies(ctx, *viewerID)
+	if err != nil {
+		return err
+	}
+	for _, r := range recipes {
+		flagAllergens(r, allergies)
+	}
+	return nil
+}
+
+func (s *service) Diets(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID) ([]DietResult, error) {
+	r, err := s.getVisible(ctx, viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+	return ClassifyDiets(r), nil
+}
+
+func (s *service) Export(ctx context.Context, viewerID *uuid.UUID, id uuid.UUID, format string) (*Document, error) {
+	r, err := s.getVisible(ctx, viewerID, id)
+	if err != nil {
+		return nil, err
+	}
+	return Export(r, format)
+}
+
+func (s *service) ExportLibrary(ctx context.Context, userID uuid.UUID, format string) (*Document, error) {
+	if _, err := checkFormat(format); err != nil {
+		return nil, err
+	}
+	recipes, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return ExportArchive("recipes", recipes, format)
+}
+
+// relabelBatch is how many recipes Relabel loads at a time.
+const relabelBatch = 500
+
+func (s *service) Relabel(ctx context.Context) (int, error) {
+	changed := 0
+	after := uuid.Nil
+	for {
+		recipes, err := s.repo.ListAfter(ctx, after, relabelBatch)
+		if err != nil {
+			return changed, err
+		}
+		for _, r := range recipes {
+			allergens, undeclared := r.Allergens, r.UndeclaredAllergens
+			diets, undeclaredDiets := r.DietaryCategories, r.UndeclaredDiets
+			applyAllergens(r, r.declaredAllergens())
+			applyDiets(r, r.declaredDiets())
+			if slices.Equal(r.Allergens, allergens) && slices.Equal(r.UndeclaredAllergens, undeclared) &&
+				slices.Equal(r.DietaryCategories, diets) && slices.Equal(r.UndeclaredDiets, undeclaredDiets) {
+				continue
+			}
+			if err := s.repo.UpdateLabels(ctx, r); err != nil {
+				return changed, err
+			}
+			changed++
+		}
+		if len(recipes) < relabelBatch {
+			return changed, nil
+		}
+		after = recipes[len(recipes)-1].ID
+	}
+}
+
+// viewerAllergies returns the normalized allergies of the given user. Unknown
+// users are treated as having none.
+func (s *service) viewerAllergies(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	u, err := s.users.GetByID(ctx, userID)
+	if err == apperrors.ErrUserNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return normalizeAllergens(u.Allergies), nil
+}
+
+// viewerUnits returns the preferred unit system of the given user, or an
+// empty system when the user has none or is unknown.
+func (s *service) viewerUnits(ctx context.Context, userID uuid.UUID) (units.System, error) {
+	u, err := s.users.GetByID(ctx, userID)
+	if err == apperrors.ErrUserNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	system, _ := units.ParseSystem(u.UnitSystem)
+	return system, nil
+}
+
+// flagAllergens sets the recipe's allergen warnings to the declared or
+// detected allergens that appear in allergies.
+func flagAllergens(r *Recipe, allergies []string) {
+	r.AllergenWarnings = nil
+	for _, a := range normalizeAllergens(r.Allergens) {
+		for _, allergy := range allergies {
+			if a == allergy {
+				r.AllergenWarnings = append(r.AllergenWarnings, a)
+				break
+			}
+		}
+	}
+}
+
+// resolveEmbedding fills params.Embedding from the reference recipe, which
+// must be visible to the viewer, or, when none is given, by embedding the
+// text query.
+func (s *service) resolveEmbedding(ctx context.Context, params *SearchParams) error {
+	switch {
+	case params.SimilarTo != nil:
+		// The reference ranks the results, so it must be visible too.
+		if _, err := s.getVisible(ctx, params.ViewerID, *params.SimilarTo); err != nil {
+			return err
+		}
+		embedding, err := s.repo.GetEmbedding(ctx, *params.SimilarTo)
+		if err != nil {
+			return err
+		}
+		if len(embedding) == 0 {
+			return ErrEmbeddingNotFound
+		}
+		params.Embedding = embedding
+	case params.Query != "":
+		if s.embedder == nil {
+			return ErrSemanticSearchUnavailable
+		}
+		embedding, err := s.embedder.Embed(ctx, params.Query)
+		if err != nil {
+			return err
+		}
+		params.Embedding = embedding
+	default:
+		return validator.InvalidField("q", "q or similar_to is required for semantic search")
+	}
+	return nil
+}
+
+// embed stores an embedding for the recipe when an embedder is configured.
+// Failures are logged rather than returned so that an unavailable embedding
+// backend does not block saving recipes.
+func (s *service) embed(ctx context.Context, r *Recipe) {
+	if s.embedder == nil {
+		return
+	}
+	embedding, err := s.embedder.Embed(ctx, embeddingText(r))
+	if err == nil {
+		err = s.repo.SaveEmbedding(ctx, r.ID, embedding)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Warnw("failed to store recipe embedding", "recipe_id", r.ID, "error", err)
+	}
+}
+
+// embeddingText is the text representation of a recipe used for embeddings.
+func embeddingText(r *Recipe) string {
+	parts := []string{r.Title, r.Description, r.Category}
+	for _, ing := range r.Ingredients {
+		parts = append(parts, ing.Name)
+	}
+	parts = append(parts, strings.Join(r.Tags, " "))
+	return strings.Join(parts, "\n")
+}
+
+// validate checks the invariants of a recipe before it is stored.
+func validate(r *Recipe) error {
+	switch {
+	case r.Title == "":
+		return validator.InvalidField("title", "title is required")
+	case len(r.Title) > 255:
+		return validator.InvalidField("title", "title must be at most 255 characters")
+	case r.PrepTime < 0:
+		return validator.InvalidField("prep_time", "prep_time must not be negative")
+	case r.CookTime < 0:
+		return validator.InvalidField("cook_time", "cook_time must not be negative")
+	case r.Servings < 0:
+		return validator.InvalidField("servings", "servings must not be negative")
+	}
+	if err := validateSteps(r); err != nil {
+		return err
+	}
+	return validateTags(r.Tags)
+}
+
+// normalizeLabels lowercases, trims and de-duplicates category style labels
+// such as diets and allergens so they compare consistently.
+func normalizeLabels(labels []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, l := range labels {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || seen[l] {
+			continue
+		}
+		seen[l] = true
+		out = append(out, l)
+	}
+	return out
+}
@@ -0,0 +1,340 @@
+package recipe
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user"
+	"alchemorsel/backend/internal/domain/user/usertest"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// fakeRepository implements the Repository methods used by the tests. Calls to
+// any other method panic through the nil embedded interface.
+type fakeRepository struct {
+	Repository
+	recipes      []*Recipe
+	updated      *Recipe
+	revisions    []*Revision
+	revertedFrom int
+	ancestors    []*Recipe
+	forks        []*Recipe
+	searched     SearchParams
+	hits         []*SearchHit
+	favorites    []*Recipe
+	labeled      []*Recipe
+}
+
+func (f *fakeRepository) Search(ctx context.Context, params SearchParams) (*SearchResult, error) {
+	f.searched = params
+	return &SearchResult{Recipes: f.hits}, nil
+}
+
+func (f *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*Recipe, error) {
+	for _, r := range f.recipes {
+		if r.ID == id {
+			copy := *r
+			return &copy, nil
+		}
+	}
+	return nil, apperrors.ErrRecipeNotFound
+}
+
+func (f *fakeRepository) Create(ctx context.Context, r *Recipe) error {
+	r.Version = 1
+	f.recipes = append(f.recipes, r)
+	return nil
+}
+
+func (f *fakeRepository) ListAncestors(ctx context.Context, id uuid.UUID) ([]*Recipe, error) {
+	return f.ancestors, nil
+}
+
+func (f *fakeRepository) ListForks(ctx context.Context, id uuid.UUID, viewerID *uuid.UUID) ([]*Recipe, error) {
+	return f.forks, nil
+}
+
+func (f *fakeRepository) Update(ctx context.Context, r *Recipe, revertedFrom int) error {
+	f.updated = r
+	f.revertedFrom = revertedFrom
+	r.Version++
+	return nil
+}
+
+func (f *fakeRepository) GetRevision(ctx context.Context, recipeID uuid.UUID, version int) (*Revision, error) {
+	for _, rev := range f.revisions {
+		if rev.RecipeID == recipeID && rev.Version == version {
+			return rev, nil
+		}
+	}
+	return nil, ErrRevisionNotFound
+}
+
+func (f *fakeRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*Recipe, error) {
+	var out []*Recipe
+	for _, r := range f.recipes {
+		if r.UserID == userID {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeRepository) GetUserFavorites(ctx context.Context, userID uuid.UUID) ([]*Recipe, error) {
+	return f.favorites, nil
+}
+
+// ListAfter pages through the recipes in the order they were added rather
+// than by id, which is enough for walking all of them.
+func (f *fakeRepository) ListAfter(ctx context.Context, after uuid.UUID, limit int) ([]*Recipe, error) {
+	start := 0
+	for i, r := range f.recipes {
+		if r.ID == after {
+			start = i + 1
+		}
+	}
+	end := min(start+limit, len(f.recipes))
+	return f.recipes[start:end], nil
+}
+
+func (f *fakeRepository) UpdateImage(ctx context.Context, id uuid.UUID, url *string) error {
+	for _, r := range f.recipes {
+		if r.ID == id {
+			r.ImageURL = url
+			return nil
+		}
+	}
+	return apperrors.ErrRecipeNotFound
+}
+
+func (f *fakeRepository) UpdateLabels(ctx context.Context, r *Recipe) error {
+	f.labeled = append(f.labeled, r)
+	return nil
+}
+
+func TestSearchAppliesViewerAllergies(t *testing.T) {
+	viewer := uuid.New()
+	repo := &fakeRepository{hits: []*SearchHit{{Recipe: &Recipe{Allergens: []string{"Peanuts", "soy"}}}}}
+	users := usertest.NewUsers(&user.User{ID: viewer, Allergies: []string{" peanuts "}})
+	svc := NewService(repo, users)
+
+	res, err := svc.Search(context.Background(), SearchParams{ViewerID: &viewer, DisableAllergenFilter: true})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if !reflect.DeepEqual(repo.searched.Allergies, []string{"peanuts"}) {
+		t.Fatalf("expected normalized allergies to reach the repository, got %v", repo.searched.Allergies)
+	}
+	if got := res.Recipes[0].AllergenWarnings; !reflect.DeepEqual(got, []string{"peanuts"}) {
+		t.Fatalf("unexpected allergen warnings %v", got)
+	}
+
+	anonymous := NewService(&fakeRepository{}, users)
+	if _, err := anonymous.Search(context.Background(), SearchParams{Favorites: true}); err != apperrors.ErrUnauthorized {
+		t.Fatalf("expected unauthorized for anonymous favorites search, got %v", err)
+	}
+}
+
+func TestSearchSimilarToHiddenRecipe(t *testing.T) {
+	viewer := uuid.New()
+	private := &Recipe{ID: uuid.New(), UserID: uuid.New(), Title: "Secret"}
+	svc := NewService(&fakeRepository{recipes: []*Recipe{private}}, usertest.NewUsers())
+
+	_, err := svc.Search(context.Background(), SearchParams{Mode: ModeSemantic, SimilarTo: &private.ID, ViewerID: &viewer})
+	if err != apperrors.ErrRecipeNotFound {
+		t.Fatalf("expected another user's private recipe to be not found, got %v", err)
+	}
+}
+
+func TestGetFavoritesFlagsAllergens(t *testing.T) {
+	viewer := uuid.New()
+	repo := &fakeRepository{favorites: []*Recipe{
+		{Title: "Pad Thai", Allergens: []string{"peanuts"}},
+		{Title: "Rice"},
+	}}
+	users := usertest.NewUsers(&user.User{ID: viewer, Allergies: []string{"peanuts"}})
+
+	favs, err := NewService(repo, users).GetFavorites(context.Background(), viewer)
+	if err != nil {
+		t.Fatalf("get favorites: %v", err)
+	}
+	if len(favs) != 2 || len(favs[0].AllergenWarnings) != 1 || favs[1].AllergenWarnings != nil {
+		t.Fatalf("unexpected warnings: %+v", favs)
+	}
+}
+
+func TestListForksFlagsAllergens(t *testing.T) {
+	viewer := uuid.New()
+	src := &Recipe{ID: uuid.New(), UserID: uuid.New(), Title: "Satay", IsPublic: true}
+	repo := &fakeRepository{recipes: []*Recipe{src}, forks: []*Recipe{
+		{Title: "Peanut satay", Allergens: []string{"peanuts"}},
+		{Title: "Chicken satay"},
+	}}
+	users := usertest.NewUsers(&user.User{ID: viewer, Allergies: []string{"peanuts"}})
+
+	forks, err := NewService(repo, users).ListForks(context.Background(), &viewer, src.ID)
+	if err != nil {
+		t.Fatalf("list forks: %v", err)
+	}
+	if len(forks) != 2 || len(forks[0].AllergenWarnings) != 1 || forks[1].AllergenWarnings != nil {
+		t.Fatalf("unexpected warnings: %+v", forks)
+	}
+}
+
+func TestAddFavoriteRequiresVisibility(t *testing.T) {
+	viewer := uuid.New()
+	private := &Recipe{ID: uuid.New(), UserID: uuid.New(), Title: "Secret"}
+	svc := NewService(&fakeRepository{recipes: []*Recipe{private}}, usertest.NewUsers())
+
+	if err := svc.AddFavorite(context.Background(), viewer, private.ID); err != apperrors.ErrRecipeNotFound {
+		t.Fatalf("expected another user's private recipe to be not found, got %v", err)
+	}
+}
+
+func TestUpdateChecksOwnershipAndVersion(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	public := &Recipe{ID: uuid.New(), UserID: owner, Title: "Soup", IsPublic: true, Version: 3}
+	private := &Recipe{ID: uuid.New(), UserID: owner, Title: "Secret", Version: 1}
+	repo := &fakeRepository{recipes: []*Recipe{public, private}}
+	svc := NewService(repo, usertest.NewUsers())
+	ctx := context.Background()
+	title := "Better Soup"
+
+	if _, err := svc.Update(ctx, owner, public.ID, UpdateRequest{Title: &title}); err != apperrors.ErrPreconditionRequired {
+		t.Fatalf("expected precondition required without a version, got %v", err)
+	}
+	if err := svc.Delete(ctx, owner, public.ID, 0); err != apperrors.ErrPreconditionRequired {
+		t.Fatalf("expected precondition required to delete without a version, got %v", err)
+	}
+	if _, err := svc.Update(ctx, other, public.ID, UpdateRequest{Version: 3, Title: &title}); err != apperrors.ErrForbidden {
+		t.Fatalf("expected forbidden for non-owner, got %v", err)
+	}
+	if _, err := svc.Update(ctx, other, private.ID, UpdateRequest{Version: 1, Title: &title}); err != apperrors.ErrRecipeNotFound {
+		t.Fatalf("expected not found for another user's private recipe, got %v", err)
+	}
+	empty := " "
+	if _, err := svc.Update(ctx, owner, public.ID, UpdateRequest{Version: 3, Title: &empty}); err == nil {
+		t.Fatalf("expected validation error for blank title")
+	}
+
+	got, err := svc.Update(ctx, owner, public.ID, UpdateRequest{Version: 3, Title: &title})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got.Title != title || got.Description != public.Description || repo.updated.Version != 4 {
+		t.Fatalf("unexpected partial update result: %+v", got)
+	}
+}
+
+func TestSetImageLeavesVersion(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	r := &Recipe{ID: uuid.New(), UserID: owner, Title: "Soup", IsPublic: true, Version: 3}
+	repo := &fakeRepository{recipes: []*Recipe{r}}
+	svc := NewService(repo, usertest.NewUsers())
+	ctx := context.Background()
+
+	if err := svc.SetImage(ctx, other, r.ID, "/media/soup.jpg"); err != apperrors.ErrForbidden {
+		t.Fatalf("expected forbidden for non-owner, got %v", err)
+	}
+	if err := svc.SetImage(ctx, owner, r.ID, "/media/soup.jpg"); err != nil {
+		t.Fatalf("set image: %v", err)
+	}
+	if r.ImageURL == nil || *r.ImageURL != "/media/soup.jpg" || r.Version != 3 || repo.updated != nil {
+		t.Fatalf("expected only the image to change, got %+v", r)
+	}
+	if err := svc.SetImage(ctx, owner, r.ID, ""); err != nil || r.ImageURL != nil {
+		t.Fatalf("expected the image cleared, got %v, %v", r.ImageURL, err)
+	}
+}
+
+func TestRevertRestoresContentButKeepsVisibility(t *testing.T) {
+	owner := uuid.New()
+	cover, oldCover := "/media/stew.jpg", "/media/old-stew.jpg"
+	current := &Recipe{ID: uuid.New(), UserID: owner, Title: "Stew v2", Instructions: TextSteps([]string{"simmer"}), IsPublic: false, Version: 2, ImageURL: &cover}
+	old := &Recipe{ID: current.ID, UserID: owner, Title: "Stew", Instructions: TextSteps([]string{"boil"}), IsPublic: true, Version: 1, ImageURL: &oldCover}
+	repo := &fakeRepository{
+		recipes:   []*Recipe{current},
+		revisions: []*Revision{{RecipeID: current.ID, Version: 1, Snapshot: old}},
+	}
+	svc := NewService(repo, usertest.NewUsers())
+	ctx := context.Background()
+
+	if _, err := svc.Revert(ctx, owner, current.ID, 1, 0); err != apperrors.ErrPreconditionRequired {
+		t.Fatalf("expected precondition required, got %v", err)
+	}
+	if _, err := svc.Revert(ctx, owner, current.ID, 7, 2); err != ErrRevisionNotFound {
+		t.Fatalf("expected revision not found, got %v", err)
+	}
+
+	got, err := svc.Revert(ctx, owner, current.ID, 1, 2)
+	if err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+	if got.Title != "Stew" || got.Instructions[0].Text != "boil" || got.IsPublic || got.Version != 3 || *got.ImageURL != cover {
+		t.Fatalf("unexpected reverted recipe: %+v", got)
+	}
+	if repo.revertedFrom != 1 {
+		t.Fatalf("expected revision to record revert from 1, got %d", repo.revertedFrom)
+	}
+}
+
+func TestForkCopiesWithAttribution(t *testing.T) {
+	author, forker := uuid.New(), uuid.New()
+	image := "/media/recipes/ramen.jpg"
+	src := &Recipe{
+		ID: uuid.New(), UserID: author, Title: "Ramen", IsPublic: true, Version: 4, ImageURL: &image,
+		RatingAverage: 4.5, RatingCount: 12, Allergens: []string{"eggs"}, UndeclaredAllergens: []string{"eggs"},
+		DietaryCategories: []string{"vegetarian"}, UndeclaredDiets: []string{"vegetarian"},
+	}
+	secret := &Recipe{ID: uuid.New(), UserID: author, Title: "Secret"}
+	repo := &fakeRepository{recipes: []*Recipe{src, secret}}
+	users := usertest.NewUsers(&user.User{ID: author, Username: "chef"})
+	svc := NewService(repo, users)
+	ctx := context.Background()
+
+	if _, err := svc.Fork(ctx, forker, secret.ID); err != apperrors.ErrRecipeNotFound {
+		t.Fatalf("expected private recipe to be unforkable, got %v", err)
+	}
+	fork, err := svc.Fork(ctx, forker, src.ID)
+	if err != nil {
+		t.Fatalf("fork: %v", err)
+	}
+	if fork.ID == src.ID || fork.UserID != forker || fork.IsPublic || fork.Version != 1 || fork.Title != "Ramen" {
+		t.Fatalf("unexpected fork %+v", fork)
+	}
+	if fork.RatingCount != 0 || fork.RatingAverage != 0 || fork.ImageURL != nil || fork.UndeclaredAllergens != nil ||
+		fork.UndeclaredDiets != nil || !reflect.DeepEqual(fork.Allergens, []string{"eggs"}) ||
+		!reflect.DeepEqual(fork.DietaryCategories, []string{"vegetarian"}) {
+		t.Fatalf("fork kept the original's ratings, image or label warnings: %+v", fork)
+	}
+	want := ForkOrigin{RecipeID: src.ID, Version: 4, Title: "Ramen", AuthorID: author, AuthorUsername: "chef"}
+	if fork.ForkedFrom == nil || *fork.ForkedFrom != want {
+		t.Fatalf("forked_from = %+v, want %+v", fork.ForkedFrom, want)
+	}
+}
+
+func TestAncestryHidesUnavailableAncestors(t *testing.T) {
+	viewer, other := uuid.New(), uuid.New()
+	root := &Recipe{ID: uuid.New(), UserID: other, Title: "Root", IsPublic: true}
+	middle := &Recipe{ID: uuid.New(), UserID: other, Title: "Middle", ForkedFrom: &ForkOrigin{RecipeID: root.ID, Title: "Root"}}
+	leaf := &Recipe{ID: uuid.New(), UserID: viewer, Title: "Leaf", ForkedFrom: &ForkOrigin{RecipeID: middle.ID, Title: "Middle"}}
+	repo := &fakeRepository{recipes: []*Recipe{leaf}, ancestors: []*Recipe{middle, root}}
+	svc := NewService(repo, usertest.NewUsers())
+
+	chain, err := svc.Ancestry(context.Background(), &viewer, leaf.ID)
+	if err != nil {
+		t.Fatalf("ancestry: %v", err)
+	}
+	if len(chain) != 2 {
+		t.Fatalf("expected 2 ancestors, got %d", len(chain))
+	}
+	if chain[0].Origin.Title != "Middle" || chain[0].Recipe != nil {
+		t.Fatalf("private ancestor should only carry attribution: %+v", chain[0])
+	}
+	if chain[1].Origin.Title != "Root" || chain[1].Recipe == nil || chain[1].Recipe.ID != root.ID {
+		t.Fatalf("unexpected root ancestor %+v", chain[1])
+	}
+}
@@ -0,0 +1,202 @@
+package recipe
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"alchemorsel/backend/internal/pkg/units"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// Temperature units of steps.
+const (
+	Celsius    = "C"
+	Fahrenheit = "F"
+)
+
+// Limits on steps.
+const (
+	MaxSectionLength = 100
+	MaxStepLength    = 2000
+)
+
+// Step is one instruction of a recipe.
+type Step struct {
+	// Section names the part of the recipe, such as "For the sauce", that
+	// starts with this step and runs until the next step with a section.
+	Section string `json:"section,omitempty"`
+	Text    string `json:"text"`
+	// Duration is how long the step takes in minutes, like PrepTime and
+	// CookTime.
+	Duration int `json:"duration,omitempty"`
+	// Passive marks steps that need no attention, such as resting dough or
+	// baking.
+	Passive     bool         `json:"passive,omitempty"`
+	Temperature *Temperature `json:"temperature,omitempty"`
+	// Ingredients lists the positions in the recipe's ingredients, counted
+	// from 0, of the ingredients the step uses.
+	Ingredients []int `json:"ingredients,omitempty"`
+}
+
+// Temperature is an oven or cooking temperature in Celsius or Fahrenheit.
+type Temperature struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
+}
+
+// UnmarshalJSON reads a step object or a plain string of text, the form
+// instructions had before steps were structured. Older revisions and
+// clients still send strings.
+func (s *Step) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*s = Step{Text: text}
+		return nil
+	}
+	type plain Step
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// TextSteps makes a step of each line of text.
+func TextSteps(lines []string) []Step {
+	steps := make([]Step, len(lines))
+	for i, line := range lines {
+		steps[i] = Step{Text: line}
+	}
+	return steps
+}
+
+// stepLines formats each step with FormatStep, for formats whose
+// instructions are plain text. Sections are left out.
+func stepLines(steps []Step) []string {
+	lines := make([]string, len(steps))
+	for i, s := range steps {
+		lines[i] = FormatStep(s)
+	}
+	return lines
+}
+
+// equal reports whether two steps are the same in every field.
+func (s Step) equal(o Step) bool {
+	return s.Section == o.Section && s.Text == o.Text && s.Duration == o.Duration && s.Passive == o.Passive &&
+		slices.Equal(s.Ingredients, o.Ingredients) &&
+		(s.Temperature == o.Temperature || s.Temperature != nil && o.Temperature != nil && *s.Temperature == *o.Temperature)
+}
+
+// unit returns the units package unit of a temperature.
+func (t Temperature) unit() units.Unit {
+	if t.Unit == Fahrenheit {
+		return units.Fahrenheit
+	}
+	return units.Celsius
+}
+
+// String formats a temperature as recipes write it, such as "180 °C".
+func (t Temperature) String() string {
+	return fmt.Sprintf("%s %s", units.FormatAmount(t.Value), t.unit().Symbol)
+}
+
+// FormatStep writes a step's text followed by its duration and temperature,
+// such as "Bake until golden. (25 min, 180 °C)".
+func FormatStep(s Step) string {
+	var notes []string
+	if s.Duration > 0 {
+		d := formatMinutes(s.Duration)
+		if s.Passive {
+			d += " unattended"
+		}
+		notes = append(notes, d)
+	}
+	if s.Temperature != nil {
+		notes = append(notes, s.Temperature.String())
+	}
+	if len(notes) == 0 {
+		return s.Text
+	}
+	return fmt.Sprintf("%s (%s)", s.Text, strings.Join(notes, ", "))
+}
+
+// StepTimes adds up the durations of the steps, split into the time that
+// needs attention and the time that does not.
+func StepTimes(steps []Step) (active, passive int) {
+	for _, s := range steps {
+		if s.Passive {
+			passive += s.Duration
+		} else {
+			active += s.Duration
+		}
+	}
+	return active, passive
+}
+
+// convertStep expresses a step's temperatures in the given unit system. The
+// temperature in the text keeps the original in parentheses, as
+// units.ConvertTemperatures writes it.
+func convertStep(s Step, system units.System) Step {
+	s.Text = units.ConvertTemperatures(s.Text, system)
+	if t := s.Temperature; t != nil {
+		from, to := t.unit(), system.Temperature()
+		if v, ok := units.Convert(t.Value, from, to); ok && from != to {
+			unit := Celsius
+			if to == units.Fahrenheit {
+				unit = Fahrenheit
+			}
+			s.Temperature = &Temperature{Value: units.RoundTemperature(v, to), Unit: unit}
+		}
+	}
+	return s
+}
+
+// sanitizeSteps cleans the text of each step as validator.SanitizeText does
+// and normalizes temperature units.
+func sanitizeSteps(steps []Step) []Step {
+	out := make([]Step, len(steps))
+	for i, s := range steps {
+		s.Section = validator.SanitizeText(s.Section)
+		s.Text = validator.SanitizeText(s.Text)
+		if s.Temperature != nil {
+			t := *s.Temperature
+			t.Unit = strings.ToUpper(strings.TrimSpace(t.Unit))
+			s.Temperature = &t
+		}
+		out[i] = s
+	}
+	return out
+}
+
+// validateSteps checks each step of a recipe and that the recipe's prep and
+// cook times leave room for the steps' durations.
+func validateSteps(r *Recipe) error {
+	for i, s := range r.Instructions {
+		if n := len([]rune(s.Text)); n == 0 || n > MaxStepLength {
+			return validator.InvalidField("instructions", fmt.Sprintf("step %d must have between 1 and %d characters of text", i+1, MaxStepLength))
+		}
+		if len([]rune(s.Section)) > MaxSectionLength {
+			return validator.InvalidField("instructions", fmt.Sprintf("the section of step %d must be at most %d characters", i+1, MaxSectionLength))
+		}
+		if s.Duration < 0 {
+			return validator.InvalidField("instructions", fmt.Sprintf("the duration of step %d must not be negative", i+1))
+		}
+		if t := s.Temperature; t != nil {
+			if t.Unit != Celsius && t.Unit != Fahrenheit {
+				return validator.InvalidField("instructions", fmt.Sprintf("the temperature unit of step %d must be C or F", i+1))
+			}
+			if c, _ := units.Convert(t.Value, t.unit(), units.Celsius); c < -50 || c > 600 {
+				return validator.InvalidField("instructions", fmt.Sprintf("the temperature of step %d must be between -50 °C and 600 °C", i+1))
+			}
+		}
+		for _, k := range s.Ingredients {
+			if k < 0 || k >= len(r.Ingredients) {
+				return validator.InvalidField("instructions", fmt.Sprintf("step %d refers to ingredient %d, which does not exist", i+1, k))
+			}
+		}
+	}
+
+	active, passive := StepTimes(r.Instructions)
+	if total := r.PrepTime + r.CookTime; total > 0 && active+passive > total {
+		return validator.InvalidField("cook_time", fmt.Sprintf("prep_time and cook_time add up to %d minutes but the steps take %d", total, active+passive))
+	}
+	return nil
+}
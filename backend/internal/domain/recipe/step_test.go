@@ -0,0 +1,105 @@
+package recipe
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user/usertest"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+func TestStepReadsPlainStrings(t *testing.T) {
+	var r Recipe
+	data := `{"instructions": ["Mix.", {"section": "To bake", "text": "Bake.", "duration": 30, "passive": true,
+		"temperature": {"value": 180, "unit": "C"}, "ingredients": [0, 2]}]}`
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatal(err)
+	}
+	want := []Step{
+		{Text: "Mix."},
+		{Section: "To bake", Text: "Bake.", Duration: 30, Passive: true,
+			Temperature: &Temperature{Value: 180, Unit: Celsius}, Ingredients: []int{0, 2}},
+	}
+	if !reflect.DeepEqual(r.Instructions, want) {
+		t.Fatalf("instructions = %+v, want %+v", r.Instructions, want)
+	}
+}
+
+func TestFormatStep(t *testing.T) {
+	tests := []struct {
+		step Step
+		want string
+	}{
+		{Step{Text: "Mix."}, "Mix."},
+		{Step{Text: "Knead.", Duration: 10}, "Knead. (10 min)"},
+		{Step{Text: "Rest.", Duration: 90, Passive: true}, "Rest. (1 h 30 min unattended)"},
+		{Step{Text: "Bake.", Temperature: &Temperature{Value: 425, Unit: Fahrenheit}}, "Bake. (425 °F)"},
+	}
+	for _, tt := range tests {
+		if got := FormatStep(tt.step); got != tt.want {
+			t.Errorf("FormatStep(%+v) = %q, want %q", tt.step, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSteps(t *testing.T) {
+	ingredients := []Ingredient{{Name: "flour"}, {Name: "water"}}
+	tests := []struct {
+		name     string
+		steps    []Step
+		prep     int
+		cook     int
+		badField string
+	}{
+		{"plain text", TextSteps([]string{"Mix.", "Bake."}), 0, 0, ""},
+		{"durations within the times", []Step{{Text: "Mix.", Duration: 10}, {Text: "Bake.", Duration: 30, Passive: true}}, 10, 30, ""},
+		{"durations without times", []Step{{Text: "Bake.", Duration: 30}}, 0, 0, ""},
+		{"durations over the times", []Step{{Text: "Mix.", Duration: 10}, {Text: "Bake.", Duration: 40}}, 10, 30, "cook_time"},
+		{"empty text", []Step{{Section: "Sauce", Text: ""}}, 0, 0, "instructions"},
+		{"negative duration", []Step{{Text: "Mix.", Duration: -1}}, 0, 0, "instructions"},
+		{"unknown unit", []Step{{Text: "Bake.", Temperature: &Temperature{Value: 180, Unit: "K"}}}, 0, 0, "instructions"},
+		{"implausible temperature", []Step{{Text: "Bake.", Temperature: &Temperature{Value: 1800, Unit: Fahrenheit}}}, 0, 0, "instructions"},
+		{"unknown ingredient", []Step{{Text: "Mix.", Ingredients: []int{0, 2}}}, 0, 0, "instructions"},
+	}
+	for _, tt := range tests {
+		r := &Recipe{Title: "Bread", Ingredients: ingredients, Instructions: tt.steps, PrepTime: tt.prep, CookTime: tt.cook}
+		err := validate(r)
+		if tt.badField == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		if appErr, ok := err.(*apperrors.AppError); !ok || appErr.Details["field"] != tt.badField {
+			t.Errorf("%s: error = %v, want an invalid %s", tt.name, err, tt.badField)
+		}
+	}
+}
+
+func TestSanitizeSteps(t *testing.T) {
+	got := sanitizeSteps([]Step{{Section: " <b>Sauce</b> ", Text: " Stir. ", Temperature: &Temperature{Value: 90, Unit: " c"}}})
+	if got[0].Section != "Sauce" || got[0].Text != "Stir." || got[0].Temperature.Unit != Celsius {
+		t.Fatalf("unexpected step %+v", got[0])
+	}
+}
+
+type stubGenerator struct{ recipe *Recipe }
+
+func (g stubGenerator) Generate(ctx context.Context, req GenerateRequest) (*Recipe, []float64, error) {
+	return g.recipe, nil, nil
+}
+
+func TestGenerateValidatesSteps(t *testing.T) {
+	generated := &Recipe{Title: "Bread", CookTime: 20, Instructions: []Step{{Text: " Bake. ", Duration: 45}}}
+	repo := &fakeRepository{}
+	svc := NewService(repo, usertest.NewUsers(), WithGenerator(stubGenerator{generated}))
+
+	_, err := svc.Generate(context.Background(), uuid.New(), GenerateRequest{})
+	if appErr, ok := err.(*apperrors.AppError); !ok || appErr.Details["field"] != "cook_time" || len(repo.recipes) != 0 {
+		t.Fatalf("expected an invalid cook_time and nothing saved, got %v", err)
+	}
+}
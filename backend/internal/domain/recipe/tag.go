@@ -0,0 +1,202 @@
+package recipe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// Limits on the tags of a single recipe.
+const (
+	MaxTags      = 10
+	MaxTagLength = 32
+)
+
+// TagCount is a tag with the number of public recipes carrying it.
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// SuggestRequest describes a recipe, possibly unsaved, to suggest tags for.
+// Tags already present in Existing are not suggested again.
+type SuggestRequest struct {
+	Title       string
+	Ingredients []Ingredient
+	Existing    []string
+	Limit       int
+}
+
+// Bounds on SuggestRequest.Limit and the number of popular tags considered
+// when suggesting.
+const (
+	DefaultSuggestions  = 5
+	MaxSuggestions      = 20
+	suggestionTagsCount = 200
+)
+
+// NormalizeTag converts free-form text to the canonical tag form: lowercase
+// letters and digits separated by single hyphens, so that "Kid Friendly",
+// "kid_friendly" and "kid-friendly" are the same tag.
+func NormalizeTag(s string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+		case r == '-' || r == '_' || unicode.IsSpace(r):
+			pendingHyphen = true
+		}
+	}
+	return b.String()
+}
+
+// normalizeTags normalizes and de-duplicates tags, dropping empty ones.
+func normalizeTags(tags []string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, t := range tags {
+		t = NormalizeTag(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+func validateTags(tags []string) error {
+	if len(tags) > MaxTags {
+		return validator.InvalidField("tags", fmt.Sprintf("a recipe can have at most %d tags", MaxTags))
+	}
+	for _, t := range tags {
+		if len([]rune(t)) > MaxTagLength {
+			return validator.InvalidField("tags", fmt.Sprintf("tags must be at most %d characters", MaxTagLength))
+		}
+	}
+	return nil
+}
+
+// tagKeywords maps common tags to words and phrases in a recipe's title or
+// ingredients that suggest them. Keywords are singular; plurals also match.
+var tagKeywords = map[string][]string{
+	"baking":      {"bake", "baked", "cake", "cookie", "muffin", "bread", "brownie", "scone", "yeast", "pastry", "loaf"},
+	"dessert":     {"cake", "cookie", "brownie", "pudding", "cheesecake", "tart", "sorbet", "ice cream", "mousse", "custard"},
+	"breakfast":   {"breakfast", "pancake", "waffle", "omelet", "omelette", "granola", "oatmeal", "porridge", "frittata"},
+	"grilling":    {"grill", "grilled", "bbq", "barbecue", "skewer", "kebab"},
+	"slow-cooker": {"slow cooker", "crockpot", "crock pot"},
+	"one-pot":     {"one pot", "one pan", "sheet pan"},
+	"soup":        {"soup", "broth", "bisque", "chowder", "gazpacho"},
+	"stew":        {"stew", "casserole", "braise", "braised"},
+	"salad":       {"salad", "slaw"},
+	"pasta":       {"pasta", "spaghetti", "penne", "linguine", "fettuccine", "lasagna", "macaroni", "rigatoni", "ravioli", "gnocchi"},
+	"noodles":     {"noodle", "ramen", "udon", "soba"},
+	"curry":       {"curry", "garam masala", "tikka", "korma", "vindaloo"},
+	"seafood":     {"shrimp", "prawn", "salmon", "tuna", "cod", "crab", "lobster", "mussel", "clam", "scallop", "fish"},
+	"chicken":     {"chicken"},
+	"beef":        {"beef", "steak", "brisket"},
+	"pork":        {"pork", "bacon", "ham", "chorizo", "sausage"},
+	"spicy":       {"chili", "chilli", "jalapeno", "jalapeño", "cayenne", "sriracha", "habanero", "hot sauce", "chipotle"},
+	"sandwich":    {"sandwich", "burger", "panini", "wrap", "sub"},
+	"drinks":      {"smoothie", "cocktail", "lemonade", "latte", "punch"},
+}
+
+// suggestTags scores candidate tags for a recipe. A keyword found in the
+// title counts twice as much as one found in an ingredient. Popular tags
+// whose words all appear in the recipe text are also suggested, with ties
+// broken by popularity and then by name.
+func suggestTags(req SuggestRequest, popular []TagCount) []string {
+	title := textWords(req.Title)
+	// Each ingredient is padded separately so that phrases cannot span two
+	// ingredients.
+	var ingredientText string
+	for _, ing := range req.Ingredients {
+		ingredientText += textWords(ing.Name)
+	}
+
+	score := map[string]float64{}
+	for tag, keywords := range tagKeywords {
+		for _, kw := range keywords {
+			if containsWords(title, kw) {
+				score[tag] += 2
+			}
+			if containsWords(ingredientText, kw) {
+				score[tag]++
+			}
+		}
+	}
+	counts := map[string]int{}
+	for _, tc := range popular {
+		counts[tc.Name] = tc.Count
+		phrase := strings.ReplaceAll(tc.Name, "-", " ")
+		if containsWords(title, phrase) {
+			score[tc.Name] += 2
+		} else if containsWords(ingredientText, phrase) {
+			score[tc.Name]++
+		}
+	}
+
+	existing := map[string]bool{}
+	for _, t := range req.Existing {
+		existing[NormalizeTag(t)] = true
+	}
+	tags := []string{}
+	for tag, s := range score {
+		if s > 0 && !existing[tag] {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		a, b := tags[i], tags[j]
+		if score[a] != score[b] {
+			return score[a] > score[b]
+		}
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return a < b
+	})
+	if len(tags) > req.Limit {
+		tags = tags[:req.Limit]
+	}
+	return tags
+}
+
+// textWords lowercases s and splits it into words, dropping a plural "s" or
+// "es" from words longer than three letters. The result is padded with
+// spaces so that whole words and phrases can be found with strings.Contains.
+func textWords(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = singular(w)
+	}
+	return " " + strings.Join(words, " ") + " "
+}
+
+// containsWords reports whether the padded word text contains the phrase as
+// whole words.
+func containsWords(text, phrase string) bool {
+	return strings.Contains(text, textWords(phrase))
+}
+
+func singular(w string) string {
+	n := len([]rune(w))
+	switch {
+	case n > 4 && (strings.HasSuffix(w, "ches") || strings.HasSuffix(w, "shes") || strings.HasSuffix(w, "oes")):
+		return strings.TrimSuffix(w, "es")
+	case n > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		return strings.TrimSuffix(w, "s")
+	}
+	return w
+}
@@ -0,0 +1,120 @@
+package recipe
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user/usertest"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"one-pot", "one-pot"},
+		{"  Kid Friendly ", "kid-friendly"},
+		{"kid_friendly", "kid-friendly"},
+		{"--Grilling--", "grilling"},
+		{"30 minute   meals", "30-minute-meals"},
+		{"Crème brûlée!", "crème-brûlée"},
+		{"a & b", "a-b"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeTag(tt.in); got != tt.want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     SuggestRequest
+		popular []TagCount
+		want    []string
+	}{
+		{
+			name: "title keywords outrank ingredient keywords",
+			req: SuggestRequest{
+				Title:       "Grilled Chicken Skewers",
+				Ingredients: []Ingredient{{Name: "chicken thighs"}, {Name: "jalapeños"}},
+				Limit:       5,
+			},
+			want: []string{"grilling", "chicken", "spicy"},
+		},
+		{
+			name: "existing tags are skipped",
+			req: SuggestRequest{
+				Title:    "Chocolate Chip Cookies",
+				Existing: []string{"Baking"},
+				Limit:    5,
+			},
+			want: []string{"dessert"},
+		},
+		{
+			name: "phrases do not span ingredients",
+			req: SuggestRequest{
+				Title:       "Tacos",
+				Ingredients: []Ingredient{{Name: "hot"}, {Name: "sauce"}},
+				Limit:       5,
+			},
+			want: []string{},
+		},
+		{
+			name: "popular tags match as words",
+			req: SuggestRequest{
+				Title:       "Kid Friendly Mac and Cheese",
+				Ingredients: []Ingredient{{Name: "macaroni"}},
+				Limit:       5,
+			},
+			popular: []TagCount{{Name: "kid-friendly", Count: 40}, {Name: "cheese", Count: 3}, {Name: "kid", Count: 1}},
+			want:    []string{"kid-friendly", "cheese", "kid", "pasta"},
+		},
+		{
+			name: "limit",
+			req: SuggestRequest{
+				Title: "Beef and Pork Chili Soup",
+				Limit: 2,
+			},
+			want: []string{"beef", "pork"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suggestTags(tt.req, tt.popular)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("suggestTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateNormalizesAndLimitsTags(t *testing.T) {
+	svc := NewService(&fakeRepository{}, usertest.NewUsers())
+	ctx := context.Background()
+
+	r, err := svc.Create(ctx, uuid.New(), CreateRequest{Title: "Stew", Tags: []string{"One Pot", "one-pot", " Winter "}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !reflect.DeepEqual(r.Tags, []string{"one-pot", "winter"}) {
+		t.Fatalf("unexpected tags %v", r.Tags)
+	}
+
+	tooMany := make([]string, MaxTags+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("x", i+1)
+	}
+	if _, err := svc.Create(ctx, uuid.New(), CreateRequest{Title: "Stew", Tags: tooMany}); err == nil {
+		t.Fatalf("expected error for %d tags", len(tooMany))
+	}
+	long := strings.Repeat("y", MaxTagLength+1)
+	if _, err := svc.Create(ctx, uuid.New(), CreateRequest{Title: "Stew", Tags: []string{long}}); err == nil {
+		t.Fatalf("expected error for a long tag")
+	}
+}
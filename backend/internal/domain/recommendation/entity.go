@@ -0,0 +1,57 @@
+package recommendation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+// Kinds of signals.
+const (
+	SignalFavorite  = "favorite"
+	SignalRating    = "rating"
+	SignalGenerated = "generated"
+)
+
+// Signal is something a user did with a recipe that hints at their taste:
+// saving it as a favorite, rating it or generating it. Rating holds the
+// stars of rating signals.
+type Signal struct {
+	RecipeID uuid.UUID
+	Kind     string
+	Rating   int
+	At       time.Time
+}
+
+// Entry is a cached recommendation. BecauseOf is the recipe the
+// recommendation was found through, if any.
+type Entry struct {
+	RecipeID    uuid.UUID  `json:"recipe_id"`
+	Score       float64    `json:"score"`
+	Explanation string     `json:"explanation"`
+	BecauseOf   *uuid.UUID `json:"because_of,omitempty"`
+}
+
+// Cache holds a user's latest recommendations, best first.
+type Cache struct {
+	UserID     uuid.UUID
+	Entries    []Entry
+	ComputedAt time.Time
+}
+
+// Recommendation is a recipe recommended to a user, with an explanation
+// such as "Because you liked Pad Thai".
+type Recommendation struct {
+	*recipe.Recipe
+	Score       float64    `json:"score"`
+	Explanation string     `json:"explanation"`
+	BecauseOf   *uuid.UUID `json:"because_of,omitempty"`
+}
+
+// Feed is a user's "For you" page.
+type Feed struct {
+	Recipes    []*Recommendation `json:"recipes"`
+	ComputedAt time.Time         `json:"computed_at"`
+}
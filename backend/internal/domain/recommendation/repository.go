@@ -0,0 +1,27 @@
+package recommendation
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Repository defines persistence operations for recommendations.
+type Repository interface {
+	// Signals returns the user's latest signals on live recipes, newest
+	// first.
+	Signals(ctx context.Context, userID uuid.UUID, limit int) ([]Signal, error)
+	// Get returns nil when nothing has been computed for the user yet.
+	Get(ctx context.Context, userID uuid.UUID) (*Cache, error)
+	// Save replaces the user's cached recommendations, which are no longer
+	// stale or failed.
+	Save(ctx context.Context, c *Cache) error
+	// ListStale returns the users whose cached recommendations were
+	// computed before the given time or before their latest signal, or lost
+	// a signal since, least recently computed first. Users whose last refresh
+	// failed after retryBefore are left out.
+	ListStale(ctx context.Context, before, retryBefore time.Time, limit int) ([]uuid.UUID, error)
+	// MarkFailed records that refreshing the user's recommendations failed.
+	MarkFailed(ctx context.Context, userID uuid.UUID, at time.Time) error
+}
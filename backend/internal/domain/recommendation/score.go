@@ -0,0 +1,209 @@
+package recommendation
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+// Weights of the signals. A rating of three stars is neutral, and lower
+// ratings push similar recipes down.
+const (
+	favoriteWeight  = 1.0
+	generatedWeight = 0.6
+)
+
+// signalHalfLife is how long it takes a signal to lose half its weight, so
+// that recommendations follow changing tastes.
+const signalHalfLife = 90 * 24 * time.Hour
+
+// popularityWeight scales the boost well rated recipes get, and
+// popularityRatings is how many ratings a recipe needs for the full boost.
+const (
+	popularityWeight  = 0.2
+	popularityRatings = 5
+)
+
+// diversityWeight trades relevance for variety when picking
+// recommendations: each pick is penalized by its overlap with the closest
+// recipe already picked.
+const diversityWeight = 0.35
+
+// seed is a recipe the user interacted with, weighted by how much it says
+// about their taste. Negative weights mark recipes they disliked.
+type seed struct {
+	recipeID uuid.UUID
+	weight   float64
+	kind     string
+}
+
+// weighSignals combines the signals on each recipe into seeds, strongest
+// first. The kind of a seed is that of its strongest positive signal.
+func weighSignals(signals []Signal, now time.Time) []seed {
+	byRecipe := map[uuid.UUID]*seed{}
+	strongest := map[uuid.UUID]float64{}
+	var order []uuid.UUID
+	for _, sig := range signals {
+		var w float64
+		switch sig.Kind {
+		case SignalFavorite:
+			w = favoriteWeight
+		case SignalRating:
+			w = float64(sig.Rating-3) / 2
+		case SignalGenerated:
+			w = generatedWeight
+		}
+		w *= math.Pow(0.5, float64(now.Sub(sig.At))/float64(signalHalfLife))
+		sd, ok := byRecipe[sig.RecipeID]
+		if !ok {
+			sd = &seed{recipeID: sig.RecipeID}
+			byRecipe[sig.RecipeID] = sd
+			order = append(order, sig.RecipeID)
+		}
+		sd.weight += w
+		if w > strongest[sig.RecipeID] {
+			strongest[sig.RecipeID] = w
+			sd.kind = sig.Kind
+		}
+	}
+	seeds := make([]seed, 0, len(order))
+	for _, id := range order {
+		if sd := byRecipe[id]; sd.weight != 0 {
+			seeds = append(seeds, *sd)
+		}
+	}
+	slices.SortStableFunc(seeds, func(a, b seed) int {
+		return cmp.Compare(math.Abs(b.weight), math.Abs(a.weight))
+	})
+	return seeds
+}
+
+// candidate is a recipe that may be recommended. Because is the seed recipe
+// that contributed most to its score.
+type candidate struct {
+	recipe  *recipe.Recipe
+	score   float64
+	best    float64
+	because *recipe.Recipe
+	kind    string
+}
+
+// add scores the candidate by its similarity to a seed recipe.
+func (c *candidate) add(sd seed, source *recipe.Recipe, similarity float64) {
+	contribution := sd.weight * similarity
+	c.score += contribution
+	if contribution > c.best {
+		c.best, c.because, c.kind = contribution, source, sd.kind
+	}
+}
+
+// explanation tells the user why the candidate was recommended.
+func (c *candidate) explanation() string {
+	switch {
+	case c.because == nil:
+		return "Highly rated by other cooks"
+	case c.kind == SignalGenerated:
+		return "Because you generated " + c.because.Title
+	}
+	return "Because you liked " + c.because.Title
+}
+
+func (c *candidate) entry() Entry {
+	e := Entry{RecipeID: c.recipe.ID, Score: math.Round(c.score*1000) / 1000, Explanation: c.explanation()}
+	if c.because != nil {
+		id := c.because.ID
+		e.BecauseOf = &id
+	}
+	return e
+}
+
+// popularity is the boost a recipe gets from its ratings, trusted more as
+// ratings accumulate.
+func popularity(r *recipe.Recipe) float64 {
+	trust := math.Min(1, float64(r.RatingCount)/popularityRatings)
+	return popularityWeight * r.RatingAverage / 5 * trust
+}
+
+// diversify picks up to n candidates, best first, penalizing each by its
+// overlap with the picks so far so that the results are not all variations
+// of one dish.
+func diversify(cands []*candidate, n int) []*candidate {
+	top := 0.0
+	features := make([]map[string]bool, len(cands))
+	for i, c := range cands {
+		top = math.Max(top, c.score)
+		features[i] = recipeFeatures(c.recipe)
+	}
+	if top == 0 {
+		return nil
+	}
+
+	var picked []*candidate
+	var pickedFeatures []map[string]bool
+	used := make([]bool, len(cands))
+	for len(picked) < n {
+		best, bestValue := -1, math.Inf(-1)
+		for i, c := range cands {
+			if used[i] {
+				continue
+			}
+			overlap := 0.0
+			for _, f := range pickedFeatures {
+				overlap = math.Max(overlap, jaccard(features[i], f))
+			}
+			if value := c.score/top - diversityWeight*overlap; value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+		if best < 0 {
+			break
+		}
+		used[best] = true
+		picked = append(picked, cands[best])
+		pickedFeatures = append(pickedFeatures, features[best])
+	}
+	return picked
+}
+
+// titleStopWords are title words that say nothing about the dish.
+var titleStopWords = map[string]bool{"with": true, "easy": true, "quick": true, "best": true, "homemade": true, "simple": true}
+
+// recipeFeatures describes what kind of dish a recipe is by its category,
+// tags and the words of its title.
+func recipeFeatures(r *recipe.Recipe) map[string]bool {
+	f := map[string]bool{}
+	if r.Category != "" {
+		f["category:"+strings.ToLower(r.Category)] = true
+	}
+	for _, t := range r.Tags {
+		f["tag:"+t] = true
+	}
+	for _, w := range strings.FieldsFunc(strings.ToLower(r.Title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len(w) > 3 && !titleStopWords[w] {
+			f["word:"+w] = true
+		}
+	}
+	return f
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for k := range a {
+		if b[k] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
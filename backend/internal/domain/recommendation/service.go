@@ -0,0 +1,322 @@
+package recommendation
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/user"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/logger"
+	"alchemorsel/backend/internal/pkg/pagination"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+const (
+	// MaxRecommendations is how many recommendations are kept per user.
+	MaxRecommendations = 30
+	// DefaultLimit is how many recommendations a feed shows by default.
+	DefaultLimit = 10
+	// StaleAfter is how long cached recommendations are served before they
+	// are recomputed even without new signals.
+	StaleAfter = 24 * time.Hour
+	// RetryAfter is how long RefreshStale waits before retrying a user whose
+	// recommendations failed to refresh.
+	RetryAfter = time.Hour
+)
+
+// Bounds on the work done to compute one user's recommendations.
+const (
+	maxSignals  = 100
+	maxLikes    = 10
+	maxDislikes = 5
+	perSeed     = 20
+	// refreshBatch is how many users RefreshStale recomputes at once.
+	refreshBatch = 50
+)
+
+// fallbackSimilarity is the similarity given to the best full-text match
+// for a seed recipe without an embedding; weaker matches get a share of it.
+const fallbackSimilarity = 0.5
+
+// Service defines business logic for recommendations.
+type Service interface {
+	// ForYou returns up to limit of the user's cached recommendations,
+	// computing them on first use. Zero means DefaultLimit.
+	ForYou(ctx context.Context, userID uuid.UUID, limit int) (*Feed, error)
+	// Refresh recomputes and caches the user's recommendations.
+	Refresh(ctx context.Context, userID uuid.UUID) (*Feed, error)
+	// RefreshStale recomputes a batch of cached recommendations that are
+	// older than StaleAfter or than the user's latest favorite, rating or
+	// generation, and returns how many were recomputed.
+	RefreshStale(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repo    Repository
+	recipes recipe.Service
+	users   user.Repository
+}
+
+// NewService creates a recommendation service. Candidates are found through
+// the recipe service's search, so they only include recipes the user may
+// see and leave out those declaring the user's allergies.
+func NewService(repo Repository, recipes recipe.Service, users user.Repository) Service {
+	return &service{repo: repo, recipes: recipes, users: users}
+}
+
+func (s *service) ForYou(ctx context.Context, userID uuid.UUID, limit int) (*Feed, error) {
+	switch {
+	case limit == 0:
+		limit = DefaultLimit
+	case limit < 0 || limit > MaxRecommendations:
+		return nil, validator.InvalidField("limit", fmt.Sprintf("limit must be between 1 and %d", MaxRecommendations))
+	}
+	c, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		if c, err = s.refresh(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.feed(ctx, c, limit)
+}
+
+func (s *service) Refresh(ctx context.Context, userID uuid.UUID) (*Feed, error) {
+	c, err := s.refresh(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.feed(ctx, c, DefaultLimit)
+}
+
+func (s *service) RefreshStale(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	ids, err := s.repo.ListStale(ctx, now.Add(-StaleAfter), now.Add(-RetryAfter), refreshBatch)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, id := range ids {
+		if _, err := s.refresh(ctx, id); err != nil {
+			// One user's failure should not hold up the others, in this
+			// batch or, once recorded, in the next ones.
+			logger.FromContext(ctx).Warnw("failed to refresh recommendations", "user_id", id, "error", err)
+			if err := s.repo.MarkFailed(ctx, id, now); err != nil {
+				return n, err
+			}
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (s *service) refresh(ctx context.Context, userID uuid.UUID) (*Cache, error) {
+	entries, err := s.compute(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{UserID: userID, Entries: entries, ComputedAt: time.Now().UTC()}
+	if err := s.repo.Save(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// feed loads the cached recipes, skipping those that were deleted, made
+// private or now conflict with the user's allergies since they were
+// recommended.
+func (s *service) feed(ctx context.Context, c *Cache, limit int) (*Feed, error) {
+	f := &Feed{Recipes: []*Recommendation{}, ComputedAt: c.ComputedAt}
+	for _, e := range c.Entries {
+		if len(f.Recipes) == limit {
+			break
+		}
+		r, err := s.recipes.Get(ctx, &c.UserID, e.RecipeID)
+		if errors.Is(err, apperrors.ErrRecipeNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(r.AllergenWarnings) > 0 {
+			continue
+		}
+		f.Recipes = append(f.Recipes, &Recommendation{
+			Recipe:      r,
+			Score:       e.Score,
+			Explanation: e.Explanation,
+			BecauseOf:   e.BecauseOf,
+		})
+	}
+	return f, nil
+}
+
+// compute recommends recipes similar to those the user liked or generated
+// and unlike those they rated poorly, topped up with well rated recipes,
+// and picks a varied selection of them. Recipes the user wrote or already
+// interacted with are left out.
+func (s *service) compute(ctx context.Context, userID uuid.UUID) ([]Entry, error) {
+	u, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	signals, err := s.repo.Signals(ctx, userID, maxSignals)
+	if err != nil {
+		return nil, err
+	}
+	known := map[uuid.UUID]bool{}
+	for _, sig := range signals {
+		known[sig.RecipeID] = true
+	}
+	eligible := func(r *recipe.Recipe) bool {
+		return !known[r.ID] && r.UserID != userID && len(r.AllergenWarnings) == 0
+	}
+
+	cands := map[uuid.UUID]*candidate{}
+	likes, dislikes := 0, 0
+	for _, sd := range weighSignals(signals, time.Now()) {
+		if (sd.weight > 0 && likes == maxLikes) || (sd.weight < 0 && dislikes == maxDislikes) {
+			continue
+		}
+		source, err := s.recipes.Get(ctx, &userID, sd.recipeID)
+		if errors.Is(err, apperrors.ErrRecipeNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		hits, err := s.similar(ctx, u, source)
+		if err != nil {
+			return nil, err
+		}
+		if sd.weight > 0 {
+			likes++
+		} else {
+			dislikes++
+		}
+		for _, h := range hits {
+			if !eligible(h.recipe) {
+				continue
+			}
+			c, ok := cands[h.recipe.ID]
+			if !ok {
+				c = &candidate{recipe: h.recipe}
+				cands[h.recipe.ID] = c
+			}
+			c.add(sd, source, h.similarity)
+		}
+	}
+
+	if len(cands) < MaxRecommendations {
+		// New users and users with narrow tastes get well rated recipes.
+		res, err := s.recipes.Search(ctx, recipe.SearchParams{
+			ViewerID:   &userID,
+			Dietary:    u.DietaryPreferences,
+			Sort:       recipe.SortRating,
+			Order:      "desc",
+			Pagination: pagination.Params{PerPage: 2 * MaxRecommendations},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range res.Recipes {
+			if _, ok := cands[hit.ID]; !ok && eligible(hit.Recipe) {
+				cands[hit.ID] = &candidate{recipe: hit.Recipe}
+			}
+		}
+	}
+
+	var list []*candidate
+	for _, c := range cands {
+		c.score += popularity(c.recipe)
+		if c.score > 0 {
+			list = append(list, c)
+		}
+	}
+	slices.SortFunc(list, func(a, b *candidate) int {
+		if a.score != b.score {
+			return cmp.Compare(b.score, a.score)
+		}
+		return strings.Compare(a.recipe.ID.String(), b.recipe.ID.String())
+	})
+
+	entries := []Entry{}
+	for _, c := range diversify(list, MaxRecommendations) {
+		entries = append(entries, c.entry())
+	}
+	return entries, nil
+}
+
+type similarRecipe struct {
+	recipe     *recipe.Recipe
+	similarity float64
+}
+
+// similar finds the recipes most like the source that fit the user's diet.
+// Recipes are compared by their embeddings, or by their tags and category
+// when the source has no embedding.
+func (s *service) similar(ctx context.Context, u *user.User, source *recipe.Recipe) ([]similarRecipe, error) {
+	res, err := s.recipes.Search(ctx, recipe.SearchParams{
+		Mode:       recipe.ModeSemantic,
+		SimilarTo:  &source.ID,
+		ViewerID:   &u.ID,
+		Dietary:    u.DietaryPreferences,
+		Pagination: pagination.Params{PerPage: perSeed},
+	})
+	if err == nil {
+		hits := make([]similarRecipe, len(res.Recipes))
+		for i, hit := range res.Recipes {
+			hits[i] = similarRecipe{recipe: hit.Recipe, similarity: math.Max(0, hit.Rank)}
+		}
+		return hits, nil
+	}
+	if !errors.Is(err, recipe.ErrEmbeddingNotFound) {
+		return nil, err
+	}
+
+	terms := slices.Clone(source.Tags)
+	if source.Category != "" {
+		terms = append(terms, source.Category)
+	}
+	if len(terms) == 0 {
+		terms = []string{source.Title}
+	}
+	for i, t := range terms {
+		terms[i] = `"` + strings.ReplaceAll(t, `"`, "") + `"`
+	}
+	res, err = s.recipes.Search(ctx, recipe.SearchParams{
+		Mode:       recipe.ModeFullText,
+		Query:      strings.Join(terms, " OR "),
+		ViewerID:   &u.ID,
+		Dietary:    u.DietaryPreferences,
+		Sort:       recipe.SortRelevance,
+		Order:      "desc",
+		Pagination: pagination.Params{PerPage: perSeed},
+	})
+	if err != nil {
+		return nil, err
+	}
+	top := 0.0
+	for _, hit := range res.Recipes {
+		top = math.Max(top, hit.Rank)
+	}
+	hits := make([]similarRecipe, 0, len(res.Recipes))
+	for _, hit := range res.Recipes {
+		if top > 0 {
+			hits = append(hits, similarRecipe{recipe: hit.Recipe, similarity: fallbackSimilarity * hit.Rank / top})
+		}
+	}
+	return hits, nil
+}
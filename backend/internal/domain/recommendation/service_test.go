@@ -0,0 +1,210 @@
+package recommendation
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/recipe/recipetest"
+	"alchemorsel/backend/internal/domain/user"
+	"alchemorsel/backend/internal/domain/user/usertest"
+)
+
+type fakeRepository struct {
+	Repository
+	signals []Signal
+	caches  map[uuid.UUID]*Cache
+	stale   []uuid.UUID
+	failed  []uuid.UUID
+}
+
+func (f *fakeRepository) Signals(ctx context.Context, userID uuid.UUID, limit int) ([]Signal, error) {
+	return f.signals, nil
+}
+
+func (f *fakeRepository) Get(ctx context.Context, userID uuid.UUID) (*Cache, error) {
+	return f.caches[userID], nil
+}
+
+func (f *fakeRepository) Save(ctx context.Context, c *Cache) error {
+	copy := *c
+	f.caches[c.UserID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) ListStale(ctx context.Context, before, retryBefore time.Time, limit int) ([]uuid.UUID, error) {
+	return f.stale, nil
+}
+
+func (f *fakeRepository) MarkFailed(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	f.failed = append(f.failed, userID)
+	return nil
+}
+
+// fakeRecipes adds searches to the shared in-memory recipes. Similar recipes
+// are looked up in similar by the id of the recipe they are similar to;
+// recipes missing from it have no embedding and fall back to full-text
+// search, which returns textHits. Other searches return popular.
+type fakeRecipes struct {
+	*recipetest.Recipes
+	similar  map[uuid.UUID][]*recipe.SearchHit
+	textHits []*recipe.SearchHit
+	popular  []*recipe.SearchHit
+	searches []recipe.SearchParams
+}
+
+func (f *fakeRecipes) Search(ctx context.Context, params recipe.SearchParams) (*recipe.SearchResult, error) {
+	f.searches = append(f.searches, params)
+	switch {
+	case params.SimilarTo != nil:
+		hits, ok := f.similar[*params.SimilarTo]
+		if !ok {
+			return nil, recipe.ErrEmbeddingNotFound
+		}
+		return &recipe.SearchResult{Recipes: hits}, nil
+	case params.Query != "":
+		return &recipe.SearchResult{Recipes: f.textHits}, nil
+	}
+	return &recipe.SearchResult{Recipes: f.popular}, nil
+}
+
+func newRecipe(author uuid.UUID, title, category string, tags ...string) *recipe.Recipe {
+	return &recipe.Recipe{ID: uuid.New(), UserID: author, Title: title, Category: category, Tags: tags, IsPublic: true}
+}
+
+func TestWeighSignals(t *testing.T) {
+	now := time.Now()
+	loved, disliked, generated, neutral := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	seeds := weighSignals([]Signal{
+		{RecipeID: generated, Kind: SignalGenerated, At: now.Add(-signalHalfLife)},
+		{RecipeID: loved, Kind: SignalFavorite, At: now},
+		{RecipeID: loved, Kind: SignalRating, Rating: 5, At: now},
+		{RecipeID: disliked, Kind: SignalRating, Rating: 1, At: now},
+		{RecipeID: neutral, Kind: SignalRating, Rating: 3, At: now},
+	}, now)
+
+	if len(seeds) != 3 {
+		t.Fatalf("seeds = %+v, want 3", seeds)
+	}
+	if seeds[0].recipeID != loved || seeds[0].weight != 2 || seeds[0].kind != SignalFavorite {
+		t.Fatalf("first seed = %+v", seeds[0])
+	}
+	if seeds[1].recipeID != disliked || seeds[1].weight != -1 {
+		t.Fatalf("second seed = %+v", seeds[1])
+	}
+	if seeds[2].recipeID != generated || seeds[2].kind != SignalGenerated || seeds[2].weight < 0.29 || seeds[2].weight > 0.31 {
+		t.Fatalf("old generation should have half its weight, got %+v", seeds[2])
+	}
+}
+
+func TestDiversifyMixesDishes(t *testing.T) {
+	author := uuid.New()
+	var cands []*candidate
+	for i, title := range []string{"Pasta carbonara", "Pasta alla norma", "Pasta puttanesca", "Pasta primavera"} {
+		cands = append(cands, &candidate{recipe: newRecipe(author, title, "dinner", "pasta", "italian"), score: 1 - float64(i)*0.02})
+	}
+	cands = append(cands, &candidate{recipe: newRecipe(author, "Fattoush", "salad", "lebanese"), score: 0.8})
+
+	picked := diversify(cands, 3)
+	if len(picked) != 3 || picked[0] != cands[0] {
+		t.Fatalf("picked %d recipes, want the best first", len(picked))
+	}
+	if picked[1].recipe.Title != "Fattoush" {
+		t.Fatalf("second pick = %q, want the salad", picked[1].recipe.Title)
+	}
+}
+
+func TestForYouComputesAndCaches(t *testing.T) {
+	ctx := context.Background()
+	viewer, author := uuid.New(), uuid.New()
+
+	liked := newRecipe(author, "Pad Thai", "dinner", "thai")
+	hated := newRecipe(author, "Liver and onions", "dinner", "offal")
+	generated := newRecipe(viewer, "Green curry", "dinner", "thai", "curry")
+	noodles := newRecipe(author, "Drunken noodles", "dinner", "thai", "noodles")
+	liverPate := newRecipe(author, "Liver pate", "starter", "offal")
+	laksa := newRecipe(author, "Laksa", "soup", "malaysian", "curry")
+	peanutSatay := newRecipe(author, "Peanut satay", "starter", "thai")
+	peanutSatay.AllergenWarnings = []string{"peanuts"}
+	ownRecipe := newRecipe(viewer, "My stir fry", "dinner", "thai")
+	pie := newRecipe(author, "Apple pie", "dessert", "baking")
+	pie.RatingAverage, pie.RatingCount = 4.8, 40
+
+	recipes := &fakeRecipes{Recipes: recipetest.NewRecipes(liked, hated, generated, noodles, liverPate, laksa, peanutSatay, ownRecipe, pie)}
+	recipes.similar = map[uuid.UUID][]*recipe.SearchHit{
+		liked.ID: {
+			{Recipe: liked, Rank: 1}, {Recipe: noodles, Rank: 0.9}, {Recipe: peanutSatay, Rank: 0.85},
+			{Recipe: ownRecipe, Rank: 0.8}, {Recipe: liverPate, Rank: 0.3},
+		},
+		hated.ID: {{Recipe: hated, Rank: 1}, {Recipe: liverPate, Rank: 0.9}},
+	}
+	// The generated curry has no embedding and is matched by its tags.
+	recipes.textHits = []*recipe.SearchHit{{Recipe: laksa, Rank: 0.4}, {Recipe: generated, Rank: 0.2}}
+	recipes.popular = []*recipe.SearchHit{{Recipe: pie}, {Recipe: noodles}}
+
+	now := time.Now()
+	repo := &fakeRepository{caches: map[uuid.UUID]*Cache{}, signals: []Signal{
+		{RecipeID: liked.ID, Kind: SignalFavorite, At: now},
+		{RecipeID: hated.ID, Kind: SignalRating, Rating: 1, At: now},
+		{RecipeID: generated.ID, Kind: SignalGenerated, At: now},
+	}}
+	users := usertest.NewUsers(&user.User{ID: viewer, DietaryPreferences: []string{"vegetarian"}})
+	svc := NewService(repo, recipes, users)
+
+	feed, err := svc.ForYou(ctx, viewer, 0)
+	if err != nil {
+		t.Fatalf("for you: %v", err)
+	}
+	got := map[string]string{}
+	var titles []string
+	for _, r := range feed.Recipes {
+		got[r.Title] = r.Explanation
+		titles = append(titles, r.Title)
+	}
+	want := map[string]string{
+		"Drunken noodles": "Because you liked Pad Thai",
+		"Laksa":           "Because you generated Green curry",
+		"Apple pie":       "Highly rated by other cooks",
+	}
+	if len(got) != len(want) || titles[0] != "Drunken noodles" {
+		t.Fatalf("recommended %q, want %d recipes led by Drunken noodles", titles, len(want))
+	}
+	for title, explanation := range want {
+		if got[title] != explanation {
+			t.Fatalf("%s explained as %q, want %q", title, got[title], explanation)
+		}
+	}
+	for _, p := range recipes.searches {
+		if p.ViewerID == nil || *p.ViewerID != viewer || strings.Join(p.Dietary, ",") != "vegetarian" {
+			t.Fatalf("search did not apply the viewer's diet: %+v", p)
+		}
+	}
+	if p := recipes.searches[len(recipes.searches)-2]; p.Mode != recipe.ModeFullText || p.Query != `"thai" OR "curry" OR "dinner"` {
+		t.Fatalf("fallback search = %+v", p)
+	}
+
+	// The cached recommendations are served without recomputing them, and
+	// recipes that have since disappeared are skipped.
+	searches := len(recipes.searches)
+	delete(recipes.ByID, laksa.ID)
+	feed, err = svc.ForYou(ctx, viewer, 1)
+	if err != nil || len(feed.Recipes) != 1 || feed.Recipes[0].ID != noodles.ID || len(recipes.searches) != searches {
+		t.Fatalf("unexpected cached feed %+v, %v", feed, err)
+	}
+	if _, err := svc.ForYou(ctx, viewer, MaxRecommendations+1); err == nil {
+		t.Fatal("expected an error for too large a limit")
+	}
+
+	unknown := uuid.New()
+	repo.stale = []uuid.UUID{viewer, unknown}
+	if n, err := svc.RefreshStale(ctx); err != nil || n != 1 {
+		t.Fatalf("refreshed %d, %v; want 1 refreshed and the unknown user skipped", n, err)
+	}
+	if len(repo.failed) != 1 || repo.failed[0] != unknown {
+		t.Fatalf("expected the unknown user's failure to be recorded, got %v", repo.failed)
+	}
+}
@@ -0,0 +1,22 @@
+package review
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Review is a user's rating of a recipe with optional written feedback. A
+// user has at most one review per recipe.
+type Review struct {
+	ID           uuid.UUID `json:"id"`
+	RecipeID     uuid.UUID `json:"recipe_id"`
+	UserID       uuid.UUID `json:"user_id"`
+	Username     string    `json:"username"`
+	Rating       int       `json:"rating"`
+	Body         string    `json:"body"`
+	MadeIt       bool      `json:"made_it"`
+	HelpfulCount int       `json:"helpful_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,46 @@
+package review
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+// Sort keys accepted by ListParams.Sort.
+const (
+	SortHelpful = "helpful"
+	SortNewest  = "newest"
+	SortOldest  = "oldest"
+	SortHighest = "highest"
+	SortLowest  = "lowest"
+)
+
+// ListParams selects a page of a recipe's reviews.
+type ListParams struct {
+	Sort       string
+	Pagination pagination.Params
+}
+
+// ListResult holds a page of reviews.
+type ListResult struct {
+	Reviews    []*Review       `json:"reviews"`
+	Pagination pagination.Meta `json:"pagination"`
+}
+
+// Repository defines persistence operations for reviews.
+type Repository interface {
+	// Upsert creates the user's review of the recipe or replaces the existing
+	// one, refreshing the recipe's rating aggregates in the same transaction.
+	// It reports whether the review was created.
+	Upsert(ctx context.Context, r *Review) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*Review, error)
+	// Delete removes the user's review of the recipe and refreshes the
+	// recipe's rating aggregates.
+	Delete(ctx context.Context, recipeID, userID uuid.UUID) error
+	List(ctx context.Context, recipeID uuid.UUID, params ListParams) (*ListResult, error)
+	// AddHelpfulVote and RemoveHelpfulVote are idempotent.
+	AddHelpfulVote(ctx context.Context, reviewID, userID uuid.UUID) error
+	RemoveHelpfulVote(ctx context.Context, reviewID, userID uuid.UUID) error
+}
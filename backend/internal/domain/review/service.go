@@ -0,0 +1,146 @@
+package review
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// MaxBodyLength is the maximum length of a review's text in characters,
+// counted after sanitization.
+const MaxBodyLength = 5000
+
+var (
+	// ErrReviewNotFound is returned when a review cannot be located.
+	ErrReviewNotFound = apperrors.New("review_not_found", "review not found", 404)
+	// ErrOwnRecipe is returned when users try to review their own recipe.
+	ErrOwnRecipe = apperrors.New("own_recipe", "you cannot review your own recipe", 422)
+	// ErrOwnReview is returned when users vote on their own review.
+	ErrOwnReview = apperrors.New("own_review", "you cannot vote on your own review", 422)
+)
+
+var sortKeys = map[string]bool{
+	SortHelpful: true,
+	SortNewest:  true,
+	SortOldest:  true,
+	SortHighest: true,
+	SortLowest:  true,
+}
+
+// Service defines business logic for reviews.
+type Service interface {
+	// Save creates or replaces the user's review of a recipe and reports
+	// whether it was created.
+	Save(ctx context.Context, userID, recipeID uuid.UUID, req SaveRequest) (*Review, bool, error)
+	Delete(ctx context.Context, userID, recipeID uuid.UUID) error
+	List(ctx context.Context, viewerID *uuid.UUID, recipeID uuid.UUID, params ListParams) (*ListResult, error)
+	MarkHelpful(ctx context.Context, userID, reviewID uuid.UUID) error
+	UnmarkHelpful(ctx context.Context, userID, reviewID uuid.UUID) error
+}
+
+type SaveRequest struct {
+	Rating int
+	Body   string
+	MadeIt bool
+}
+
+type service struct {
+	repo    Repository
+	recipes recipe.Service
+}
+
+// NewService creates a review service. Recipe visibility is checked through
+// the recipe service.
+func NewService(repo Repository, recipes recipe.Service) Service {
+	return &service{repo: repo, recipes: recipes}
+}
+
+func (s *service) Save(ctx context.Context, userID, recipeID uuid.UUID, req SaveRequest) (*Review, bool, error) {
+	if req.Rating < 1 || req.Rating > 5 {
+		return nil, false, validator.InvalidField("rating", "rating must be between 1 and 5")
+	}
+	body, err := validator.Text("body", req.Body, 0, MaxBodyLength)
+	if err != nil {
+		return nil, false, err
+	}
+	now := time.Now().UTC()
+	r := &Review{
+		ID:        uuid.New(),
+		RecipeID:  recipeID,
+		UserID:    userID,
+		Rating:    req.Rating,
+		Body:      body,
+		MadeIt:    req.MadeIt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	rec, err := s.recipes.Get(ctx, &userID, recipeID)
+	if err != nil {
+		return nil, false, err
+	}
+	if rec.UserID == userID {
+		return nil, false, ErrOwnRecipe
+	}
+	created, err := s.repo.Upsert(ctx, r)
+	if err != nil {
+		return nil, false, err
+	}
+	return r, created, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, recipeID uuid.UUID) error {
+	return s.repo.Delete(ctx, recipeID, userID)
+}
+
+func (s *service) List(ctx context.Context, viewerID *uuid.UUID, recipeID uuid.UUID, params ListParams) (*ListResult, error) {
+	if params.Sort == "" {
+		params.Sort = SortHelpful
+	}
+	if !sortKeys[params.Sort] {
+		return nil, validator.InvalidField("sort", "unsupported sort field")
+	}
+	params.Pagination = params.Pagination.Normalize()
+	if _, err := s.recipes.Get(ctx, viewerID, recipeID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, recipeID, params)
+}
+
+func (s *service) MarkHelpful(ctx context.Context, userID, reviewID uuid.UUID) error {
+	r, err := s.getVisible(ctx, userID, reviewID)
+	if err != nil {
+		return err
+	}
+	if r.UserID == userID {
+		return ErrOwnReview
+	}
+	return s.repo.AddHelpfulVote(ctx, reviewID, userID)
+}
+
+func (s *service) UnmarkHelpful(ctx context.Context, userID, reviewID uuid.UUID) error {
+	if _, err := s.getVisible(ctx, userID, reviewID); err != nil {
+		return err
+	}
+	return s.repo.RemoveHelpfulVote(ctx, reviewID, userID)
+}
+
+// getVisible loads a review on a recipe the user may view. Reviews on hidden
+// recipes are reported as missing.
+func (s *service) getVisible(ctx context.Context, userID, reviewID uuid.UUID) (*Review, error) {
+	r, err := s.repo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.recipes.Get(ctx, &userID, r.RecipeID); err != nil {
+		if err == apperrors.ErrRecipeNotFound {
+			return nil, ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return r, nil
+}
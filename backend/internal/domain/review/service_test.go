@@ -0,0 +1,96 @@
+package review
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/recipe/recipetest"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// fakeRepository implements the Repository methods used by the tests. Calls to
+// any other method panic through the nil embedded interface.
+type fakeRepository struct {
+	Repository
+	reviews map[uuid.UUID]*Review
+	upserts int
+	votes   int
+}
+
+func (f *fakeRepository) Upsert(ctx context.Context, r *Review) (bool, error) {
+	f.upserts++
+	return true, nil
+}
+
+func (f *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*Review, error) {
+	if r, ok := f.reviews[id]; ok {
+		return r, nil
+	}
+	return nil, ErrReviewNotFound
+}
+
+func (f *fakeRepository) AddHelpfulVote(ctx context.Context, reviewID, userID uuid.UUID) error {
+	f.votes++
+	return nil
+}
+
+func TestSaveValidatesAndRejectsOwnRecipe(t *testing.T) {
+	author, reader := uuid.New(), uuid.New()
+	rec := &recipe.Recipe{ID: uuid.New(), UserID: author, IsPublic: true}
+	repo := &fakeRepository{}
+	svc := NewService(repo, recipetest.NewRecipes(rec))
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		req    SaveRequest
+		code   string
+	}{
+		{"rating too low", reader, SaveRequest{Rating: 0}, apperrors.ErrInvalidInput.Code},
+		{"rating too high", reader, SaveRequest{Rating: 6}, apperrors.ErrInvalidInput.Code},
+		{"own recipe", author, SaveRequest{Rating: 5}, ErrOwnRecipe.Code},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := svc.Save(ctx, tt.userID, rec.ID, tt.req)
+			appErr, ok := err.(*apperrors.AppError)
+			if !ok || appErr.Code != tt.code {
+				t.Fatalf("expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+
+	r, created, err := svc.Save(ctx, reader, rec.ID, SaveRequest{Rating: 4, Body: "  Great!  ", MadeIt: true})
+	if err != nil || !created {
+		t.Fatalf("save: created=%v err=%v", created, err)
+	}
+	if r.Body != "Great!" || r.Rating != 4 || !r.MadeIt || repo.upserts != 1 {
+		t.Fatalf("unexpected review %+v", r)
+	}
+}
+
+func TestMarkHelpful(t *testing.T) {
+	author, reader := uuid.New(), uuid.New()
+	public := &recipe.Recipe{ID: uuid.New(), UserID: uuid.New(), IsPublic: true}
+	private := &recipe.Recipe{ID: uuid.New(), UserID: uuid.New()}
+	onPublic := &Review{ID: uuid.New(), RecipeID: public.ID, UserID: author}
+	onPrivate := &Review{ID: uuid.New(), RecipeID: private.ID, UserID: author}
+	repo := &fakeRepository{reviews: map[uuid.UUID]*Review{onPublic.ID: onPublic, onPrivate.ID: onPrivate}}
+	recipes := recipetest.NewRecipes(public, private)
+	svc := NewService(repo, recipes)
+	ctx := context.Background()
+
+	if err := svc.MarkHelpful(ctx, author, onPublic.ID); err != ErrOwnReview {
+		t.Fatalf("expected own review error, got %v", err)
+	}
+	if err := svc.MarkHelpful(ctx, reader, onPrivate.ID); err != ErrReviewNotFound {
+		t.Fatalf("expected review on hidden recipe to be missing, got %v", err)
+	}
+	if err := svc.MarkHelpful(ctx, reader, onPublic.ID); err != nil || repo.votes != 1 {
+		t.Fatalf("mark helpful: votes=%d err=%v", repo.votes, err)
+	}
+}
@@ -0,0 +1,105 @@
+package shopping
+
+import (
+	"strings"
+	"unicode"
+)
+
+// aisleRule files items whose names contain one of its keywords, as whole
+// singular words, in an aisle. The longest keyword found wins, so that "bell
+// pepper" is produce rather than a spice; between keywords of the same
+// length the earlier rule wins, so that "frozen peas" are frozen and
+// "chicken stock" is a pantry item.
+type aisleRule struct {
+	aisle    string
+	keywords []string
+}
+
+var aisleRules = []aisleRule{
+	{AisleFrozen, []string{"frozen", "ice cream", "sorbet"}},
+	{AislePantry, []string{
+		"stock", "broth", "bouillon", "canned", "tinned", "can", "paste", "passata", "sauce",
+		"ketchup", "mustard", "mayonnaise", "mayo", "vinegar", "oil", "flour", "sugar", "honey",
+		"syrup", "molasses", "rice", "pasta", "spaghetti", "noodle", "macaroni", "penne", "oat",
+		"oatmeal", "quinoa", "couscous", "bulgur", "lentil", "chickpea", "bean", "cornstarch",
+		"baking powder", "baking soda", "yeast", "cocoa", "chocolate", "chip", "raisin", "nut",
+		"almond", "walnut", "pecan", "cashew", "peanut", "peanut butter", "jam", "coconut milk",
+		"breadcrumb", "panko", "cracker", "cereal", "tahini", "vanilla", "extract", "gelatin",
+	}},
+	{AisleSpices, []string{
+		"salt", "pepper", "peppercorn", "cinnamon", "cumin", "paprika", "turmeric", "nutmeg",
+		"ground clove", "cardamom", "oregano", "thyme", "rosemary", "bay leaf", "chili powder",
+		"curry powder", "garam masala", "allspice", "coriander seed", "fennel seed",
+		"cayenne", "seasoning", "spice",
+	}},
+	{AisleDairy, []string{
+		"milk", "butter", "cream", "cheese", "yogurt", "yoghurt", "egg", "buttermilk", "ghee",
+		"parmesan", "mozzarella", "cheddar", "feta", "ricotta", "mascarpone", "creme fraiche",
+		"crème fraîche", "half and half", "sour cream", "tofu",
+	}},
+	{AisleMeat, []string{
+		"chicken", "beef", "pork", "lamb", "turkey", "duck", "bacon", "ham", "sausage",
+		"mince", "ground meat", "steak", "veal", "chorizo", "prosciutto", "salami", "fish",
+		"salmon", "tuna", "cod", "shrimp", "prawn", "crab", "lobster", "mussel", "clam",
+		"scallop", "squid", "anchovy",
+	}},
+	{AisleBakery, []string{
+		"bread", "baguette", "bun", "roll", "tortilla", "pita", "naan", "bagel", "croissant",
+		"brioche", "focaccia", "sourdough", "ciabatta",
+	}},
+	{AisleBeverages, []string{"wine", "beer", "juice", "coffee", "tea", "soda", "water"}},
+	{AisleProduce, []string{
+		"onion", "shallot", "garlic", "ginger", "potato", "carrot", "celery", "tomato",
+		"lettuce", "spinach", "kale", "cabbage", "broccoli", "cauliflower", "zucchini",
+		"courgette", "eggplant", "aubergine", "cucumber", "bell pepper", "chili", "chile",
+		"jalapeño", "jalapeno", "mushroom", "pea", "corn", "leek", "scallion", "green onion",
+		"avocado", "lemon", "lime", "orange", "apple", "banana", "berry", "strawberry",
+		"blueberry", "raspberry", "grape", "mango", "pineapple", "peach", "pear", "cherry",
+		"herb", "basil", "parsley", "cilantro", "coriander", "mint", "dill", "chive",
+		"squash", "pumpkin", "sweet potato", "radish", "beet", "asparagus", "arugula",
+		"fennel", "melon", "sprout", "green pepper",
+	}},
+}
+
+// aisleFor guesses the aisle an item is found in from its name.
+func aisleFor(name string) string {
+	text := " " + strings.Join(words(name), " ") + " "
+	aisle, longest := AisleOther, 0
+	for _, rule := range aisleRules {
+		for _, kw := range rule.keywords {
+			kwWords := words(kw)
+			if len(kwWords) > longest && strings.Contains(text, " "+strings.Join(kwWords, " ")+" ") {
+				aisle, longest = rule.aisle, len(kwWords)
+			}
+		}
+	}
+	return aisle
+}
+
+// words splits text into lowercase, singular words.
+func words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		fields[i] = singular(f)
+	}
+	return fields
+}
+
+// singular makes a rough singular of an English plural so that "onions"
+// and "onion" are bought together.
+func singular(w string) string {
+	switch {
+	case len(w) <= 3:
+		return w
+	case strings.HasSuffix(w, "ies"):
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "oes"), strings.HasSuffix(w, "ches"), strings.HasSuffix(w, "shes"),
+		strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "xes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us"):
+		return w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,125 @@
+package shopping
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/pkg/units"
+)
+
+// source is a recipe to shop for, with the factor its amounts are scaled by
+// to make the planned servings.
+type source struct {
+	title       string
+	ingredients []recipe.Ingredient
+	factor      float64
+}
+
+// line accumulates the amounts of an ingredient measured in compatible
+// units.
+type line struct {
+	item *Item
+	name string
+	// base is the total in millilitres or grams for units of volume or
+	// mass, which is then written in the most readable unit of the family
+	// of the first unit seen; for other units it is the total count.
+	base  float64
+	unit  units.Unit
+	known bool
+	// plural is a plural spelling of an unknown unit, used for totals
+	// above one.
+	plural string
+}
+
+// consolidate turns the ingredients of recipes into shopping list items.
+// The same ingredient is bought once: amounts in units of the same
+// dimension are added up, so that 2 tbsp and ¼ cup of butter make ⅜ cup,
+// and amounts in other units, such as cloves, add up only with the same
+// unit. Incompatible amounts stay on separate lines. Ranges are bought at
+// their upper end, optional ingredients are left out, and an ingredient
+// without a measure, such as "salt, to taste", is listed only when no
+// recipe measures it. Items are sorted by aisle and name.
+func consolidate(sources []source) []*Item {
+	var lines []*line
+	byKey := map[string]*line{}
+	measured := map[string]bool{}
+	for _, src := range sources {
+		for _, ing := range src.ingredients {
+			name := strings.TrimSpace(ing.Name)
+			nameKey := strings.Join(words(name), " ")
+			if ing.Optional || nameKey == "" {
+				continue
+			}
+			amount := max(ing.Amount, ing.AmountMax) * src.factor
+			var unitKey string
+			u, known := units.Lookup(ing.Unit)
+			known = known && (u.Dimension == units.Volume || u.Dimension == units.Mass)
+			switch {
+			case amount <= 0 || units.Unmeasured(ing.Unit):
+				amount, unitKey = 0, "-"
+			case known:
+				amount, unitKey = amount*u.Base, "dimension:"+strconv.Itoa(int(u.Dimension))
+			default:
+				unitKey = "unit:" + strings.Join(words(ing.Unit), " ")
+			}
+			if amount > 0 {
+				measured[nameKey] = true
+			}
+
+			key := nameKey + "|" + unitKey
+			l := byKey[key]
+			if l == nil {
+				l = &line{
+					item: &Item{Name: name, Aisle: aisleFor(name), Recipes: []string{}},
+					name: nameKey, unit: u, known: known,
+				}
+				if !known && amount > 0 {
+					l.item.Unit = strings.TrimSpace(ing.Unit)
+				}
+				byKey[key] = l
+				lines = append(lines, l)
+			}
+			l.base += amount
+			if !known && amount > 0 && strings.ToLower(strings.TrimSpace(ing.Unit)) != unitKey[len("unit:"):] {
+				l.plural = strings.TrimSpace(ing.Unit)
+			}
+			if src.title != "" && !slices.Contains(l.item.Recipes, src.title) {
+				l.item.Recipes = append(l.item.Recipes, src.title)
+			}
+		}
+	}
+
+	items := make([]*Item, 0, len(lines))
+	for _, l := range lines {
+		switch {
+		case l.base <= 0:
+			if measured[l.name] {
+				continue
+			}
+		case l.known:
+			amount, best := units.Best(l.base/l.unit.Base, l.unit)
+			l.item.Amount = units.Round(amount, best)
+			l.item.Unit = best.Label(l.item.Amount)
+		default:
+			l.item.Amount = units.RoundFraction(l.base)
+			if l.item.Amount > 1 && l.plural != "" {
+				l.item.Unit = l.plural
+			}
+		}
+		items = append(items, l.item)
+	}
+	sortItems(items)
+	return items
+}
+
+// sortItems sorts items by aisle and then by name.
+func sortItems(items []*Item) {
+	slices.SortStableFunc(items, func(a, b *Item) int {
+		if d := slices.Index(Aisles, a.Aisle) - slices.Index(Aisles, b.Aisle); d != 0 {
+			return d
+		}
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+}
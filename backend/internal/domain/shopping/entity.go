@@ -0,0 +1,110 @@
+package shopping
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Grocery aisles, in the order a list groups its items.
+const (
+	AisleProduce   = "produce"
+	AisleBakery    = "bakery"
+	AisleMeat      = "meat & seafood"
+	AisleDairy     = "dairy & eggs"
+	AisleFrozen    = "frozen"
+	AislePantry    = "pantry"
+	AisleSpices    = "spices & seasonings"
+	AisleBeverages = "beverages"
+	AisleOther     = "other"
+)
+
+// Aisles lists the grocery aisles in the order a list groups its items.
+var Aisles = []string{
+	AisleProduce, AisleBakery, AisleMeat, AisleDairy, AisleFrozen,
+	AislePantry, AisleSpices, AisleBeverages, AisleOther,
+}
+
+// List is a shopping list kept by its owner and shared with the members
+// they add, who may check items off and add their own.
+type List struct {
+	ID      uuid.UUID `json:"id"`
+	OwnerID uuid.UUID `json:"owner_id"`
+	Name    string    `json:"name"`
+	// PlanID is the meal plan the list was made from, if any.
+	PlanID  *uuid.UUID `json:"plan_id,omitempty"`
+	Members []*Member  `json:"members,omitempty"`
+	// Aisles holds the items grouped by aisle. It is only populated when a
+	// single list is fetched.
+	Aisles    []*AisleItems `json:"aisles,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// Member is a user a list is shared with.
+type Member struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	AddedAt  time.Time `json:"added_at"`
+}
+
+// Item is a line of a shopping list. Amount is zero for items bought
+// without a measure, such as "salt" or "bananas" added by hand.
+type Item struct {
+	ID     uuid.UUID `json:"id"`
+	ListID uuid.UUID `json:"list_id"`
+	Name   string    `json:"name"`
+	Amount float64   `json:"amount,omitempty"`
+	Unit   string    `json:"unit,omitempty"`
+	Aisle  string    `json:"aisle"`
+	// Recipes names the recipes that call for the item. Manual items were
+	// added by hand rather than generated from recipes.
+	Recipes   []string  `json:"recipes,omitempty"`
+	Manual    bool      `json:"manual"`
+	Checked   bool      `json:"checked"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AisleItems are the items of a list found in one aisle.
+type AisleItems struct {
+	Aisle string  `json:"aisle"`
+	Items []*Item `json:"items"`
+}
+
+// isMember reports whether the list is shared with the user.
+func (l *List) isMember(userID uuid.UUID) bool {
+	for _, m := range l.Members {
+		if m.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// canEdit reports whether the user may check off and change the list's
+// items: its owner and members.
+func (l *List) canEdit(userID uuid.UUID) bool {
+	return l.OwnerID == userID || l.isMember(userID)
+}
+
+// groupByAisle groups items by aisle in the order of Aisles, keeping the
+// order of the items within an aisle.
+func groupByAisle(items []*Item) []*AisleItems {
+	byAisle := map[string]*AisleItems{}
+	for _, it := range items {
+		g := byAisle[it.Aisle]
+		if g == nil {
+			g = &AisleItems{Aisle: it.Aisle}
+			byAisle[it.Aisle] = g
+		}
+		g.Items = append(g.Items, it)
+	}
+	groups := []*AisleItems{}
+	for _, a := range Aisles {
+		if g := byAisle[a]; g != nil {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,80 @@
+package shopping
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strconv"
+	"strings"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/pkg/units"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// Export formats of a shopping list.
+const (
+	FormatText = "txt"
+	FormatCSV  = "csv"
+)
+
+// Export writes a list, with its items grouped by aisle, as plain text with
+// a checkbox per item or as CSV with one row per item.
+func Export(l *List, format string) (*recipe.Document, error) {
+	name := recipe.NormalizeTag(l.Name)
+	if name == "" {
+		name = "shopping-list"
+	}
+	switch format {
+	case "", FormatText:
+		return &recipe.Document{Filename: name + ".txt", ContentType: "text/plain; charset=utf-8", Data: listText(l)}, nil
+	case FormatCSV:
+		return &recipe.Document{Filename: name + ".csv", ContentType: "text/csv; charset=utf-8", Data: listCSV(l)}, nil
+	}
+	return nil, validator.InvalidField("format", "format must be txt or csv")
+}
+
+func listText(l *List) []byte {
+	var b strings.Builder
+	b.WriteString(l.Name + "\n")
+	for _, g := range l.Aisles {
+		b.WriteString("\n" + strings.ToUpper(g.Aisle[:1]) + g.Aisle[1:] + "\n")
+		for _, it := range g.Items {
+			box := "[ ]"
+			if it.Checked {
+				box = "[x]"
+			}
+			b.WriteString(box + " " + it.describe())
+			if len(it.Recipes) > 0 {
+				b.WriteString(" (" + strings.Join(it.Recipes, ", ") + ")")
+			}
+			b.WriteString("\n")
+		}
+	}
+	return []byte(b.String())
+}
+
+func listCSV(l *List) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"aisle", "item", "amount", "unit", "checked", "recipes"})
+	for _, g := range l.Aisles {
+		for _, it := range g.Items {
+			amount := ""
+			if it.Amount > 0 {
+				amount = strconv.FormatFloat(math.Round(it.Amount*100)/100, 'f', -1, 64)
+			}
+			w.Write([]string{g.Aisle, it.Name, amount, it.Unit, strconv.FormatBool(it.Checked), strings.Join(it.Recipes, "; ")})
+		}
+	}
+	w.Flush()
+	return buf.Bytes()
+}
+
+// describe writes the item as a shopper reads it, such as "⅜ cup butter".
+func (it *Item) describe() string {
+	if it.Amount <= 0 {
+		return it.Name
+	}
+	return strings.Join(strings.Fields(units.FormatAmount(it.Amount)+" "+it.Unit+" "+it.Name), " ")
+}
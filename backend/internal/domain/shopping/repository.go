@@ -0,0 +1,33 @@
+package shopping
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository defines persistence operations for shopping lists.
+type Repository interface {
+	// Create stores the list with its items in one transaction.
+	Create(ctx context.Context, l *List, items []*Item) error
+	// GetByID returns the list with its members but without its items.
+	GetByID(ctx context.Context, id uuid.UUID) (*List, error)
+	Update(ctx context.Context, l *List) error
+	// Delete removes the list with its items and members.
+	Delete(ctx context.Context, id uuid.UUID) error
+	// ListForMember returns the lists the user owns or is a member of, most
+	// recently updated first.
+	ListForMember(ctx context.Context, userID uuid.UUID) ([]*List, error)
+
+	// ListItems returns the list's items ordered by aisle, as in Aisles, and
+	// position.
+	ListItems(ctx context.Context, listID uuid.UUID) ([]*Item, error)
+	GetItem(ctx context.Context, listID, id uuid.UUID) (*Item, error)
+	// CreateItem adds an item after the others and sets its position.
+	CreateItem(ctx context.Context, it *Item) error
+	UpdateItem(ctx context.Context, it *Item) error
+	DeleteItem(ctx context.Context, listID, id uuid.UUID) error
+
+	AddMember(ctx context.Context, listID uuid.UUID, m *Member) error
+	RemoveMember(ctx context.Context, listID, userID uuid.UUID) error
+}
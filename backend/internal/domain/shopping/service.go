@@ -0,0 +1,397 @@
+package shopping
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/mealplan"
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/user"
+	"alchemorsel/backend/internal/pkg/date"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// Limits on lists and items, counted in characters after sanitization.
+const (
+	MaxNameLength     = 100
+	MaxItemNameLength = 200
+	MaxUnitLength     = 30
+	MaxAmount         = 100000
+	// MaxRecipes bounds the recipes a list is made from at once.
+	MaxRecipes = 50
+)
+
+var (
+	// ErrListNotFound is returned when a list does not exist or is not
+	// shared with the user.
+	ErrListNotFound = apperrors.New("shopping_list_not_found", "shopping list not found", 404)
+	// ErrItemNotFound is returned when a list has no such item.
+	ErrItemNotFound = apperrors.New("shopping_list_item_not_found", "shopping list item not found", 404)
+	// ErrMemberNotFound is returned when a list is not shared with the user.
+	ErrMemberNotFound = apperrors.New("shopping_list_member_not_found", "shopping list member not found", 404)
+	// ErrAlreadyMember is returned when sharing a list twice with a user.
+	ErrAlreadyMember = apperrors.New("already_member", "the list is already shared with this user", 409)
+	// ErrShareWithOwner is returned when owners share a list with themselves.
+	ErrShareWithOwner = apperrors.New("share_with_owner", "you cannot share a list with its owner", 422)
+)
+
+// Service defines business logic for shopping lists.
+type Service interface {
+	// Create makes a list from the recipes of a meal plan, from chosen
+	// recipes, or both, consolidating their ingredients.
+	Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*List, error)
+	// Get returns the list with its items grouped by aisle.
+	Get(ctx context.Context, userID, id uuid.UUID) (*List, error)
+	// List returns the user's own lists and those shared with them.
+	List(ctx context.Context, userID uuid.UUID) ([]*List, error)
+	// Update renames a list. Only its owner may rename it.
+	Update(ctx context.Context, userID, id uuid.UUID, req UpdateRequest) (*List, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// Export writes the list as plain text or CSV.
+	Export(ctx context.Context, userID, id uuid.UUID, format string) (*recipe.Document, error)
+
+	// AddItem adds an item by hand. Its aisle is guessed from its name
+	// unless given.
+	AddItem(ctx context.Context, userID, id uuid.UUID, req ItemRequest) (*Item, error)
+	// UpdateItem changes the set fields of an item, such as checking it off.
+	UpdateItem(ctx context.Context, userID, id, itemID uuid.UUID, req UpdateItemRequest) (*Item, error)
+	RemoveItem(ctx context.Context, userID, id, itemID uuid.UUID) error
+
+	// Share lets a user, by username, see the list and change its items.
+	Share(ctx context.Context, userID, id uuid.UUID, username string) (*Member, error)
+	// Unshare removes a member. Owners may remove anyone; members may only
+	// leave.
+	Unshare(ctx context.Context, userID, id, memberID uuid.UUID) error
+}
+
+// CreateRequest chooses what a list is made from: the meals of a plan
+// between From and To, which default to the plan's first and last days,
+// and any other recipes. The name defaults to the plan's.
+type CreateRequest struct {
+	Name    string
+	PlanID  *uuid.UUID
+	From    date.Date
+	To      date.Date
+	Recipes []RecipeRequest
+}
+
+// RecipeRequest is a recipe to shop for. Servings default to the recipe's
+// own.
+type RecipeRequest struct {
+	RecipeID uuid.UUID
+	Servings int
+}
+
+// UpdateRequest changes the set fields of a list.
+type UpdateRequest struct {
+	Name *string
+}
+
+// ItemRequest describes an item added by hand.
+type ItemRequest struct {
+	Name   string
+	Amount float64
+	Unit   string
+	Aisle  string
+}
+
+// UpdateItemRequest changes the set fields of an item.
+type UpdateItemRequest struct {
+	Name    *string
+	Amount  *float64
+	Unit    *string
+	Aisle   *string
+	Checked *bool
+}
+
+type service struct {
+	repo    Repository
+	recipes recipe.Service
+	plans   mealplan.Service
+	users   user.Repository
+}
+
+// NewService creates a shopping list service. Recipes and meal plans are
+// read through their services, so lists only draw on what the user may see.
+func NewService(repo Repository, recipes recipe.Service, plans mealplan.Service, users user.Repository) Service {
+	return &service{repo: repo, recipes: recipes, plans: plans, users: users}
+}
+
+func (s *service) Create(ctx context.Context, userID uuid.UUID, req CreateRequest) (*List, error) {
+	if req.PlanID == nil && len(req.Recipes) == 0 {
+		return nil, validator.InvalidField("recipes", "choose a meal plan or recipes to shop for")
+	}
+	if len(req.Recipes) > MaxRecipes {
+		return nil, validator.InvalidField("recipes", fmt.Sprintf("a list is made from at most %d recipes", MaxRecipes))
+	}
+	if !req.From.IsZero() && !req.To.IsZero() && req.To.Before(req.From) {
+		return nil, validator.InvalidField("to", "to must not be before from")
+	}
+
+	var sources []source
+	name := req.Name
+	if req.PlanID != nil {
+		plan, err := s.plans.Get(ctx, userID, *req.PlanID)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(name) == "" {
+			name = plan.Name
+		}
+		for _, e := range plan.Entries {
+			if e.Recipe == nil || (!req.From.IsZero() && e.Date.Before(req.From)) || (!req.To.IsZero() && e.Date.After(req.To)) {
+				continue
+			}
+			sources = append(sources, recipeSource(e.Recipe, e.Servings))
+		}
+	}
+	for i, rr := range req.Recipes {
+		if rr.Servings < 0 || rr.Servings > recipe.MaxServings {
+			return nil, validator.InvalidField(fmt.Sprintf("recipes[%d].servings", i), fmt.Sprintf("servings must be between 1 and %d", recipe.MaxServings))
+		}
+		rec, err := s.recipes.Get(ctx, &userID, rr.RecipeID)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, recipeSource(rec, rr.Servings))
+	}
+	if strings.TrimSpace(name) == "" {
+		name = "Shopping list"
+	}
+	name, err := validator.Text("name", name, 1, MaxNameLength)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	l := &List{
+		ID:        uuid.New(),
+		OwnerID:   userID,
+		Name:      name,
+		PlanID:    req.PlanID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	items := consolidate(sources)
+	for i, it := range items {
+		it.ID, it.ListID, it.Position, it.CreatedAt = uuid.New(), l.ID, i, now
+	}
+	if err := s.repo.Create(ctx, l, items); err != nil {
+		return nil, err
+	}
+	l.Aisles = groupByAisle(items)
+	return l, nil
+}
+
+func (s *service) Get(ctx context.Context, userID, id uuid.UUID) (*List, error) {
+	l, err := s.getVisible(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	l.Aisles = groupByAisle(items)
+	return l, nil
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID) ([]*List, error) {
+	return s.repo.ListForMember(ctx, userID)
+}
+
+func (s *service) Update(ctx context.Context, userID, id uuid.UUID, req UpdateRequest) (*List, error) {
+	l, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		if l.Name, err = validator.Text("name", *req.Name, 1, MaxNameLength); err != nil {
+			return nil, err
+		}
+	}
+	l.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(ctx, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	if _, err := s.getOwned(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) Export(ctx context.Context, userID, id uuid.UUID, format string) (*recipe.Document, error) {
+	l, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return Export(l, format)
+}
+
+func (s *service) AddItem(ctx context.Context, userID, id uuid.UUID, req ItemRequest) (*Item, error) {
+	if _, err := s.getVisible(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	it := &Item{ID: uuid.New(), ListID: id, Recipes: []string{}, Manual: true, CreatedAt: time.Now().UTC()}
+	var err error
+	if it.Name, err = validator.Text("name", req.Name, 1, MaxItemNameLength); err != nil {
+		return nil, err
+	}
+	if it.Amount, err = checkAmount(req.Amount); err != nil {
+		return nil, err
+	}
+	if it.Unit, err = validator.Text("unit", req.Unit, 0, MaxUnitLength); err != nil {
+		return nil, err
+	}
+	if it.Aisle, err = checkAisle(req.Aisle, it.Name); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateItem(ctx, it); err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+func (s *service) UpdateItem(ctx context.Context, userID, id, itemID uuid.UUID, req UpdateItemRequest) (*Item, error) {
+	if _, err := s.getVisible(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	it, err := s.repo.GetItem(ctx, id, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		if it.Name, err = validator.Text("name", *req.Name, 1, MaxItemNameLength); err != nil {
+			return nil, err
+		}
+	}
+	if req.Amount != nil {
+		if it.Amount, err = checkAmount(*req.Amount); err != nil {
+			return nil, err
+		}
+	}
+	if req.Unit != nil {
+		if it.Unit, err = validator.Text("unit", *req.Unit, 0, MaxUnitLength); err != nil {
+			return nil, err
+		}
+	}
+	if req.Aisle != nil {
+		if it.Aisle, err = checkAisle(*req.Aisle, it.Name); err != nil {
+			return nil, err
+		}
+	}
+	if req.Checked != nil {
+		it.Checked = *req.Checked
+	}
+	if err := s.repo.UpdateItem(ctx, it); err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+func (s *service) RemoveItem(ctx context.Context, userID, id, itemID uuid.UUID) error {
+	if _, err := s.getVisible(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteItem(ctx, id, itemID)
+}
+
+func (s *service) Share(ctx context.Context, userID, id uuid.UUID, username string) (*Member, error) {
+	l, err := s.getOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, validator.InvalidField("username", "username is required")
+	}
+	u, err := s.users.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if u.ID == l.OwnerID {
+		return nil, ErrShareWithOwner
+	}
+	m := &Member{UserID: u.ID, Username: u.Username, AddedAt: time.Now().UTC()}
+	if err := s.repo.AddMember(ctx, id, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *service) Unshare(ctx context.Context, userID, id, memberID uuid.UUID) error {
+	l, err := s.getVisible(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if l.OwnerID != userID && memberID != userID {
+		return apperrors.ErrForbidden
+	}
+	return s.repo.RemoveMember(ctx, id, memberID)
+}
+
+// getVisible loads a list the user owns or is a member of. Other lists are
+// reported as missing.
+func (s *service) getVisible(ctx context.Context, userID, id uuid.UUID) (*List, error) {
+	l, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !l.canEdit(userID) {
+		return nil, ErrListNotFound
+	}
+	return l, nil
+}
+
+// getOwned loads a list owned by the user.
+func (s *service) getOwned(ctx context.Context, userID, id uuid.UUID) (*List, error) {
+	l, err := s.getVisible(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if l.OwnerID != userID {
+		return nil, apperrors.ErrForbidden
+	}
+	return l, nil
+}
+
+// recipeSource shops for a recipe scaled to the servings, or as written
+// when either the servings or the recipe's own are unknown.
+func recipeSource(r *recipe.Recipe, servings int) source {
+	factor := 1.0
+	if servings > 0 && r.Servings > 0 {
+		factor = float64(servings) / float64(r.Servings)
+	}
+	return source{title: r.Title, ingredients: r.Ingredients, factor: factor}
+}
+
+func checkAmount(amount float64) (float64, error) {
+	if math.IsNaN(amount) || amount < 0 || amount > MaxAmount {
+		return 0, validator.InvalidField("amount", fmt.Sprintf("amount must be between 0 and %d", MaxAmount))
+	}
+	return amount, nil
+}
+
+// checkAisle checks an aisle name, guessing the aisle from the item's name
+// when none is given.
+func checkAisle(aisle, name string) (string, error) {
+	aisle = strings.ToLower(strings.TrimSpace(aisle))
+	if aisle == "" {
+		return aisleFor(name), nil
+	}
+	if !slices.Contains(Aisles, aisle) {
+		return "", validator.InvalidField("aisle", "aisle must be one of "+strings.Join(Aisles, ", "))
+	}
+	return aisle, nil
+}
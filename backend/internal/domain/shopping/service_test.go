@@ -0,0 +1,281 @@
+package shopping
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/mealplan"
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/recipe/recipetest"
+	"alchemorsel/backend/internal/domain/user"
+	"alchemorsel/backend/internal/domain/user/usertest"
+	"alchemorsel/backend/internal/pkg/date"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+type fakeRepository struct {
+	Repository
+	lists map[uuid.UUID]*List
+	items map[uuid.UUID]*Item
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{lists: map[uuid.UUID]*List{}, items: map[uuid.UUID]*Item{}}
+}
+
+func (f *fakeRepository) Create(ctx context.Context, l *List, items []*Item) error {
+	copy := *l
+	f.lists[l.ID] = &copy
+	for _, it := range items {
+		itemCopy := *it
+		f.items[it.ID] = &itemCopy
+	}
+	return nil
+}
+
+func (f *fakeRepository) GetByID(ctx context.Context, id uuid.UUID) (*List, error) {
+	if l, ok := f.lists[id]; ok {
+		copy := *l
+		return &copy, nil
+	}
+	return nil, ErrListNotFound
+}
+
+func (f *fakeRepository) Update(ctx context.Context, l *List) error {
+	copy := *l
+	f.lists[l.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) ListItems(ctx context.Context, listID uuid.UUID) ([]*Item, error) {
+	items := []*Item{}
+	for _, it := range f.items {
+		if it.ListID == listID {
+			copy := *it
+			items = append(items, &copy)
+		}
+	}
+	sortItems(items)
+	return items, nil
+}
+
+func (f *fakeRepository) GetItem(ctx context.Context, listID, id uuid.UUID) (*Item, error) {
+	if it, ok := f.items[id]; ok && it.ListID == listID {
+		copy := *it
+		return &copy, nil
+	}
+	return nil, ErrItemNotFound
+}
+
+func (f *fakeRepository) CreateItem(ctx context.Context, it *Item) error {
+	copy := *it
+	f.items[it.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) UpdateItem(ctx context.Context, it *Item) error {
+	copy := *it
+	f.items[it.ID] = &copy
+	return nil
+}
+
+func (f *fakeRepository) AddMember(ctx context.Context, listID uuid.UUID, m *Member) error {
+	l := f.lists[listID]
+	if l.isMember(m.UserID) {
+		return ErrAlreadyMember
+	}
+	l.Members = append(l.Members, m)
+	return nil
+}
+
+// fakePlans implements the mealplan.Service methods used by shopping lists.
+type fakePlans struct {
+	mealplan.Service
+	plan *mealplan.Plan
+}
+
+func (f *fakePlans) Get(ctx context.Context, userID, id uuid.UUID) (*mealplan.Plan, error) {
+	if f.plan == nil || f.plan.ID != id || f.plan.UserID != userID {
+		return nil, mealplan.ErrPlanNotFound
+	}
+	return f.plan, nil
+}
+
+func TestConsolidateMergesCompatibleUnits(t *testing.T) {
+	items := consolidate([]source{
+		{title: "Curry", factor: 1, ingredients: []recipe.Ingredient{
+			{Name: "butter", Amount: 2, Unit: "tbsp"},
+			{Name: "onions", Amount: 2},
+			{Name: "garlic", Amount: 2, Unit: "cloves"},
+			{Name: "salt", Unit: "to taste"},
+			{Name: "cilantro", Amount: 1, Unit: "bunch", Optional: true},
+		}},
+		{title: "Cookies", factor: 2, ingredients: []recipe.Ingredient{
+			{Name: "Butter", Amount: 0.125, Unit: "cup"},
+			{Name: "butter", Amount: 50, Unit: "g"},
+			{Name: "onion", Amount: 1},
+			{Name: "garlic", Amount: 1, Unit: "clove"},
+			{Name: "salt", Amount: 1, AmountMax: 2, Unit: "pinch"},
+			{Name: "salt", Amount: 0.5, Unit: "tsp"},
+			{Name: "frozen peas", Amount: 1, AmountMax: 2, Unit: "cups"},
+		}},
+	})
+
+	got := map[string]string{}
+	var order []string
+	for _, it := range items {
+		got[it.describe()] = it.Aisle
+		order = append(order, it.describe())
+	}
+	want := map[string]string{
+		"⅜ cup butter":       AisleDairy,
+		"100 g butter":       AisleDairy,
+		"4 onions":           AisleProduce,
+		"4 cloves garlic":    AisleProduce,
+		"1 tsp salt":         AisleSpices,
+		"4 cups frozen peas": AisleFrozen,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("items = %q, want %d items", order, len(want))
+	}
+	for line, aisle := range want {
+		if got[line] != aisle {
+			t.Fatalf("items = %q, want %q in %s", order, line, aisle)
+		}
+	}
+	if order[0] != "4 cloves garlic" || order[len(order)-1] != "1 tsp salt" {
+		t.Fatalf("items are not sorted by aisle and name: %q", order)
+	}
+	for _, it := range items {
+		if it.Name == "butter" && it.Unit == "cup" && strings.Join(it.Recipes, ",") != "Curry,Cookies" {
+			t.Fatalf("butter recipes = %v", it.Recipes)
+		}
+	}
+}
+
+func TestAisleFor(t *testing.T) {
+	for name, want := range map[string]string{
+		"red bell peppers":  AisleProduce,
+		"black pepper":      AisleSpices,
+		"chicken stock":     AislePantry,
+		"chicken thighs":    AisleMeat,
+		"peanut butter":     AislePantry,
+		"eggplant":          AisleProduce,
+		"large eggs":        AisleDairy,
+		"dragon fruit":      AisleOther,
+		"corn tortillas":    AisleBakery,
+		"vanilla ice cream": AisleFrozen,
+	} {
+		if got := aisleFor(name); got != want {
+			t.Errorf("aisleFor(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCreateFromPlanAndRecipes(t *testing.T) {
+	owner := uuid.New()
+	start := date.New(2024, time.June, 3)
+	curry := &recipe.Recipe{ID: uuid.New(), UserID: owner, Title: "Curry", Servings: 4,
+		Ingredients: []recipe.Ingredient{{Name: "rice", Amount: 2, Unit: "cups"}}}
+	salad := &recipe.Recipe{ID: uuid.New(), UserID: uuid.New(), Title: "Salad", Servings: 2, IsPublic: true,
+		Ingredients: []recipe.Ingredient{{Name: "lettuce", Amount: 1, Unit: "head"}}}
+	hidden := &recipe.Recipe{ID: uuid.New(), UserID: uuid.New(), Title: "Secret"}
+	plan := &mealplan.Plan{ID: uuid.New(), UserID: owner, Name: "June week", StartDate: start, EndDate: start.AddDays(6),
+		Entries: []*mealplan.Entry{
+			{Date: start, Slot: mealplan.SlotDinner, RecipeID: &curry.ID, Servings: 2, Recipe: curry},
+			{Date: start.AddDays(1), Slot: mealplan.SlotLunch, Text: "Leftovers"},
+			{Date: start.AddDays(5), Slot: mealplan.SlotDinner, RecipeID: &curry.ID, Servings: 8, Recipe: curry},
+		}}
+	repo := newFakeRepository()
+	svc := NewService(repo, recipetest.NewRecipes(curry, salad, hidden), &fakePlans{plan: plan}, usertest.NewUsers())
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, owner, CreateRequest{}); err == nil {
+		t.Fatal("expected an error for a list made from nothing")
+	}
+	if _, err := svc.Create(ctx, owner, CreateRequest{Recipes: []RecipeRequest{{RecipeID: hidden.ID}}}); !errors.Is(err, apperrors.ErrRecipeNotFound) {
+		t.Fatalf("expected ErrRecipeNotFound for a hidden recipe, got %v", err)
+	}
+
+	l, err := svc.Create(ctx, owner, CreateRequest{
+		PlanID:  &plan.ID,
+		To:      start.AddDays(2),
+		Recipes: []RecipeRequest{{RecipeID: salad.ID, Servings: 4}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if l.Name != "June week" || len(l.Aisles) != 2 {
+		t.Fatalf("unexpected list %+v", l)
+	}
+	if got := l.Aisles[0].Items[0].describe(); got != "2 head lettuce" {
+		t.Fatalf("produce = %q, want 2 head lettuce", got)
+	}
+	if got := l.Aisles[1].Items[0].describe(); got != "1 cup rice" {
+		t.Fatalf("pantry = %q, want only the first curry's rice", got)
+	}
+}
+
+func TestSharingAndItems(t *testing.T) {
+	owner, member, stranger := uuid.New(), uuid.New(), uuid.New()
+	rec := &recipe.Recipe{ID: uuid.New(), UserID: owner, Title: "Pancakes", Servings: 2,
+		Ingredients: []recipe.Ingredient{{Name: "milk", Amount: 1, Unit: "cup"}, {Name: "eggs", Amount: 2}}}
+	users := usertest.NewUsers(&user.User{ID: owner, Username: "owner"}, &user.User{ID: member, Username: "member"})
+	svc := NewService(newFakeRepository(), recipetest.NewRecipes(rec), &fakePlans{}, users)
+	ctx := context.Background()
+
+	l, err := svc.Create(ctx, owner, CreateRequest{Name: "Brunch", Recipes: []RecipeRequest{{RecipeID: rec.ID}}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.Get(ctx, stranger, l.ID); err != ErrListNotFound {
+		t.Fatalf("expected ErrListNotFound for a stranger, got %v", err)
+	}
+	if _, err := svc.Share(ctx, owner, l.ID, "owner"); err != ErrShareWithOwner {
+		t.Fatalf("expected ErrShareWithOwner, got %v", err)
+	}
+	if _, err := svc.Share(ctx, owner, l.ID, "member"); err != nil {
+		t.Fatalf("share: %v", err)
+	}
+
+	// Members check items off and add their own, but only owners rename.
+	eggs := l.Aisles[0].Items[0]
+	checked := true
+	if _, err := svc.UpdateItem(ctx, member, l.ID, eggs.ID, UpdateItemRequest{Checked: &checked}); err != nil {
+		t.Fatalf("check off: %v", err)
+	}
+	if _, err := svc.AddItem(ctx, member, l.ID, ItemRequest{Name: "Bananas", Amount: 6}); err != nil {
+		t.Fatalf("add item: %v", err)
+	}
+	if _, err := svc.AddItem(ctx, member, l.ID, ItemRequest{Name: "Napkins", Aisle: "garden"}); err == nil {
+		t.Fatal("expected an error for an unknown aisle")
+	}
+	name := "Sunday brunch"
+	if _, err := svc.Update(ctx, member, l.ID, UpdateRequest{Name: &name}); err != apperrors.ErrForbidden {
+		t.Fatalf("expected ErrForbidden for a member renaming, got %v", err)
+	}
+
+	doc, err := svc.Export(ctx, member, l.ID, FormatText)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	want := "Brunch\n\nProduce\n[ ] 6 Bananas\n\nDairy & eggs\n[x] 2 eggs (Pancakes)\n[ ] 1 cup milk (Pancakes)\n"
+	if string(doc.Data) != want || doc.Filename != "brunch.txt" {
+		t.Fatalf("text export %s =\n%s", doc.Filename, doc.Data)
+	}
+	doc, err = svc.Export(ctx, owner, l.ID, FormatCSV)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(doc.Data)), "\n"); len(lines) != 4 || lines[2] != "dairy & eggs,eggs,2,,true,Pancakes" {
+		t.Fatalf("csv export =\n%s", doc.Data)
+	}
+	if _, err := svc.Export(ctx, owner, l.ID, "pdf"); err == nil {
+		t.Fatal("expected an error for an unsupported format")
+	}
+}
@@ -16,6 +16,7 @@ type User struct {
 	ProfilePictureURL  *string    `json:"profile_picture_url,omitempty"`
 	DietaryPreferences []string   `json:"dietary_preferences"`
 	Allergies          []string   `json:"allergies"`
+	UnitSystem         string     `json:"unit_system,omitempty"` // "metric", "us", "uk" or empty for recipes as written
 	CreatedAt          time.Time  `json:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at"`
 	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
@@ -23,6 +23,7 @@ type RegisterRequest struct {
 	Name               string
 	DietaryPreferences []string
 	Allergies          []string
+	UnitSystem         string
 }
 
 // UpdateRequest represents profile update data.
@@ -30,4 +31,5 @@ type UpdateRequest struct {
 	Name               string
 	DietaryPreferences []string
 	Allergies          []string
+	UnitSystem         string
 }
@@ -0,0 +1,42 @@
+// Package usertest provides an in-memory user repository for testing the
+// services that look users up.
+package usertest
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// Users implements the lookups of user.Repository over a fixed list of
+// users. Calls to any other method panic through the nil embedded interface.
+type Users struct {
+	user.Repository
+	Users []*user.User
+}
+
+// NewUsers returns a repository holding the given users.
+func NewUsers(users ...*user.User) *Users {
+	return &Users{Users: users}
+}
+
+func (f *Users) GetByID(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	for _, u := range f.Users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, apperrors.ErrUserNotFound
+}
+
+func (f *Users) GetByUsername(ctx context.Context, username string) (*user.User, error) {
+	for _, u := range f.Users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return nil, apperrors.ErrUserNotFound
+}
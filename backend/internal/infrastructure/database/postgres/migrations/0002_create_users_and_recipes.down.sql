@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS recipe_favorites;
+DROP TABLE IF EXISTS recipes;
+DROP TABLE IF EXISTS users;
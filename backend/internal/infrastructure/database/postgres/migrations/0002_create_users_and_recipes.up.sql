@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    username VARCHAR(50) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    profile_picture_url TEXT,
+    dietary_preferences TEXT[] NOT NULL DEFAULT '{}',
+    allergies TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS recipes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    ingredients JSONB NOT NULL DEFAULT '[]',
+    instructions TEXT[] NOT NULL DEFAULT '{}',
+    prep_time INTEGER NOT NULL DEFAULT 0,
+    cook_time INTEGER NOT NULL DEFAULT 0,
+    servings INTEGER NOT NULL DEFAULT 0,
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    dietary_categories TEXT[] NOT NULL DEFAULT '{}',
+    allergens TEXT[] NOT NULL DEFAULT '{}',
+    nutritional_info JSONB NOT NULL DEFAULT '{}',
+    image_url TEXT,
+    is_public BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipes_user_id ON recipes(user_id);
+
+CREATE TABLE IF NOT EXISTS recipe_favorites (
+    user_id UUID NOT NULL REFERENCES users(id),
+    recipe_id UUID NOT NULL REFERENCES recipes(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, recipe_id)
+);
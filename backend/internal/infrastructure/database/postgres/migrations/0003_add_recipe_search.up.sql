@@ -0,0 +1,25 @@
+-- Weighted full-text index: title (A) > description (B) > ingredient names (C) > instructions (D).
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS search_language REGCONFIG NOT NULL DEFAULT 'english';
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+CREATE OR REPLACE FUNCTION recipes_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector(NEW.search_language, coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector(NEW.search_language, coalesce(NEW.description, '')), 'B') ||
+        setweight(to_tsvector(NEW.search_language, coalesce(
+            (SELECT string_agg(i->>'name', ' ') FROM jsonb_array_elements(NEW.ingredients) AS i), '')), 'C') ||
+        setweight(to_tsvector(NEW.search_language, coalesce(array_to_string(NEW.instructions, ' '), '')), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS recipes_search_vector_trigger ON recipes;
+CREATE TRIGGER recipes_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, description, ingredients, instructions, search_language ON recipes
+    FOR EACH ROW EXECUTE FUNCTION recipes_search_vector_update();
+
+-- Backfill rows created before the trigger existed.
+UPDATE recipes SET title = title;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_search_vector ON recipes USING GIN (search_vector);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS recipe_embeddings (
+    recipe_id UUID PRIMARY KEY REFERENCES recipes(id) ON DELETE CASCADE,
+    embedding DOUBLE PRECISION[] NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- When pgvector is installed, mirror embeddings into an indexed vector column.
+-- Without it the application falls back to scoring embeddings in Go.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+        CREATE EXTENSION IF NOT EXISTS vector;
+        ALTER TABLE recipe_embeddings ADD COLUMN IF NOT EXISTS embedding_vector vector(1536);
+        CREATE INDEX IF NOT EXISTS idx_recipe_embeddings_vector
+            ON recipe_embeddings USING hnsw (embedding_vector vector_cosine_ops);
+    END IF;
+END
+$$;
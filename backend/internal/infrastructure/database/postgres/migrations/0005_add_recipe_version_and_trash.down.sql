@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_recipes_trash;
+ALTER TABLE recipes DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE recipes DROP COLUMN IF EXISTS version;
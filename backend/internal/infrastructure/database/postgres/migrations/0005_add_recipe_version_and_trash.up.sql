@@ -0,0 +1,4 @@
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_trash ON recipes(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,46 @@
+CREATE TABLE IF NOT EXISTS recipe_revisions (
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    author_id UUID NOT NULL REFERENCES users(id),
+    snapshot JSONB NOT NULL,
+    reverted_from INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (recipe_id, version)
+);
+
+-- Revisions are immutable once written.
+CREATE OR REPLACE FUNCTION recipe_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'recipe revisions are immutable';
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS recipe_revisions_no_update ON recipe_revisions;
+CREATE TRIGGER recipe_revisions_no_update
+    BEFORE UPDATE ON recipe_revisions
+    FOR EACH ROW EXECUTE FUNCTION recipe_revisions_immutable();
+
+-- Seed the history with the current state of existing recipes.
+INSERT INTO recipe_revisions (recipe_id, version, author_id, snapshot, created_at)
+SELECT id, version, user_id, jsonb_build_object(
+        'id', id,
+        'user_id', user_id,
+        'title', title,
+        'description', description,
+        'ingredients', ingredients,
+        'instructions', to_jsonb(instructions),
+        'prep_time', prep_time,
+        'cook_time', cook_time,
+        'servings', servings,
+        'category', category,
+        'dietary_categories', to_jsonb(dietary_categories),
+        'allergens', to_jsonb(allergens),
+        'nutritional_info', nutritional_info,
+        'image_url', image_url,
+        'is_public', is_public,
+        'version', version,
+        'created_at', created_at,
+        'updated_at', updated_at
+    ), updated_at
+FROM recipes
+ON CONFLICT (recipe_id, version) DO NOTHING;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_recipes_forked_from;
+ALTER TABLE recipes DROP COLUMN IF EXISTS forked_from;
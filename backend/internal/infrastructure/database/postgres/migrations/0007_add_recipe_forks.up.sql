@@ -0,0 +1,7 @@
+-- forked_from holds the attribution of the recipe a fork was copied from. It
+-- is a snapshot rather than a foreign key so that it survives the parent being
+-- made private or removed.
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS forked_from JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_forked_from ON recipes(((forked_from->>'recipe_id')::uuid))
+    WHERE forked_from IS NOT NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_recipes_rating;
+DROP TABLE IF EXISTS review_helpful_votes;
+DROP TABLE IF EXISTS recipe_reviews;
+ALTER TABLE recipes DROP COLUMN IF EXISTS rating_count;
+ALTER TABLE recipes DROP COLUMN IF EXISTS rating_average;
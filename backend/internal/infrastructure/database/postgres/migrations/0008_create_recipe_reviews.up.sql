@@ -0,0 +1,26 @@
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS rating_average DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS recipe_reviews (
+    id UUID PRIMARY KEY,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    body TEXT NOT NULL DEFAULT '',
+    made_it BOOLEAN NOT NULL DEFAULT false,
+    helpful_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (recipe_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_reviews_recipe_helpful ON recipe_reviews(recipe_id, helpful_count DESC, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS review_helpful_votes (
+    review_id UUID NOT NULL REFERENCES recipe_reviews(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (review_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipes_rating ON recipes(rating_average DESC, rating_count DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS recipe_comments (
+    id UUID PRIMARY KEY,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES recipe_comments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    body TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    edited_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_comments_thread ON recipe_comments(recipe_id, created_at DESC, id DESC)
+    WHERE parent_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_recipe_comments_replies ON recipe_comments(parent_id, created_at, id)
+    WHERE parent_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS comment_reports (
+    id UUID PRIMARY KEY,
+    comment_id UUID NOT NULL REFERENCES recipe_comments(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id),
+    reason VARCHAR(50) NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolved_by UUID REFERENCES users(id),
+    UNIQUE (comment_id, reporter_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_reports_queue ON comment_reports(status, created_at, id);
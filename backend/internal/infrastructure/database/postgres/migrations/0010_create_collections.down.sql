@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS collection_collaborators;
+DROP TABLE IF EXISTS collection_recipes;
+DROP TABLE IF EXISTS collections;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_recipes_tags;
+ALTER TABLE recipes DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,4 @@
+-- Free-form user tags, stored normalized (lowercase words joined by hyphens).
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_recipes_tags ON recipes USING GIN (tags);
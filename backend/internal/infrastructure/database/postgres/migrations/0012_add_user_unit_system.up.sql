@@ -0,0 +1,3 @@
+-- Preferred unit system for displaying recipes; empty shows them as written.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS unit_system VARCHAR(10) NOT NULL DEFAULT ''
+    CHECK (unit_system IN ('', 'metric', 'us', 'uk'));
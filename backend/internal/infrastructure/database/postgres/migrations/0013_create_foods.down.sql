@@ -0,0 +1,2 @@
+ALTER TABLE recipes DROP COLUMN IF EXISTS nutrition_estimate;
+DROP TABLE IF EXISTS foods;
@@ -0,0 +1,21 @@
+-- Nutrient database imported from USDA FoodData Central. Nutrients are per
+-- 100 g of food: calories in kcal, sodium in milligrams, the rest in grams.
+CREATE TABLE IF NOT EXISTS foods (
+    id BIGINT PRIMARY KEY, -- FoodData Central fdc_id
+    description TEXT NOT NULL,
+    calories REAL NOT NULL DEFAULT 0,
+    protein REAL NOT NULL DEFAULT 0,
+    carbohydrates REAL NOT NULL DEFAULT 0,
+    fat REAL NOT NULL DEFAULT 0,
+    fiber REAL NOT NULL DEFAULT 0,
+    sugar REAL NOT NULL DEFAULT 0,
+    sodium REAL NOT NULL DEFAULT 0,
+    -- Weight of one piece, such as one large egg; 0 when unknown.
+    portion_grams REAL NOT NULL DEFAULT 0,
+    search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('english', description)) STORED
+);
+
+CREATE INDEX IF NOT EXISTS idx_foods_search_vector ON foods USING GIN (search_vector);
+
+-- How the recipe's nutritional information was estimated from the foods.
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS nutrition_estimate JSONB;
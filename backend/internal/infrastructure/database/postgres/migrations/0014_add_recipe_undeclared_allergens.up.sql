@@ -0,0 +1,3 @@
+-- Allergens detected in the ingredients that the author did not declare.
+-- They are also stored in allergens so that filtering covers them.
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS undeclared_allergens TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,21 @@
+-- Photo galleries of recipes. Variants lists the scaled copies of each
+-- image with their storage keys; key locates a file kept as uploaded.
+CREATE TABLE IF NOT EXISTS recipe_images (
+    id UUID PRIMARY KEY,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    is_cover BOOLEAN NOT NULL DEFAULT false,
+    alt_text TEXT NOT NULL DEFAULT '',
+    step INTEGER,
+    content_type VARCHAR(50) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    key TEXT NOT NULL DEFAULT '',
+    variants JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_images_recipe ON recipe_images(recipe_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recipe_images_cover ON recipe_images(recipe_id) WHERE is_cover;
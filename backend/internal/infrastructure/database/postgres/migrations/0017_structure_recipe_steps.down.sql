@@ -0,0 +1,25 @@
+DROP TRIGGER IF EXISTS recipes_search_vector_trigger ON recipes;
+
+ALTER TABLE recipes ADD COLUMN texts TEXT[] NOT NULL DEFAULT '{}';
+UPDATE recipes SET texts = ARRAY(
+    SELECT s->>'text' FROM jsonb_array_elements(instructions) WITH ORDINALITY AS t(s, n) ORDER BY n);
+ALTER TABLE recipes DROP COLUMN instructions;
+ALTER TABLE recipes RENAME COLUMN texts TO instructions;
+
+CREATE OR REPLACE FUNCTION recipes_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector(NEW.search_language, coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector(NEW.search_language, coalesce(NEW.description, '')), 'B') ||
+        setweight(to_tsvector(NEW.search_language, coalesce(
+            (SELECT string_agg(i->>'name', ' ') FROM jsonb_array_elements(NEW.ingredients) AS i), '')), 'C') ||
+        setweight(to_tsvector(NEW.search_language, coalesce(array_to_string(NEW.instructions, ' '), '')), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER recipes_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, description, ingredients, instructions, search_language ON recipes
+    FOR EACH ROW EXECUTE FUNCTION recipes_search_vector_update();
+
+DROP FUNCTION IF EXISTS recipe_steps_text(JSONB);
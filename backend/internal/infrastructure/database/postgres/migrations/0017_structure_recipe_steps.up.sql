@@ -0,0 +1,33 @@
+-- Instructions become a JSONB list of step objects with optional section,
+-- duration, passive flag, temperature and ingredient references. Existing
+-- steps keep their text. Revision snapshots still hold plain strings, which
+-- the application reads as steps with only text.
+CREATE OR REPLACE FUNCTION recipe_steps_text(steps JSONB) RETURNS TEXT AS $$
+    SELECT coalesce(string_agg(concat_ws(' ', s->>'section', s->>'text'), ' ' ORDER BY n), '')
+    FROM jsonb_array_elements(steps) WITH ORDINALITY AS t(s, n)
+$$ LANGUAGE sql IMMUTABLE;
+
+DROP TRIGGER IF EXISTS recipes_search_vector_trigger ON recipes;
+
+ALTER TABLE recipes ADD COLUMN steps JSONB NOT NULL DEFAULT '[]';
+UPDATE recipes SET steps = (
+    SELECT coalesce(jsonb_agg(jsonb_build_object('text', t) ORDER BY n), '[]')
+    FROM unnest(instructions) WITH ORDINALITY AS u(t, n));
+ALTER TABLE recipes DROP COLUMN instructions;
+ALTER TABLE recipes RENAME COLUMN steps TO instructions;
+
+CREATE OR REPLACE FUNCTION recipes_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector(NEW.search_language, coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector(NEW.search_language, coalesce(NEW.description, '')), 'B') ||
+        setweight(to_tsvector(NEW.search_language, coalesce(
+            (SELECT string_agg(i->>'name', ' ') FROM jsonb_array_elements(NEW.ingredients) AS i), '')), 'C') ||
+        setweight(to_tsvector(NEW.search_language, recipe_steps_text(NEW.instructions)), 'D');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER recipes_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, description, ingredients, instructions, search_language ON recipes
+    FOR EACH ROW EXECUTE FUNCTION recipes_search_vector_update();
@@ -0,0 +1,29 @@
+-- Meal plans cover a range of days. Each entry plans a meal in a slot of a
+-- day: a recipe with the servings to cook, or free text when recipe_id is
+-- null.
+CREATE TABLE IF NOT EXISTS meal_plans (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_meal_plans_user ON meal_plans(user_id, start_date DESC);
+
+CREATE TABLE IF NOT EXISTS meal_plan_entries (
+    id UUID PRIMARY KEY,
+    plan_id UUID NOT NULL REFERENCES meal_plans(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    slot VARCHAR(20) NOT NULL,
+    position INTEGER NOT NULL,
+    recipe_id UUID REFERENCES recipes(id) ON DELETE CASCADE,
+    servings INTEGER NOT NULL DEFAULT 0,
+    text TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_meal_plan_entries_plan ON meal_plan_entries(plan_id, date);
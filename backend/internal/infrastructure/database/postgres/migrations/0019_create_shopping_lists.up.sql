@@ -0,0 +1,38 @@
+-- Shopping lists made from meal plans or recipes. Items generated from
+-- recipes name them in recipes; manual items were added by hand. Members
+-- are the users a list is shared with.
+CREATE TABLE IF NOT EXISTS shopping_lists (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    plan_id UUID REFERENCES meal_plans(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shopping_lists_owner ON shopping_lists(owner_id, updated_at DESC);
+
+CREATE TABLE IF NOT EXISTS shopping_list_items (
+    id UUID PRIMARY KEY,
+    list_id UUID NOT NULL REFERENCES shopping_lists(id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL,
+    amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+    unit VARCHAR(30) NOT NULL DEFAULT '',
+    aisle VARCHAR(30) NOT NULL,
+    recipes TEXT[] NOT NULL DEFAULT '{}',
+    manual BOOLEAN NOT NULL DEFAULT false,
+    checked BOOLEAN NOT NULL DEFAULT false,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_shopping_list_items_list ON shopping_list_items(list_id, position);
+
+CREATE TABLE IF NOT EXISTS shopping_list_members (
+    list_id UUID NOT NULL REFERENCES shopping_lists(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shopping_list_members_user ON shopping_list_members(user_id);
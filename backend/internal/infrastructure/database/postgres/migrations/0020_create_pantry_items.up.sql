@@ -0,0 +1,14 @@
+-- Ingredients users have at home. An amount of 0 means the quantity was
+-- not recorded, and a NULL expires_on means the item does not expire.
+CREATE TABLE IF NOT EXISTS pantry_items (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(200) NOT NULL,
+    amount DOUBLE PRECISION NOT NULL DEFAULT 0,
+    unit VARCHAR(30) NOT NULL DEFAULT '',
+    expires_on DATE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pantry_items_user ON pantry_items(user_id, name);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_recipe_reviews_user;
+DROP INDEX IF EXISTS idx_recipe_favorites_user;
+DROP TABLE IF EXISTS recommendations;
+DROP TABLE IF EXISTS recipe_generations;
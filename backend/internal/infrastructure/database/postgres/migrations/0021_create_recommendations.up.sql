@@ -0,0 +1,26 @@
+-- Recipes generated for a user and what they asked for, kept as history
+-- that informs recommendations.
+CREATE TABLE IF NOT EXISTS recipe_generations (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    style TEXT NOT NULL DEFAULT '',
+    ingredients TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_generations_user ON recipe_generations(user_id, created_at DESC);
+
+-- Each user's latest recommendations, computed in the background. Entries
+-- hold the recommended recipe ids in order with their scores and
+-- explanations.
+CREATE TABLE IF NOT EXISTS recommendations (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    entries JSONB NOT NULL DEFAULT '[]',
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recommendations_computed ON recommendations(computed_at);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_favorites_user ON recipe_favorites(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_recipe_reviews_user ON recipe_reviews(user_id, updated_at DESC);
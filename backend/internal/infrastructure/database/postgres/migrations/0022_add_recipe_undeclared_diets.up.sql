@@ -0,0 +1,3 @@
+-- Diets the classifier found the recipe fits that the author did not declare.
+-- They are also stored in dietary_categories so that filtering covers them.
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS undeclared_diets TEXT[] NOT NULL DEFAULT '{}';
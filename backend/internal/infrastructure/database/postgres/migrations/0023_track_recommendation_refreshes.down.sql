@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS recipe_reviews_removed ON recipe_reviews;
+DROP TRIGGER IF EXISTS recipe_favorites_removed ON recipe_favorites;
+DROP FUNCTION IF EXISTS recommendations_mark_stale();
+ALTER TABLE recommendations DROP COLUMN IF EXISTS failed_at;
+ALTER TABLE recommendations DROP COLUMN IF EXISTS stale;
@@ -0,0 +1,24 @@
+-- Removing a favorite or a review leaves no newer row to compare with the
+-- time recommendations were computed, so the removal marks them stale
+-- instead. failed_at is the time of the last failed refresh, so that users
+-- whose refresh keeps failing are retried later rather than at the head of
+-- every batch.
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS stale BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE recommendations ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE OR REPLACE FUNCTION recommendations_mark_stale() RETURNS trigger AS $$
+BEGIN
+    UPDATE recommendations SET stale = TRUE WHERE user_id = OLD.user_id;
+    RETURN OLD;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS recipe_favorites_removed ON recipe_favorites;
+CREATE TRIGGER recipe_favorites_removed
+    AFTER DELETE ON recipe_favorites
+    FOR EACH ROW EXECUTE FUNCTION recommendations_mark_stale();
+
+DROP TRIGGER IF EXISTS recipe_reviews_removed ON recipe_reviews;
+CREATE TRIGGER recipe_reviews_removed
+    AFTER DELETE ON recipe_reviews
+    FOR EACH ROW EXECUTE FUNCTION recommendations_mark_stale();
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"alchemorsel/backend/internal/domain/collection"
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+)
+
+const collectionColumns = `k.id, k.owner_id, k.name, k.description, k.cover_image_url, k.is_public,
+	k.created_at, k.updated_at,
+	(SELECT count(*) FROM collection_recipes cr JOIN recipes r ON r.id = cr.recipe_id
+		WHERE cr.collection_id = k.id AND r.deleted_at IS NULL)`
+
+type collectionRepository struct {
+	db *postgres.DB
+}
+
+// NewCollectionRepository returns a PostgreSQL backed collection repository.
+func NewCollectionRepository(db *postgres.DB) collection.Repository {
+	return &collectionRepository{db: db}
+}
+
+func (r *collectionRepository) Create(ctx context.Context, c *collection.Collection) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO collections (id, owner_id, name, description, cover_image_url, is_public, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		c.ID, c.OwnerID, c.Name, c.Description, c.CoverImageURL, c.IsPublic, c.CreatedAt, c.UpdatedAt)
+	return err
+}
+
+func (r *collectionRepository) GetByID(ctx context.Context, id uuid.UUID) (*collection.Collection, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+collectionColumns+` FROM collections k WHERE k.id = $1`, id)
+	c, err := scanCollection(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, collection.ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT cc.user_id, u.username, cc.invited_by, cc.status, cc.created_at, cc.accepted_at
+		FROM collection_collaborators cc JOIN users u ON u.id = cc.user_id
+		WHERE cc.collection_id = $1
+		ORDER BY cc.created_at, cc.user_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		cb := &collection.Collaborator{}
+		if err := rows.Scan(&cb.UserID, &cb.Username, &cb.InvitedBy, &cb.Status, &cb.CreatedAt, &cb.AcceptedAt); err != nil {
+			return nil, err
+		}
+		c.Collaborators = append(c.Collaborators, cb)
+	}
+	return c, rows.Err()
+}
+
+func (r *collectionRepository) Update(ctx context.Context, c *collection.Collection) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE collections SET name = $2, description = $3, cover_image_url = $4, is_public = $5, updated_at = $6
+		WHERE id = $1`,
+		c.ID, c.Name, c.Description, c.CoverImageURL, c.IsPublic, c.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, collection.ErrCollectionNotFound)
+}
+
+func (r *collectionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM collections WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, collection.ErrCollectionNotFound)
+}
+
+func (r *collectionRepository) ListForMember(ctx context.Context, userID uuid.UUID) ([]*collection.Collection, error) {
+	return r.list(ctx, `
+		SELECT `+collectionColumns+` FROM collections k
+		WHERE k.owner_id = $1 OR EXISTS (
+			SELECT 1 FROM collection_collaborators cc
+			WHERE cc.collection_id = k.id AND cc.user_id = $1 AND cc.status = 'accepted'
+		)
+		ORDER BY k.updated_at DESC, k.id`, userID)
+}
+
+func (r *collectionRepository) ListPublic(ctx context.Context, ownerID uuid.UUID) ([]*collection.Collection, error) {
+	return r.list(ctx, `
+		SELECT `+collectionColumns+` FROM collections k
+		WHERE k.owner_id = $1 AND k.is_public
+		ORDER BY k.updated_at DESC, k.id`, ownerID)
+}
+
+func (r *collectionRepository) ListInvitations(ctx context.Context, userID uuid.UUID) ([]*collection.Collection, error) {
+	return r.list(ctx, `
+		SELECT `+collectionColumns+` FROM collections k
+		JOIN collection_collaborators cc ON cc.collection_id = k.id
+		WHERE cc.user_id = $1 AND cc.status = 'pending'
+		ORDER BY cc.created_at DESC, k.id`, userID)
+}
+
+func (r *collectionRepository) list(ctx context.Context, query string, args ...any) ([]*collection.Collection, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []*collection.Collection{}
+	for rows.Next() {
+		c, err := scanCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+	return collections, rows.Err()
+}
+
+func (r *collectionRepository) ListRecipes(ctx context.Context, collectionID uuid.UUID, viewerID *uuid.UUID) ([]*recipe.Recipe, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+recipeColumns+`
+		FROM collection_recipes cr JOIN recipes r ON r.id = cr.recipe_id
+		WHERE cr.collection_id = $1 AND r.deleted_at IS NULL AND (r.is_public OR r.user_id = $2)
+		ORDER BY cr.position, cr.added_at`, collectionID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipes := []*recipe.Recipe{}
+	for rows.Next() {
+		rec, err := scanRecipe(rows)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, rec)
+	}
+	return recipes, rows.Err()
+}
+
+func (r *collectionRepository) AddRecipe(ctx context.Context, collectionID, recipeID, addedBy uuid.UUID, position int) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockCollection(ctx, tx, collectionID); err != nil {
+			return err
+		}
+		var count int
+		if err := tx.QueryRowContext(ctx, `SELECT count(*) FROM collection_recipes WHERE collection_id = $1`, collectionID).Scan(&count); err != nil {
+			return err
+		}
+		if position < 0 || position > count {
+			position = count
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE collection_recipes SET position = position + 1
+			WHERE collection_id = $1 AND position >= $2`, collectionID, position); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO collection_recipes (collection_id, recipe_id, position, added_by)
+			VALUES ($1, $2, $3, $4)`, collectionID, recipeID, position, addedBy)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return collection.ErrRecipeAlreadyAdded
+		}
+		if err != nil {
+			return err
+		}
+		return touchCollection(ctx, tx, collectionID)
+	})
+}
+
+func (r *collectionRepository) RemoveRecipe(ctx context.Context, collectionID, recipeID uuid.UUID) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockCollection(ctx, tx, collectionID); err != nil {
+			return err
+		}
+		var position int
+		err := tx.QueryRowContext(ctx, `
+			DELETE FROM collection_recipes WHERE collection_id = $1 AND recipe_id = $2
+			RETURNING position`, collectionID, recipeID).Scan(&position)
+		if errors.Is(err, sql.ErrNoRows) {
+			return collection.ErrRecipeNotInCollection
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE collection_recipes SET position = position - 1
+			WHERE collection_id = $1 AND position > $2`, collectionID, position); err != nil {
+			return err
+		}
+		return touchCollection(ctx, tx, collectionID)
+	})
+}
+
+func (r *collectionRepository) ReorderRecipes(ctx context.Context, collectionID uuid.UUID, recipeIDs []uuid.UUID) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockCollection(ctx, tx, collectionID); err != nil {
+			return err
+		}
+		var found int
+		if err := tx.QueryRowContext(ctx, `
+			SELECT count(*) FROM collection_recipes WHERE collection_id = $1 AND recipe_id = ANY($2)`,
+			collectionID, pq.Array(recipeIDs)).Scan(&found); err != nil {
+			return err
+		}
+		if found != len(recipeIDs) {
+			return collection.ErrOrderMismatch
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE collection_recipes cr SET position = o.position
+			FROM (
+				SELECT c.recipe_id, row_number() OVER (ORDER BY n.ord NULLS LAST, c.position, c.added_at) - 1 AS position
+				FROM collection_recipes c
+				LEFT JOIN unnest($2::uuid[]) WITH ORDINALITY AS n(recipe_id, ord) ON n.recipe_id = c.recipe_id
+				WHERE c.collection_id = $1
+			) o
+			WHERE cr.collection_id = $1 AND cr.recipe_id = o.recipe_id`,
+			collectionID, pq.Array(recipeIDs)); err != nil {
+			return err
+		}
+		return touchCollection(ctx, tx, collectionID)
+	})
+}
+
+func (r *collectionRepository) AddCollaborator(ctx context.Context, collectionID uuid.UUID, cb *collection.Collaborator) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO collection_collaborators (collection_id, user_id, invited_by, status, created_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		collectionID, cb.UserID, cb.InvitedBy, cb.Status, cb.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return collection.ErrAlreadyCollaborator
+	}
+	return err
+}
+
+func (r *collectionRepository) AcceptInvitation(ctx context.Context, collectionID, userID uuid.UUID, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE collection_collaborators SET status = 'accepted', accepted_at = $3
+		WHERE collection_id = $1 AND user_id = $2 AND status = 'pending'`, collectionID, userID, at)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, collection.ErrCollaboratorNotFound)
+}
+
+func (r *collectionRepository) RemoveCollaborator(ctx context.Context, collectionID, userID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM collection_collaborators WHERE collection_id = $1 AND user_id = $2`, collectionID, userID)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, collection.ErrCollaboratorNotFound)
+}
+
+// lockCollection locks the collection row so that concurrent edits to its
+// recipe order are applied one at a time.
+func lockCollection(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	var locked uuid.UUID
+	err := tx.QueryRowContext(ctx, `SELECT id FROM collections WHERE id = $1 FOR UPDATE`, id).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return collection.ErrCollectionNotFound
+	}
+	return err
+}
+
+func touchCollection(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `UPDATE collections SET updated_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+func scanCollection(s scanner) (*collection.Collection, error) {
+	c := &collection.Collection{}
+	err := s.Scan(&c.ID, &c.OwnerID, &c.Name, &c.Description, &c.CoverImageURL, &c.IsPublic,
+		&c.CreatedAt, &c.UpdatedAt, &c.RecipeCount)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
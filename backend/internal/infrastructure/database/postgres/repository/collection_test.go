@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/collection"
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+func TestCollectionRepository(t *testing.T) {
+	db := setupTestDB(t)
+	recipes := NewRecipeRepository(db)
+	repo := NewCollectionRepository(db)
+	ctx := context.Background()
+	owner, friend := createTestUser(t, db), createTestUser(t, db)
+
+	var recs []*recipe.Recipe
+	for _, title := range []string{"Tacos", "Pasta", "Curry"} {
+		rec := newTestRecipe(owner, title)
+		if err := recipes.Create(ctx, rec); err != nil {
+			t.Fatalf("create recipe: %v", err)
+		}
+		recs = append(recs, rec)
+	}
+	secret := newTestRecipe(owner, "Secret")
+	secret.IsPublic = false
+	if err := recipes.Create(ctx, secret); err != nil {
+		t.Fatalf("create recipe: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	c := &collection.Collection{ID: uuid.New(), OwnerID: owner, Name: "Weeknight dinners", CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(ctx, c); err != nil {
+		t.Fatalf("create collection: %v", err)
+	}
+
+	// Appending Tacos, Pasta and Secret, then inserting Curry first, gives
+	// Curry, Tacos, Pasta, Secret.
+	for _, id := range []uuid.UUID{recs[0].ID, recs[1].ID, secret.ID} {
+		if err := repo.AddRecipe(ctx, c.ID, id, owner, -1); err != nil {
+			t.Fatalf("add recipe: %v", err)
+		}
+	}
+	if err := repo.AddRecipe(ctx, c.ID, recs[2].ID, owner, 0); err != nil {
+		t.Fatalf("insert recipe: %v", err)
+	}
+	if err := repo.AddRecipe(ctx, c.ID, recs[2].ID, owner, -1); err != collection.ErrRecipeAlreadyAdded {
+		t.Fatalf("expected duplicate recipe to fail, got %v", err)
+	}
+	assertTitles(t, repo, c.ID, &owner, "Curry", "Tacos", "Pasta", "Secret")
+	assertTitles(t, repo, c.ID, &friend, "Curry", "Tacos", "Pasta")
+
+	// A collaborator who cannot see Secret reorders the rest; Secret stays last.
+	if err := repo.ReorderRecipes(ctx, c.ID, []uuid.UUID{recs[1].ID, recs[0].ID, recs[2].ID}); err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+	assertTitles(t, repo, c.ID, &owner, "Pasta", "Tacos", "Curry", "Secret")
+	if err := repo.ReorderRecipes(ctx, c.ID, []uuid.UUID{uuid.New()}); err != collection.ErrOrderMismatch {
+		t.Fatalf("expected unknown recipe to fail, got %v", err)
+	}
+
+	if err := repo.RemoveRecipe(ctx, c.ID, recs[0].ID); err != nil {
+		t.Fatalf("remove recipe: %v", err)
+	}
+	if err := repo.RemoveRecipe(ctx, c.ID, recs[0].ID); err != collection.ErrRecipeNotInCollection {
+		t.Fatalf("expected second removal to fail, got %v", err)
+	}
+	if err := repo.AddRecipe(ctx, c.ID, recs[0].ID, owner, 1); err != nil {
+		t.Fatalf("re-add recipe: %v", err)
+	}
+	assertTitles(t, repo, c.ID, &owner, "Pasta", "Tacos", "Curry", "Secret")
+
+	cb := &collection.Collaborator{UserID: friend, InvitedBy: owner, Status: collection.StatusPending, CreatedAt: now}
+	if err := repo.AddCollaborator(ctx, c.ID, cb); err != nil {
+		t.Fatalf("add collaborator: %v", err)
+	}
+	if err := repo.AddCollaborator(ctx, c.ID, cb); err != collection.ErrAlreadyCollaborator {
+		t.Fatalf("expected duplicate invitation to fail, got %v", err)
+	}
+	invites, err := repo.ListInvitations(ctx, friend)
+	if err != nil || len(invites) != 1 || invites[0].ID != c.ID {
+		t.Fatalf("unexpected invitations %+v: %v", invites, err)
+	}
+	if shared, err := repo.ListForMember(ctx, friend); err != nil || len(shared) != 0 {
+		t.Fatalf("pending invitee should not be a member: %+v, %v", shared, err)
+	}
+	if err := repo.AcceptInvitation(ctx, c.ID, friend, now); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	if err := repo.AcceptInvitation(ctx, c.ID, friend, now); err != collection.ErrCollaboratorNotFound {
+		t.Fatalf("expected second accept to fail, got %v", err)
+	}
+	shared, err := repo.ListForMember(ctx, friend)
+	if err != nil || len(shared) != 1 || shared[0].RecipeCount != 4 {
+		t.Fatalf("unexpected shared collections %+v: %v", shared, err)
+	}
+
+	got, err := repo.GetByID(ctx, c.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(got.Collaborators) != 1 || got.Collaborators[0].Status != collection.StatusAccepted {
+		t.Fatalf("unexpected collaborators %+v", got.Collaborators)
+	}
+	if public, err := repo.ListPublic(ctx, owner); err != nil || len(public) != 0 {
+		t.Fatalf("private collection listed publicly: %+v, %v", public, err)
+	}
+
+	if err := repo.Delete(ctx, c.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, c.ID); err != collection.ErrCollectionNotFound {
+		t.Fatalf("expected deleted collection to be missing, got %v", err)
+	}
+}
+
+func assertTitles(t *testing.T, repo collection.Repository, id uuid.UUID, viewerID *uuid.UUID, want ...string) {
+	t.Helper()
+	recs, err := repo.ListRecipes(context.Background(), id, viewerID)
+	if err != nil {
+		t.Fatalf("list recipes: %v", err)
+	}
+	var got []string
+	for _, r := range recs {
+		got = append(got, r.Title)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("titles = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("titles = %v, want %v", got, want)
+		}
+	}
+}
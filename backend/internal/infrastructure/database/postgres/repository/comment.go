@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"alchemorsel/backend/internal/domain/comment"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+const commentColumns = `c.id, c.recipe_id, c.parent_id, c.user_id, u.username, c.body,
+	c.created_at, c.edited_at, c.deleted_at,
+	(SELECT count(*) FROM recipe_comments rc WHERE rc.parent_id = c.id)`
+
+const commentFrom = `recipe_comments c JOIN users u ON u.id = c.user_id`
+
+const reportColumns = `p.id, p.comment_id, p.reporter_id, p.reason, p.details, p.status,
+	p.created_at, p.resolved_at, p.resolved_by`
+
+// uniqueViolation is the PostgreSQL error code for unique constraint failures.
+const uniqueViolation = "23505"
+
+type commentRepository struct {
+	db *postgres.DB
+}
+
+// NewCommentRepository returns a PostgreSQL backed comment repository.
+func NewCommentRepository(db *postgres.DB) comment.Repository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Create(ctx context.Context, c *comment.Comment) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO recipe_comments (id, recipe_id, parent_id, user_id, body, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		c.ID, c.RecipeID, c.ParentID, c.UserID, c.Body, c.CreatedAt)
+	return err
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id uuid.UUID) (*comment.Comment, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+commentColumns+` FROM `+commentFrom+` WHERE c.id = $1`, id)
+	c, err := scanComment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, comment.ErrCommentNotFound
+	}
+	return c, err
+}
+
+func (r *commentRepository) UpdateBody(ctx context.Context, id uuid.UUID, body string, editedAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE recipe_comments SET body = $2, edited_at = $3
+		WHERE id = $1 AND deleted_at IS NULL`, id, body, editedAt)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, comment.ErrCommentNotFound)
+}
+
+func (r *commentRepository) SoftDelete(ctx context.Context, id uuid.UUID, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE recipe_comments SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`, id, at)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, comment.ErrCommentNotFound)
+}
+
+func (r *commentRepository) ListThreads(ctx context.Context, recipeID uuid.UUID, after *pagination.Cursor, limit int) ([]*comment.Comment, error) {
+	args := &queryArgs{}
+	where := fmt.Sprintf("c.recipe_id = %s AND c.parent_id IS NULL", args.add(recipeID))
+	if after != nil {
+		where += fmt.Sprintf(" AND (c.created_at, c.id) < (%s, %s)", args.add(after.CreatedAt), args.add(after.ID))
+	}
+	return r.listComments(ctx, fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY c.created_at DESC, c.id DESC LIMIT %s`,
+		commentColumns, commentFrom, where, args.add(limit)), args.values()...)
+}
+
+func (r *commentRepository) ListReplies(ctx context.Context, parentID uuid.UUID, after *pagination.Cursor, limit int) ([]*comment.Comment, error) {
+	args := &queryArgs{}
+	where := fmt.Sprintf("c.parent_id = %s", args.add(parentID))
+	if after != nil {
+		where += fmt.Sprintf(" AND (c.created_at, c.id) > (%s, %s)", args.add(after.CreatedAt), args.add(after.ID))
+	}
+	return r.listComments(ctx, fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY c.created_at, c.id LIMIT %s`,
+		commentColumns, commentFrom, where, args.add(limit)), args.values()...)
+}
+
+func (r *commentRepository) listComments(ctx context.Context, query string, args ...any) ([]*comment.Comment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []*comment.Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+func (r *commentRepository) CreateReport(ctx context.Context, rep *comment.Report) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO comment_reports (id, comment_id, reporter_id, reason, details, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		rep.ID, rep.CommentID, rep.ReporterID, rep.Reason, rep.Details, rep.Status, rep.CreatedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return comment.ErrAlreadyReported
+	}
+	return err
+}
+
+func (r *commentRepository) GetReport(ctx context.Context, id uuid.UUID) (*comment.Report, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+reportColumns+`, `+commentColumns+`
+		FROM comment_reports p JOIN `+commentFrom+` ON c.id = p.comment_id
+		WHERE p.id = $1`, id)
+	rep, err := scanReport(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, comment.ErrReportNotFound
+	}
+	return rep, err
+}
+
+func (r *commentRepository) ListReports(ctx context.Context, status string, after *pagination.Cursor, limit int) ([]*comment.Report, error) {
+	args := &queryArgs{}
+	where := fmt.Sprintf("p.status = %s", args.add(status))
+	if after != nil {
+		where += fmt.Sprintf(" AND (p.created_at, p.id) > (%s, %s)", args.add(after.CreatedAt), args.add(after.ID))
+	}
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s, %s FROM comment_reports p JOIN %s ON c.id = p.comment_id
+		WHERE %s ORDER BY p.created_at, p.id LIMIT %s`,
+		reportColumns, commentColumns, commentFrom, where, args.add(limit)), args.values()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []*comment.Report{}
+	for rows.Next() {
+		rep, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, rep)
+	}
+	return reports, rows.Err()
+}
+
+func (r *commentRepository) ResolveReport(ctx context.Context, id uuid.UUID, status string, by uuid.UUID, at time.Time) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE comment_reports SET status = $2, resolved_by = $3, resolved_at = $4
+		WHERE id = $1 AND status = 'open'`, id, status, by, at)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, comment.ErrReportResolved)
+}
+
+func (r *commentRepository) ResolveCommentReports(ctx context.Context, commentID uuid.UUID, status string, by uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE comment_reports SET status = $2, resolved_by = $3, resolved_at = $4
+		WHERE comment_id = $1 AND status = 'open'`, commentID, status, by, at)
+	return err
+}
+
+// scanComment scans any leading destinations followed by commentColumns.
+func scanComment(s scanner, leading ...any) (*comment.Comment, error) {
+	c := &comment.Comment{Author: &comment.Author{}}
+	dest := []any{
+		&c.ID, &c.RecipeID, &c.ParentID, &c.UserID, &c.Author.Username, &c.Body,
+		&c.CreatedAt, &c.EditedAt, &c.DeletedAt, &c.ReplyCount,
+	}
+	if err := s.Scan(append(leading, dest...)...); err != nil {
+		return nil, err
+	}
+	c.Author.ID = c.UserID
+	return c, nil
+}
+
+// scanReport scans reportColumns followed by the reported comment's
+// commentColumns.
+func scanReport(s scanner) (*comment.Report, error) {
+	rep := &comment.Report{}
+	c, err := scanComment(s, &rep.ID, &rep.CommentID, &rep.ReporterID, &rep.Reason, &rep.Details, &rep.Status,
+		&rep.CreatedAt, &rep.ResolvedAt, &rep.ResolvedBy)
+	if err != nil {
+		return nil, err
+	}
+	rep.Comment = c
+	return rep, nil
+}
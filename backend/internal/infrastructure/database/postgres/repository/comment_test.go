@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/comment"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+func TestCommentRepository(t *testing.T) {
+	db := setupTestDB(t)
+	recipes := NewRecipeRepository(db)
+	repo := NewCommentRepository(db)
+	ctx := context.Background()
+	author, reader := createTestUser(t, db), createTestUser(t, db)
+
+	rec := newTestRecipe(author, "Chili")
+	if err := recipes.Create(ctx, rec); err != nil {
+		t.Fatalf("create recipe: %v", err)
+	}
+
+	base := time.Now().UTC().Truncate(time.Microsecond)
+	var threads []*comment.Comment
+	for i := 0; i < 3; i++ {
+		c := &comment.Comment{ID: uuid.New(), RecipeID: rec.ID, UserID: reader, Body: "comment", CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		if err := repo.Create(ctx, c); err != nil {
+			t.Fatalf("create comment: %v", err)
+		}
+		threads = append(threads, c)
+	}
+	reply := &comment.Comment{ID: uuid.New(), RecipeID: rec.ID, ParentID: &threads[0].ID, UserID: author, Body: "thanks", CreatedAt: base.Add(time.Hour)}
+	if err := repo.Create(ctx, reply); err != nil {
+		t.Fatalf("create reply: %v", err)
+	}
+
+	first, err := repo.ListThreads(ctx, rec.ID, nil, 2)
+	if err != nil {
+		t.Fatalf("list threads: %v", err)
+	}
+	if len(first) != 2 || first[0].ID != threads[2].ID || first[1].ID != threads[1].ID {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+	cursor := &pagination.Cursor{CreatedAt: first[1].CreatedAt, ID: first[1].ID}
+	second, err := repo.ListThreads(ctx, rec.ID, cursor, 2)
+	if err != nil {
+		t.Fatalf("list threads: %v", err)
+	}
+	if len(second) != 1 || second[0].ID != threads[0].ID || second[0].ReplyCount != 1 {
+		t.Fatalf("unexpected second page %+v", second)
+	}
+
+	if err := repo.SoftDelete(ctx, threads[0].ID, base); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	if err := repo.UpdateBody(ctx, threads[0].ID, "edit", base); err != comment.ErrCommentNotFound {
+		t.Fatalf("expected deleted comment to be read-only, got %v", err)
+	}
+	replies, err := repo.ListReplies(ctx, threads[0].ID, nil, 10)
+	if err != nil || len(replies) != 1 || replies[0].Author.ID != author {
+		t.Fatalf("unexpected replies %+v: %v", replies, err)
+	}
+
+	report := &comment.Report{ID: uuid.New(), CommentID: threads[1].ID, ReporterID: author, Reason: comment.ReasonSpam, Status: comment.StatusOpen, CreatedAt: base}
+	if err := repo.CreateReport(ctx, report); err != nil {
+		t.Fatalf("create report: %v", err)
+	}
+	report.ID = uuid.New()
+	if err := repo.CreateReport(ctx, report); err != comment.ErrAlreadyReported {
+		t.Fatalf("expected duplicate report to fail, got %v", err)
+	}
+	queue, err := repo.ListReports(ctx, comment.StatusOpen, nil, 10)
+	if err != nil || len(queue) != 1 || queue[0].Comment.ID != threads[1].ID {
+		t.Fatalf("unexpected queue %+v: %v", queue, err)
+	}
+	if err := repo.ResolveReport(ctx, queue[0].ID, comment.StatusDismissed, author, base); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if err := repo.ResolveReport(ctx, queue[0].ID, comment.StatusDismissed, author, base); err != comment.ErrReportResolved {
+		t.Fatalf("expected second resolution to fail, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fergusstrange/embedded-postgres"
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/config"
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+)
+
+// setupTestDB starts an embedded PostgreSQL server with all migrations applied.
+// The test is skipped when the server cannot be started.
+func setupTestDB(t *testing.T) *postgres.DB {
+	t.Helper()
+
+	password := uuid.New().String()
+	ep := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().Password(password))
+	if err := ep.Start(); err != nil {
+		t.Skipf("embedded postgres unavailable: %v", err)
+	}
+	t.Cleanup(func() { ep.Stop() })
+
+	cfg := config.DatabaseConfig{
+		Host:         "localhost",
+		Port:         5432,
+		User:         "postgres",
+		Password:     password,
+		Name:         "postgres",
+		SSLMode:      "disable",
+		MaxOpenConns: 5,
+		MaxIdleConns: 2,
+	}
+	db, err := postgres.Connect(cfg, filepath.Join("..", "migrations"))
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// createTestUser inserts a user row and returns its id.
+func createTestUser(t *testing.T, db *postgres.DB) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	_, err := db.Exec(`INSERT INTO users (id, email, username, password_hash, name) VALUES ($1, $2, $3, 'x', 'Test')`,
+		id, id.String()+"@example.com", id.String()[:8])
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return id
+}
+
+func newTestRecipe(userID uuid.UUID, title string) *recipe.Recipe {
+	now := time.Now().UTC()
+	return &recipe.Recipe{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Title:     title,
+		IsPublic:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
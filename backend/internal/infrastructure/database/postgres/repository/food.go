@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"alchemorsel/backend/internal/domain/nutrition"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+)
+
+const foodColumns = `id, description, calories, protein, carbohydrates, fat, fiber, sugar, sodium, portion_grams`
+
+type foodRepository struct {
+	db *postgres.DB
+}
+
+// NewFoodRepository returns a PostgreSQL backed nutrient database.
+func NewFoodRepository(db *postgres.DB) nutrition.Repository {
+	return &foodRepository{db: db}
+}
+
+// Search matches any of the words against the food descriptions. Words are
+// expected to be plain lowercase words, as produced by the nutrition
+// matcher, so they are safe to join into a tsquery.
+func (r *foodRepository) Search(ctx context.Context, words []string, limit int) ([]*nutrition.Food, error) {
+	if len(words) == 0 {
+		return nil, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+foodColumns+` FROM foods
+		WHERE search_vector @@ to_tsquery($1, $2)
+		ORDER BY ts_rank(search_vector, to_tsquery($1, $2)) DESC, length(description), id
+		LIMIT $3`, searchLanguage, strings.Join(words, " | "), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var foods []*nutrition.Food
+	for rows.Next() {
+		f := &nutrition.Food{}
+		if err := rows.Scan(&f.ID, &f.Description, &f.Calories, &f.Protein, &f.Carbohydrates,
+			&f.Fat, &f.Fiber, &f.Sugar, &f.Sodium, &f.PortionGrams); err != nil {
+			return nil, err
+		}
+		foods = append(foods, f)
+	}
+	return foods, rows.Err()
+}
+
+func (r *foodRepository) Save(ctx context.Context, foods []*nutrition.Food) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO foods (`+foodColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			ON CONFLICT (id) DO UPDATE SET description = EXCLUDED.description,
+				calories = EXCLUDED.calories, protein = EXCLUDED.protein,
+				carbohydrates = EXCLUDED.carbohydrates, fat = EXCLUDED.fat, fiber = EXCLUDED.fiber,
+				sugar = EXCLUDED.sugar, sodium = EXCLUDED.sodium, portion_grams = EXCLUDED.portion_grams`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, f := range foods {
+			if _, err := stmt.ExecContext(ctx, f.ID, f.Description, f.Calories, f.Protein, f.Carbohydrates,
+				f.Fat, f.Fiber, f.Sugar, f.Sodium, f.PortionGrams); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"alchemorsel/backend/internal/domain/nutrition"
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+func TestFoodRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewFoodRepository(db)
+	ctx := context.Background()
+
+	foods := []*nutrition.Food{
+		{ID: 1, Description: "Butter, salted", Calories: 717, Fat: 81, Sodium: 643},
+		{ID: 2, Description: "Wheat flour, white, all-purpose, unenriched", Calories: 364, Carbohydrates: 76},
+		{ID: 3, Description: "Egg, whole, raw, fresh", Calories: 143, Protein: 12.6, PortionGrams: 50},
+	}
+	if err := repo.Save(ctx, foods); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	foods[0].Calories = 720
+	if err := repo.Save(ctx, foods[:1]); err != nil {
+		t.Fatalf("save again: %v", err)
+	}
+
+	got, err := repo.Search(ctx, []string{"flour", "egg"}, 10)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected flour and egg, got %+v", got)
+	}
+	got, err = repo.Search(ctx, []string{"butter"}, 10)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(got) != 1 || got[0].Calories != 720 || got[0].Sodium != 643 {
+		t.Fatalf("expected the replaced butter, got %+v", got)
+	}
+}
+
+func TestRecipeRepository_NutritionEstimate(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecipeRepository(db)
+	ctx := context.Background()
+
+	rec := newTestRecipe(createTestUser(t, db), "Omelette")
+	rec.NutritionEstimate = &recipe.NutritionEstimate{
+		Confidence: 0.8,
+		Unmatched:  []string{"chives"},
+		ComputedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if err := repo.Create(ctx, rec); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	got, err := repo.GetByID(ctx, rec.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.NutritionEstimate == nil || got.NutritionEstimate.Confidence != 0.8 || got.NutritionEstimate.Unmatched[0] != "chives" {
+		t.Fatalf("unexpected estimate %+v", got.NutritionEstimate)
+	}
+
+	got.NutritionEstimate = nil
+	if err := repo.Update(ctx, got, 0); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got, _ := repo.GetByID(ctx, rec.ID); got.NutritionEstimate != nil {
+		t.Fatalf("expected the estimate to be cleared, got %+v", got.NutritionEstimate)
+	}
+}
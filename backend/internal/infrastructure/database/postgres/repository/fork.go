@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+// maxAncestryDepth bounds the fork chain followed by ListAncestors.
+const maxAncestryDepth = 100
+
+func (r *recipeRepository) ListForks(ctx context.Context, id uuid.UUID, viewerID *uuid.UUID) ([]*recipe.Recipe, error) {
+	return r.list(ctx, `
+		SELECT `+recipeColumns+` FROM recipes r
+		WHERE (r.forked_from->>'recipe_id')::uuid = $1 AND r.deleted_at IS NULL
+			AND (r.is_public OR r.user_id = $2)
+		ORDER BY r.created_at DESC, r.id`, id, viewerID)
+}
+
+func (r *recipeRepository) ListAncestors(ctx context.Context, id uuid.UUID) ([]*recipe.Recipe, error) {
+	return r.list(ctx, `
+		WITH RECURSIVE chain AS (
+			SELECT p.*, 1 AS depth
+			FROM recipes c JOIN recipes p ON p.id = (c.forked_from->>'recipe_id')::uuid
+			WHERE c.id = $1
+			UNION ALL
+			SELECT p.*, chain.depth + 1
+			FROM chain JOIN recipes p ON p.id = (chain.forked_from->>'recipe_id')::uuid
+			WHERE chain.depth < $2
+		)
+		SELECT `+recipeColumns+` FROM chain r ORDER BY r.depth`, id, maxAncestryDepth)
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"alchemorsel/backend/internal/domain/gallery"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+)
+
+const imageColumns = `i.id, i.recipe_id, i.user_id, i.position, i.is_cover, i.alt_text, i.step, i.content_type,
+	i.width, i.height, i.url, i.key, i.variants, i.created_at`
+
+// storedVariant is a variant as stored, including the storage key the
+// domain type keeps out of JSON responses.
+type storedVariant struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+	Key    string `json:"key"`
+}
+
+type galleryRepository struct {
+	db *postgres.DB
+}
+
+// NewGalleryRepository returns a PostgreSQL backed recipe image repository.
+func NewGalleryRepository(db *postgres.DB) gallery.Repository {
+	return &galleryRepository{db: db}
+}
+
+func (r *galleryRepository) Create(ctx context.Context, img *gallery.Image) error {
+	stored := make([]storedVariant, len(img.Variants))
+	for i, v := range img.Variants {
+		stored[i] = storedVariant(v)
+	}
+	variants, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO recipe_images (id, recipe_id, user_id, position, is_cover, alt_text, step, content_type,
+			width, height, url, key, variants, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		img.ID, img.RecipeID, img.UserID, img.Position, img.IsCover, img.AltText, img.Step, img.ContentType,
+		img.Width, img.Height, img.URL, img.Key, variants, img.CreatedAt)
+	return err
+}
+
+func (r *galleryRepository) GetByID(ctx context.Context, id uuid.UUID) (*gallery.Image, error) {
+	img, err := scanImage(r.db.QueryRowContext(ctx, `SELECT `+imageColumns+` FROM recipe_images i WHERE i.id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, gallery.ErrImageNotFound
+	}
+	return img, err
+}
+
+func (r *galleryRepository) ListByRecipe(ctx context.Context, recipeID uuid.UUID) ([]*gallery.Image, error) {
+	return r.list(ctx, `
+		SELECT `+imageColumns+` FROM recipe_images i
+		WHERE i.recipe_id = $1
+		ORDER BY i.position, i.created_at, i.id`, recipeID)
+}
+
+func (r *galleryRepository) Update(ctx context.Context, img *gallery.Image) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE recipe_images SET alt_text = $2, step = $3 WHERE id = $1`,
+		img.ID, img.AltText, img.Step)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, gallery.ErrImageNotFound)
+}
+
+func (r *galleryRepository) SetCover(ctx context.Context, recipeID, id uuid.UUID) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE recipe_images SET is_cover = false WHERE recipe_id = $1 AND is_cover AND id <> $2`,
+			recipeID, id); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `UPDATE recipe_images SET is_cover = true WHERE recipe_id = $1 AND id = $2`,
+			recipeID, id)
+		if err != nil {
+			return err
+		}
+		return requireRow(res, gallery.ErrImageNotFound)
+	})
+}
+
+func (r *galleryRepository) Reorder(ctx context.Context, recipeID uuid.UUID, ids []uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE recipe_images i SET position = o.ord - 1
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, ord)
+		WHERE i.recipe_id = $1 AND i.id = o.id`,
+		recipeID, pq.Array(ids))
+	return err
+}
+
+func (r *galleryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM recipe_images WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, gallery.ErrImageNotFound)
+}
+
+func (r *galleryRepository) ListTrashed(ctx context.Context, before time.Time) ([]*gallery.Image, error) {
+	return r.list(ctx, `
+		SELECT `+imageColumns+` FROM recipe_images i JOIN recipes r ON r.id = i.recipe_id
+		WHERE r.deleted_at < $1
+		ORDER BY i.recipe_id, i.position`, before)
+}
+
+func (r *galleryRepository) list(ctx context.Context, query string, args ...any) ([]*gallery.Image, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []*gallery.Image{}
+	for rows.Next() {
+		img, err := scanImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, rows.Err()
+}
+
+func scanImage(s scanner) (*gallery.Image, error) {
+	img := &gallery.Image{}
+	var variants []byte
+	if err := s.Scan(&img.ID, &img.RecipeID, &img.UserID, &img.Position, &img.IsCover, &img.AltText, &img.Step,
+		&img.ContentType, &img.Width, &img.Height, &img.URL, &img.Key, &variants, &img.CreatedAt); err != nil {
+		return nil, err
+	}
+	var stored []storedVariant
+	if err := json.Unmarshal(variants, &stored); err != nil {
+		return nil, err
+	}
+	img.Variants = make([]gallery.Variant, len(stored))
+	for i, v := range stored {
+		img.Variants[i] = gallery.Variant(v)
+	}
+	return img, nil
+}
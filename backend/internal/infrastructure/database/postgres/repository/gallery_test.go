@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/gallery"
+)
+
+func TestGalleryRepository(t *testing.T) {
+	db := setupTestDB(t)
+	recipes := NewRecipeRepository(db)
+	repo := NewGalleryRepository(db)
+	ctx := context.Background()
+	owner := createTestUser(t, db)
+
+	rec := newTestRecipe(owner, "Tacos")
+	if err := recipes.Create(ctx, rec); err != nil {
+		t.Fatalf("create recipe: %v", err)
+	}
+	var images []*gallery.Image
+	for i := range 3 {
+		img := &gallery.Image{
+			ID: uuid.New(), RecipeID: rec.ID, UserID: owner, Position: i, ContentType: "image/jpeg",
+			Width: 800, Height: 600, URL: "/media/large.jpg",
+			Variants:  []gallery.Variant{{Name: "large", Width: 800, Height: 600, URL: "/media/large.jpg", Key: "large.jpg"}},
+			CreatedAt: time.Now().UTC(),
+		}
+		if err := repo.Create(ctx, img); err != nil {
+			t.Fatalf("create image: %v", err)
+		}
+		images = append(images, img)
+	}
+
+	if err := repo.SetCover(ctx, rec.ID, images[0].ID); err != nil {
+		t.Fatalf("set cover: %v", err)
+	}
+	if err := repo.SetCover(ctx, rec.ID, images[2].ID); err != nil {
+		t.Fatalf("move cover: %v", err)
+	}
+	step := 1
+	images[1].AltText, images[1].Step = "Warming the tortillas", &step
+	if err := repo.Update(ctx, images[1]); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.Reorder(ctx, rec.ID, []uuid.UUID{images[2].ID, images[0].ID, images[1].ID}); err != nil {
+		t.Fatalf("reorder: %v", err)
+	}
+
+	got, err := repo.ListByRecipe(ctx, rec.ID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(got) != 3 || got[0].ID != images[2].ID || !got[0].IsCover || got[1].IsCover || got[2].ID != images[1].ID {
+		t.Fatalf("unexpected gallery %+v", got)
+	}
+	if *got[2].Step != 1 || got[2].AltText != "Warming the tortillas" || got[2].Variants[0].Key != "large.jpg" {
+		t.Fatalf("unexpected image %+v", got[2])
+	}
+
+	if trashed, err := repo.ListTrashed(ctx, time.Now()); err != nil || len(trashed) != 0 {
+		t.Fatalf("expected no trashed images, got %v, %v", trashed, err)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE recipes SET deleted_at = NOW() - INTERVAL '40 days' WHERE id = $1`, rec.ID); err != nil {
+		t.Fatalf("trash recipe: %v", err)
+	}
+	trashed, err := repo.ListTrashed(ctx, time.Now().Add(-gallery.TrashRetention))
+	if err != nil || len(trashed) != 3 {
+		t.Fatalf("expected 3 trashed images, got %v, %v", trashed, err)
+	}
+
+	if err := repo.Delete(ctx, images[0].ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, images[0].ID); err != gallery.ErrImageNotFound {
+		t.Fatalf("expected ErrImageNotFound, got %v", err)
+	}
+}
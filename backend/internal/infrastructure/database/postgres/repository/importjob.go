@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/importjob"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+)
+
+const importJobColumns = `j.id, j.user_id, j.format, j.status, j.import_duplicates, j.total, j.imported,
+	j.duplicates, j.failed, j.error, j.created_at, j.started_at, j.finished_at`
+
+type importJobRepository struct {
+	db *postgres.DB
+}
+
+// NewImportJobRepository returns a PostgreSQL backed import job repository.
+func NewImportJobRepository(db *postgres.DB) importjob.Repository {
+	return &importJobRepository{db: db}
+}
+
+func (r *importJobRepository) Create(ctx context.Context, job *importjob.Job) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO import_jobs (id, user_id, format, status, import_duplicates, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		job.ID, job.UserID, job.Format, job.Status, job.ImportDuplicates, job.CreatedAt)
+	return err
+}
+
+func (r *importJobRepository) Update(ctx context.Context, job *importjob.Job) error {
+	items, err := json.Marshal(job.Items)
+	if err != nil {
+		return err
+	}
+	if job.Items == nil {
+		items = []byte("[]")
+	}
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE import_jobs SET status = $2, total = $3, imported = $4, duplicates = $5, failed = $6,
+			items = $7, error = $8, started_at = $9, finished_at = $10
+		WHERE id = $1`,
+		job.ID, job.Status, job.Total, job.Imported, job.Duplicates, job.Failed,
+		items, job.Error, job.StartedAt, job.FinishedAt)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, importjob.ErrJobNotFound)
+}
+
+func (r *importJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*importjob.Job, error) {
+	var items []byte
+	job := &importjob.Job{}
+	err := r.db.QueryRowContext(ctx, `SELECT `+importJobColumns+`, j.items FROM import_jobs j WHERE j.id = $1`, id).
+		Scan(append(importJobFields(job), &items)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, importjob.ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(items, &job.Items); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *importJobRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*importjob.Job, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+importJobColumns+` FROM import_jobs j
+		WHERE j.user_id = $1
+		ORDER BY j.created_at DESC, j.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*importjob.Job{}
+	for rows.Next() {
+		job := &importjob.Job{}
+		if err := rows.Scan(importJobFields(job)...); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *importJobRepository) FailUnfinished(ctx context.Context, message string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE import_jobs SET status = $1, error = $2, finished_at = NOW()
+		WHERE status IN ($3, $4)`,
+		importjob.StatusFailed, message, importjob.StatusPending, importjob.StatusRunning)
+	return err
+}
+
+// importJobFields returns the scan destinations for importJobColumns.
+func importJobFields(job *importjob.Job) []any {
+	return []any{&job.ID, &job.UserID, &job.Format, &job.Status, &job.ImportDuplicates, &job.Total,
+		&job.Imported, &job.Duplicates, &job.Failed, &job.Error, &job.CreatedAt, &job.StartedAt, &job.FinishedAt}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/importjob"
+)
+
+func TestImportJobRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewImportJobRepository(db)
+	ctx := context.Background()
+	owner := createTestUser(t, db)
+
+	job := &importjob.Job{ID: uuid.New(), UserID: owner, Format: "csv", Status: importjob.StatusPending, CreatedAt: time.Now().UTC()}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	stale := &importjob.Job{ID: uuid.New(), UserID: owner, Format: "paprika", Status: importjob.StatusRunning, CreatedAt: time.Now().UTC()}
+	if err := repo.Create(ctx, stale); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	recipeID := uuid.New()
+	now := time.Now().UTC()
+	job.Status, job.Total, job.Imported, job.Failed, job.FinishedAt = importjob.StatusCompleted, 2, 1, 1, &now
+	job.Items = []importjob.Item{
+		{Index: 0, Title: "Tacos", Status: importjob.ItemImported, RecipeID: &recipeID},
+		{Index: 1, Status: importjob.ItemFailed, Error: "recipe has no title"},
+	}
+	if err := repo.Update(ctx, job); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.FailUnfinished(ctx, "interrupted"); err != nil {
+		t.Fatalf("fail unfinished: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status != importjob.StatusCompleted || got.Imported != 1 || len(got.Items) != 2 || *got.Items[0].RecipeID != recipeID {
+		t.Fatalf("unexpected job %+v", got)
+	}
+	jobs, err := repo.ListByUser(ctx, owner)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != stale.ID || jobs[0].Status != importjob.StatusFailed || jobs[0].Error != "interrupted" {
+		t.Fatalf("unexpected jobs %+v", jobs)
+	}
+	if _, err := repo.GetByID(ctx, uuid.New()); err != importjob.ErrJobNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
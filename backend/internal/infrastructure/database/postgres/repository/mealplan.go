@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/mealplan"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+	"alchemorsel/backend/internal/pkg/date"
+)
+
+const mealPlanColumns = `id, user_id, name, start_date, end_date, created_at, updated_at`
+
+const mealPlanEntryColumns = `id, plan_id, date, slot, position, recipe_id, servings, text, created_at`
+
+type mealPlanRepository struct {
+	db *postgres.DB
+}
+
+// NewMealPlanRepository returns a PostgreSQL backed meal plan repository.
+func NewMealPlanRepository(db *postgres.DB) mealplan.Repository {
+	return &mealPlanRepository{db: db}
+}
+
+func (r *mealPlanRepository) Create(ctx context.Context, p *mealplan.Plan) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO meal_plans (`+mealPlanColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		p.ID, p.UserID, p.Name, p.StartDate, p.EndDate, p.CreatedAt, p.UpdatedAt)
+	return err
+}
+
+func (r *mealPlanRepository) GetByID(ctx context.Context, id uuid.UUID) (*mealplan.Plan, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+mealPlanColumns+` FROM meal_plans WHERE id = $1`, id)
+	p, err := scanMealPlan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, mealplan.ErrPlanNotFound
+	}
+	return p, err
+}
+
+func (r *mealPlanRepository) Update(ctx context.Context, p *mealplan.Plan) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE meal_plans SET name = $2, start_date = $3, end_date = $4, updated_at = $5
+		WHERE id = $1`,
+		p.ID, p.Name, p.StartDate, p.EndDate, p.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, mealplan.ErrPlanNotFound)
+}
+
+func (r *mealPlanRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM meal_plans WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, mealplan.ErrPlanNotFound)
+}
+
+func (r *mealPlanRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*mealplan.Plan, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+mealPlanColumns+` FROM meal_plans
+		WHERE user_id = $1
+		ORDER BY start_date DESC, created_at DESC, id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plans := []*mealplan.Plan{}
+	for rows.Next() {
+		p, err := scanMealPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, p)
+	}
+	return plans, rows.Err()
+}
+
+func (r *mealPlanRepository) ListEntries(ctx context.Context, planID uuid.UUID) ([]*mealplan.Entry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+mealPlanEntryColumns+` FROM meal_plan_entries
+		WHERE plan_id = $1
+		ORDER BY date, array_position(ARRAY['breakfast', 'lunch', 'dinner', 'snack'], slot::text), position, created_at`, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*mealplan.Entry{}
+	for rows.Next() {
+		e, err := scanMealPlanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *mealPlanRepository) GetEntry(ctx context.Context, planID, id uuid.UUID) (*mealplan.Entry, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+mealPlanEntryColumns+` FROM meal_plan_entries WHERE plan_id = $1 AND id = $2`, planID, id)
+	e, err := scanMealPlanEntry(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, mealplan.ErrEntryNotFound
+	}
+	return e, err
+}
+
+func (r *mealPlanRepository) CreateEntries(ctx context.Context, entries []*mealplan.Entry) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, e := range entries {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO meal_plan_entries (`+mealPlanEntryColumns+`)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+				e.ID, e.PlanID, e.Date, e.Slot, e.Position, e.RecipeID, e.Servings, e.Text, e.CreatedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *mealPlanRepository) UpdateEntry(ctx context.Context, e *mealplan.Entry) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE meal_plan_entries SET date = $3, slot = $4, position = $5, recipe_id = $6, servings = $7, text = $8
+		WHERE plan_id = $1 AND id = $2`,
+		e.PlanID, e.ID, e.Date, e.Slot, e.Position, e.RecipeID, e.Servings, e.Text)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, mealplan.ErrEntryNotFound)
+}
+
+func (r *mealPlanRepository) DeleteEntry(ctx context.Context, planID, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM meal_plan_entries WHERE plan_id = $1 AND id = $2`, planID, id)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, mealplan.ErrEntryNotFound)
+}
+
+func (r *mealPlanRepository) CountOutside(ctx context.Context, planID uuid.UUID, from, to date.Date) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT count(*) FROM meal_plan_entries
+		WHERE plan_id = $1 AND (date < $2 OR date > $3)`, planID, from, to).Scan(&n)
+	return n, err
+}
+
+func scanMealPlan(s scanner) (*mealplan.Plan, error) {
+	p := &mealplan.Plan{}
+	if err := s.Scan(&p.ID, &p.UserID, &p.Name, &p.StartDate, &p.EndDate, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func scanMealPlanEntry(s scanner) (*mealplan.Entry, error) {
+	e := &mealplan.Entry{}
+	err := s.Scan(&e.ID, &e.PlanID, &e.Date, &e.Slot, &e.Position, &e.RecipeID, &e.Servings, &e.Text, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
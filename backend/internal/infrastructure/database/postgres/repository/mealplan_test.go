@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/mealplan"
+	"alchemorsel/backend/internal/pkg/date"
+)
+
+func TestMealPlanRepository(t *testing.T) {
+	db := setupTestDB(t)
+	recipes := NewRecipeRepository(db)
+	repo := NewMealPlanRepository(db)
+	ctx := context.Background()
+	owner := createTestUser(t, db)
+
+	rec := newTestRecipe(owner, "Pancakes")
+	if err := recipes.Create(ctx, rec); err != nil {
+		t.Fatalf("create recipe: %v", err)
+	}
+	now := time.Now().UTC()
+	start := date.New(2024, time.June, 3)
+	p := &mealplan.Plan{
+		ID: uuid.New(), UserID: owner, Name: "Week of 2024-06-03",
+		StartDate: start, EndDate: start.AddDays(6), CreatedAt: now, UpdatedAt: now,
+	}
+	if err := repo.Create(ctx, p); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	entries := []*mealplan.Entry{
+		{ID: uuid.New(), PlanID: p.ID, Date: start, Slot: mealplan.SlotDinner, Text: "Dinner out", CreatedAt: now},
+		{ID: uuid.New(), PlanID: p.ID, Date: start, Slot: mealplan.SlotBreakfast, RecipeID: &rec.ID, Servings: 2, CreatedAt: now},
+		{ID: uuid.New(), PlanID: p.ID, Date: start.AddDays(2), Slot: mealplan.SlotLunch, Text: "Leftovers", CreatedAt: now},
+	}
+	if err := repo.CreateEntries(ctx, entries); err != nil {
+		t.Fatalf("create entries: %v", err)
+	}
+	got, err := repo.ListEntries(ctx, p.ID)
+	if err != nil {
+		t.Fatalf("list entries: %v", err)
+	}
+	if len(got) != 3 || got[0].ID != entries[1].ID || got[1].ID != entries[0].ID || got[2].Date != start.AddDays(2) {
+		t.Fatalf("unexpected entries %+v", got)
+	}
+	if *got[0].RecipeID != rec.ID || got[0].Servings != 2 {
+		t.Fatalf("unexpected recipe entry %+v", got[0])
+	}
+
+	entries[2].Date = start.AddDays(8)
+	if err := repo.UpdateEntry(ctx, entries[2]); err != nil {
+		t.Fatalf("update entry: %v", err)
+	}
+	if n, err := repo.CountOutside(ctx, p.ID, p.StartDate, p.EndDate); err != nil || n != 1 {
+		t.Fatalf("CountOutside = %d, %v, want 1", n, err)
+	}
+	if err := repo.DeleteEntry(ctx, p.ID, entries[2].ID); err != nil {
+		t.Fatalf("delete entry: %v", err)
+	}
+	if _, err := repo.GetEntry(ctx, p.ID, entries[2].ID); err != mealplan.ErrEntryNotFound {
+		t.Fatalf("expected ErrEntryNotFound, got %v", err)
+	}
+
+	p.Name, p.EndDate = "June", start.AddDays(13)
+	if err := repo.Update(ctx, p); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	plans, err := repo.ListByUser(ctx, owner)
+	if err != nil || len(plans) != 1 || plans[0].Name != "June" || plans[0].EndDate != start.AddDays(13) {
+		t.Fatalf("unexpected plans %+v, %v", plans, err)
+	}
+
+	if err := repo.Delete(ctx, p.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, p.ID); err != mealplan.ErrPlanNotFound {
+		t.Fatalf("expected ErrPlanNotFound, got %v", err)
+	}
+	if got, _ := repo.ListEntries(ctx, p.ID); len(got) != 0 {
+		t.Fatalf("expected entries to be deleted with the plan, got %d", len(got))
+	}
+}
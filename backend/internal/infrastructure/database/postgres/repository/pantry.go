@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/pantry"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+)
+
+const pantryItemColumns = `id, user_id, name, amount, unit, expires_on, created_at, updated_at`
+
+type pantryRepository struct {
+	db *postgres.DB
+}
+
+// NewPantryRepository returns a PostgreSQL backed pantry repository.
+func NewPantryRepository(db *postgres.DB) pantry.Repository {
+	return &pantryRepository{db: db}
+}
+
+func (r *pantryRepository) Create(ctx context.Context, it *pantry.Item) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO pantry_items (`+pantryItemColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		it.ID, it.UserID, it.Name, it.Amount, it.Unit, it.Expires, it.CreatedAt, it.UpdatedAt)
+	return err
+}
+
+func (r *pantryRepository) GetByID(ctx context.Context, userID, id uuid.UUID) (*pantry.Item, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+pantryItemColumns+` FROM pantry_items WHERE user_id = $1 AND id = $2`, userID, id)
+	it, err := scanPantryItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, pantry.ErrItemNotFound
+	}
+	return it, err
+}
+
+func (r *pantryRepository) Update(ctx context.Context, it *pantry.Item) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE pantry_items SET name = $3, amount = $4, unit = $5, expires_on = $6, updated_at = $7
+		WHERE user_id = $1 AND id = $2`,
+		it.UserID, it.ID, it.Name, it.Amount, it.Unit, it.Expires, it.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, pantry.ErrItemNotFound)
+}
+
+func (r *pantryRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM pantry_items WHERE user_id = $1 AND id = $2`, userID, id)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, pantry.ErrItemNotFound)
+}
+
+func (r *pantryRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*pantry.Item, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+pantryItemColumns+` FROM pantry_items
+		WHERE user_id = $1
+		ORDER BY lower(name), created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*pantry.Item{}
+	for rows.Next() {
+		it, err := scanPantryItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+func (r *pantryRepository) CountByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT count(*) FROM pantry_items WHERE user_id = $1`, userID).Scan(&n)
+	return n, err
+}
+
+func scanPantryItem(s scanner) (*pantry.Item, error) {
+	it := &pantry.Item{}
+	err := s.Scan(&it.ID, &it.UserID, &it.Name, &it.Amount, &it.Unit, &it.Expires, &it.CreatedAt, &it.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return it, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/pantry"
+	"alchemorsel/backend/internal/pkg/date"
+)
+
+func TestPantryRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewPantryRepository(db)
+	ctx := context.Background()
+	owner, other := createTestUser(t, db), createTestUser(t, db)
+
+	now := time.Now().UTC()
+	expires := date.New(2024, time.June, 5)
+	milk := &pantry.Item{ID: uuid.New(), UserID: owner, Name: "milk", Amount: 1, Unit: "l", Expires: expires, CreatedAt: now, UpdatedAt: now}
+	flour := &pantry.Item{ID: uuid.New(), UserID: owner, Name: "Flour", CreatedAt: now, UpdatedAt: now}
+	for _, it := range []*pantry.Item{milk, flour} {
+		if err := repo.Create(ctx, it); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	items, err := repo.ListByUser(ctx, owner)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 2 || items[0].ID != flour.ID || items[1].Expires != expires || !items[0].Expires.IsZero() {
+		t.Fatalf("unexpected items %+v", items)
+	}
+	if n, err := repo.CountByUser(ctx, owner); err != nil || n != 2 {
+		t.Fatalf("count = %d, %v", n, err)
+	}
+
+	milk.Amount, milk.Expires = 0.5, date.Date{}
+	if err := repo.Update(ctx, milk); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := repo.GetByID(ctx, owner, milk.ID)
+	if err != nil || got.Amount != 0.5 || !got.Expires.IsZero() {
+		t.Fatalf("unexpected item %+v, %v", got, err)
+	}
+	if _, err := repo.GetByID(ctx, other, milk.ID); err != pantry.ErrItemNotFound {
+		t.Fatalf("expected ErrItemNotFound for another user, got %v", err)
+	}
+	if err := repo.Delete(ctx, other, milk.ID); err != pantry.ErrItemNotFound {
+		t.Fatalf("expected ErrItemNotFound deleting another user's item, got %v", err)
+	}
+	if err := repo.Delete(ctx, owner, milk.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, owner, milk.ID); err != pantry.ErrItemNotFound {
+		t.Fatalf("expected ErrItemNotFound, got %v", err)
+	}
+}
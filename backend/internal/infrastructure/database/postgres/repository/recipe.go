@@ -0,0 +1,792 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/logger"
+	"alchemorsel/backend/internal/pkg/pagination"
+	"alchemorsel/backend/internal/pkg/vector"
+)
+
+// searchLanguage is the text search configuration used to parse queries. It
+// matches the default of the recipes.search_language column.
+const searchLanguage = "english"
+
+const recipeColumns = `r.id, r.user_id, r.title, r.description, r.ingredients, r.instructions,
+	r.prep_time, r.cook_time, r.servings, r.category, r.dietary_categories, r.allergens,
+	r.nutritional_info, r.image_url, r.is_public, r.version, r.created_at, r.updated_at, r.deleted_at, r.forked_from,
+	r.rating_average, r.rating_count, r.tags, r.nutrition_estimate, r.undeclared_allergens,
+	r.undeclared_diets`
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>"
+
+// pgvectorDimensions is the size of the indexed pgvector column. Embeddings of
+// other sizes are still stored and searched, but without the index.
+const pgvectorDimensions = 1536
+
+var sortColumns = map[string]string{
+	recipe.SortRelevance:   "rank",
+	recipe.SortCreatedAt:   "r.created_at",
+	recipe.SortTitle:       "lower(r.title)",
+	recipe.SortPrepTime:    "r.prep_time",
+	recipe.SortRating:      "r.rating_average",
+	recipe.SortRatingCount: "r.rating_count",
+}
+
+type recipeRepository struct {
+	db *postgres.DB
+
+	pgvectorMu    sync.Mutex
+	pgvectorKnown bool
+	pgvector      bool
+}
+
+// NewRecipeRepository returns a PostgreSQL backed recipe repository.
+func NewRecipeRepository(db *postgres.DB) recipe.Repository {
+	return &recipeRepository{db: db}
+}
+
+func (r *recipeRepository) Create(ctx context.Context, rec *recipe.Recipe) error {
+	ingredients, err := json.Marshal(rec.Ingredients)
+	if err != nil {
+		return err
+	}
+	nutrition, err := json.Marshal(rec.NutritionalInfo)
+	if err != nil {
+		return err
+	}
+	var forkedFrom []byte
+	if rec.ForkedFrom != nil {
+		if forkedFrom, err = json.Marshal(rec.ForkedFrom); err != nil {
+			return err
+		}
+	}
+	estimate, err := marshalEstimate(rec.NutritionEstimate)
+	if err != nil {
+		return err
+	}
+	steps, err := marshalSteps(rec.Instructions)
+	if err != nil {
+		return err
+	}
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO recipes (id, user_id, title, description, ingredients, instructions,
+				prep_time, cook_time, servings, category, dietary_categories, allergens,
+				nutritional_info, image_url, is_public, version, created_at, updated_at, forked_from, tags,
+				nutrition_estimate, undeclared_allergens, undeclared_diets)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, 1, $16, $17, $18, $19, $20, $21, $22)`,
+			rec.ID, rec.UserID, rec.Title, rec.Description, ingredients, steps,
+			rec.PrepTime, rec.CookTime, rec.Servings, rec.Category, pq.Array(nonNil(rec.DietaryCategories)),
+			pq.Array(nonNil(rec.Allergens)), nutrition, rec.ImageURL, rec.IsPublic, rec.CreatedAt, rec.UpdatedAt,
+			forkedFrom, pq.Array(nonNil(rec.Tags)), estimate, pq.Array(nonNil(rec.UndeclaredAllergens)),
+			pq.Array(nonNil(rec.UndeclaredDiets)),
+		)
+		if err != nil {
+			return err
+		}
+		rec.Version = 1
+		return insertRevision(ctx, tx, rec, 0)
+	})
+}
+
+func (r *recipeRepository) GetByID(ctx context.Context, id uuid.UUID) (*recipe.Recipe, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+recipeColumns+` FROM recipes r WHERE r.id = $1 AND r.deleted_at IS NULL`, id)
+	rec, err := scanRecipe(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrRecipeNotFound
+	}
+	return rec, err
+}
+
+func (r *recipeRepository) Update(ctx context.Context, rec *recipe.Recipe, revertedFrom int) error {
+	ingredients, err := json.Marshal(rec.Ingredients)
+	if err != nil {
+		return err
+	}
+	nutrition, err := json.Marshal(rec.NutritionalInfo)
+	if err != nil {
+		return err
+	}
+	estimate, err := marshalEstimate(rec.NutritionEstimate)
+	if err != nil {
+		return err
+	}
+	steps, err := marshalSteps(rec.Instructions)
+	if err != nil {
+		return err
+	}
+	var version int
+	err = inTx(ctx, r.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			UPDATE recipes SET title = $2, description = $3, ingredients = $4, instructions = $5,
+				prep_time = $6, cook_time = $7, servings = $8, category = $9, dietary_categories = $10,
+				allergens = $11, nutritional_info = $12, is_public = $13, updated_at = $14,
+				tags = $16, nutrition_estimate = $17, undeclared_allergens = $18, undeclared_diets = $19,
+				version = version + 1
+			WHERE id = $1 AND version = $15 AND deleted_at IS NULL
+			RETURNING version`,
+			rec.ID, rec.Title, rec.Description, ingredients, steps,
+			rec.PrepTime, rec.CookTime, rec.Servings, rec.Category, pq.Array(nonNil(rec.DietaryCategories)),
+			pq.Array(nonNil(rec.Allergens)), nutrition, rec.IsPublic, rec.UpdatedAt, rec.Version,
+			pq.Array(nonNil(rec.Tags)), estimate, pq.Array(nonNil(rec.UndeclaredAllergens)),
+			pq.Array(nonNil(rec.UndeclaredDiets)),
+		).Scan(&version)
+		if err != nil {
+			return err
+		}
+		updated := *rec
+		updated.Version = version
+		return insertRevision(ctx, tx, &updated, revertedFrom)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return r.versionMismatch(ctx, rec.ID)
+	}
+	if err != nil {
+		return err
+	}
+	rec.Version = version
+	return nil
+}
+
+func (r *recipeRepository) SoftDelete(ctx context.Context, id uuid.UUID, version int) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE recipes SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL AND version = $2`, id, version)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	return r.versionMismatch(ctx, id)
+}
+
+func (r *recipeRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE recipes SET deleted_at = NULL, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, apperrors.ErrRecipeNotFound)
+}
+
+func (r *recipeRepository) GetTrashed(ctx context.Context, id uuid.UUID) (*recipe.Recipe, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+recipeColumns+` FROM recipes r WHERE r.id = $1 AND r.deleted_at IS NOT NULL`, id)
+	rec, err := scanRecipe(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrRecipeNotFound
+	}
+	return rec, err
+}
+
+func (r *recipeRepository) ListTrash(ctx context.Context, userID uuid.UUID) ([]*recipe.Recipe, error) {
+	return r.list(ctx, `
+		SELECT `+recipeColumns+` FROM recipes r
+		WHERE r.user_id = $1 AND r.deleted_at IS NOT NULL
+		ORDER BY r.deleted_at DESC`, userID)
+}
+
+func (r *recipeRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*recipe.Recipe, error) {
+	return r.list(ctx, `
+		SELECT `+recipeColumns+` FROM recipes r
+		WHERE r.user_id = $1 AND r.deleted_at IS NULL
+		ORDER BY r.created_at DESC`, userID)
+}
+
+// versionMismatch explains why a conditional write on id matched no rows.
+func (r *recipeRepository) versionMismatch(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM recipes WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return apperrors.ErrRecipeNotFound
+	}
+	return recipe.ErrVersionConflict
+}
+
+func (r *recipeRepository) Search(ctx context.Context, params recipe.SearchParams) (*recipe.SearchResult, error) {
+	if params.Mode == recipe.ModeSemantic || params.Mode == recipe.ModeHybrid {
+		return r.semanticSearch(ctx, params)
+	}
+
+	args := &queryArgs{}
+	f := buildSearchFilter(params, "", args)
+	if f.text {
+		f.where = append(f.where, f.textMatch())
+	}
+
+	total, unsafe, err := r.count(ctx, &f, args)
+	if err != nil {
+		return nil, err
+	}
+	filter := f.sql()
+
+	rank := "0::real"
+	highlights := "NULL::text, NULL::text"
+	if f.text {
+		rank = "ts_rank(r.search_vector, q)"
+		highlights = fmt.Sprintf(`ts_headline(r.search_language, r.title, q, '%s, HighlightAll=true'),
+			ts_headline(r.search_language, concat_ws(' ', r.description,
+				(SELECT string_agg(i->>'name', ', ') FROM jsonb_array_elements(r.ingredients) AS i),
+				recipe_steps_text(r.instructions)), q, '%s, MaxFragments=2, MaxWords=20, MinWords=5')`,
+			headlineOptions, headlineOptions)
+	}
+
+	sortKey := params.Sort
+	if sortKey == recipe.SortRelevance && !f.text {
+		sortKey = recipe.SortCreatedAt
+	}
+	order := "DESC"
+	if params.Order == "asc" {
+		order = "ASC"
+	}
+
+	page := params.Pagination.Normalize()
+	query := fmt.Sprintf(`SELECT %s, %s AS rank, %s FROM %s ORDER BY %s %s, r.id LIMIT %s OFFSET %s`,
+		recipeColumns, rank, highlights, filter, sortColumns[sortKey], order,
+		args.add(page.PerPage), args.add(page.Offset()))
+
+	rows, err := r.db.QueryContext(ctx, query, args.values()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &recipe.SearchResult{Recipes: []*recipe.SearchHit{}}
+	for rows.Next() {
+		hit := &recipe.SearchHit{}
+		var title, snippet sql.NullString
+		rec, err := scanRecipe(rows, &hit.Rank, &title, &snippet)
+		if err != nil {
+			return nil, err
+		}
+		hit.Recipe = rec
+		if title.Valid {
+			hit.Highlights = &recipe.Highlights{Title: title.String, Snippet: snippet.String}
+		}
+		result.Recipes = append(result.Recipes, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	result.Pagination = pagination.NewMeta(page, total)
+	result.AllergenFilter = f.allergenFilter(params, unsafe)
+	return result, nil
+}
+
+func (r *recipeRepository) ListTags(ctx context.Context, prefix string, limit int) ([]recipe.TagCount, error) {
+	// Normalized tags contain no LIKE wildcards, so prefix needs no escaping.
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT t.tag, count(*) FROM recipes r, unnest(r.tags) AS t(tag)
+		WHERE r.is_public AND r.deleted_at IS NULL AND t.tag LIKE $1::text || '%'
+		GROUP BY t.tag
+		ORDER BY count(*) DESC, t.tag
+		LIMIT $2`, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []recipe.TagCount{}
+	for rows.Next() {
+		var tc recipe.TagCount
+		if err := rows.Scan(&tc.Name, &tc.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tc)
+	}
+	return tags, rows.Err()
+}
+
+// count returns the number of recipes matching the filter and how many of
+// them conflict with the viewer's allergies. When those are hidden, the
+// filter is narrowed to exclude them and they are left out of the total.
+func (r *recipeRepository) count(ctx context.Context, f *searchFilter, args *queryArgs) (total, unsafe int, err error) {
+	var safe int
+	query := fmt.Sprintf("SELECT count(*) FILTER (WHERE NOT %[1]s), count(*) FILTER (WHERE %[1]s) FROM %[2]s", f.unsafe, f.sql())
+	if err := r.db.QueryRowContext(ctx, query, args.values()...).Scan(&safe, &unsafe); err != nil {
+		return 0, 0, err
+	}
+	if f.hideUnsafe {
+		f.where = append(f.where, "NOT "+f.unsafe)
+		return safe, unsafe, nil
+	}
+	return safe + unsafe, unsafe, nil
+}
+
+// semanticSearch ranks recipes by embedding similarity, optionally blended
+// with the full-text rank. With pgvector the ranking and pagination are done
+// by the database using the vector index; otherwise every candidate
+// embedding is scored in Go.
+func (r *recipeRepository) semanticSearch(ctx context.Context, params recipe.SearchParams) (*recipe.SearchResult, error) {
+	args := &queryArgs{}
+	f := buildSearchFilter(params, "LEFT JOIN recipe_embeddings e ON e.recipe_id = r.id", args)
+
+	hybrid := params.Mode == recipe.ModeHybrid && f.text
+	if hybrid {
+		f.where = append(f.where, "(e.recipe_id IS NOT NULL OR "+f.textMatch()+")")
+	} else {
+		f.where = append(f.where, "e.recipe_id IS NOT NULL")
+	}
+
+	total, unsafe, err := r.count(ctx, &f, args)
+	if err != nil {
+		return nil, err
+	}
+	page := params.Pagination.Normalize()
+	result := &recipe.SearchResult{
+		Recipes:        []*recipe.SearchHit{},
+		Pagination:     pagination.NewMeta(page, total),
+		AllergenFilter: f.allergenFilter(params, unsafe),
+	}
+
+	var scored []scoredRecipe
+	if r.hasPGVector(ctx) && len(params.Embedding) == pgvectorDimensions {
+		scored, err = r.rankIndexed(ctx, params, f, args, hybrid, page)
+	} else {
+		scored, err = r.rankInProcess(ctx, params, f, args, hybrid, page)
+	}
+	if err != nil || len(scored) == 0 {
+		return result, err
+	}
+
+	ids := make([]uuid.UUID, len(scored))
+	for i, sr := range scored {
+		ids[i] = sr.id
+	}
+	recipes, err := r.getByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, sr := range scored {
+		if rec, ok := recipes[sr.id]; ok {
+			result.Recipes = append(result.Recipes, &recipe.SearchHit{Recipe: rec, Rank: sr.score})
+		}
+	}
+	return result, nil
+}
+
+// rankIndexed returns one page of recipes matching the filter ranked by the
+// database. Pure semantic searches are ordered by vector distance so that
+// the pgvector index can serve them.
+func (r *recipeRepository) rankIndexed(ctx context.Context, params recipe.SearchParams, f searchFilter, args *queryArgs, hybrid bool, page pagination.Params) ([]scoredRecipe, error) {
+	vec := args.add(vectorLiteral(params.Embedding))
+	distance := fmt.Sprintf("e.embedding_vector <=> %s::vector", vec)
+	score := fmt.Sprintf("coalesce(1 - (%s), 0)", distance)
+	order := distance
+	if hybrid {
+		w := args.add(params.SemanticWeight)
+		score = fmt.Sprintf("%[1]s::float8 * %[2]s + (1 - %[1]s::float8) * ts_rank(r.search_vector, q, 32)", w, score)
+		order = "score DESC"
+	}
+	query := fmt.Sprintf(`SELECT r.id, %s AS score FROM %s ORDER BY %s, r.id LIMIT %s OFFSET %s`,
+		score, f.sql(), order, args.add(page.PerPage), args.add(page.Offset()))
+
+	rows, err := r.db.QueryContext(ctx, query, args.values()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scored []scoredRecipe
+	for rows.Next() {
+		var sr scoredRecipe
+		if err := rows.Scan(&sr.id, &sr.score); err != nil {
+			return nil, err
+		}
+		scored = append(scored, sr)
+	}
+	return scored, rows.Err()
+}
+
+// rankInProcess scores every recipe matching the filter in Go and returns
+// the requested page.
+func (r *recipeRepository) rankInProcess(ctx context.Context, params recipe.SearchParams, f searchFilter, args *queryArgs, hybrid bool, page pagination.Params) ([]scoredRecipe, error) {
+	textRank := "0::real"
+	if hybrid {
+		textRank = "ts_rank(r.search_vector, q, 32)"
+	}
+	query := fmt.Sprintf(`SELECT r.id, e.embedding, %s FROM %s`, textRank, f.sql())
+	rows, err := r.db.QueryContext(ctx, query, args.values()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scored []scoredRecipe
+	for rows.Next() {
+		var (
+			sr        scoredRecipe
+			embedding []float64
+			text      float64
+		)
+		if err := rows.Scan(&sr.id, pq.Array(&embedding), &text); err != nil {
+			return nil, err
+		}
+		sr.score = vector.Cosine(params.Embedding, embedding)
+		if hybrid {
+			sr.score = params.SemanticWeight*sr.score + (1-params.SemanticWeight)*text
+		}
+		scored = append(scored, sr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].id.String() < scored[j].id.String()
+	})
+	lo := min(page.Offset(), len(scored))
+	hi := min(lo+page.PerPage, len(scored))
+	return scored[lo:hi], nil
+}
+
+func (r *recipeRepository) GetUserFavorites(ctx context.Context, userID uuid.UUID) ([]*recipe.Recipe, error) {
+	return r.list(ctx, `
+		SELECT `+recipeColumns+`
+		FROM recipes r
+		JOIN recipe_favorites f ON f.recipe_id = r.id
+		WHERE f.user_id = $1 AND r.deleted_at IS NULL AND (r.is_public OR r.user_id = $1)
+		ORDER BY f.created_at DESC`, userID)
+}
+
+// list runs a query selecting recipeColumns and returns the scanned recipes.
+func (r *recipeRepository) list(ctx context.Context, query string, args ...any) ([]*recipe.Recipe, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipes := []*recipe.Recipe{}
+	for rows.Next() {
+		rec, err := scanRecipe(rows)
+		if err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, rec)
+	}
+	return recipes, rows.Err()
+}
+
+func (r *recipeRepository) AddFavorite(ctx context.Context, userID, recipeID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO recipe_favorites (user_id, recipe_id) VALUES ($1, $2)
+		ON CONFLICT (user_id, recipe_id) DO NOTHING`, userID, recipeID)
+	return err
+}
+
+func (r *recipeRepository) RemoveFavorite(ctx context.Context, userID, recipeID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM recipe_favorites WHERE user_id = $1 AND recipe_id = $2`, userID, recipeID)
+	return err
+}
+
+func (r *recipeRepository) SaveEmbedding(ctx context.Context, recipeID uuid.UUID, embedding []float64) error {
+	if r.hasPGVector(ctx) {
+		var vec any
+		if len(embedding) == pgvectorDimensions {
+			vec = vectorLiteral(embedding)
+		}
+		_, err := r.db.ExecContext(ctx, `
+			INSERT INTO recipe_embeddings (recipe_id, embedding, embedding_vector, updated_at)
+			VALUES ($1, $2, $3::vector, NOW())
+			ON CONFLICT (recipe_id) DO UPDATE
+			SET embedding = EXCLUDED.embedding, embedding_vector = EXCLUDED.embedding_vector, updated_at = NOW()`,
+			recipeID, pq.Array(embedding), vec)
+		return err
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO recipe_embeddings (recipe_id, embedding, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (recipe_id) DO UPDATE SET embedding = EXCLUDED.embedding, updated_at = NOW()`,
+		recipeID, pq.Array(embedding))
+	return err
+}
+
+func (r *recipeRepository) GetEmbedding(ctx context.Context, recipeID uuid.UUID) ([]float64, error) {
+	var embedding []float64
+	err := r.db.QueryRowContext(ctx, `SELECT embedding FROM recipe_embeddings WHERE recipe_id = $1`, recipeID).
+		Scan(pq.Array(&embedding))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return embedding, err
+}
+
+func (r *recipeRepository) RecordGeneration(ctx context.Context, g *recipe.Generation) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO recipe_generations (id, user_id, recipe_id, style, ingredients, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		g.ID, g.UserID, g.RecipeID, g.Style, pq.Array(nonNil(g.Ingredients)), g.CreatedAt)
+	return err
+}
+
+func (r *recipeRepository) UpdateImage(ctx context.Context, id uuid.UUID, url *string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE recipes SET image_url = $2 WHERE id = $1 AND deleted_at IS NULL`, id, url)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, apperrors.ErrRecipeNotFound)
+}
+
+func (r *recipeRepository) ListAfter(ctx context.Context, after uuid.UUID, limit int) ([]*recipe.Recipe, error) {
+	return r.list(ctx, `SELECT `+recipeColumns+` FROM recipes r WHERE r.id > $1 ORDER BY r.id LIMIT $2`, after, limit)
+}
+
+func (r *recipeRepository) UpdateLabels(ctx context.Context, rec *recipe.Recipe) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE recipes SET allergens = $2, undeclared_allergens = $3, dietary_categories = $4, undeclared_diets = $5
+		WHERE id = $1`,
+		rec.ID, pq.Array(nonNil(rec.Allergens)), pq.Array(nonNil(rec.UndeclaredAllergens)),
+		pq.Array(nonNil(rec.DietaryCategories)), pq.Array(nonNil(rec.UndeclaredDiets)))
+	if err != nil {
+		return err
+	}
+	return requireRow(res, apperrors.ErrRecipeNotFound)
+}
+
+// getByIDs loads the given recipes keyed by id.
+func (r *recipeRepository) getByIDs(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*recipe.Recipe, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+recipeColumns+` FROM recipes r WHERE r.id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipes := make(map[uuid.UUID]*recipe.Recipe, len(ids))
+	for rows.Next() {
+		rec, err := scanRecipe(rows)
+		if err != nil {
+			return nil, err
+		}
+		recipes[rec.ID] = rec
+	}
+	return recipes, rows.Err()
+}
+
+// hasPGVector reports whether the pgvector column was created by the
+// migrations. A successful probe is cached for the lifetime of the
+// repository; failed ones are retried by later calls.
+func (r *recipeRepository) hasPGVector(ctx context.Context) bool {
+	r.pgvectorMu.Lock()
+	defer r.pgvectorMu.Unlock()
+	if r.pgvectorKnown {
+		return r.pgvector
+	}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'recipe_embeddings' AND column_name = 'embedding_vector'
+		)`).Scan(&r.pgvector)
+	if err != nil {
+		logger.FromContext(ctx).Warnw("pgvector detection failed, using in-process similarity", "error", err)
+		return false
+	}
+	r.pgvectorKnown = true
+	return r.pgvector
+}
+
+// searchFilter is the FROM and WHERE clause shared by the search queries.
+type searchFilter struct {
+	from  string
+	where []string
+	// text reports whether the query parsed to a tsquery, which is then
+	// available to the query as q.
+	text bool
+	// unsafe is a boolean SQL expression that is true for recipes declaring
+	// one of the viewer's allergies.
+	unsafe     string
+	hideUnsafe bool
+}
+
+// allergenFilter describes the allergy filtering applied to a search, given
+// the number of matching recipes that conflict with the viewer's allergies.
+func (f searchFilter) allergenFilter(params recipe.SearchParams, conflicts int) *recipe.AllergenFilter {
+	if len(params.Allergies) == 0 {
+		return nil
+	}
+	af := &recipe.AllergenFilter{Enabled: f.hideUnsafe, Allergies: params.Allergies}
+	if f.hideUnsafe {
+		af.Hidden = conflicts
+	}
+	return af
+}
+
+func (f searchFilter) sql() string {
+	return f.from + " WHERE " + strings.Join(f.where, " AND ")
+}
+
+func (f searchFilter) textMatch() string {
+	// A query made only of stop words parses to an empty tsquery, which
+	// matches everything rather than nothing.
+	return "(numnode(q) = 0 OR r.search_vector @@ q)"
+}
+
+// buildSearchFilter translates the search parameters into SQL conditions.
+// The full-text match itself is left to the caller so that it can be combined
+// with other criteria.
+func buildSearchFilter(params recipe.SearchParams, join string, args *queryArgs) searchFilter {
+	f := searchFilter{from: "recipes r", unsafe: "false", where: []string{"r.deleted_at IS NULL"}}
+	if join != "" {
+		f.from += " " + join
+	}
+	if len(params.Allergies) > 0 {
+		f.unsafe = fmt.Sprintf("(r.allergens && %s::text[])", args.add(pq.Array(params.Allergies)))
+		f.hideUnsafe = !params.DisableAllergenFilter
+	}
+
+	if params.ViewerID != nil {
+		f.where = append(f.where, fmt.Sprintf("(r.is_public OR r.user_id = %s)", args.add(*params.ViewerID)))
+	} else {
+		f.where = append(f.where, "r.is_public")
+	}
+	if tsquery := buildTSQuery(params.Query); tsquery != "" {
+		f.from += fmt.Sprintf(", to_tsquery(%s::regconfig, %s) AS q", args.add(searchLanguage), args.add(tsquery))
+		f.text = true
+	}
+	if params.SimilarTo != nil {
+		f.where = append(f.where, fmt.Sprintf("r.id <> %s", args.add(*params.SimilarTo)))
+	}
+	if params.Category != "" {
+		f.where = append(f.where, fmt.Sprintf("lower(r.category) = lower(%s)", args.add(params.Category)))
+	}
+	if len(params.Dietary) > 0 {
+		f.where = append(f.where, fmt.Sprintf("r.dietary_categories @> %s::text[]", args.add(pq.Array(params.Dietary))))
+	}
+	if len(params.Tags) > 0 {
+		f.where = append(f.where, fmt.Sprintf("r.tags @> %s::text[]", args.add(pq.Array(params.Tags))))
+	}
+	if len(params.Exclude) > 0 {
+		f.where = append(f.where, fmt.Sprintf("NOT (r.allergens && %s::text[])", args.add(pq.Array(params.Exclude))))
+	}
+	if len(params.IngredientWords) > 0 {
+		// Matched like a full-text query for any of the words, so that plurals
+		// still match, but against the ingredient names alone.
+		lang := args.add(searchLanguage)
+		words := buildTSQuery(strings.Join(params.IngredientWords, " OR "))
+		f.where = append(f.where, fmt.Sprintf(`to_tsvector(%s::regconfig,
+			(SELECT coalesce(string_agg(i->>'name', ' '), '') FROM jsonb_array_elements(r.ingredients) AS i))
+			@@ to_tsquery(%s::regconfig, %s)`, lang, lang, args.add(words)))
+	}
+	if params.AuthorID != nil {
+		f.where = append(f.where, fmt.Sprintf("r.user_id = %s", args.add(*params.AuthorID)))
+	}
+	if params.Favorites && params.ViewerID != nil {
+		f.where = append(f.where, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM recipe_favorites f WHERE f.recipe_id = r.id AND f.user_id = %s)", args.add(*params.ViewerID)))
+	}
+	return f
+}
+
+type scoredRecipe struct {
+	id    uuid.UUID
+	score float64
+}
+
+// vectorLiteral formats an embedding in pgvector's text representation.
+func vectorLiteral(v []float64) string {
+	parts := make([]string, len(v))
+	for i, x := range v {
+		parts[i] = strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanRecipe scans the columns listed in recipeColumns followed by any extra
+// destinations selected after them.
+func scanRecipe(s scanner, extra ...any) (*recipe.Recipe, error) {
+	rec := &recipe.Recipe{}
+	var ingredients, steps, nutrition, forkedFrom, estimate []byte
+	dest := []any{
+		&rec.ID, &rec.UserID, &rec.Title, &rec.Description, &ingredients, &steps,
+		&rec.PrepTime, &rec.CookTime, &rec.Servings, &rec.Category, pq.Array(&rec.DietaryCategories),
+		pq.Array(&rec.Allergens), &nutrition, &rec.ImageURL, &rec.IsPublic, &rec.Version,
+		&rec.CreatedAt, &rec.UpdatedAt, &rec.DeletedAt, &forkedFrom,
+		&rec.RatingAverage, &rec.RatingCount, pq.Array(&rec.Tags), &estimate,
+		pq.Array(&rec.UndeclaredAllergens), pq.Array(&rec.UndeclaredDiets),
+	}
+	if err := s.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(ingredients, &rec.Ingredients); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(steps, &rec.Instructions); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(nutrition, &rec.NutritionalInfo); err != nil {
+		return nil, err
+	}
+	if forkedFrom != nil {
+		if err := json.Unmarshal(forkedFrom, &rec.ForkedFrom); err != nil {
+			return nil, err
+		}
+	}
+	if estimate != nil {
+		if err := json.Unmarshal(estimate, &rec.NutritionEstimate); err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
+// marshalEstimate encodes a nutrition estimate, mapping nil to SQL NULL.
+func marshalEstimate(e *recipe.NutritionEstimate) ([]byte, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return json.Marshal(e)
+}
+
+// marshalSteps encodes instruction steps, mapping nil to an empty list.
+func marshalSteps(steps []recipe.Step) ([]byte, error) {
+	if steps == nil {
+		steps = []recipe.Step{}
+	}
+	return json.Marshal(steps)
+}
+
+// queryArgs collects positional arguments for dynamically built queries.
+type queryArgs []any
+
+// add appends v and returns its positional placeholder.
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+func (a *queryArgs) values() []any {
+	return *a
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,476 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+func TestRecipeRepository_Search(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecipeRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+
+	titleMatch := newTestRecipe(userID, "Garlic Bread")
+	titleMatch.Description = "Crusty bread with butter"
+	ingredientMatch := newTestRecipe(userID, "Roast Chicken")
+	ingredientMatch.Ingredients = []recipe.Ingredient{{Name: "garlic", Amount: 4, Unit: "cloves"}}
+	instructionMatch := newTestRecipe(userID, "Tomato Soup")
+	instructionMatch.Instructions = recipe.TextSteps([]string{"Saute the garlic in olive oil", "Add tomatoes"})
+	private := newTestRecipe(userID, "Secret Garlic Sauce")
+	private.IsPublic = false
+
+	for _, rec := range []*recipe.Recipe{titleMatch, ingredientMatch, instructionMatch, private} {
+		if err := repo.Create(ctx, rec); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	res, err := repo.Search(ctx, recipe.SearchParams{Query: "garlic", Sort: recipe.SortRelevance, Order: "desc"})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if res.Pagination.Total != 3 {
+		t.Fatalf("expected 3 public matches, got %d", res.Pagination.Total)
+	}
+	want := []string{titleMatch.Title, ingredientMatch.Title, instructionMatch.Title}
+	for i, hit := range res.Recipes {
+		if hit.Title != want[i] {
+			t.Fatalf("rank %d: expected %q, got %q", i, want[i], hit.Title)
+		}
+	}
+	if h := res.Recipes[0].Highlights; h == nil || !strings.Contains(h.Title, "<mark>Garlic</mark>") {
+		t.Fatalf("expected highlighted title, got %+v", h)
+	}
+
+	res, err = repo.Search(ctx, recipe.SearchParams{Query: `"olive oil"`, Sort: recipe.SortRelevance})
+	if err != nil {
+		t.Fatalf("phrase search: %v", err)
+	}
+	if len(res.Recipes) != 1 || res.Recipes[0].ID != instructionMatch.ID {
+		t.Fatalf("unexpected phrase results: %+v", res.Recipes)
+	}
+
+	res, err = repo.Search(ctx, recipe.SearchParams{Query: "tomat*", Sort: recipe.SortRelevance})
+	if err != nil {
+		t.Fatalf("prefix search: %v", err)
+	}
+	if len(res.Recipes) != 1 || res.Recipes[0].ID != instructionMatch.ID {
+		t.Fatalf("unexpected prefix results: %+v", res.Recipes)
+	}
+
+	res, err = repo.Search(ctx, recipe.SearchParams{Query: "garlic", ViewerID: &userID, Sort: recipe.SortTitle, Order: "asc",
+		Pagination: pagination.Params{Page: 1, PerPage: 2}})
+	if err != nil {
+		t.Fatalf("owner search: %v", err)
+	}
+	if res.Pagination.Total != 4 || len(res.Recipes) != 2 || !res.Pagination.HasNext {
+		t.Fatalf("unexpected paginated owner results: %+v", res.Pagination)
+	}
+
+	res, err = repo.Search(ctx, recipe.SearchParams{Query: "garlic OR clove", IngredientWords: []string{"garlic", "clove"}, Sort: recipe.SortRelevance})
+	if err != nil {
+		t.Fatalf("ingredient search: %v", err)
+	}
+	if len(res.Recipes) != 1 || res.Recipes[0].ID != ingredientMatch.ID {
+		t.Fatalf("expected only the recipe using garlic, got %+v", res.Recipes)
+	}
+}
+
+func TestRecipeRepository_Favorites(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecipeRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+
+	rec := newTestRecipe(userID, "Pancakes")
+	if err := repo.Create(ctx, rec); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := repo.AddFavorite(ctx, userID, rec.ID); err != nil {
+			t.Fatalf("add favorite: %v", err)
+		}
+	}
+	favs, err := repo.GetUserFavorites(ctx, userID)
+	if err != nil {
+		t.Fatalf("get favorites: %v", err)
+	}
+	if len(favs) != 1 || favs[0].ID != rec.ID {
+		t.Fatalf("unexpected favorites: %+v", favs)
+	}
+
+	// Another user's favorite disappears once the recipe is made private.
+	other := createTestUser(t, db)
+	if err := repo.AddFavorite(ctx, other, rec.ID); err != nil {
+		t.Fatalf("add favorite: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE recipes SET is_public = false WHERE id = $1`, rec.ID); err != nil {
+		t.Fatalf("make private: %v", err)
+	}
+	if favs, err := repo.GetUserFavorites(ctx, other); err != nil || len(favs) != 0 {
+		t.Fatalf("expected the private recipe to be hidden, got %+v, %v", favs, err)
+	}
+	if favs, _ := repo.GetUserFavorites(ctx, userID); len(favs) != 1 {
+		t.Fatalf("expected the owner to keep their favorite, got %d", len(favs))
+	}
+	if err := repo.RemoveFavorite(ctx, userID, rec.ID); err != nil {
+		t.Fatalf("remove favorite: %v", err)
+	}
+	if favs, _ := repo.GetUserFavorites(ctx, userID); len(favs) != 0 {
+		t.Fatalf("expected no favorites, got %d", len(favs))
+	}
+}
+
+func TestRecipeRepository_SemanticSearch(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecipeRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+
+	curry := newTestRecipe(userID, "Chickpea Curry")
+	stew := newTestRecipe(userID, "Lentil Stew")
+	cake := newTestRecipe(userID, "Chocolate Cake")
+	embeddings := map[*recipe.Recipe][]float64{
+		curry: {0.9, 0.1, 0},
+		stew:  {0.7, 0.3, 0},
+		cake:  {0, 0.1, 0.9},
+	}
+	for rec, emb := range embeddings {
+		if err := repo.Create(ctx, rec); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := repo.SaveEmbedding(ctx, rec.ID, emb); err != nil {
+			t.Fatalf("save embedding: %v", err)
+		}
+	}
+
+	got, err := repo.GetEmbedding(ctx, curry.ID)
+	if err != nil || len(got) != 3 || got[0] != 0.9 {
+		t.Fatalf("unexpected embedding %v: %v", got, err)
+	}
+
+	res, err := repo.Search(ctx, recipe.SearchParams{Mode: recipe.ModeSemantic, SimilarTo: &curry.ID, Embedding: got})
+	if err != nil {
+		t.Fatalf("semantic search: %v", err)
+	}
+	if len(res.Recipes) != 2 || res.Recipes[0].ID != stew.ID || res.Recipes[1].ID != cake.ID {
+		t.Fatalf("unexpected semantic order: %+v", res.Recipes)
+	}
+
+	res, err = repo.Search(ctx, recipe.SearchParams{
+		Mode: recipe.ModeHybrid, Query: "cake", Embedding: []float64{1, 0, 0}, SemanticWeight: 0.2,
+	})
+	if err != nil {
+		t.Fatalf("hybrid search: %v", err)
+	}
+	if len(res.Recipes) != 3 || res.Recipes[0].ID != cake.ID {
+		t.Fatalf("expected text match to win with low semantic weight: %+v", res.Recipes)
+	}
+
+	res, err = repo.Search(ctx, recipe.SearchParams{
+		Mode: recipe.ModeSemantic, Embedding: []float64{1, 0, 0}, Pagination: pagination.Params{Page: 2, PerPage: 2},
+	})
+	if err != nil {
+		t.Fatalf("semantic search: %v", err)
+	}
+	if res.Pagination.Total != 3 || len(res.Recipes) != 1 || res.Recipes[0].ID != cake.ID {
+		t.Fatalf("unexpected second page %+v of %+v", res.Recipes, res.Pagination)
+	}
+}
+
+func TestRecipeRepository_SearchAllergenFilter(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecipeRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+
+	satay := newTestRecipe(userID, "Chicken Satay")
+	satay.Allergens = []string{"peanuts", "soy"}
+	salad := newTestRecipe(userID, "Chicken Salad")
+	for _, rec := range []*recipe.Recipe{satay, salad} {
+		if err := repo.Create(ctx, rec); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	params := recipe.SearchParams{Query: "chicken", Sort: recipe.SortRelevance, Allergies: []string{"peanuts"}}
+	res, err := repo.Search(ctx, params)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(res.Recipes) != 1 || res.Recipes[0].ID != salad.ID {
+		t.Fatalf("expected only the safe recipe, got %+v", res.Recipes)
+	}
+	if af := res.AllergenFilter; af == nil || !af.Enabled || af.Hidden != 1 || res.Pagination.Total != 1 {
+		t.Fatalf("unexpected allergen filter meta: %+v total=%d", af, res.Pagination.Total)
+	}
+
+	params.DisableAllergenFilter = true
+	res, err = repo.Search(ctx, params)
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(res.Recipes) != 2 || res.AllergenFilter.Enabled || res.AllergenFilter.Hidden != 0 {
+		t.Fatalf("expected filter to be disabled: %+v", res.AllergenFilter)
+	}
+}
+
+func TestRecipeRepository_UpdateAndTrash(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecipeRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+
+	rec := newTestRecipe(userID, "Flatbread")
+	if err := repo.Create(ctx, rec); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if rec.Version != 1 {
+		t.Fatalf("expected version 1, got %d", rec.Version)
+	}
+
+	rec.Title = "Garlic Flatbread"
+	if err := repo.Update(ctx, rec, 0); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if rec.Version != 2 {
+		t.Fatalf("expected version 2, got %d", rec.Version)
+	}
+	cover := "/media/cover.jpg"
+	if err := repo.UpdateImage(ctx, rec.ID, &cover); err != nil {
+		t.Fatalf("update image: %v", err)
+	}
+	if got, err := repo.GetByID(ctx, rec.ID); err != nil || got.ImageURL == nil || *got.ImageURL != cover || got.Version != 2 {
+		t.Fatalf("expected the image stored without a new version, got %+v, %v", got, err)
+	}
+
+	stale := *rec
+	stale.Version = 1
+	stale.Title = "Stale"
+	if err := repo.Update(ctx, &stale, 0); err != recipe.ErrVersionConflict {
+		t.Fatalf("expected version conflict, got %v", err)
+	}
+	if err := repo.SoftDelete(ctx, rec.ID, 1); err != recipe.ErrVersionConflict {
+		t.Fatalf("expected version conflict on delete, got %v", err)
+	}
+
+	if err := repo.SoftDelete(ctx, rec.ID, rec.Version); err != nil {
+		t.Fatalf("soft delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, rec.ID); err != apperrors.ErrRecipeNotFound {
+		t.Fatalf("expected deleted recipe to be hidden, got %v", err)
+	}
+	trash, err := repo.ListTrash(ctx, userID)
+	if err != nil || len(trash) != 1 || trash[0].DeletedAt == nil {
+		t.Fatalf("unexpected trash %+v: %v", trash, err)
+	}
+
+	if err := repo.Restore(ctx, rec.ID); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	got, err := repo.GetByID(ctx, rec.ID)
+	if err != nil {
+		t.Fatalf("get restored: %v", err)
+	}
+	if got.Title != "Garlic Flatbread" || got.Version != 4 {
+		t.Fatalf("unexpected restored recipe: %+v", got)
+	}
+}
+
+func TestRecipeRepository_Revisions(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecipeRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+
+	rec := newTestRecipe(userID, "Pancakes")
+	if err := repo.Create(ctx, rec); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	rec.Instructions = append(rec.Instructions, recipe.Step{Text: "Serve warm", Duration: 2})
+	if err := repo.Update(ctx, rec, 0); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	updated, err := repo.GetByID(ctx, rec.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if last := updated.Instructions[len(updated.Instructions)-1]; last.Text != "Serve warm" || last.Duration != 2 {
+		t.Fatalf("unexpected last step %+v", last)
+	}
+	if err := repo.Update(ctx, rec, 1); err != nil {
+		t.Fatalf("revert: %v", err)
+	}
+
+	revisions, err := repo.ListRevisions(ctx, rec.ID)
+	if err != nil {
+		t.Fatalf("list revisions: %v", err)
+	}
+	if len(revisions) != 3 || revisions[0].Version != 3 || revisions[2].Version != 1 {
+		t.Fatalf("unexpected revisions %+v", revisions)
+	}
+	if revisions[0].RevertedFrom == nil || *revisions[0].RevertedFrom != 1 || revisions[1].RevertedFrom != nil {
+		t.Fatalf("unexpected reverted_from on %+v", revisions[:2])
+	}
+
+	first, err := repo.GetRevision(ctx, rec.ID, 1)
+	if err != nil {
+		t.Fatalf("get revision: %v", err)
+	}
+	if first.Snapshot.Title != "Pancakes" || len(first.Snapshot.Instructions) != len(rec.Instructions)-1 {
+		t.Fatalf("unexpected snapshot %+v", first.Snapshot)
+	}
+	if _, err := repo.GetRevision(ctx, rec.ID, 9); err != recipe.ErrRevisionNotFound {
+		t.Fatalf("expected revision not found, got %v", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `UPDATE recipe_revisions SET version = 99 WHERE recipe_id = $1`, rec.ID); err == nil {
+		t.Fatal("expected revisions to be immutable")
+	}
+}
+
+func TestRecipeRepository_Forks(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecipeRepository(db)
+	ctx := context.Background()
+	alice, bob := createTestUser(t, db), createTestUser(t, db)
+
+	root := newTestRecipe(alice, "Original")
+	if err := repo.Create(ctx, root); err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	fork := newTestRecipe(bob, "Original")
+	fork.IsPublic = false
+	fork.ForkedFrom = &recipe.ForkOrigin{RecipeID: root.ID, Version: 1, Title: "Original", AuthorID: alice}
+	if err := repo.Create(ctx, fork); err != nil {
+		t.Fatalf("create fork: %v", err)
+	}
+	grandchild := newTestRecipe(alice, "Remix")
+	grandchild.ForkedFrom = &recipe.ForkOrigin{RecipeID: fork.ID, Version: 1, Title: "Original", AuthorID: bob}
+	if err := repo.Create(ctx, grandchild); err != nil {
+		t.Fatalf("create grandchild: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, fork.ID)
+	if err != nil || got.ForkedFrom == nil || *got.ForkedFrom != *fork.ForkedFrom {
+		t.Fatalf("unexpected fork origin %+v: %v", got, err)
+	}
+
+	forks, err := repo.ListForks(ctx, root.ID, nil)
+	if err != nil || len(forks) != 0 {
+		t.Fatalf("private fork should be hidden from anonymous viewers: %+v, %v", forks, err)
+	}
+	forks, err = repo.ListForks(ctx, root.ID, &bob)
+	if err != nil || len(forks) != 1 || forks[0].ID != fork.ID {
+		t.Fatalf("unexpected forks for owner: %+v, %v", forks, err)
+	}
+
+	if err := repo.SoftDelete(ctx, root.ID, 0); err != nil {
+		t.Fatalf("delete root: %v", err)
+	}
+	ancestors, err := repo.ListAncestors(ctx, grandchild.ID)
+	if err != nil {
+		t.Fatalf("list ancestors: %v", err)
+	}
+	if len(ancestors) != 2 || ancestors[0].ID != fork.ID || ancestors[1].ID != root.ID || ancestors[1].DeletedAt == nil {
+		t.Fatalf("unexpected ancestors %+v", ancestors)
+	}
+}
+
+func TestRecipeRepository_Tags(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecipeRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+
+	chili := newTestRecipe(userID, "Chili")
+	chili.Tags = []string{"one-pot", "spicy"}
+	risotto := newTestRecipe(userID, "Risotto")
+	risotto.Tags = []string{"one-pot"}
+	secret := newTestRecipe(userID, "Secret Stew")
+	secret.Tags = []string{"one-pot", "secret"}
+	secret.IsPublic = false
+	for _, rec := range []*recipe.Recipe{chili, risotto, secret} {
+		if err := repo.Create(ctx, rec); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	tags, err := repo.ListTags(ctx, "", 10)
+	if err != nil {
+		t.Fatalf("list tags: %v", err)
+	}
+	want := []recipe.TagCount{{Name: "one-pot", Count: 2}, {Name: "spicy", Count: 1}}
+	if len(tags) != len(want) || tags[0] != want[0] || tags[1] != want[1] {
+		t.Fatalf("tags = %+v, want %+v", tags, want)
+	}
+	if tags, err := repo.ListTags(ctx, "sp", 10); err != nil || len(tags) != 1 || tags[0].Name != "spicy" {
+		t.Fatalf("unexpected prefix matches %+v: %v", tags, err)
+	}
+
+	res, err := repo.Search(ctx, recipe.SearchParams{Tags: []string{"one-pot", "spicy"}, Sort: recipe.SortTitle})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if len(res.Recipes) != 1 || res.Recipes[0].ID != chili.ID {
+		t.Fatalf("unexpected tag search results %+v", res.Recipes)
+	}
+
+	got, err := repo.GetByID(ctx, risotto.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	got.Tags = nil
+	if err := repo.Update(ctx, got, 0); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got, err = repo.GetByID(ctx, risotto.ID); err != nil || len(got.Tags) != 0 {
+		t.Fatalf("expected tags to be cleared, got %v: %v", got, err)
+	}
+}
+
+func TestRecipeRepository_ListAfterAndUpdateLabels(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewRecipeRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+
+	for _, title := range []string{"Custard", "Flan", "Trifle"} {
+		if err := repo.Create(ctx, newTestRecipe(userID, title)); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	first, err := repo.ListAfter(ctx, uuid.Nil, 2)
+	if err != nil || len(first) != 2 || first[0].ID.String() > first[1].ID.String() {
+		t.Fatalf("unexpected first batch %+v, %v", first, err)
+	}
+	rest, err := repo.ListAfter(ctx, first[1].ID, 2)
+	if err != nil || len(rest) != 1 {
+		t.Fatalf("unexpected second batch %+v, %v", rest, err)
+	}
+
+	rec := rest[0]
+	rec.Allergens, rec.UndeclaredAllergens = []string{"dairy", "eggs"}, []string{"eggs"}
+	rec.DietaryCategories, rec.UndeclaredDiets = []string{"vegetarian", "halal"}, []string{"vegetarian"}
+	if err := repo.UpdateLabels(ctx, rec); err != nil {
+		t.Fatalf("update labels: %v", err)
+	}
+	got, err := repo.GetByID(ctx, rec.ID)
+	if err != nil || len(got.Allergens) != 2 || len(got.UndeclaredAllergens) != 1 || got.Version != rec.Version {
+		t.Fatalf("expected labels stored without a new version, got %+v, %v", got, err)
+	}
+	if len(got.DietaryCategories) != 2 || len(got.UndeclaredDiets) != 1 {
+		t.Fatalf("expected labels stored without a new version, got %+v, %v", got, err)
+	}
+	if revs, _ := repo.ListRevisions(ctx, rec.ID); len(revs) != 1 {
+		t.Fatalf("expected no new revision, got %d", len(revs))
+	}
+}
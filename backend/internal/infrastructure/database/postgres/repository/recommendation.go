@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recommendation"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+)
+
+type recommendationRepository struct {
+	db *postgres.DB
+}
+
+// NewRecommendationRepository returns a PostgreSQL backed recommendation
+// repository.
+func NewRecommendationRepository(db *postgres.DB) recommendation.Repository {
+	return &recommendationRepository{db: db}
+}
+
+func (r *recommendationRepository) Signals(ctx context.Context, userID uuid.UUID, limit int) ([]recommendation.Signal, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.recipe_id, s.kind, s.rating, s.at FROM (
+			SELECT recipe_id, $2::text AS kind, 0 AS rating, created_at AS at FROM recipe_favorites WHERE user_id = $1
+			UNION ALL
+			SELECT recipe_id, $3::text, rating, updated_at FROM recipe_reviews WHERE user_id = $1
+			UNION ALL
+			SELECT recipe_id, $4::text, 0, created_at FROM recipe_generations WHERE user_id = $1
+		) s
+		JOIN recipes r ON r.id = s.recipe_id AND r.deleted_at IS NULL
+		ORDER BY s.at DESC, s.recipe_id
+		LIMIT $5`,
+		userID, recommendation.SignalFavorite, recommendation.SignalRating, recommendation.SignalGenerated, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var signals []recommendation.Signal
+	for rows.Next() {
+		var s recommendation.Signal
+		if err := rows.Scan(&s.RecipeID, &s.Kind, &s.Rating, &s.At); err != nil {
+			return nil, err
+		}
+		signals = append(signals, s)
+	}
+	return signals, rows.Err()
+}
+
+func (r *recommendationRepository) Get(ctx context.Context, userID uuid.UUID) (*recommendation.Cache, error) {
+	c := &recommendation.Cache{UserID: userID}
+	var entries []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT entries, computed_at FROM recommendations WHERE user_id = $1`, userID).Scan(&entries, &c.ComputedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(entries, &c.Entries); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *recommendationRepository) Save(ctx context.Context, c *recommendation.Cache) error {
+	if c.Entries == nil {
+		c.Entries = []recommendation.Entry{}
+	}
+	entries, err := json.Marshal(c.Entries)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO recommendations (user_id, entries, computed_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET entries = EXCLUDED.entries, computed_at = EXCLUDED.computed_at,
+			stale = FALSE, failed_at = NULL`,
+		c.UserID, entries, c.ComputedAt)
+	return err
+}
+
+func (r *recommendationRepository) ListStale(ctx context.Context, before, retryBefore time.Time, limit int) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.user_id FROM recommendations c
+		WHERE (c.failed_at IS NULL OR c.failed_at < $2)
+			AND (c.computed_at < $1 OR c.stale
+				OR EXISTS (SELECT 1 FROM recipe_favorites f WHERE f.user_id = c.user_id AND f.created_at > c.computed_at)
+				OR EXISTS (SELECT 1 FROM recipe_reviews v WHERE v.user_id = c.user_id AND v.updated_at > c.computed_at)
+				OR EXISTS (SELECT 1 FROM recipe_generations g WHERE g.user_id = c.user_id AND g.created_at > c.computed_at))
+		ORDER BY c.computed_at, c.user_id
+		LIMIT $3`, before, retryBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *recommendationRepository) MarkFailed(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE recommendations SET failed_at = $2 WHERE user_id = $1`, userID, at)
+	return err
+}
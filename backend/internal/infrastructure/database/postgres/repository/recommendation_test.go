@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/recommendation"
+)
+
+func TestRecommendationRepository(t *testing.T) {
+	db := setupTestDB(t)
+	recipes := NewRecipeRepository(db)
+	repo := NewRecommendationRepository(db)
+	ctx := context.Background()
+	author, viewer := createTestUser(t, db), createTestUser(t, db)
+
+	liked, generated := newTestRecipe(author, "Pad Thai"), newTestRecipe(viewer, "Green curry")
+	for _, rec := range []*recipe.Recipe{liked, generated} {
+		if err := recipes.Create(ctx, rec); err != nil {
+			t.Fatalf("create recipe: %v", err)
+		}
+	}
+	if err := recipes.AddFavorite(ctx, viewer, liked.ID); err != nil {
+		t.Fatalf("add favorite: %v", err)
+	}
+	if err := recipes.RecordGeneration(ctx, &recipe.Generation{
+		ID: uuid.New(), UserID: viewer, RecipeID: generated.ID, Ingredients: []string{"basil"}, CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		t.Fatalf("record generation: %v", err)
+	}
+
+	signals, err := repo.Signals(ctx, viewer, 10)
+	if err != nil {
+		t.Fatalf("signals: %v", err)
+	}
+	kinds := map[uuid.UUID]string{}
+	for _, s := range signals {
+		kinds[s.RecipeID] = s.Kind
+	}
+	if len(signals) != 2 || kinds[liked.ID] != recommendation.SignalFavorite || kinds[generated.ID] != recommendation.SignalGenerated {
+		t.Fatalf("unexpected signals %+v", signals)
+	}
+
+	if c, err := repo.Get(ctx, viewer); err != nil || c != nil {
+		t.Fatalf("expected no cached recommendations, got %+v, %v", c, err)
+	}
+	computed := time.Now().UTC().Add(-time.Hour)
+	c := &recommendation.Cache{UserID: viewer, ComputedAt: computed, Entries: []recommendation.Entry{
+		{RecipeID: liked.ID, Score: 0.8, Explanation: "Because you liked Green curry", BecauseOf: &generated.ID},
+	}}
+	if err := repo.Save(ctx, c); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, err := repo.Get(ctx, viewer)
+	if err != nil || len(got.Entries) != 1 || got.Entries[0].RecipeID != liked.ID || *got.Entries[0].BecauseOf != generated.ID {
+		t.Fatalf("unexpected cache %+v, %v", got, err)
+	}
+
+	// The favorite and generation were recorded after the cache was computed.
+	stale, err := repo.ListStale(ctx, computed.Add(-time.Hour), computed, 10)
+	if err != nil || len(stale) != 1 || stale[0] != viewer {
+		t.Fatalf("expected the viewer's cache to be stale, got %v, %v", stale, err)
+	}
+	c.ComputedAt = time.Now().UTC().Add(time.Minute)
+	if err := repo.Save(ctx, c); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if stale, err := repo.ListStale(ctx, computed.Add(-time.Hour), computed, 10); err != nil || len(stale) != 0 {
+		t.Fatalf("expected no stale caches, got %v, %v", stale, err)
+	}
+
+	// Removing a favorite leaves nothing newer behind, but still makes the
+	// cache stale. A failed refresh holds the user back until retryBefore
+	// passes it.
+	if err := recipes.RemoveFavorite(ctx, viewer, liked.ID); err != nil {
+		t.Fatalf("remove favorite: %v", err)
+	}
+	if stale, err := repo.ListStale(ctx, computed.Add(-time.Hour), computed, 10); err != nil || len(stale) != 1 {
+		t.Fatalf("expected the cache to be stale after removing a favorite, got %v, %v", stale, err)
+	}
+	failed := time.Now().UTC()
+	if err := repo.MarkFailed(ctx, viewer, failed); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	if stale, err := repo.ListStale(ctx, computed.Add(-time.Hour), failed.Add(-time.Minute), 10); err != nil || len(stale) != 0 {
+		t.Fatalf("expected the failed user to wait for a retry, got %v, %v", stale, err)
+	}
+	if stale, err := repo.ListStale(ctx, computed.Add(-time.Hour), failed.Add(time.Minute), 10); err != nil || len(stale) != 1 {
+		t.Fatalf("expected the failed user to be retried, got %v, %v", stale, err)
+	}
+	if err := repo.Save(ctx, c); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if stale, err := repo.ListStale(ctx, computed.Add(-time.Hour), failed.Add(time.Minute), 10); err != nil || len(stale) != 0 {
+		t.Fatalf("expected saving to clear the stale mark, got %v, %v", stale, err)
+	}
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/review"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+const reviewColumns = `v.id, v.recipe_id, v.user_id, u.username, v.rating, v.body, v.made_it,
+	v.helpful_count, v.created_at, v.updated_at`
+
+var reviewOrders = map[string]string{
+	review.SortHelpful: "v.helpful_count DESC, v.created_at DESC",
+	review.SortNewest:  "v.created_at DESC",
+	review.SortOldest:  "v.created_at ASC",
+	review.SortHighest: "v.rating DESC, v.created_at DESC",
+	review.SortLowest:  "v.rating ASC, v.created_at DESC",
+}
+
+type reviewRepository struct {
+	db *postgres.DB
+}
+
+// NewReviewRepository returns a PostgreSQL backed review repository.
+func NewReviewRepository(db *postgres.DB) review.Repository {
+	return &reviewRepository{db: db}
+}
+
+func (r *reviewRepository) Upsert(ctx context.Context, rev *review.Review) (bool, error) {
+	var created bool
+	err := inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockRecipe(ctx, tx, rev.RecipeID); err != nil {
+			return err
+		}
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO recipe_reviews (id, recipe_id, user_id, rating, body, made_it, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (recipe_id, user_id) DO UPDATE
+			SET rating = EXCLUDED.rating, body = EXCLUDED.body, made_it = EXCLUDED.made_it, updated_at = EXCLUDED.updated_at
+			RETURNING id, helpful_count, created_at, (SELECT username FROM users WHERE id = $3), xmax = 0`,
+			rev.ID, rev.RecipeID, rev.UserID, rev.Rating, rev.Body, rev.MadeIt, rev.CreatedAt, rev.UpdatedAt,
+		).Scan(&rev.ID, &rev.HelpfulCount, &rev.CreatedAt, &rev.Username, &created)
+		if err != nil {
+			return err
+		}
+		return refreshRating(ctx, tx, rev.RecipeID)
+	})
+	return created, err
+}
+
+func (r *reviewRepository) GetByID(ctx context.Context, id uuid.UUID) (*review.Review, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+reviewColumns+` FROM recipe_reviews v JOIN users u ON u.id = v.user_id
+		WHERE v.id = $1`, id)
+	rev, err := scanReview(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, review.ErrReviewNotFound
+	}
+	return rev, err
+}
+
+func (r *reviewRepository) Delete(ctx context.Context, recipeID, userID uuid.UUID) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if err := lockRecipe(ctx, tx, recipeID); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM recipe_reviews WHERE recipe_id = $1 AND user_id = $2`, recipeID, userID)
+		if err != nil {
+			return err
+		}
+		if err := requireRow(res, review.ErrReviewNotFound); err != nil {
+			return err
+		}
+		return refreshRating(ctx, tx, recipeID)
+	})
+}
+
+func (r *reviewRepository) List(ctx context.Context, recipeID uuid.UUID, params review.ListParams) (*review.ListResult, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT count(*) FROM recipe_reviews WHERE recipe_id = $1`, recipeID).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	page := params.Pagination.Normalize()
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s FROM recipe_reviews v JOIN users u ON u.id = v.user_id
+		WHERE v.recipe_id = $1
+		ORDER BY %s, v.id LIMIT $2 OFFSET $3`, reviewColumns, reviewOrders[params.Sort]),
+		recipeID, page.PerPage, page.Offset())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := &review.ListResult{Reviews: []*review.Review{}, Pagination: pagination.NewMeta(page, total)}
+	for rows.Next() {
+		rev, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Reviews = append(result.Reviews, rev)
+	}
+	return result, rows.Err()
+}
+
+func (r *reviewRepository) AddHelpfulVote(ctx context.Context, reviewID, userID uuid.UUID) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO review_helpful_votes (review_id, user_id) VALUES ($1, $2)
+			ON CONFLICT (review_id, user_id) DO NOTHING`, reviewID, userID)
+		if err != nil {
+			return err
+		}
+		return adjustHelpful(ctx, tx, res, reviewID, 1)
+	})
+}
+
+func (r *reviewRepository) RemoveHelpfulVote(ctx context.Context, reviewID, userID uuid.UUID) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM review_helpful_votes WHERE review_id = $1 AND user_id = $2`, reviewID, userID)
+		if err != nil {
+			return err
+		}
+		return adjustHelpful(ctx, tx, res, reviewID, -1)
+	})
+}
+
+// adjustHelpful applies delta to the review's helpful count when the vote
+// statement changed a row.
+func adjustHelpful(ctx context.Context, tx *sql.Tx, res sql.Result, reviewID uuid.UUID, delta int) error {
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE recipe_reviews SET helpful_count = helpful_count + $2 WHERE id = $1`, reviewID, delta)
+	return err
+}
+
+// lockRecipe locks the recipe row so that concurrent reviews of the same
+// recipe refresh its aggregates one at a time.
+func lockRecipe(ctx context.Context, tx *sql.Tx, recipeID uuid.UUID) error {
+	var id uuid.UUID
+	err := tx.QueryRowContext(ctx, `SELECT id FROM recipes WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, recipeID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperrors.ErrRecipeNotFound
+	}
+	return err
+}
+
+// refreshRating recomputes the recipe's rating average and count.
+func refreshRating(ctx context.Context, tx *sql.Tx, recipeID uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE recipes SET (rating_average, rating_count) = (
+			SELECT coalesce(avg(rating), 0), count(*) FROM recipe_reviews WHERE recipe_id = $1
+		)
+		WHERE id = $1`, recipeID)
+	return err
+}
+
+func scanReview(s scanner) (*review.Review, error) {
+	rev := &review.Review{}
+	err := s.Scan(&rev.ID, &rev.RecipeID, &rev.UserID, &rev.Username, &rev.Rating, &rev.Body, &rev.MadeIt,
+		&rev.HelpfulCount, &rev.CreatedAt, &rev.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
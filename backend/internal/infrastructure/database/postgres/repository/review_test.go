@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/review"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+func TestReviewRepository(t *testing.T) {
+	db := setupTestDB(t)
+	recipes := NewRecipeRepository(db)
+	repo := NewReviewRepository(db)
+	ctx := context.Background()
+	author, alice, bob := createTestUser(t, db), createTestUser(t, db), createTestUser(t, db)
+
+	rec := newTestRecipe(author, "Lasagna")
+	if err := recipes.Create(ctx, rec); err != nil {
+		t.Fatalf("create recipe: %v", err)
+	}
+
+	save := func(userID uuid.UUID, rating int) *review.Review {
+		t.Helper()
+		now := time.Now().UTC()
+		rev := &review.Review{ID: uuid.New(), RecipeID: rec.ID, UserID: userID, Rating: rating, CreatedAt: now, UpdatedAt: now}
+		if _, err := repo.Upsert(ctx, rev); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+		return rev
+	}
+	first := save(alice, 5)
+	save(bob, 2)
+	again := save(alice, 3)
+	if again.ID != first.ID {
+		t.Fatalf("expected the second review by the same user to replace the first")
+	}
+
+	got, err := recipes.GetByID(ctx, rec.ID)
+	if err != nil {
+		t.Fatalf("get recipe: %v", err)
+	}
+	if got.RatingCount != 2 || got.RatingAverage != 2.5 {
+		t.Fatalf("unexpected aggregates avg=%v count=%d", got.RatingAverage, got.RatingCount)
+	}
+
+	if err := repo.AddHelpfulVote(ctx, first.ID, bob); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+	if err := repo.AddHelpfulVote(ctx, first.ID, bob); err != nil {
+		t.Fatalf("repeat vote: %v", err)
+	}
+	res, err := repo.List(ctx, rec.ID, review.ListParams{Sort: review.SortHelpful, Pagination: pagination.Params{Page: 1, PerPage: 10}})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if res.Pagination.Total != 2 || res.Reviews[0].ID != first.ID || res.Reviews[0].HelpfulCount != 1 {
+		t.Fatalf("unexpected listing %+v", res.Reviews)
+	}
+
+	if err := repo.Delete(ctx, rec.ID, bob); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := repo.Delete(ctx, rec.ID, bob); err != review.ErrReviewNotFound {
+		t.Fatalf("expected review not found, got %v", err)
+	}
+	got, _ = recipes.GetByID(ctx, rec.ID)
+	if got.RatingCount != 1 || got.RatingAverage != 3 {
+		t.Fatalf("unexpected aggregates after delete avg=%v count=%d", got.RatingAverage, got.RatingCount)
+	}
+}
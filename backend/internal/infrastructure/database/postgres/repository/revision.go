@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+func (r *recipeRepository) ListRevisions(ctx context.Context, recipeID uuid.UUID) ([]*recipe.Revision, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT recipe_id, version, author_id, reverted_from, created_at
+		FROM recipe_revisions WHERE recipe_id = $1
+		ORDER BY version DESC`, recipeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*recipe.Revision{}
+	for rows.Next() {
+		rev := &recipe.Revision{}
+		if err := rows.Scan(&rev.RecipeID, &rev.Version, &rev.AuthorID, &rev.RevertedFrom, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *recipeRepository) GetRevision(ctx context.Context, recipeID uuid.UUID, version int) (*recipe.Revision, error) {
+	rev := &recipe.Revision{}
+	var snapshot []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT recipe_id, version, author_id, reverted_from, snapshot, created_at
+		FROM recipe_revisions WHERE recipe_id = $1 AND version = $2`, recipeID, version).
+		Scan(&rev.RecipeID, &rev.Version, &rev.AuthorID, &rev.RevertedFrom, &snapshot, &rev.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, recipe.ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// insertRevision records rec, at its current version, as a revision.
+func insertRevision(ctx context.Context, tx *sql.Tx, rec *recipe.Recipe, revertedFrom int) error {
+	snapshot := *rec
+	snapshot.DeletedAt = nil
+	snapshot.AllergenWarnings = nil
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	var from *int
+	if revertedFrom > 0 {
+		from = &revertedFrom
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO recipe_revisions (recipe_id, version, author_id, snapshot, reverted_from, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		rec.ID, rec.Version, rec.UserID, data, from, rec.UpdatedAt)
+	return err
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"alchemorsel/backend/internal/domain/shopping"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+)
+
+const shoppingListColumns = `l.id, l.owner_id, l.name, l.plan_id, l.created_at, l.updated_at`
+
+const shoppingItemColumns = `id, list_id, name, amount, unit, aisle, recipes, manual, checked, position, created_at`
+
+type shoppingRepository struct {
+	db *postgres.DB
+}
+
+// NewShoppingRepository returns a PostgreSQL backed shopping list repository.
+func NewShoppingRepository(db *postgres.DB) shopping.Repository {
+	return &shoppingRepository{db: db}
+}
+
+func (r *shoppingRepository) Create(ctx context.Context, l *shopping.List, items []*shopping.Item) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO shopping_lists (id, owner_id, name, plan_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			l.ID, l.OwnerID, l.Name, l.PlanID, l.CreatedAt, l.UpdatedAt); err != nil {
+			return err
+		}
+		for _, it := range items {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO shopping_list_items (`+shoppingItemColumns+`)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+				it.ID, it.ListID, it.Name, it.Amount, it.Unit, it.Aisle, pq.Array(nonNil(it.Recipes)),
+				it.Manual, it.Checked, it.Position, it.CreatedAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *shoppingRepository) GetByID(ctx context.Context, id uuid.UUID) (*shopping.List, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+shoppingListColumns+` FROM shopping_lists l WHERE l.id = $1`, id)
+	l, err := scanShoppingList(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, shopping.ErrListNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT m.user_id, u.username, m.added_at
+		FROM shopping_list_members m JOIN users u ON u.id = m.user_id
+		WHERE m.list_id = $1
+		ORDER BY m.added_at, m.user_id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		m := &shopping.Member{}
+		if err := rows.Scan(&m.UserID, &m.Username, &m.AddedAt); err != nil {
+			return nil, err
+		}
+		l.Members = append(l.Members, m)
+	}
+	return l, rows.Err()
+}
+
+func (r *shoppingRepository) Update(ctx context.Context, l *shopping.List) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE shopping_lists SET name = $2, updated_at = $3 WHERE id = $1`, l.ID, l.Name, l.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, shopping.ErrListNotFound)
+}
+
+func (r *shoppingRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM shopping_lists WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, shopping.ErrListNotFound)
+}
+
+func (r *shoppingRepository) ListForMember(ctx context.Context, userID uuid.UUID) ([]*shopping.List, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+shoppingListColumns+` FROM shopping_lists l
+		WHERE l.owner_id = $1 OR EXISTS (
+			SELECT 1 FROM shopping_list_members m WHERE m.list_id = l.id AND m.user_id = $1
+		)
+		ORDER BY l.updated_at DESC, l.id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := []*shopping.List{}
+	for rows.Next() {
+		l, err := scanShoppingList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+	return lists, rows.Err()
+}
+
+func (r *shoppingRepository) ListItems(ctx context.Context, listID uuid.UUID) ([]*shopping.Item, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+shoppingItemColumns+` FROM shopping_list_items
+		WHERE list_id = $1
+		ORDER BY array_position($2::text[], aisle::text), position, created_at`,
+		listID, pq.Array(shopping.Aisles))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*shopping.Item{}
+	for rows.Next() {
+		it, err := scanShoppingItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+func (r *shoppingRepository) GetItem(ctx context.Context, listID, id uuid.UUID) (*shopping.Item, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+shoppingItemColumns+` FROM shopping_list_items WHERE list_id = $1 AND id = $2`, listID, id)
+	it, err := scanShoppingItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, shopping.ErrItemNotFound
+	}
+	return it, err
+}
+
+func (r *shoppingRepository) CreateItem(ctx context.Context, it *shopping.Item) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		// Touching the list first locks it, so concurrent additions take
+		// positions one after the other.
+		if err := touchShoppingList(ctx, tx, it.ListID); err != nil {
+			return err
+		}
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COALESCE(max(position) + 1, 0) FROM shopping_list_items WHERE list_id = $1`,
+			it.ListID).Scan(&it.Position); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO shopping_list_items (`+shoppingItemColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			it.ID, it.ListID, it.Name, it.Amount, it.Unit, it.Aisle, pq.Array(nonNil(it.Recipes)),
+			it.Manual, it.Checked, it.Position, it.CreatedAt)
+		return err
+	})
+}
+
+func (r *shoppingRepository) UpdateItem(ctx context.Context, it *shopping.Item) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE shopping_list_items SET name = $3, amount = $4, unit = $5, aisle = $6, checked = $7
+			WHERE list_id = $1 AND id = $2`,
+			it.ListID, it.ID, it.Name, it.Amount, it.Unit, it.Aisle, it.Checked)
+		if err != nil {
+			return err
+		}
+		if err := requireRow(res, shopping.ErrItemNotFound); err != nil {
+			return err
+		}
+		return touchShoppingList(ctx, tx, it.ListID)
+	})
+}
+
+func (r *shoppingRepository) DeleteItem(ctx context.Context, listID, id uuid.UUID) error {
+	return inTx(ctx, r.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM shopping_list_items WHERE list_id = $1 AND id = $2`, listID, id)
+		if err != nil {
+			return err
+		}
+		if err := requireRow(res, shopping.ErrItemNotFound); err != nil {
+			return err
+		}
+		return touchShoppingList(ctx, tx, listID)
+	})
+}
+
+func (r *shoppingRepository) AddMember(ctx context.Context, listID uuid.UUID, m *shopping.Member) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO shopping_list_members (list_id, user_id, added_at) VALUES ($1, $2, $3)`,
+		listID, m.UserID, m.AddedAt)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return shopping.ErrAlreadyMember
+	}
+	return err
+}
+
+func (r *shoppingRepository) RemoveMember(ctx context.Context, listID, userID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM shopping_list_members WHERE list_id = $1 AND user_id = $2`, listID, userID)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, shopping.ErrMemberNotFound)
+}
+
+// touchShoppingList marks the list as updated when its items change, so
+// that members see which lists are active.
+func touchShoppingList(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	res, err := tx.ExecContext(ctx, `UPDATE shopping_lists SET updated_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, shopping.ErrListNotFound)
+}
+
+func scanShoppingList(s scanner) (*shopping.List, error) {
+	l := &shopping.List{}
+	if err := s.Scan(&l.ID, &l.OwnerID, &l.Name, &l.PlanID, &l.CreatedAt, &l.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func scanShoppingItem(s scanner) (*shopping.Item, error) {
+	it := &shopping.Item{}
+	err := s.Scan(&it.ID, &it.ListID, &it.Name, &it.Amount, &it.Unit, &it.Aisle, pq.Array(&it.Recipes),
+		&it.Manual, &it.Checked, &it.Position, &it.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return it, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/shopping"
+)
+
+func TestShoppingRepository(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewShoppingRepository(db)
+	ctx := context.Background()
+	owner, member := createTestUser(t, db), createTestUser(t, db)
+
+	now := time.Now().UTC()
+	l := &shopping.List{ID: uuid.New(), OwnerID: owner, Name: "Weekend", CreatedAt: now, UpdatedAt: now}
+	items := []*shopping.Item{
+		{ID: uuid.New(), ListID: l.ID, Name: "butter", Amount: 0.375, Unit: "cup", Aisle: shopping.AisleDairy,
+			Recipes: []string{"Curry", "Cookies"}, Position: 0, CreatedAt: now},
+		{ID: uuid.New(), ListID: l.ID, Name: "onions", Amount: 4, Aisle: shopping.AisleProduce, Position: 1, CreatedAt: now},
+	}
+	if err := repo.Create(ctx, l, items); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	manual := &shopping.Item{ID: uuid.New(), ListID: l.ID, Name: "Napkins", Aisle: shopping.AisleOther, Manual: true, CreatedAt: now}
+	if err := repo.CreateItem(ctx, manual); err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	if manual.Position != 2 {
+		t.Fatalf("manual item position = %d, want 2", manual.Position)
+	}
+
+	items[0].Checked = true
+	if err := repo.UpdateItem(ctx, items[0]); err != nil {
+		t.Fatalf("update item: %v", err)
+	}
+	got, err := repo.ListItems(ctx, l.ID)
+	if err != nil {
+		t.Fatalf("list items: %v", err)
+	}
+	if len(got) != 3 || got[0].Name != "onions" || got[1].Name != "butter" || got[2].ID != manual.ID {
+		t.Fatalf("items are not ordered by aisle: %+v", got)
+	}
+	if !got[1].Checked || len(got[1].Recipes) != 2 || got[1].Amount != 0.375 {
+		t.Fatalf("unexpected item %+v", got[1])
+	}
+
+	if err := repo.AddMember(ctx, l.ID, &shopping.Member{UserID: member, AddedAt: now}); err != nil {
+		t.Fatalf("add member: %v", err)
+	}
+	if err := repo.AddMember(ctx, l.ID, &shopping.Member{UserID: member, AddedAt: now}); err != shopping.ErrAlreadyMember {
+		t.Fatalf("expected ErrAlreadyMember, got %v", err)
+	}
+	shared, err := repo.ListForMember(ctx, member)
+	if err != nil || len(shared) != 1 || shared[0].ID != l.ID {
+		t.Fatalf("unexpected shared lists %+v, %v", shared, err)
+	}
+	fetched, err := repo.GetByID(ctx, l.ID)
+	if err != nil || len(fetched.Members) != 1 || fetched.Members[0].UserID != member {
+		t.Fatalf("unexpected list %+v, %v", fetched, err)
+	}
+	if err := repo.RemoveMember(ctx, l.ID, member); err != nil {
+		t.Fatalf("remove member: %v", err)
+	}
+	if err := repo.RemoveMember(ctx, l.ID, member); err != shopping.ErrMemberNotFound {
+		t.Fatalf("expected ErrMemberNotFound, got %v", err)
+	}
+
+	if err := repo.DeleteItem(ctx, l.ID, manual.ID); err != nil {
+		t.Fatalf("delete item: %v", err)
+	}
+	if _, err := repo.GetItem(ctx, l.ID, manual.ID); err != shopping.ErrItemNotFound {
+		t.Fatalf("expected ErrItemNotFound, got %v", err)
+	}
+	if err := repo.Delete(ctx, l.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, l.ID); err != shopping.ErrListNotFound {
+		t.Fatalf("expected ErrListNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// buildTSQuery converts a user supplied search string into to_tsquery syntax.
+//
+// Bare words are combined with AND, "quoted phrases" become followed-by
+// sequences, a trailing '*' turns a word into a prefix match, a leading '-'
+// negates a term and the keyword OR joins its neighbours with OR. Characters
+// that carry meaning in tsquery syntax are stripped so the result is always
+// safe to pass to to_tsquery. An empty string is returned when nothing
+// searchable remains.
+func buildTSQuery(q string) string {
+	b := &strings.Builder{}
+	or := false
+	for _, tok := range tokenizeQuery(q) {
+		if !tok.phrase && strings.EqualFold(tok.text, "or") {
+			or = b.Len() > 0
+			continue
+		}
+		term := tok.term()
+		if term == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			if or {
+				b.WriteString(" | ")
+			} else {
+				b.WriteString(" & ")
+			}
+		}
+		b.WriteString(term)
+		or = false
+	}
+	return b.String()
+}
+
+type queryToken struct {
+	text   string
+	phrase bool
+	negate bool
+}
+
+func (t queryToken) term() string {
+	text := t.text
+	prefix := false
+	if !t.phrase {
+		prefix = strings.HasSuffix(text, "*")
+		text = strings.TrimRight(text, "*")
+	}
+	words := splitWords(text)
+	if len(words) == 0 {
+		return ""
+	}
+	if prefix {
+		words[len(words)-1] += ":*"
+	}
+	term := strings.Join(words, " <-> ")
+	if len(words) > 1 {
+		term = "(" + term + ")"
+	}
+	if t.negate {
+		term = "!" + term
+	}
+	return term
+}
+
+func tokenizeQuery(q string) []queryToken {
+	var tokens []queryToken
+	rs := []rune(q)
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) {
+			i++
+			continue
+		}
+		negate := false
+		if rs[i] == '-' && i+1 < len(rs) && !unicode.IsSpace(rs[i+1]) {
+			negate = true
+			i++
+		}
+		if rs[i] == '"' {
+			end := i + 1
+			for end < len(rs) && rs[end] != '"' {
+				end++
+			}
+			tokens = append(tokens, queryToken{text: string(rs[i+1 : end]), phrase: true, negate: negate})
+			i = end + 1
+			continue
+		}
+		end := i
+		for end < len(rs) && !unicode.IsSpace(rs[end]) && rs[end] != '"' {
+			end++
+		}
+		tokens = append(tokens, queryToken{text: string(rs[i:end]), negate: negate})
+		i = end
+	}
+	return tokens
+}
+
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,33 @@
+package repository
+
+import "testing"
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"garlic", "garlic"},
+		{"Garlic Bread", "garlic & bread"},
+		{`"olive oil"`, "(olive <-> oil)"},
+		{`"olive oil" lemon`, "(olive <-> oil) & lemon"},
+		{"tom*", "tom:*"},
+		{"chicken -curry", "chicken & !curry"},
+		{`-"sour cream" dip`, "!(sour <-> cream) & dip"},
+		{"basil OR mint", "basil | mint"},
+		{"or basil", "basil"},
+		{"all-purpose flour", "(all <-> purpose) & flour"},
+		{"crème brûlée", "crème & brûlée"},
+		{"a&b|c:*!()", "(a <-> b <-> c)"},
+		{"'; DROP TABLE recipes;--", "drop & table & recipes"},
+		{`"unterminated phrase`, "(unterminated <-> phrase)"},
+		{"- * \"\"", ""},
+	}
+	for _, tt := range tests {
+		if got := buildTSQuery(tt.in); got != tt.want {
+			t.Errorf("buildTSQuery(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+)
+
+// inTx runs fn in a transaction that is committed when fn succeeds and
+// rolled back otherwise.
+func inTx(ctx context.Context, db *postgres.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
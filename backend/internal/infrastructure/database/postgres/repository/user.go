@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"alchemorsel/backend/internal/domain/user"
+	"alchemorsel/backend/internal/infrastructure/database/postgres"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+const userColumns = `id, email, username, password_hash, name, profile_picture_url,
+	dietary_preferences, allergies, unit_system, created_at, updated_at, deleted_at`
+
+type userRepository struct {
+	db *postgres.DB
+}
+
+// NewUserRepository returns a PostgreSQL backed user repository.
+func NewUserRepository(db *postgres.DB) user.Repository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) Create(ctx context.Context, u *user.User) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO users (id, email, username, password_hash, name, profile_picture_url,
+			dietary_preferences, allergies, unit_system, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		u.ID, u.Email, u.Username, u.PasswordHash, u.Name, u.ProfilePictureURL,
+		pq.Array(nonNil(u.DietaryPreferences)), pq.Array(nonNil(u.Allergies)), u.UnitSystem, u.CreatedAt, u.UpdatedAt,
+	)
+	return err
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	return r.getOne(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1 AND deleted_at IS NULL`, id)
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*user.User, error) {
+	return r.getOne(ctx, `SELECT `+userColumns+` FROM users WHERE lower(email) = lower($1) AND deleted_at IS NULL`, email)
+}
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*user.User, error) {
+	return r.getOne(ctx, `SELECT `+userColumns+` FROM users WHERE username = $1 AND deleted_at IS NULL`, username)
+}
+
+func (r *userRepository) Update(ctx context.Context, u *user.User) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE users SET email = $2, username = $3, password_hash = $4, name = $5,
+			profile_picture_url = $6, dietary_preferences = $7, allergies = $8, unit_system = $9,
+			updated_at = $10
+		WHERE id = $1 AND deleted_at IS NULL`,
+		u.ID, u.Email, u.Username, u.PasswordHash, u.Name, u.ProfilePictureURL,
+		pq.Array(nonNil(u.DietaryPreferences)), pq.Array(nonNil(u.Allergies)), u.UnitSystem, u.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, apperrors.ErrUserNotFound)
+}
+
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	return requireRow(res, apperrors.ErrUserNotFound)
+}
+
+func (r *userRepository) getOne(ctx context.Context, query string, arg any) (*user.User, error) {
+	u := &user.User{}
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(
+		&u.ID, &u.Email, &u.Username, &u.PasswordHash, &u.Name, &u.ProfilePictureURL,
+		pq.Array(&u.DietaryPreferences), pq.Array(&u.Allergies), &u.UnitSystem, &u.CreatedAt, &u.UpdatedAt, &u.DeletedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// requireRow returns notFound when the statement affected no rows.
+func requireRow(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
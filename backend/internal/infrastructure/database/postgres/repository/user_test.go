@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/user"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+func TestUserRepository_CRUD(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	u := &user.User{
+		ID:           uuid.New(),
+		Email:        "cook@example.com",
+		Username:     "cook",
+		PasswordHash: "hash",
+		Name:         "Cook",
+		Allergies:    []string{"peanuts"},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := repo.Create(ctx, u); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got, err := repo.GetByEmail(ctx, "COOK@example.com")
+	if err != nil {
+		t.Fatalf("get by email: %v", err)
+	}
+	if got.ID != u.ID || len(got.Allergies) != 1 || got.Allergies[0] != "peanuts" {
+		t.Fatalf("unexpected user: %+v", got)
+	}
+
+	u.Allergies = []string{"peanuts", "shellfish"}
+	u.UnitSystem = "metric"
+	if err := repo.Update(ctx, u); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got, _ := repo.GetByUsername(ctx, "cook"); len(got.Allergies) != 2 || got.UnitSystem != "metric" {
+		t.Fatalf("update not persisted: %+v", got)
+	}
+
+	if err := repo.Delete(ctx, u.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, u.ID); err != apperrors.ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound after delete, got %v", err)
+	}
+}
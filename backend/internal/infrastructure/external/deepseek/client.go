@@ -19,6 +19,15 @@ type Client struct {
 	httpClient *http.Client
 }
 
+// NewClient creates a DeepSeek client. A nil httpClient selects
+// http.DefaultClient.
+func NewClient(apiKey, apiURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{apiKey: apiKey, apiURL: apiURL, httpClient: httpClient}
+}
+
 // GenerateRecipeRequest defines parameters for recipe generation.
 type GenerateRecipeRequest struct {
 	UserPreferences   any
@@ -33,38 +42,75 @@ func (c *Client) GenerateRecipe(ctx context.Context, req GenerateRecipeRequest)
 		return nil, nil, err
 	}
 
-	payloadBytes, err := json.Marshal(map[string]string{"prompt": prompt})
-	if err != nil {
+	var dsResp struct {
+		Recipe    recipe.Recipe `json:"recipe"`
+		Embedding []float64     `json:"embedding"`
+	}
+	if err := c.post(ctx, "/generate", map[string]string{"prompt": prompt}, &dsResp); err != nil {
 		return nil, nil, err
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.apiURL, "/")+"/generate", bytes.NewReader(payloadBytes))
+	return &dsResp.Recipe, dsResp.Embedding, nil
+}
+
+// recipeConstraints is the JSON form of the constraints sent in the prompt.
+type recipeConstraints struct {
+	Ingredients  []string `json:"ingredients,omitempty"`
+	CookingTime  int      `json:"cooking_time,omitempty"`
+	Servings     int      `json:"servings,omitempty"`
+	CustomPrompt string   `json:"custom_prompt,omitempty"`
+}
+
+// Generate implements recipe.Generator.
+func (c *Client) Generate(ctx context.Context, req recipe.GenerateRequest) (*recipe.Recipe, []float64, error) {
+	return c.GenerateRecipe(ctx, GenerateRecipeRequest{
+		Style: req.Style,
+		RecipeConstraints: recipeConstraints{
+			Ingredients:  req.Ingredients,
+			CookingTime:  req.CookingTime,
+			Servings:     req.Servings,
+			CustomPrompt: req.CustomPrompt,
+		},
+	})
+}
+
+// Embed returns the embedding of text. It implements recipe.Embedder.
+func (c *Client) Embed(ctx context.Context, text string) ([]float64, error) {
+	var resp struct {
+		Embedding []float64 `json:"embedding"`
+	}
+	if err := c.post(ctx, "/embeddings", map[string]string{"input": text}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Embedding, nil
+}
+
+// post sends payload as JSON to path and decodes the JSON response into out.
+func (c *Client) post(ctx context.Context, path string, payload, out any) error {
+	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return nil, nil, err
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.apiURL, "/")+path, bytes.NewReader(payloadBytes))
+	if err != nil {
+		return err
 	}
 	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, nil, fmt.Errorf("deepseek: unexpected status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("deepseek: unexpected status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var dsResp struct {
-		Recipe    recipe.Recipe `json:"recipe"`
-		Embedding []float64     `json:"embedding"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&dsResp); err != nil {
-		return nil, nil, err
-	}
-
-	return &dsResp.Recipe, dsResp.Embedding, nil
+	return json.NewDecoder(resp.Body).Decode(out)
 }
 
 func buildPrompt(req GenerateRecipeRequest) (string, error) {
@@ -7,6 +7,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"alchemorsel/backend/internal/domain/recipe"
 )
 
 func TestGenerateRecipe(t *testing.T) {
@@ -58,3 +60,59 @@ func TestGenerateRecipe_HTTPError(t *testing.T) {
 		t.Fatalf("expected error")
 	}
 }
+
+func TestGenerate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Prompt string `json:"prompt"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		if !strings.Contains(payload.Prompt, `"ingredients":["basil"]`) || !strings.Contains(payload.Prompt, `"servings":2`) {
+			t.Fatalf("prompt missing constraints: %s", payload.Prompt)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"recipe":    map[string]any{"title": "Pesto"},
+			"embedding": []float64{0.5},
+		})
+	}))
+	defer ts.Close()
+
+	c := NewClient("k", ts.URL, ts.Client())
+	rec, emb, err := c.Generate(context.Background(), recipe.GenerateRequest{Ingredients: []string{"basil"}, Servings: 2})
+	if err != nil {
+		t.Fatalf("Generate returned error: %v", err)
+	}
+	if rec.Title != "Pesto" || len(emb) != 1 {
+		t.Fatalf("unexpected result: %+v %v", rec, emb)
+	}
+}
+
+func TestEmbed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+		var payload struct {
+			Input string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		if payload.Input != "spicy noodles" {
+			t.Fatalf("unexpected input %q", payload.Input)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"embedding": []float64{0.1, 0.2}})
+	}))
+	defer ts.Close()
+
+	c := NewClient("k", ts.URL, ts.Client())
+	emb, err := c.Embed(context.Background(), "spicy noodles")
+	if err != nil {
+		t.Fatalf("Embed returned error: %v", err)
+	}
+	if len(emb) != 2 || emb[1] != 0.2 {
+		t.Fatalf("unexpected embedding: %v", emb)
+	}
+}
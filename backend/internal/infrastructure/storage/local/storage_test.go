@@ -0,0 +1,42 @@
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	root := t.TempDir()
+	s := New(root, "/media/")
+	ctx := context.Background()
+
+	url, err := s.Put(ctx, "recipes/a/b/large.jpg", "image/jpeg", []byte("jpeg"))
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if url != "/media/recipes/a/b/large.jpg" {
+		t.Fatalf("url = %q", url)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "recipes", "a", "b", "large.jpg"))
+	if err != nil || string(data) != "jpeg" {
+		t.Fatalf("read back %q, %v", data, err)
+	}
+
+	if err := s.Delete(ctx, "recipes/a/b/large.jpg"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := s.Delete(ctx, "recipes/a/b/large.jpg"); err != nil {
+		t.Fatalf("delete missing file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "recipes")); !os.IsNotExist(err) {
+		t.Fatalf("expected empty directories to be removed, got %v", err)
+	}
+
+	for _, key := range []string{"../escape.jpg", "/abs.jpg", "a/../../b.jpg", ""} {
+		if _, err := s.Put(ctx, key, "image/jpeg", nil); err == nil {
+			t.Errorf("Put(%q) succeeded, want error", key)
+		}
+	}
+}
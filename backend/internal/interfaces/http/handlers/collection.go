@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/collection"
+)
+
+type createCollectionRequest struct {
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	CoverImageURL *string `json:"cover_image_url"`
+	IsPublic      bool    `json:"is_public"`
+}
+
+type updateCollectionRequest struct {
+	Name          *string `json:"name"`
+	Description   *string `json:"description"`
+	CoverImageURL *string `json:"cover_image_url"`
+	IsPublic      *bool   `json:"is_public"`
+}
+
+type addCollectionRecipeRequest struct {
+	RecipeID uuid.UUID `json:"recipe_id"`
+	Position *int      `json:"position"`
+}
+
+type reorderCollectionRequest struct {
+	RecipeIDs []uuid.UUID `json:"recipe_ids"`
+}
+
+type inviteCollaboratorRequest struct {
+	Username string `json:"username"`
+}
+
+// ListCollections lists the current user's own and shared collections, or
+// another user's public collections when user_id is given.
+func ListCollections(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		ownerID, err := queryUUID(c, "user_id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		collections, err := svc.List(c.Request.Context(), userID, ownerID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"collections": collections})
+	}
+}
+
+// CreateCollection creates a collection owned by the current user.
+func CreateCollection(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		var req createCollectionRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		col, err := svc.Create(c.Request.Context(), userID, collection.CreateRequest{
+			Name:          req.Name,
+			Description:   req.Description,
+			CoverImageURL: req.CoverImageURL,
+			IsPublic:      req.IsPublic,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"collection": col})
+	}
+}
+
+// GetCollection returns a collection and its recipes in order.
+func GetCollection(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		col, err := svc.Get(c.Request.Context(), viewerID(c), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"collection": col})
+	}
+}
+
+// UpdateCollection changes the details of a collection.
+func UpdateCollection(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req updateCollectionRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		col, err := svc.Update(c.Request.Context(), userID, id, collection.UpdateRequest{
+			Name:          req.Name,
+			Description:   req.Description,
+			CoverImageURL: req.CoverImageURL,
+			IsPublic:      req.IsPublic,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"collection": col})
+	}
+}
+
+// DeleteCollection deletes a collection owned by the current user.
+func DeleteCollection(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.Delete(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// AddCollectionRecipe adds a recipe to a collection.
+func AddCollectionRecipe(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req addCollectionRecipeRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		if err := svc.AddRecipe(c.Request.Context(), userID, id, req.RecipeID, req.Position); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// RemoveCollectionRecipe removes a recipe from a collection.
+func RemoveCollectionRecipe(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		recipeID, err := pathUUID(c, "recipe_id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.RemoveRecipe(c.Request.Context(), userID, id, recipeID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ReorderCollectionRecipes moves the listed recipes to the front of a
+// collection in the given order.
+func ReorderCollectionRecipes(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req reorderCollectionRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		if err := svc.ReorderRecipes(c.Request.Context(), userID, id, req.RecipeIDs); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// InviteCollaborator invites a user to edit a collection.
+func InviteCollaborator(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req inviteCollaboratorRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		cb, err := svc.Invite(c.Request.Context(), userID, id, req.Username)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"collaborator": cb})
+	}
+}
+
+// RemoveCollaborator revokes a collaborator. Collaborators may remove
+// themselves to leave a collection or decline an invitation.
+func RemoveCollaborator(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		collaboratorID, err := pathUUID(c, "user_id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.RemoveCollaborator(c.Request.Context(), userID, id, collaboratorID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ListCollectionInvitations lists the collections the current user has been
+// invited to edit.
+func ListCollectionInvitations(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		collections, err := svc.ListInvitations(c.Request.Context(), userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"collections": collections})
+	}
+}
+
+// AcceptCollectionInvitation accepts the current user's invitation to edit a
+// collection.
+func AcceptCollectionInvitation(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.AcceptInvitation(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/comment"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+type createCommentRequest struct {
+	Body     string     `json:"body"`
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+type editCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type reportCommentRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+type resolveReportRequest struct {
+	Action string `json:"action"`
+}
+
+// ListComments lists the top-level comments of a recipe, newest first.
+func ListComments(svc comment.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		limit, err := queryInt(c, "limit", pagination.DefaultPerPage)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		page, err := svc.List(c.Request.Context(), viewerID(c), id, c.Query("cursor"), limit)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// CreateComment posts a comment or reply on a recipe.
+func CreateComment(svc comment.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req createCommentRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		cm, err := svc.Create(c.Request.Context(), userID, id, comment.CreateRequest{Body: req.Body, ParentID: req.ParentID})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"comment": cm})
+	}
+}
+
+// ListReplies lists the replies to a comment, oldest first.
+func ListReplies(svc comment.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		limit, err := queryInt(c, "limit", pagination.DefaultPerPage)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		page, err := svc.ListReplies(c.Request.Context(), viewerID(c), id, c.Query("cursor"), limit)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// EditComment changes the body of the current user's comment.
+func EditComment(svc comment.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req editCommentRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		cm, err := svc.Edit(c.Request.Context(), userID, id, req.Body)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"comment": cm})
+	}
+}
+
+// DeleteComment deletes the current user's comment, leaving a placeholder.
+func DeleteComment(svc comment.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.Delete(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ReportComment adds a comment to the moderation queue.
+func ReportComment(svc comment.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req reportCommentRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		report, err := svc.Report(c.Request.Context(), userID, id, comment.ReportRequest{Reason: req.Reason, Details: req.Details})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"report": report})
+	}
+}
+
+// ListCommentReports lists the moderation queue. Only moderators may use it.
+func ListCommentReports(svc comment.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		limit, err := queryInt(c, "limit", pagination.DefaultPerPage)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		page, err := svc.ListReports(c.Request.Context(), userID, c.Query("status"), c.Query("cursor"), limit)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// ResolveCommentReport dismisses a report or removes the reported comment.
+func ResolveCommentReport(svc comment.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req resolveReportRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		report, err := svc.ResolveReport(c.Request.Context(), userID, id, req.Action)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"report": report})
+	}
+}
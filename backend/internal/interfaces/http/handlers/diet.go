@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+// GetRecipeDiets reports which diets a recipe fits and why it fails the
+// others.
+func GetRecipeDiets(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		diets, err := svc.Diets(c.Request.Context(), viewerID(c), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"diets": diets})
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// etag formats a resource version as an entity tag.
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// representationETag formats the entity tag of a recipe representation as
+// the recipe's version followed by a hash of the response body. Ratings,
+// labels, the cover image and the viewer's allergen warnings change without
+// a new version, and the hash keeps those changes from being answered with
+// 304. The recipe as stored gets a strong tag, whose version writes can be
+// conditioned on; scaled or converted representations get a weak tag, so
+// that they cannot be used with If-Match.
+func representationETag(version int, variant bool, body []byte) string {
+	sum := sha256.Sum256(body)
+	tag := fmt.Sprintf(`"%d-%x"`, version, sum[:8])
+	if variant {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// parseETag extracts the version from a strong entity tag, either a bare
+// version or a representation tag. Weak tags are rejected, since If-Match
+// requires the strong comparison.
+func parseETag(tag string) (int, bool) {
+	tag = strings.TrimSpace(tag)
+	if strings.HasPrefix(tag, "W/") {
+		return 0, false
+	}
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, false
+	}
+	version, _, _ := strings.Cut(unquoted, "-")
+	v, err := strconv.Atoi(version)
+	return v, err == nil && v > 0
+}
+
+// expectedVersion returns the version a write is conditioned on. An If-Match
+// header takes precedence over the version sent in the body; fromHeader
+// reports whether it was used so that conflicts can be answered with 412.
+// "If-Match: *" names no version, so it is answered with 428 like a missing
+// precondition.
+func expectedVersion(c *gin.Context, bodyVersion int) (version int, fromHeader bool, err error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	switch header {
+	case "":
+		return bodyVersion, false, nil
+	case "*":
+		return 0, false, apperrors.ErrPreconditionRequired
+	}
+	first, _, _ := strings.Cut(header, ",")
+	v, ok := parseETag(first)
+	if !ok {
+		return 0, false, invalidParam("If-Match", "must be a version entity tag")
+	}
+	return v, true, nil
+}
+
+// conditionalError maps version conflicts on If-Match requests to 412.
+func conditionalError(err error, fromHeader bool) error {
+	if fromHeader && err == recipe.ErrVersionConflict {
+		return apperrors.ErrPreconditionFailed
+	}
+	return err
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+func TestParseETag(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+		ok   bool
+	}{
+		{etag(3), 3, true},
+		{representationETag(4, false, []byte("{}")), 4, true},
+		{`W/"7"`, 0, false},
+		{` "12" `, 12, true},
+		{`"abc"`, 0, false},
+		{`5`, 0, false},
+		{`"0"`, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseETag(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseETag(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRepresentationETag(t *testing.T) {
+	stored := representationETag(3, false, []byte(`{"rating_count":1}`))
+	if !strings.HasPrefix(stored, `"3-`) || stored == representationETag(3, false, []byte(`{"rating_count":2}`)) {
+		t.Errorf("stored recipe tagged %s, want the version and a hash of the body", stored)
+	}
+	if got := representationETag(3, false, []byte(`{"rating_count":1}`)); got != stored {
+		t.Errorf("same body tagged %s and %s", got, stored)
+	}
+	converted := representationETag(3, true, []byte(`{"rating_count":1}`))
+	if converted != "W/"+stored {
+		t.Errorf("converted recipe tagged %s, want a weak tag", converted)
+	}
+	if _, ok := parseETag(converted); ok {
+		t.Error("expected a converted recipe's tag to be unusable with If-Match")
+	}
+}
+
+func TestExpectedVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		header      string
+		body        int
+		want        int
+		fromHeader  bool
+		expectError bool
+	}{
+		{"", 4, 4, false, false},
+		{"*", 4, 0, false, true},
+		{`"2"`, 4, 2, true, false},
+		{`"2", "3"`, 0, 2, true, false},
+		{"bogus", 4, 0, false, true},
+	}
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+		if tt.header != "" {
+			c.Request.Header.Set("If-Match", tt.header)
+		}
+		v, fromHeader, err := expectedVersion(c, tt.body)
+		if (err != nil) != tt.expectError || v != tt.want || fromHeader != tt.fromHeader {
+			t.Errorf("If-Match %q: got %d, %v, %v", tt.header, v, fromHeader, err)
+		}
+	}
+
+	if err := conditionalError(recipe.ErrVersionConflict, true); err != apperrors.ErrPreconditionFailed {
+		t.Fatalf("expected 412 for If-Match conflicts, got %v", err)
+	}
+	if err := conditionalError(recipe.ErrVersionConflict, false); err != recipe.ErrVersionConflict {
+		t.Fatalf("expected 409 for body version conflicts, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/collection"
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/shopping"
+)
+
+// ExportRecipe downloads a recipe as JSON-LD, Markdown, plain text, a
+// printable PDF, or a Paprika, MealMaster or CSV file, chosen by the
+// "format" query parameter.
+func ExportRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		doc, err := svc.Export(c.Request.Context(), viewerID(c), id, c.Query("format"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		sendDocument(c, doc)
+	}
+}
+
+// ExportLibrary downloads all of the current user's recipes in the
+// requested format: one file for formats that hold many recipes, and
+// otherwise a zip archive with one file per recipe.
+func ExportLibrary(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		doc, err := svc.ExportLibrary(c.Request.Context(), userID, c.Query("format"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		sendDocument(c, doc)
+	}
+}
+
+// ExportCollection downloads the recipes of a collection as a zip archive
+// with one file per recipe in the requested format.
+func ExportCollection(svc collection.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		doc, err := svc.Export(c.Request.Context(), viewerID(c), id, c.Query("format"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		sendDocument(c, doc)
+	}
+}
+
+// ExportShoppingList downloads a shopping list as plain text or CSV.
+func ExportShoppingList(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		doc, err := svc.Export(c.Request.Context(), userID, id, c.Query("format"))
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		sendDocument(c, doc)
+	}
+}
+
+// sendDocument sends an exported file as an attachment.
+func sendDocument(c *gin.Context, doc *recipe.Document) {
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.Filename}))
+	c.Data(http.StatusOK, doc.ContentType, doc.Data)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+// ForkRecipe copies a recipe into the current user's account.
+func ForkRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		rec, err := svc.Fork(c.Request.Context(), userID, id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Header("ETag", etag(rec.Version))
+		c.JSON(http.StatusCreated, gin.H{"recipe": rec})
+	}
+}
+
+// ListForks lists the direct forks of a recipe.
+func ListForks(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		recipes, err := svc.ListForks(c.Request.Context(), viewerID(c), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"recipes": recipes})
+	}
+}
+
+// GetAncestry lists the recipes a fork descends from, nearest first.
+func GetAncestry(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		ancestors, err := svc.Ancestry(c.Request.Context(), viewerID(c), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ancestors": ancestors})
+	}
+}
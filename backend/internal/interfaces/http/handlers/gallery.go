@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/gallery"
+	"alchemorsel/backend/internal/pkg/imaging"
+)
+
+// maxImageUpload bounds image uploads, leaving room for the other form
+// fields; the image itself is held to imaging.MaxSize.
+const maxImageUpload = imaging.MaxSize + 64<<10
+
+type updateImageRequest struct {
+	AltText *string `json:"alt_text"`
+	Step    *int    `json:"step"`
+	Cover   bool    `json:"cover"`
+}
+
+type reorderImagesRequest struct {
+	ImageIDs []uuid.UUID `json:"image_ids"`
+}
+
+// ListRecipeImages returns the photo gallery of a recipe in order.
+func ListRecipeImages(svc gallery.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		images, err := svc.List(c.Request.Context(), viewerID(c), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"images": images})
+	}
+}
+
+// UploadRecipeImage adds a photo to a recipe's gallery from a multipart
+// form with an "image" file and optional "alt_text", "step" and "cover"
+// fields.
+func UploadRecipeImage(svc gallery.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		data, err := readUpload(c, "image", maxImageUpload)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		req := gallery.UploadRequest{Data: data, AltText: c.PostForm("alt_text")}
+		if v := c.PostForm("step"); v != "" {
+			step, err := strconv.Atoi(v)
+			if err != nil {
+				c.Error(invalidParam("step", "must be an integer"))
+				return
+			}
+			req.Step = &step
+		}
+		if v := c.PostForm("cover"); v != "" {
+			if req.Cover, err = strconv.ParseBool(v); err != nil {
+				c.Error(invalidParam("cover", "must be a boolean"))
+				return
+			}
+		}
+		img, err := svc.Upload(c.Request.Context(), userID, id, req)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"image": img})
+	}
+}
+
+// UpdateRecipeImage changes the alt text or step of an image, or makes it
+// the cover. A step of 0 detaches the image from its step.
+func UpdateRecipeImage(svc gallery.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		imageID, err := pathUUID(c, "image_id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req updateImageRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		img, err := svc.Update(c.Request.Context(), userID, id, imageID, gallery.UpdateRequest{
+			AltText: req.AltText,
+			Step:    req.Step,
+			Cover:   req.Cover,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"image": img})
+	}
+}
+
+// ReorderRecipeImages moves the listed images to the front of a recipe's
+// gallery in the given order.
+func ReorderRecipeImages(svc gallery.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req reorderImagesRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		images, err := svc.Reorder(c.Request.Context(), userID, id, req.ImageIDs)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"images": images})
+	}
+}
+
+// DeleteRecipeImage removes an image from a recipe's gallery.
+func DeleteRecipeImage(svc gallery.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		imageID, err := pathUUID(c, "image_id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.Delete(c.Request.Context(), userID, id, imageID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// maxImportSize bounds uploaded documents.
+const maxImportSize = 5 << 20
+
+// ImportRecipe creates a private recipe from an uploaded schema.org JSON-LD
+// document or HTML page, sent either as the request body or as the "file"
+// field of a multipart form.
+func ImportRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		document, err := readUpload(c, "file", maxImportSize)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		rec, unmapped, err := svc.Import(c.Request.Context(), userID, document)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if unmapped == nil {
+			unmapped = []string{}
+		}
+		c.Header("ETag", etag(rec.Version))
+		c.JSON(http.StatusCreated, gin.H{"recipe": rec, "unmapped_fields": unmapped})
+	}
+}
+
+// readUpload reads an uploaded file from the named multipart form field, or
+// the raw request body for other content types, up to limit bytes.
+func readUpload(c *gin.Context, field string, limit int64) ([]byte, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	body := io.Reader(c.Request.Body)
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		file, _, err := c.Request.FormFile(field)
+		if err != nil {
+			return nil, uploadError(err, field)
+		}
+		defer file.Close()
+		body = file
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, uploadError(err, field)
+	}
+	if len(data) == 0 {
+		return nil, invalidParam(field, "is required")
+	}
+	return data, nil
+}
+
+func uploadError(err error, field string) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return apperrors.NewWithDetails(apperrors.ErrInvalidInput.Code, "upload is too large",
+			http.StatusRequestEntityTooLarge, map[string]any{"limit": tooLarge.Limit})
+	}
+	return invalidParam(field, "could not be read")
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/importjob"
+)
+
+// maxImportFileSize bounds uploaded recipe manager exports, which hold a
+// whole library and its photos.
+const maxImportFileSize = 50 << 20
+
+// StartImportJob starts importing a Paprika, MealMaster or CSV file sent
+// either as the request body or as the "file" field of a multipart form.
+// The "format" query parameter names the format when it cannot be detected,
+// and "duplicates=import" imports recipes the user already has.
+func StartImportJob(svc importjob.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		var duplicates bool
+		switch c.Query("duplicates") {
+		case "", "skip":
+		case "import":
+			duplicates = true
+		default:
+			c.Error(invalidParam("duplicates", "must be skip or import"))
+			return
+		}
+		data, err := readUpload(c, "file", maxImportFileSize)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		job, err := svc.Start(c.Request.Context(), userID, importjob.StartRequest{
+			Format:           c.Query("format"),
+			Data:             data,
+			ImportDuplicates: duplicates,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{"job": job})
+	}
+}
+
+// GetImportJob reports the progress of an import job and the outcome of
+// each recipe it has read.
+func GetImportJob(svc importjob.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		job, err := svc.Get(c.Request.Context(), userID, id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"job": job})
+	}
+}
+
+// ListImportJobs lists the current user's import jobs, newest first.
+func ListImportJobs(svc importjob.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		jobs, err := svc.List(c.Request.Context(), userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+	}
+}
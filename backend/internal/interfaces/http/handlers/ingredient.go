@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/recipe"
+)
+
+// maxParseLines bounds the ingredient lines parsed in one request.
+const maxParseLines = 200
+
+// parseIngredientsRequest holds ingredient lines either as a list or as a
+// block of text with one ingredient per line.
+type parseIngredientsRequest struct {
+	Lines []string `json:"lines"`
+	Text  string   `json:"text"`
+}
+
+// ParseIngredients splits free-text ingredient lines into amount, unit,
+// name, notes and the optional marker.
+func ParseIngredients(c *gin.Context) {
+	var req parseIngredientsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	lines := req.Lines
+	if req.Text != "" {
+		lines = append(lines, strings.Split(req.Text, "\n")...)
+	}
+	if len(lines) > maxParseLines {
+		c.Error(invalidParam("lines", "must have at most 200 lines"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ingredients": recipe.ParseIngredients(lines)})
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/mealplan"
+	"alchemorsel/backend/internal/pkg/date"
+)
+
+type createMealPlanRequest struct {
+	Name      string    `json:"name"`
+	StartDate date.Date `json:"start_date"`
+	EndDate   date.Date `json:"end_date"`
+}
+
+type updateMealPlanRequest struct {
+	Name      *string    `json:"name"`
+	StartDate *date.Date `json:"start_date"`
+	EndDate   *date.Date `json:"end_date"`
+}
+
+type mealPlanEntryRequest struct {
+	Date     date.Date  `json:"date"`
+	Slot     string     `json:"slot"`
+	RecipeID *uuid.UUID `json:"recipe_id"`
+	Servings int        `json:"servings"`
+	Text     string     `json:"text"`
+}
+
+type copyWeekRequest struct {
+	From date.Date `json:"from"`
+	To   date.Date `json:"to"`
+}
+
+type autoFillRequest struct {
+	Slots    []string  `json:"slots"`
+	From     date.Date `json:"from"`
+	To       date.Date `json:"to"`
+	Servings int       `json:"servings"`
+}
+
+func (r mealPlanEntryRequest) toEntryRequest() mealplan.EntryRequest {
+	return mealplan.EntryRequest{
+		Date:     r.Date,
+		Slot:     r.Slot,
+		RecipeID: r.RecipeID,
+		Servings: r.Servings,
+		Text:     r.Text,
+	}
+}
+
+// ListMealPlans lists the current user's meal plans, latest first.
+func ListMealPlans(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		plans, err := svc.List(c.Request.Context(), userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"plans": plans})
+	}
+}
+
+// CreateMealPlan creates a meal plan for the current user.
+func CreateMealPlan(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		var req createMealPlanRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		plan, err := svc.Create(c.Request.Context(), userID, mealplan.CreateRequest{
+			Name:      req.Name,
+			StartDate: req.StartDate,
+			EndDate:   req.EndDate,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"plan": plan})
+	}
+}
+
+// GetMealPlan returns a meal plan with its meals.
+func GetMealPlan(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		plan, err := svc.Get(c.Request.Context(), userID, id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"plan": plan})
+	}
+}
+
+// UpdateMealPlan renames a meal plan or moves its dates.
+func UpdateMealPlan(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req updateMealPlanRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		plan, err := svc.Update(c.Request.Context(), userID, id, mealplan.UpdateRequest{
+			Name:      req.Name,
+			StartDate: req.StartDate,
+			EndDate:   req.EndDate,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"plan": plan})
+	}
+}
+
+// DeleteMealPlan deletes a meal plan and its meals.
+func DeleteMealPlan(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.Delete(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// AddMealPlanEntry plans a meal.
+func AddMealPlanEntry(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req mealPlanEntryRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		entry, err := svc.AddEntry(c.Request.Context(), userID, id, req.toEntryRequest())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"entry": entry})
+	}
+}
+
+// UpdateMealPlanEntry replaces a planned meal.
+func UpdateMealPlanEntry(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		entryID, err := pathUUID(c, "entry_id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req mealPlanEntryRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		entry, err := svc.UpdateEntry(c.Request.Context(), userID, id, entryID, req.toEntryRequest())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"entry": entry})
+	}
+}
+
+// DeleteMealPlanEntry removes a planned meal.
+func DeleteMealPlanEntry(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		entryID, err := pathUUID(c, "entry_id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.RemoveEntry(c.Request.Context(), userID, id, entryID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// CopyMealPlanWeek copies a week of meals to another week of the plan.
+func CopyMealPlanWeek(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req copyWeekRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		plan, err := svc.CopyWeek(c.Request.Context(), userID, id, req.From, req.To)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"plan": plan})
+	}
+}
+
+// GetMealPlanNutrition returns the nutrition of each day of a plan.
+func GetMealPlanNutrition(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		days, err := svc.Nutrition(c.Request.Context(), userID, id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"days": days})
+	}
+}
+
+// AutoFillMealPlan plans recipes for the empty slots of a plan.
+func AutoFillMealPlan(svc mealplan.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req autoFillRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		plan, added, err := svc.AutoFill(c.Request.Context(), userID, id, mealplan.AutoFillRequest{
+			Slots:    req.Slots,
+			From:     req.From,
+			To:       req.To,
+			Servings: req.Servings,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"plan": plan, "added": added})
+	}
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/pantry"
+	"alchemorsel/backend/internal/pkg/date"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+type addPantryItemRequest struct {
+	Name    string    `json:"name"`
+	Amount  float64   `json:"amount"`
+	Unit    string    `json:"unit"`
+	Expires date.Date `json:"expires"`
+}
+
+// updatePantryItemRequest leaves the expiry date alone when expires is null
+// or absent; an empty string clears it.
+type updatePantryItemRequest struct {
+	Name    *string    `json:"name"`
+	Amount  *float64   `json:"amount"`
+	Unit    *string    `json:"unit"`
+	Expires *date.Date `json:"expires"`
+}
+
+// ListPantryItems lists the current user's pantry.
+func ListPantryItems(svc pantry.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		items, err := svc.List(c.Request.Context(), userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	}
+}
+
+// AddPantryItem adds an ingredient to the current user's pantry.
+func AddPantryItem(svc pantry.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		var req addPantryItemRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		item, err := svc.Add(c.Request.Context(), userID, pantry.ItemRequest{
+			Name:    req.Name,
+			Amount:  req.Amount,
+			Unit:    req.Unit,
+			Expires: req.Expires,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"item": item})
+	}
+}
+
+// UpdatePantryItem changes an item of the current user's pantry.
+func UpdatePantryItem(svc pantry.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req updatePantryItemRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		item, err := svc.Update(c.Request.Context(), userID, id, pantry.UpdateRequest{
+			Name:    req.Name,
+			Amount:  req.Amount,
+			Unit:    req.Unit,
+			Expires: req.Expires,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"item": item})
+	}
+}
+
+// RemovePantryItem removes an item from the current user's pantry.
+func RemovePantryItem(svc pantry.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.Remove(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ListExpiringPantryItems lists the pantry items expiring within the given
+// number of days, soonest first.
+func ListExpiringPantryItems(svc pantry.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		days, err := queryInt(c, "days", pantry.DefaultExpiringDays)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		items, err := svc.Expiring(c.Request.Context(), userID, days)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	}
+}
+
+// CookableRecipes ranks recipes by how much of them the current user's
+// pantry covers, listing what is missing.
+func CookableRecipes(svc pantry.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		params := pantry.CookableParams{
+			Category: c.Query("category"),
+			Dietary:  splitList(c.Query("dietary")),
+			Tags:     splitList(c.Query("tags")),
+		}
+		var err error
+		if c.Query("max_missing") != "" {
+			maxMissing, err := queryInt(c, "max_missing", 0)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+			params.MaxMissing = &maxMissing
+		}
+		if params.Pagination.Page, err = queryInt(c, "page", 1); err != nil {
+			c.Error(err)
+			return
+		}
+		if params.Pagination.PerPage, err = queryInt(c, "per_page", pagination.DefaultPerPage); err != nil {
+			c.Error(err)
+			return
+		}
+		res, err := svc.Cookable(c.Request.Context(), userID, params)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
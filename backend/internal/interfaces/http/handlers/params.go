@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/interfaces/http/middleware"
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+	"alchemorsel/backend/internal/pkg/validator"
+)
+
+// queryInt parses an optional integer query parameter.
+func queryInt(c *gin.Context, key string, fallback int) (int, error) {
+	v := c.Query(key)
+	if v == "" {
+		return fallback, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, invalidParam(key, "must be an integer")
+	}
+	return i, nil
+}
+
+// queryFloat parses an optional floating point query parameter.
+func queryFloat(c *gin.Context, key string, fallback float64) (float64, error) {
+	v := c.Query(key)
+	if v == "" {
+		return fallback, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, invalidParam(key, "must be a number")
+	}
+	return f, nil
+}
+
+// queryBool parses an optional boolean query parameter.
+func queryBool(c *gin.Context, key string, fallback bool) (bool, error) {
+	v := c.Query(key)
+	if v == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, invalidParam(key, "must be a boolean")
+	}
+	return b, nil
+}
+
+// queryUUID parses an optional UUID query parameter.
+func queryUUID(c *gin.Context, key string) (*uuid.UUID, error) {
+	v := c.Query(key)
+	if v == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return nil, invalidParam(key, "must be a valid id")
+	}
+	return &id, nil
+}
+
+// pathUUID parses a UUID path parameter.
+func pathUUID(c *gin.Context, key string) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Param(key))
+	if err != nil {
+		return uuid.Nil, invalidParam(key, "must be a valid id")
+	}
+	return id, nil
+}
+
+// pathInt parses a positive integer path parameter.
+func pathInt(c *gin.Context, key string) (int, error) {
+	i, err := strconv.Atoi(c.Param(key))
+	if err != nil || i <= 0 {
+		return 0, invalidParam(key, "must be a positive integer")
+	}
+	return i, nil
+}
+
+// requireUserID returns the authenticated user's id. When the request is not
+// authenticated it records an unauthorized error and reports false.
+func requireUserID(c *gin.Context) (uuid.UUID, bool) {
+	id, ok := middleware.UserIDFromContext(c)
+	if !ok {
+		c.Error(apperrors.ErrUnauthorized)
+	}
+	return id, ok
+}
+
+// bindJSON decodes the request body into v. On failure it records an invalid
+// input error and reports false.
+func bindJSON(c *gin.Context, v any) bool {
+	if err := c.ShouldBindJSON(v); err != nil {
+		c.Error(apperrors.NewWithDetails(apperrors.ErrInvalidInput.Code, "invalid request body",
+			apperrors.ErrInvalidInput.Status, map[string]any{"reason": err.Error()}))
+		return false
+	}
+	return true
+}
+
+// splitList splits a comma separated query value, dropping empty items.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func invalidParam(key, reason string) error {
+	return validator.InvalidField(key, key+" "+reason)
+}
@@ -1,33 +1,457 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/pkg/pagination"
+	"alchemorsel/backend/internal/pkg/units"
+)
 
 // SearchRecipes searches for recipes.
-func SearchRecipes(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "not implemented"})
+func SearchRecipes(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := recipe.SearchParams{
+			Mode:     c.Query("mode"),
+			Query:    c.Query("q"),
+			Category: c.Query("category"),
+			Dietary:  splitList(c.Query("dietary")),
+			Exclude:  splitList(c.Query("exclude")),
+			Tags:     splitList(c.Query("tags")),
+			Sort:     c.Query("sort"),
+			Order:    c.Query("order"),
+		}
+		var err error
+		if params.AuthorID, err = queryUUID(c, "user_id"); err != nil {
+			c.Error(err)
+			return
+		}
+		if params.SimilarTo, err = queryUUID(c, "similar_to"); err != nil {
+			c.Error(err)
+			return
+		}
+		if params.SemanticWeight, err = queryFloat(c, "semantic_weight", recipe.DefaultSemanticWeight); err != nil {
+			c.Error(err)
+			return
+		}
+		if params.Favorites, err = queryBool(c, "favorites", false); err != nil {
+			c.Error(err)
+			return
+		}
+		allergenFilter, err := queryBool(c, "allergen_filter", true)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		params.DisableAllergenFilter = !allergenFilter
+		if params.Pagination.Page, err = queryInt(c, "page", 1); err != nil {
+			c.Error(err)
+			return
+		}
+		if params.Pagination.PerPage, err = queryInt(c, "per_page", pagination.DefaultPerPage); err != nil {
+			c.Error(err)
+			return
+		}
+		params.ViewerID = viewerID(c)
+
+		res, err := svc.Search(c.Request.Context(), params)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// recipeRequest is the body of create and full update requests.
+type recipeRequest struct {
+	Title             string                 `json:"title"`
+	Description       string                 `json:"description"`
+	Ingredients       []recipe.Ingredient    `json:"ingredients"`
+	Instructions      []recipe.Step          `json:"instructions"`
+	PrepTime          int                    `json:"prep_time"`
+	CookTime          int                    `json:"cook_time"`
+	Servings          int                    `json:"servings"`
+	Category          string                 `json:"category"`
+	DietaryCategories []string               `json:"dietary_categories"`
+	Allergens         []string               `json:"allergens"`
+	Tags              []string               `json:"tags"`
+	NutritionalInfo   recipe.NutritionalInfo `json:"nutritional_info"`
+	IsPublic          *bool                  `json:"is_public"`
+	Version           int                    `json:"version"`
+
+	// ImageURL and IngredientLines are accepted on create only. Afterwards
+	// the image is the cover of the recipe's gallery.
+	ImageURL        *string  `json:"image_url"`
+	IngredientLines []string `json:"ingredient_lines"`
+}
+
+func (r recipeRequest) isPublic() bool {
+	return r.IsPublic == nil || *r.IsPublic
+}
+
+// patchRecipeRequest is the body of partial update requests. Omitted fields
+// are left unchanged.
+type patchRecipeRequest struct {
+	Title             *string                 `json:"title"`
+	Description       *string                 `json:"description"`
+	Ingredients       *[]recipe.Ingredient    `json:"ingredients"`
+	Instructions      *[]recipe.Step          `json:"instructions"`
+	PrepTime          *int                    `json:"prep_time"`
+	CookTime          *int                    `json:"cook_time"`
+	Servings          *int                    `json:"servings"`
+	Category          *string                 `json:"category"`
+	DietaryCategories *[]string               `json:"dietary_categories"`
+	Allergens         *[]string               `json:"allergens"`
+	Tags              *[]string               `json:"tags"`
+	NutritionalInfo   *recipe.NutritionalInfo `json:"nutritional_info"`
+	IsPublic          *bool                   `json:"is_public"`
+	Version           int                     `json:"version"`
 }
 
 // CreateRecipe creates a new recipe.
-func CreateRecipe(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "not implemented"})
+func CreateRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		var req recipeRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		rec, err := svc.Create(c.Request.Context(), userID, recipe.CreateRequest{
+			Title:             req.Title,
+			Description:       req.Description,
+			Ingredients:       req.Ingredients,
+			Instructions:      req.Instructions,
+			PrepTime:          req.PrepTime,
+			CookTime:          req.CookTime,
+			Servings:          req.Servings,
+			Category:          req.Category,
+			DietaryCategories: req.DietaryCategories,
+			Allergens:         req.Allergens,
+			Tags:              req.Tags,
+			NutritionalInfo:   req.NutritionalInfo,
+			ImageURL:          req.ImageURL,
+			IsPublic:          req.isPublic(),
+			IngredientLines:   req.IngredientLines,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Header("ETag", etag(rec.Version))
+		c.JSON(http.StatusCreated, gin.H{"recipe": rec})
+	}
+}
+
+// GetRecipe gets a recipe by ID. A units query parameter converts it to that
+// unit system, overriding the viewer's stored preference, and a servings
+// parameter rescales it to that many servings.
+func GetRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		system, err := queryUnits(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if _, ok := c.GetQuery("servings"); ok {
+			getScaledRecipe(c, svc, id, system)
+			return
+		}
+		rec, err := svc.Convert(c.Request.Context(), viewerID(c), id, system)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		// The recipe is a variant when a unit system was applied, which may
+		// be the viewer's stored preference rather than the query parameter.
+		writeRepresentation(c, rec.Version, rec.Units != "", rec)
+	}
+}
+
+func getScaledRecipe(c *gin.Context, svc recipe.Service, id uuid.UUID, system units.System) {
+	servings, err := queryInt(c, "servings", 0)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	scaled, err := svc.Scale(c.Request.Context(), viewerID(c), id, servings, system)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	writeRepresentation(c, scaled.Version, true, scaled)
+}
+
+// writeRepresentation answers with a recipe representation and its entity
+// tag, or with 304 when the client already has it. Allergen warnings and the
+// preferred unit system depend on the signed-in user, so caches must keep
+// representations apart per user.
+func writeRepresentation(c *gin.Context, version int, variant bool, rec any) {
+	body, err := json.Marshal(gin.H{"recipe": rec})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	tag := representationETag(version, variant, body)
+	c.Header("ETag", tag)
+	c.Header("Vary", "Authorization")
+	if c.GetHeader("If-None-Match") == tag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
 }
 
-// GetRecipe gets a recipe by ID.
-func GetRecipe(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "not implemented"})
+// queryUnits parses the optional units query parameter.
+func queryUnits(c *gin.Context) (units.System, error) {
+	v := c.Query("units")
+	if v == "" {
+		return "", nil
+	}
+	system, ok := units.ParseSystem(v)
+	if !ok {
+		return "", invalidParam("units", "must be one of metric, us or uk")
+	}
+	return system, nil
 }
 
-// AddFavorite marks a recipe as favorite.
-func AddFavorite(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "not implemented"})
+// UpdateRecipe replaces a recipe owned by the current user.
+func UpdateRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req recipeRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		isPublic := req.isPublic()
+		updateRecipe(c, svc, req.Version, recipe.UpdateRequest{
+			Title:             &req.Title,
+			Description:       &req.Description,
+			Ingredients:       &req.Ingredients,
+			Instructions:      &req.Instructions,
+			PrepTime:          &req.PrepTime,
+			CookTime:          &req.CookTime,
+			Servings:          &req.Servings,
+			Category:          &req.Category,
+			DietaryCategories: &req.DietaryCategories,
+			Allergens:         &req.Allergens,
+			Tags:              &req.Tags,
+			NutritionalInfo:   &req.NutritionalInfo,
+			IsPublic:          &isPublic,
+		})
+	}
+}
+
+// PatchRecipe partially updates a recipe owned by the current user.
+func PatchRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req patchRecipeRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		updateRecipe(c, svc, req.Version, recipe.UpdateRequest{
+			Title:             req.Title,
+			Description:       req.Description,
+			Ingredients:       req.Ingredients,
+			Instructions:      req.Instructions,
+			PrepTime:          req.PrepTime,
+			CookTime:          req.CookTime,
+			Servings:          req.Servings,
+			Category:          req.Category,
+			DietaryCategories: req.DietaryCategories,
+			Allergens:         req.Allergens,
+			Tags:              req.Tags,
+			NutritionalInfo:   req.NutritionalInfo,
+			IsPublic:          req.IsPublic,
+		})
+	}
+}
+
+func updateRecipe(c *gin.Context, svc recipe.Service, bodyVersion int, req recipe.UpdateRequest) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	id, err := pathUUID(c, "id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	version, fromHeader, err := expectedVersion(c, bodyVersion)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	req.Version = version
+	rec, err := svc.Update(c.Request.Context(), userID, id, req)
+	if err != nil {
+		c.Error(conditionalError(err, fromHeader))
+		return
+	}
+	c.Header("ETag", etag(rec.Version))
+	c.JSON(http.StatusOK, gin.H{"recipe": rec})
+}
+
+// DeleteRecipe moves a recipe owned by the current user to the trash.
+func DeleteRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		version, fromHeader, err := expectedVersion(c, 0)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.Delete(c.Request.Context(), userID, id, version); err != nil {
+			c.Error(conditionalError(err, fromHeader))
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// RestoreRecipe moves a recipe out of the trash.
+func RestoreRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		rec, err := svc.Restore(c.Request.Context(), userID, id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.Header("ETag", etag(rec.Version))
+		c.JSON(http.StatusOK, gin.H{"recipe": rec})
+	}
+}
+
+// ListTrash lists the current user's deleted recipes.
+func ListTrash(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		recipes, err := svc.ListTrash(c.Request.Context(), userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"recipes": recipes})
+	}
+}
+
+// GetFavorites lists the current user's favorite recipes.
+func GetFavorites(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		recipes, err := svc.GetFavorites(c.Request.Context(), userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"recipes": recipes})
+	}
+}
+
+// AddFavorite marks a recipe visible to the current user as favorite.
+func AddFavorite(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.AddFavorite(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
 }
 
 // RemoveFavorite unmarks a recipe as favorite.
-func RemoveFavorite(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "not implemented"})
+func RemoveFavorite(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.RemoveFavorite(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+type generateRecipeRequest struct {
+	Style        string   `json:"style"`
+	Ingredients  []string `json:"ingredients"`
+	CookingTime  int      `json:"cooking_time"`
+	Servings     int      `json:"servings"`
+	CustomPrompt string   `json:"custom_prompt"`
 }
 
 // GenerateRecipe generates a recipe via LLM.
-func GenerateRecipe(c *gin.Context) {
-	c.JSON(501, gin.H{"error": "not implemented"})
+func GenerateRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		var req generateRecipeRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		rec, err := svc.Generate(c.Request.Context(), userID, recipe.GenerateRequest{
+			Style:        req.Style,
+			Ingredients:  req.Ingredients,
+			CookingTime:  req.CookingTime,
+			Servings:     req.Servings,
+			CustomPrompt: req.CustomPrompt,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"recipe": rec})
+	}
 }
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/recommendation"
+)
+
+// GetRecommendations returns the current user's "For you" recipes, each
+// with an explanation of why it was recommended.
+func GetRecommendations(svc recommendation.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		limit, err := queryInt(c, "limit", recommendation.DefaultLimit)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		feed, err := svc.ForYou(c.Request.Context(), userID, limit)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, feed)
+	}
+}
+
+// RefreshRecommendations recomputes the current user's recommendations
+// without waiting for the background refresh.
+func RefreshRecommendations(svc recommendation.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		feed, err := svc.Refresh(c.Request.Context(), userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, feed)
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/review"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+type reviewRequest struct {
+	Rating int    `json:"rating"`
+	Body   string `json:"body"`
+	MadeIt bool   `json:"made_it"`
+}
+
+// ListReviews lists the reviews of a recipe.
+func ListReviews(svc review.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		params := review.ListParams{Sort: c.Query("sort")}
+		if params.Pagination.Page, err = queryInt(c, "page", 1); err != nil {
+			c.Error(err)
+			return
+		}
+		if params.Pagination.PerPage, err = queryInt(c, "per_page", pagination.DefaultPerPage); err != nil {
+			c.Error(err)
+			return
+		}
+		res, err := svc.List(c.Request.Context(), viewerID(c), id, params)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// SaveReview creates or replaces the current user's review of a recipe.
+func SaveReview(svc review.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req reviewRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		rev, created, err := svc.Save(c.Request.Context(), userID, id, review.SaveRequest{
+			Rating: req.Rating,
+			Body:   req.Body,
+			MadeIt: req.MadeIt,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		c.JSON(status, gin.H{"review": rev})
+	}
+}
+
+// DeleteReview removes the current user's review of a recipe.
+func DeleteReview(svc review.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.Delete(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// MarkReviewHelpful records the current user's helpful vote on a review.
+func MarkReviewHelpful(svc review.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.MarkHelpful(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// UnmarkReviewHelpful withdraws the current user's helpful vote on a review.
+func UnmarkReviewHelpful(svc review.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.UnmarkHelpful(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
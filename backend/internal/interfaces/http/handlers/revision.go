@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/interfaces/http/middleware"
+)
+
+// ListRevisions lists the revisions of a recipe, newest first.
+func ListRevisions(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		revisions, err := svc.ListRevisions(c.Request.Context(), viewerID(c), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+	}
+}
+
+// GetRevision returns a single revision including its snapshot.
+func GetRevision(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		version, err := pathInt(c, "version")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		rev, err := svc.GetRevision(c.Request.Context(), viewerID(c), id, version)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"revision": rev})
+	}
+}
+
+// DiffRevisions compares the revisions given by the from and to query
+// parameters.
+func DiffRevisions(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		from, err := queryInt(c, "from", 0)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		to, err := queryInt(c, "to", 0)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if from <= 0 || to <= 0 {
+			c.Error(invalidParam("from", "and to are required revision versions"))
+			return
+		}
+		diff, err := svc.DiffRevisions(c.Request.Context(), viewerID(c), id, from, to)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"diff": diff})
+	}
+}
+
+type revertRequest struct {
+	Version int `json:"version"`
+}
+
+// RevertRecipe restores an earlier revision of a recipe owned by the current
+// user. The current version is given by If-Match or the optional body.
+func RevertRecipe(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		revision, err := pathInt(c, "version")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req revertRequest
+		if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+			return
+		}
+		version, fromHeader, err := expectedVersion(c, req.Version)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		rec, err := svc.Revert(c.Request.Context(), userID, id, revision, version)
+		if err != nil {
+			c.Error(conditionalError(err, fromHeader))
+			return
+		}
+		c.Header("ETag", etag(rec.Version))
+		c.JSON(http.StatusOK, gin.H{"recipe": rec})
+	}
+}
+
+// viewerID returns the authenticated user's id, or nil for anonymous requests.
+func viewerID(c *gin.Context) *uuid.UUID {
+	if id, ok := middleware.UserIDFromContext(c); ok {
+		return &id
+	}
+	return nil
+}
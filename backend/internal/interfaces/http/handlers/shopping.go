@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"alchemorsel/backend/internal/domain/shopping"
+	"alchemorsel/backend/internal/pkg/date"
+)
+
+type createShoppingListRequest struct {
+	Name    string                  `json:"name"`
+	PlanID  *uuid.UUID              `json:"plan_id"`
+	From    date.Date               `json:"from"`
+	To      date.Date               `json:"to"`
+	Recipes []shoppingRecipeRequest `json:"recipes"`
+}
+
+type shoppingRecipeRequest struct {
+	RecipeID uuid.UUID `json:"recipe_id"`
+	Servings int       `json:"servings"`
+}
+
+type updateShoppingListRequest struct {
+	Name *string `json:"name"`
+}
+
+type addShoppingItemRequest struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+	Unit   string  `json:"unit"`
+	Aisle  string  `json:"aisle"`
+}
+
+type updateShoppingItemRequest struct {
+	Name    *string  `json:"name"`
+	Amount  *float64 `json:"amount"`
+	Unit    *string  `json:"unit"`
+	Aisle   *string  `json:"aisle"`
+	Checked *bool    `json:"checked"`
+}
+
+type shareShoppingListRequest struct {
+	Username string `json:"username"`
+}
+
+// ListShoppingLists lists the current user's shopping lists and those
+// shared with them.
+func ListShoppingLists(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		lists, err := svc.List(c.Request.Context(), userID)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"lists": lists})
+	}
+}
+
+// CreateShoppingList makes a shopping list from a meal plan or recipes.
+func CreateShoppingList(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		var req createShoppingListRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		recipes := make([]shopping.RecipeRequest, len(req.Recipes))
+		for i, r := range req.Recipes {
+			recipes[i] = shopping.RecipeRequest{RecipeID: r.RecipeID, Servings: r.Servings}
+		}
+		list, err := svc.Create(c.Request.Context(), userID, shopping.CreateRequest{
+			Name:    req.Name,
+			PlanID:  req.PlanID,
+			From:    req.From,
+			To:      req.To,
+			Recipes: recipes,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"list": list})
+	}
+}
+
+// GetShoppingList returns a shopping list with its items grouped by aisle.
+func GetShoppingList(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		list, err := svc.Get(c.Request.Context(), userID, id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"list": list})
+	}
+}
+
+// UpdateShoppingList renames a shopping list.
+func UpdateShoppingList(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req updateShoppingListRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		list, err := svc.Update(c.Request.Context(), userID, id, shopping.UpdateRequest{Name: req.Name})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"list": list})
+	}
+}
+
+// DeleteShoppingList deletes a shopping list owned by the current user.
+func DeleteShoppingList(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.Delete(c.Request.Context(), userID, id); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// AddShoppingItem adds an item to a shopping list by hand.
+func AddShoppingItem(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req addShoppingItemRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		item, err := svc.AddItem(c.Request.Context(), userID, id, shopping.ItemRequest{
+			Name:   req.Name,
+			Amount: req.Amount,
+			Unit:   req.Unit,
+			Aisle:  req.Aisle,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"item": item})
+	}
+}
+
+// UpdateShoppingItem changes an item of a shopping list or checks it off.
+func UpdateShoppingItem(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		itemID, err := pathUUID(c, "item_id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req updateShoppingItemRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		item, err := svc.UpdateItem(c.Request.Context(), userID, id, itemID, shopping.UpdateItemRequest{
+			Name:    req.Name,
+			Amount:  req.Amount,
+			Unit:    req.Unit,
+			Aisle:   req.Aisle,
+			Checked: req.Checked,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"item": item})
+	}
+}
+
+// RemoveShoppingItem removes an item from a shopping list.
+func RemoveShoppingItem(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		itemID, err := pathUUID(c, "item_id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.RemoveItem(c.Request.Context(), userID, id, itemID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ShareShoppingList shares a shopping list with a user by username.
+func ShareShoppingList(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		var req shareShoppingListRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		member, err := svc.Share(c.Request.Context(), userID, id, req.Username)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"member": member})
+	}
+}
+
+// UnshareShoppingList removes a member from a shopping list. Members may
+// remove themselves to leave it.
+func UnshareShoppingList(svc shopping.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := requireUserID(c)
+		if !ok {
+			return
+		}
+		id, err := pathUUID(c, "id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		memberID, err := pathUUID(c, "user_id")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if err := svc.Unshare(c.Request.Context(), userID, id, memberID); err != nil {
+			c.Error(err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/pkg/pagination"
+)
+
+type suggestTagsRequest struct {
+	Title       string              `json:"title"`
+	Ingredients []recipe.Ingredient `json:"ingredients"`
+	Tags        []string            `json:"tags"`
+	Limit       int                 `json:"limit"`
+}
+
+// ListTags lists the most used tags. The prefix parameter narrows the list
+// for autocompletion.
+func ListTags(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit, err := queryInt(c, "limit", pagination.DefaultPerPage)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		tags, err := svc.ListTags(c.Request.Context(), c.Query("prefix"), limit)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"tags": tags})
+	}
+}
+
+// SuggestTags proposes tags for a recipe draft from its title and
+// ingredients, leaving out the tags it already has.
+func SuggestTags(svc recipe.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req suggestTagsRequest
+		if !bindJSON(c, &req) {
+			return
+		}
+		tags, err := svc.SuggestTags(c.Request.Context(), recipe.SuggestRequest{
+			Title:       req.Title,
+			Ingredients: req.Ingredients,
+			Existing:    req.Tags,
+			Limit:       req.Limit,
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"suggestions": tags})
+	}
+}
@@ -1,14 +1,33 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UserKey is the gin context key storing the authenticated user.
+const UserKey = "user"
 
 // Auth extracts the Authorization header and stores user info in the context.
 // In this placeholder implementation the header value itself is stored.
 func Auth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if auth := c.GetHeader("Authorization"); auth != "" {
-			c.Set("user", auth)
+			c.Set(UserKey, auth)
 		}
 		c.Next()
 	}
 }
+
+// UserIDFromContext returns the id of the authenticated user. Until token
+// validation is implemented the bearer token is expected to be the user id.
+func UserIDFromContext(c *gin.Context) (uuid.UUID, bool) {
+	token := strings.TrimSpace(strings.TrimPrefix(c.GetString(UserKey), "Bearer "))
+	id, err := uuid.Parse(token)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
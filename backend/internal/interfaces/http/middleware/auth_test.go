@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 func TestAuth(t *testing.T) {
@@ -32,3 +33,33 @@ func TestAuth(t *testing.T) {
 		t.Fatalf("expected body token123, got %s", body)
 	}
 }
+
+func TestUserIDFromContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	id := uuid.New()
+
+	tests := []struct {
+		header string
+		ok     bool
+	}{
+		{"Bearer " + id.String(), true},
+		{id.String(), true},
+		{"Bearer not-a-uuid", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		r := gin.New()
+		r.Use(Auth())
+		r.GET("/", func(c *gin.Context) {
+			got, ok := UserIDFromContext(c)
+			if ok != tt.ok || (ok && got != id) {
+				t.Errorf("header %q: got %s, %v", tt.header, got, ok)
+			}
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
@@ -3,12 +3,37 @@ package http
 import (
 	"github.com/gin-gonic/gin"
 
+	"alchemorsel/backend/internal/domain/collection"
+	"alchemorsel/backend/internal/domain/comment"
+	"alchemorsel/backend/internal/domain/gallery"
+	"alchemorsel/backend/internal/domain/importjob"
+	"alchemorsel/backend/internal/domain/mealplan"
+	"alchemorsel/backend/internal/domain/pantry"
+	"alchemorsel/backend/internal/domain/recipe"
+	"alchemorsel/backend/internal/domain/recommendation"
+	"alchemorsel/backend/internal/domain/review"
+	"alchemorsel/backend/internal/domain/shopping"
+
 	"alchemorsel/backend/internal/interfaces/http/handlers"
 	"alchemorsel/backend/internal/interfaces/http/middleware"
 )
 
+// Services holds the domain services used by the HTTP handlers.
+type Services struct {
+	Recipe          recipe.Service
+	Review          review.Service
+	Comment         comment.Service
+	Collection      collection.Service
+	Import          importjob.Service
+	Gallery         gallery.Service
+	MealPlans       mealplan.Service
+	Shopping        shopping.Service
+	Pantry          pantry.Service
+	Recommendations recommendation.Service
+}
+
 // SetupRouter configures all HTTP routes following the design docs.
-func SetupRouter() *gin.Engine {
+func SetupRouter(services Services) *gin.Engine {
 	r := gin.New()
 	r.Use(
 		middleware.Recovery(),
@@ -38,18 +63,139 @@ func SetupRouter() *gin.Engine {
 				users.GET("/profile", handlers.GetProfile)
 				users.PUT("/profile", handlers.UpdateProfile)
 				users.POST("/profile/picture", handlers.UploadProfilePicture)
+				users.GET("/favorites", handlers.GetFavorites(services.Recipe))
 			}
 
 			recipes := protected.Group("/recipes")
 			{
-				recipes.GET("/", handlers.SearchRecipes)
-				recipes.POST("/", handlers.CreateRecipe)
-				recipes.GET("/:id", handlers.GetRecipe)
-				recipes.POST("/:id/favorite", handlers.AddFavorite)
-				recipes.DELETE("/:id/favorite", handlers.RemoveFavorite)
+				recipes.GET("/", handlers.SearchRecipes(services.Recipe))
+				recipes.POST("/", handlers.CreateRecipe(services.Recipe))
+				recipes.GET("/trash", handlers.ListTrash(services.Recipe))
+				recipes.GET("/export", handlers.ExportLibrary(services.Recipe))
+				recipes.POST("/import", handlers.ImportRecipe(services.Recipe))
+				recipes.GET("/imports", handlers.ListImportJobs(services.Import))
+				recipes.POST("/imports", handlers.StartImportJob(services.Import))
+				recipes.GET("/imports/:id", handlers.GetImportJob(services.Import))
+				recipes.GET("/:id", handlers.GetRecipe(services.Recipe))
+				recipes.PUT("/:id", handlers.UpdateRecipe(services.Recipe))
+				recipes.PATCH("/:id", handlers.PatchRecipe(services.Recipe))
+				recipes.DELETE("/:id", handlers.DeleteRecipe(services.Recipe))
+				recipes.POST("/:id/restore", handlers.RestoreRecipe(services.Recipe))
+				recipes.POST("/:id/fork", handlers.ForkRecipe(services.Recipe))
+				recipes.GET("/:id/forks", handlers.ListForks(services.Recipe))
+				recipes.GET("/:id/ancestry", handlers.GetAncestry(services.Recipe))
+				recipes.GET("/:id/diets", handlers.GetRecipeDiets(services.Recipe))
+				recipes.GET("/:id/export", handlers.ExportRecipe(services.Recipe))
+				recipes.GET("/:id/images", handlers.ListRecipeImages(services.Gallery))
+				recipes.POST("/:id/images", handlers.UploadRecipeImage(services.Gallery))
+				recipes.PUT("/:id/images", handlers.ReorderRecipeImages(services.Gallery))
+				recipes.PATCH("/:id/images/:image_id", handlers.UpdateRecipeImage(services.Gallery))
+				recipes.DELETE("/:id/images/:image_id", handlers.DeleteRecipeImage(services.Gallery))
+				recipes.GET("/:id/revisions", handlers.ListRevisions(services.Recipe))
+				recipes.GET("/:id/revisions/diff", handlers.DiffRevisions(services.Recipe))
+				recipes.GET("/:id/revisions/:version", handlers.GetRevision(services.Recipe))
+				recipes.POST("/:id/revisions/:version/revert", handlers.RevertRecipe(services.Recipe))
+				recipes.GET("/:id/reviews", handlers.ListReviews(services.Review))
+				recipes.PUT("/:id/review", handlers.SaveReview(services.Review))
+				recipes.DELETE("/:id/review", handlers.DeleteReview(services.Review))
+				recipes.GET("/:id/comments", handlers.ListComments(services.Comment))
+				recipes.POST("/:id/comments", handlers.CreateComment(services.Comment))
+				recipes.POST("/:id/favorite", handlers.AddFavorite(services.Recipe))
+				recipes.DELETE("/:id/favorite", handlers.RemoveFavorite(services.Recipe))
+			}
+
+			tags := protected.Group("/tags")
+			{
+				tags.GET("/", handlers.ListTags(services.Recipe))
+				tags.POST("/suggestions", handlers.SuggestTags(services.Recipe))
+			}
+
+			protected.POST("/ingredients/parse", handlers.ParseIngredients)
+
+			collections := protected.Group("/collections")
+			{
+				collections.GET("/", handlers.ListCollections(services.Collection))
+				collections.POST("/", handlers.CreateCollection(services.Collection))
+				collections.GET("/invitations", handlers.ListCollectionInvitations(services.Collection))
+				collections.GET("/:id", handlers.GetCollection(services.Collection))
+				collections.GET("/:id/export", handlers.ExportCollection(services.Collection))
+				collections.PATCH("/:id", handlers.UpdateCollection(services.Collection))
+				collections.DELETE("/:id", handlers.DeleteCollection(services.Collection))
+				collections.POST("/:id/recipes", handlers.AddCollectionRecipe(services.Collection))
+				collections.PUT("/:id/recipes", handlers.ReorderCollectionRecipes(services.Collection))
+				collections.DELETE("/:id/recipes/:recipe_id", handlers.RemoveCollectionRecipe(services.Collection))
+				collections.POST("/:id/collaborators", handlers.InviteCollaborator(services.Collection))
+				collections.DELETE("/:id/collaborators/:user_id", handlers.RemoveCollaborator(services.Collection))
+				collections.POST("/:id/accept", handlers.AcceptCollectionInvitation(services.Collection))
+			}
+
+			mealPlans := protected.Group("/meal-plans")
+			{
+				mealPlans.GET("/", handlers.ListMealPlans(services.MealPlans))
+				mealPlans.POST("/", handlers.CreateMealPlan(services.MealPlans))
+				mealPlans.GET("/:id", handlers.GetMealPlan(services.MealPlans))
+				mealPlans.PATCH("/:id", handlers.UpdateMealPlan(services.MealPlans))
+				mealPlans.DELETE("/:id", handlers.DeleteMealPlan(services.MealPlans))
+				mealPlans.POST("/:id/entries", handlers.AddMealPlanEntry(services.MealPlans))
+				mealPlans.PUT("/:id/entries/:entry_id", handlers.UpdateMealPlanEntry(services.MealPlans))
+				mealPlans.DELETE("/:id/entries/:entry_id", handlers.DeleteMealPlanEntry(services.MealPlans))
+				mealPlans.POST("/:id/copy-week", handlers.CopyMealPlanWeek(services.MealPlans))
+				mealPlans.GET("/:id/nutrition", handlers.GetMealPlanNutrition(services.MealPlans))
+				mealPlans.POST("/:id/auto-fill", handlers.AutoFillMealPlan(services.MealPlans))
+			}
+
+			shoppingLists := protected.Group("/shopping-lists")
+			{
+				shoppingLists.GET("/", handlers.ListShoppingLists(services.Shopping))
+				shoppingLists.POST("/", handlers.CreateShoppingList(services.Shopping))
+				shoppingLists.GET("/:id", handlers.GetShoppingList(services.Shopping))
+				shoppingLists.GET("/:id/export", handlers.ExportShoppingList(services.Shopping))
+				shoppingLists.PATCH("/:id", handlers.UpdateShoppingList(services.Shopping))
+				shoppingLists.DELETE("/:id", handlers.DeleteShoppingList(services.Shopping))
+				shoppingLists.POST("/:id/items", handlers.AddShoppingItem(services.Shopping))
+				shoppingLists.PATCH("/:id/items/:item_id", handlers.UpdateShoppingItem(services.Shopping))
+				shoppingLists.DELETE("/:id/items/:item_id", handlers.RemoveShoppingItem(services.Shopping))
+				shoppingLists.POST("/:id/members", handlers.ShareShoppingList(services.Shopping))
+				shoppingLists.DELETE("/:id/members/:user_id", handlers.UnshareShoppingList(services.Shopping))
+			}
+
+			pantryItems := protected.Group("/pantry")
+			{
+				pantryItems.GET("/", handlers.ListPantryItems(services.Pantry))
+				pantryItems.POST("/", handlers.AddPantryItem(services.Pantry))
+				pantryItems.GET("/expiring", handlers.ListExpiringPantryItems(services.Pantry))
+				pantryItems.GET("/recipes", handlers.CookableRecipes(services.Pantry))
+				pantryItems.PATCH("/:id", handlers.UpdatePantryItem(services.Pantry))
+				pantryItems.DELETE("/:id", handlers.RemovePantryItem(services.Pantry))
+			}
+
+			recommendations := protected.Group("/recommendations")
+			{
+				recommendations.GET("/", handlers.GetRecommendations(services.Recommendations))
+				recommendations.POST("/refresh", handlers.RefreshRecommendations(services.Recommendations))
+			}
+
+			reviews := protected.Group("/reviews")
+			{
+				reviews.POST("/:id/helpful", handlers.MarkReviewHelpful(services.Review))
+				reviews.DELETE("/:id/helpful", handlers.UnmarkReviewHelpful(services.Review))
+			}
+
+			comments := protected.Group("/comments")
+			{
+				comments.GET("/:id/replies", handlers.ListReplies(services.Comment))
+				comments.PATCH("/:id", handlers.EditComment(services.Comment))
+				comments.DELETE("/:id", handlers.DeleteComment(services.Comment))
+				comments.POST("/:id/report", handlers.ReportComment(services.Comment))
+			}
+
+			moderation := protected.Group("/moderation")
+			{
+				moderation.GET("/reports", handlers.ListCommentReports(services.Comment))
+				moderation.POST("/reports/:id/resolve", handlers.ResolveCommentReport(services.Comment))
 			}
 
-			protected.POST("/llm/generate", handlers.GenerateRecipe)
+			protected.POST("/llm/generate", handlers.GenerateRecipe(services.Recipe))
 		}
 	}
 
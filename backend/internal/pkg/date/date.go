@@ -0,0 +1,139 @@
+// Package date represents calendar days, such as the days of a meal plan,
+// without a time of day or time zone. Dates are written as "2006-01-02" in
+// JSON and stored in DATE columns.
+package date
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Layout is the ISO 8601 form dates are parsed from and formatted as.
+const Layout = "2006-01-02"
+
+// Date is a calendar day. The zero Date is not a valid day and marshals as
+// null.
+type Date struct {
+	// t is midnight UTC of the day.
+	t time.Time
+}
+
+// New returns the date of the given year, month and day, normalizing values
+// out of range as time.Date does.
+func New(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// Of returns the day t falls on in its own location.
+func Of(t time.Time) Date {
+	return New(t.Date())
+}
+
+// Today returns the current day in UTC.
+func Today() Date {
+	return Of(time.Now().UTC())
+}
+
+// Parse reads a date written as "2006-01-02".
+func Parse(s string) (Date, error) {
+	t, err := time.Parse(Layout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("date must be written as YYYY-MM-DD: %q", s)
+	}
+	return Date{t}, nil
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.t.Format(Layout)
+}
+
+// IsZero reports whether d is the zero Date.
+func (d Date) IsZero() bool { return d.t.IsZero() }
+
+// Time returns midnight UTC of the day.
+func (d Date) Time() time.Time { return d.t }
+
+// Weekday returns the day of the week.
+func (d Date) Weekday() time.Weekday { return d.t.Weekday() }
+
+// AddDays returns the date n days after d, or before it for negative n.
+func (d Date) AddDays(n int) Date {
+	return Date{d.t.AddDate(0, 0, n)}
+}
+
+// Sub returns the number of days from e to d.
+func (d Date) Sub(e Date) int {
+	return int(d.t.Sub(e.t).Hours() / 24)
+}
+
+// Before reports whether d is earlier than e.
+func (d Date) Before(e Date) bool { return d.t.Before(e.t) }
+
+// After reports whether d is later than e.
+func (d Date) After(e Date) bool { return d.t.After(e.t) }
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("date must be written as YYYY-MM-DD")
+	}
+	if s == nil || *s == "" {
+		*d = Date{}
+		return nil
+	}
+	parsed, err := Parse(*s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value stores a date as a DATE, and the zero Date as NULL.
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.String(), nil
+}
+
+// Scan reads a DATE column. NULL yields the zero Date.
+func (d *Date) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = Of(v)
+	case string:
+		return d.scanText(v)
+	case []byte:
+		return d.scanText(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into a date", src)
+	}
+	return nil
+}
+
+func (d *Date) scanText(s string) error {
+	if len(s) > len(Layout) {
+		s = s[:len(Layout)]
+	}
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
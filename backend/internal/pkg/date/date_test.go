@@ -0,0 +1,62 @@
+package date
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestArithmetic(t *testing.T) {
+	d := New(2024, time.February, 27)
+	if got := d.AddDays(3).String(); got != "2024-03-01" {
+		t.Fatalf("AddDays(3) = %s, want 2024-03-01", got)
+	}
+	if n := New(2024, time.March, 31).Sub(New(2024, time.March, 1)); n != 30 {
+		t.Fatalf("Sub = %d, want 30", n)
+	}
+	// The switch to summer time does not shorten a day.
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	if berlin != nil {
+		if got := Of(time.Date(2024, time.March, 31, 23, 30, 0, 0, berlin)); got != New(2024, time.March, 31) {
+			t.Fatalf("Of = %s, want 2024-03-31", got)
+		}
+	}
+	if !d.Before(d.AddDays(1)) || d.After(d) || d.Weekday() != time.Tuesday {
+		t.Fatalf("unexpected comparisons for %s", d)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Day  Date `json:"day"`
+		None Date `json:"none"`
+	}
+	if err := json.Unmarshal([]byte(`{"day": "2024-06-01", "none": null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Day != New(2024, time.June, 1) || !v.None.IsZero() {
+		t.Fatalf("unexpected dates %+v", v)
+	}
+	out, _ := json.Marshal(v)
+	if string(out) != `{"day":"2024-06-01","none":null}` {
+		t.Fatalf("marshal = %s", out)
+	}
+	if err := json.Unmarshal([]byte(`{"day": "01/06/2024"}`), &v); err == nil {
+		t.Fatal("expected an error for a date in another layout")
+	}
+}
+
+func TestScan(t *testing.T) {
+	var d Date
+	for _, src := range []any{time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), "2024-06-01", []byte("2024-06-01T00:00:00Z")} {
+		if err := d.Scan(src); err != nil || d != New(2024, time.June, 1) {
+			t.Fatalf("Scan(%v) = %s, %v", src, d, err)
+		}
+	}
+	if err := d.Scan(nil); err != nil || !d.IsZero() {
+		t.Fatalf("Scan(nil) = %s, %v", d, err)
+	}
+	if v, _ := New(2024, time.June, 1).Value(); v != "2024-06-01" {
+		t.Fatalf("Value = %v", v)
+	}
+}
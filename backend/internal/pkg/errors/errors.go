@@ -31,4 +31,14 @@ var (
 	ErrUserNotFound = New("user_not_found", "user not found", 404)
 	// ErrInvalidInput indicates invalid client supplied data.
 	ErrInvalidInput = New("invalid_input", "invalid input", 400)
+	// ErrUnauthorized indicates the request requires an authenticated user.
+	ErrUnauthorized = New("unauthorized", "authentication required", 401)
+	// ErrForbidden indicates the user may not perform the requested action.
+	ErrForbidden = New("forbidden", "not allowed to modify this resource", 403)
+	// ErrPreconditionFailed is returned when an If-Match precondition does not hold.
+	ErrPreconditionFailed = New("precondition_failed", "resource has been modified", 412)
+	// ErrPreconditionRequired is returned when a conditional request is mandatory.
+	ErrPreconditionRequired = New("precondition_required", "If-Match header or version is required", 428)
+	// ErrRecipeNotFound is returned when a recipe cannot be located.
+	ErrRecipeNotFound = New("recipe_not_found", "recipe not found", 404)
 )
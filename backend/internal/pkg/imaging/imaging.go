@@ -0,0 +1,342 @@
+// Package imaging checks uploaded pictures and scales them into responsive
+// variants. JPEG and PNG files are decoded with the standard library; WebP
+// files are accepted by reading their dimensions from the file header but
+// cannot be decoded, so they are kept as uploaded.
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// Limits on uploaded pictures, the same for profile pictures and recipe
+// photos.
+const (
+	MaxSize      = 5 << 20
+	MinDimension = 100
+	MaxDimension = 2000
+)
+
+// Content types of accepted pictures.
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	WebP = "image/webp"
+)
+
+var (
+	// ErrTooLarge is returned for pictures over MaxSize bytes.
+	ErrTooLarge = apperrors.NewWithDetails(apperrors.ErrInvalidInput.Code, "upload is too large",
+		http.StatusRequestEntityTooLarge, map[string]any{"limit": MaxSize})
+	// ErrUnsupportedImage is returned for files that are not JPEG, PNG or
+	// WebP pictures, or that cannot be read as one.
+	ErrUnsupportedImage = apperrors.New("unsupported_image", "image must be a JPEG, PNG or WebP file", http.StatusUnsupportedMediaType)
+)
+
+// Info describes a picture as it is displayed, after any rotation recorded
+// in its EXIF metadata.
+type Info struct {
+	ContentType string
+	Width       int
+	Height      int
+	// orientation is the EXIF orientation of a JPEG, from 1 to 8.
+	orientation int
+}
+
+// Inspect checks that data is a JPEG, PNG or WebP picture within the size
+// and dimension limits and describes it.
+func Inspect(data []byte) (Info, error) {
+	if len(data) > MaxSize {
+		return Info{}, ErrTooLarge
+	}
+	info := Info{ContentType: http.DetectContentType(data), orientation: 1}
+	switch info.ContentType {
+	case JPEG, PNG:
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return Info{}, ErrUnsupportedImage
+		}
+		info.Width, info.Height = cfg.Width, cfg.Height
+		if info.ContentType == JPEG {
+			info.orientation = jpegOrientation(data)
+		}
+		if info.orientation >= 5 {
+			info.Width, info.Height = info.Height, info.Width
+		}
+	case WebP:
+		w, h, ok := webpSize(data)
+		if !ok {
+			return Info{}, ErrUnsupportedImage
+		}
+		info.Width, info.Height = w, h
+	default:
+		return Info{}, ErrUnsupportedImage
+	}
+	if info.Width < MinDimension || info.Height < MinDimension || info.Width > MaxDimension || info.Height > MaxDimension {
+		return Info{}, apperrors.NewWithDetails("invalid_image_dimensions",
+			"image must be between 100x100 and 2000x2000 pixels", http.StatusUnprocessableEntity,
+			map[string]any{"width": info.Width, "height": info.Height})
+	}
+	return info, nil
+}
+
+// Size names a responsive variant and the width it is scaled down to.
+type Size struct {
+	Name  string
+	Width int
+}
+
+// Rendition is an encoded variant of a picture.
+type Rendition struct {
+	Name        string
+	Width       int
+	Height      int
+	ContentType string
+	Data        []byte
+}
+
+// Render scales a picture described by info into one rendition per size,
+// upright and stripped of metadata. Pictures narrower than a size are
+// re-encoded at their own width. JPEGs are written as JPEGs and PNGs as PNGs
+// to keep transparency. WebP pictures yield no renditions.
+func Render(data []byte, info Info, sizes []Size) ([]Rendition, error) {
+	if info.ContentType == WebP {
+		return nil, nil
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, src.Bounds().Dx(), src.Bounds().Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, src.Bounds().Min, draw.Src)
+
+	var out []Rendition
+	for _, size := range sizes {
+		w := min(size.Width, info.Width)
+		h := max(1, (info.Height*w+info.Width/2)/info.Width)
+		// Scale before rotating, so the unrotated picture is scaled to the
+		// rotated dimensions.
+		sw, sh := w, h
+		if info.orientation >= 5 {
+			sw, sh = h, w
+		}
+		img := orient(scale(rgba, sw, sh), info.orientation)
+
+		var buf bytes.Buffer
+		if info.ContentType == PNG {
+			err = png.Encode(&buf, img)
+		} else {
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Rendition{Name: size.Name, Width: w, Height: h, ContentType: info.ContentType, Data: buf.Bytes()})
+	}
+	return out, nil
+}
+
+// scale resizes src to w by h pixels by averaging the source pixels each
+// destination pixel covers. It only shrinks; a size equal to the source
+// copies it.
+func scale(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	if w == sw && h == sh {
+		return src
+	}
+	// The horizontal pass writes one row of w pixels per source row, the
+	// vertical pass combines those rows.
+	cols, rows := weights(sw, w), weights(sh, h)
+	tmp := make([]float64, w*sh*4)
+	for y := 0; y < sh; y++ {
+		for x, ws := range cols {
+			var acc [4]float64
+			for _, c := range ws {
+				p := src.PixOffset(c.index, y)
+				for k := range acc {
+					acc[k] += float64(src.Pix[p+k]) * c.weight
+				}
+			}
+			copy(tmp[(y*w+x)*4:], acc[:])
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y, ws := range rows {
+		for x := 0; x < w; x++ {
+			var acc [4]float64
+			for _, c := range ws {
+				for k := range acc {
+					acc[k] += tmp[(c.index*w+x)*4+k] * c.weight
+				}
+			}
+			p := dst.PixOffset(x, y)
+			for k, v := range acc {
+				dst.Pix[p+k] = uint8(min(255, v+0.5))
+			}
+		}
+	}
+	return dst
+}
+
+type contribution struct {
+	index  int
+	weight float64
+}
+
+// weights lists, for each of n destination pixels, the source pixels out of
+// from that it covers and the share of each in its value.
+func weights(from, n int) [][]contribution {
+	ratio := float64(from) / float64(n)
+	out := make([][]contribution, n)
+	for i := range out {
+		start, end := float64(i)*ratio, float64(i+1)*ratio
+		for j := int(start); j < from && float64(j) < end; j++ {
+			overlap := min(end, float64(j+1)) - max(start, float64(j))
+			if overlap > 0 {
+				out[i] = append(out[i], contribution{j, overlap / ratio})
+			}
+		}
+	}
+	return out
+}
+
+// orient turns a picture upright according to its EXIF orientation.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	w, h := sw, sh
+	if orientation >= 5 {
+		w, h = sh, sw
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = sw-1-x, y
+			case 3:
+				sx, sy = sw-1-x, sh-1-y
+			case 4:
+				sx, sy = x, sh-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, sh-1-x
+			case 7:
+				sx, sy = sw-1-y, sh-1-x
+			case 8:
+				sx, sy = sw-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):][:4], src.Pix[src.PixOffset(sx, sy):][:4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation of a JPEG, or 1 when it has
+// none.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return 1
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xff:
+			// Fill byte before a marker.
+			i++
+			continue
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			i += 2
+			continue
+		case marker == 0xda || marker == 0xd9:
+			// Metadata precedes the image data.
+			return 1
+		}
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if n < 2 || i+2+n > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+n]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + n
+	}
+	return 1
+}
+
+// exifOrientation reads the orientation tag from the first directory of
+// TIFF-formatted EXIF data.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	dir := int(order.Uint32(tiff[4:]))
+	if dir < 8 || dir+2 > len(tiff) {
+		return 1
+	}
+	for k := range int(order.Uint16(tiff[dir:])) {
+		entry := dir + 2 + 12*k
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			break
+		}
+	}
+	return 1
+}
+
+// webpSize reads the dimensions of a WebP picture from its first chunk,
+// which is a lossy, lossless or extended format header.
+func webpSize(data []byte) (width, height int, ok bool) {
+	if len(data) < 30 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+	switch string(data[12:16]) {
+	case "VP8 ":
+		if !bytes.Equal(data[23:26], []byte{0x9d, 0x01, 0x2a}) {
+			return 0, 0, false
+		}
+		width = int(binary.LittleEndian.Uint16(data[26:]) & 0x3fff)
+		height = int(binary.LittleEndian.Uint16(data[28:]) & 0x3fff)
+	case "VP8L":
+		if data[20] != 0x2f {
+			return 0, 0, false
+		}
+		bits := binary.LittleEndian.Uint32(data[21:])
+		width, height = int(bits&0x3fff)+1, int(bits>>14&0x3fff)+1
+	case "VP8X":
+		width = (int(data[24]) | int(data[25])<<8 | int(data[26])<<16) + 1
+		height = (int(data[27]) | int(data[28])<<8 | int(data[29])<<16) + 1
+	default:
+		return 0, 0, false
+	}
+	return width, height, true
+}
@@ -0,0 +1,162 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+// picture draws a w by h picture whose left half is red and right half
+// blue.
+func picture(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withOrientation inserts an EXIF segment recording orientation after the
+// start of a JPEG.
+func withOrientation(data []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	entry := make([]byte, 12)
+	binary.BigEndian.PutUint16(entry, 0x0112)
+	binary.BigEndian.PutUint16(entry[2:], 3)
+	binary.BigEndian.PutUint32(entry[4:], 1)
+	binary.BigEndian.PutUint16(entry[8:], orientation)
+	tiff = append(append(tiff, entry...), 0, 0, 0, 0)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	header := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(header[2:], uint16(len(segment)+2))
+	return bytes.Join([][]byte{data[:2], header, segment, data[2:]}, nil)
+}
+
+func TestInspect(t *testing.T) {
+	var pngData bytes.Buffer
+	png.Encode(&pngData, picture(120, 2000))
+	vp8x := []byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x00\x00\x00\x00\x1f\x03\x00\xc7\x00\x00")
+	vp8l := []byte("RIFF\x00\x00\x00\x00WEBPVP8L\x00\x00\x00\x00\x2f\xc7\xc0\x4a\x00\x00\x00\x00\x00\x00")
+
+	tests := []struct {
+		name        string
+		data        []byte
+		contentType string
+		w, h        int
+		code        string
+	}{
+		{"jpeg", encodeJPEG(t, picture(400, 300)), JPEG, 400, 300, ""},
+		{"rotated jpeg", withOrientation(encodeJPEG(t, picture(400, 300)), 6), JPEG, 300, 400, ""},
+		{"png", pngData.Bytes(), PNG, 120, 2000, ""},
+		{"extended webp", vp8x, WebP, 800, 200, ""},
+		{"lossless webp", vp8l, WebP, 200, 300, ""},
+		{"too small", encodeJPEG(t, picture(99, 300)), "", 0, 0, "invalid_image_dimensions"},
+		{"too big", encodeJPEG(t, picture(2001, 300)), "", 0, 0, "invalid_image_dimensions"},
+		{"text", []byte("not a picture"), "", 0, 0, "unsupported_image"},
+		{"gif", []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), "", 0, 0, "unsupported_image"},
+		{"truncated jpeg", encodeJPEG(t, picture(400, 300))[:20], "", 0, 0, "unsupported_image"},
+		{"oversized", append(vp8x, make([]byte, MaxSize)...), "", 0, 0, "invalid_input"},
+	}
+	for _, tt := range tests {
+		info, err := Inspect(tt.data)
+		if tt.code != "" {
+			if appErr, ok := err.(*apperrors.AppError); !ok || appErr.Code != tt.code {
+				t.Errorf("%s: error = %v, want %s", tt.name, err, tt.code)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if info.ContentType != tt.contentType || info.Width != tt.w || info.Height != tt.h {
+			t.Errorf("%s: info = %+v, want %s %dx%d", tt.name, info, tt.contentType, tt.w, tt.h)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	sizes := []Size{{"thumbnail", 200}, {"large", 1600}}
+	data := encodeJPEG(t, picture(400, 300))
+	info, err := Inspect(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renditions, err := Render(data, info, sizes)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if len(renditions) != 2 {
+		t.Fatalf("expected 2 renditions, got %d", len(renditions))
+	}
+	for i, want := range [][2]int{{200, 150}, {400, 300}} {
+		r := renditions[i]
+		img, err := jpeg.Decode(bytes.NewReader(r.Data))
+		if err != nil {
+			t.Fatalf("%s: %v", r.Name, err)
+		}
+		if r.Width != want[0] || r.Height != want[1] || img.Bounds().Dx() != want[0] || img.Bounds().Dy() != want[1] {
+			t.Errorf("%s is %dx%d (decoded %v), want %dx%d", r.Name, r.Width, r.Height, img.Bounds(), want[0], want[1])
+		}
+	}
+
+	// A picture taken with the camera turned right is stored on its side:
+	// turned upright, its red half is on top.
+	rotated := withOrientation(data, 6)
+	info, err = Inspect(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renditions, err = Render(rotated, info, sizes[:1])
+	if err != nil {
+		t.Fatalf("render rotated: %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(renditions[0].Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 267 {
+		t.Fatalf("rotated rendition is %v, want 200x267", b)
+	}
+	top, _, _, _ := img.At(100, 20).RGBA()
+	_, _, bottom, _ := img.At(100, 240).RGBA()
+	if top < 0xc000 || bottom < 0xc000 {
+		t.Fatalf("expected red above blue, got top %v and bottom %v", img.At(100, 20), img.At(100, 240))
+	}
+
+	webp := []byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00\x00\x00\x00\x00\x1f\x03\x00\xc7\x00\x00")
+	if renditions, err := Render(webp, Info{ContentType: WebP, Width: 800, Height: 200}, sizes); err != nil || renditions != nil {
+		t.Fatalf("expected no renditions of a WebP picture, got %v, %v", renditions, err)
+	}
+}
+
+func TestScaleAveragesCoveredPixels(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 3, 1))
+	copy(src.Pix, []uint8{0, 0, 0, 255, 90, 90, 90, 255, 180, 180, 180, 255})
+	dst := scale(src, 2, 1)
+	// Each destination pixel covers one and a half source pixels.
+	if want := []uint8{30, 30, 30, 255, 150, 150, 150, 255}; !bytes.Equal(dst.Pix, want) {
+		t.Fatalf("pixels = %v, want %v", dst.Pix, want)
+	}
+}
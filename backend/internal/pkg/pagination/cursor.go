@@ -0,0 +1,52 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor marks a position in a result set ordered by creation time and id.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Encode returns the opaque string form of the cursor handed to clients.
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by Encode. An empty string yields a
+// nil cursor, meaning the first page.
+func DecodeCursor(s string) (*Cursor, bool) {
+	if s == "" {
+		return nil, true
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, false
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, false
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, false
+	}
+	return &Cursor{CreatedAt: createdAt, ID: uid}, true
+}
+
+// NormalizeLimit applies the page size defaults and bounds to a cursor page
+// limit.
+func NormalizeLimit(limit int) int {
+	return Params{PerPage: limit}.Normalize().PerPage
+}
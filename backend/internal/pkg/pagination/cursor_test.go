@@ -0,0 +1,36 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := Cursor{CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC), ID: uuid.New()}
+	got, ok := DecodeCursor(c.Encode())
+	if !ok || got == nil || !got.CreatedAt.Equal(c.CreatedAt) || got.ID != c.ID {
+		t.Fatalf("round trip of %+v gave %+v, %v", c, got, ok)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	if c, ok := DecodeCursor(""); !ok || c != nil {
+		t.Fatalf("expected empty cursor to mean the first page, got %+v, %v", c, ok)
+	}
+	for _, s := range []string{"!!", "bm9waXBl", "MjAyNC0wMS0wMXxub3QtYS11dWlk"} {
+		if _, ok := DecodeCursor(s); ok {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
+
+func TestNormalizeLimit(t *testing.T) {
+	if l := NormalizeLimit(0); l != DefaultPerPage {
+		t.Fatalf("expected default limit, got %d", l)
+	}
+	if l := NormalizeLimit(1000); l != MaxPerPage {
+		t.Fatalf("expected capped limit, got %d", l)
+	}
+}
@@ -0,0 +1,58 @@
+package pagination
+
+const (
+	// DefaultPerPage is used when the client does not request a page size.
+	DefaultPerPage = 20
+	// MaxPerPage caps the page size a client may request.
+	MaxPerPage = 100
+)
+
+// Params holds page based pagination input.
+type Params struct {
+	Page    int
+	PerPage int
+}
+
+// Normalize applies defaults and bounds to the pagination parameters.
+func (p Params) Normalize() Params {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = DefaultPerPage
+	}
+	if p.PerPage > MaxPerPage {
+		p.PerPage = MaxPerPage
+	}
+	return p
+}
+
+// Offset returns the number of rows to skip for the current page.
+func (p Params) Offset() int {
+	p = p.Normalize()
+	return (p.Page - 1) * p.PerPage
+}
+
+// Meta describes the pagination state of a result set.
+type Meta struct {
+	Page       int  `json:"page"`
+	PerPage    int  `json:"per_page"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
+
+// NewMeta builds pagination metadata for the given parameters and total.
+func NewMeta(p Params, total int) Meta {
+	p = p.Normalize()
+	pages := (total + p.PerPage - 1) / p.PerPage
+	return Meta{
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+		Total:      total,
+		TotalPages: pages,
+		HasNext:    p.Page < pages,
+		HasPrev:    p.Page > 1,
+	}
+}
@@ -0,0 +1,34 @@
+package pagination
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	p := Params{}.Normalize()
+	if p.Page != 1 || p.PerPage != DefaultPerPage {
+		t.Fatalf("unexpected defaults: %+v", p)
+	}
+	p = Params{Page: 3, PerPage: 500}.Normalize()
+	if p.Page != 3 || p.PerPage != MaxPerPage {
+		t.Fatalf("expected per page to be capped, got %+v", p)
+	}
+}
+
+func TestOffset(t *testing.T) {
+	if off := (Params{Page: 3, PerPage: 10}).Offset(); off != 20 {
+		t.Fatalf("expected offset 20, got %d", off)
+	}
+}
+
+func TestNewMeta(t *testing.T) {
+	m := NewMeta(Params{Page: 2, PerPage: 20}, 45)
+	if m.TotalPages != 3 {
+		t.Fatalf("expected 3 pages, got %d", m.TotalPages)
+	}
+	if !m.HasNext || !m.HasPrev {
+		t.Fatalf("expected next and prev pages: %+v", m)
+	}
+	m = NewMeta(Params{Page: 1}, 0)
+	if m.TotalPages != 0 || m.HasNext || m.HasPrev {
+		t.Fatalf("unexpected meta for empty result: %+v", m)
+	}
+}
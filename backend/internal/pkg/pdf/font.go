@@ -0,0 +1,81 @@
+package pdf
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Character widths of the standard fonts in thousandths of the font size,
+// for the printable ASCII characters from space to tilde. Oblique shares
+// the widths of the upright font.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// upperWidths are the widths of the WinAnsi characters above ASCII that
+// recipes use. Others are measured as a digit, which is close for the
+// accented letters that make up most of the range.
+var upperWidths = map[byte]int{
+	0x80: 556, 0x85: 1000, 0x91: 222, 0x92: 222, 0x93: 333, 0x94: 333, 0x95: 350,
+	0x96: 556, 0x97: 1000, 0x99: 1000, 0xA0: 278, 0xB0: 400, 0xB1: 584, 0xB7: 278,
+	0xBC: 834, 0xBD: 834, 0xBE: 834, 0xD7: 584,
+}
+
+func charWidth(widths [95]int, b byte) int {
+	if b >= 32 && b <= 126 {
+		return widths[b-32]
+	}
+	if w, ok := upperWidths[b]; ok {
+		return w
+	}
+	return 556
+}
+
+// winAnsi maps the characters of WinAnsiEncoding between 0x80 and 0x9F,
+// where it departs from Latin-1.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// substitutes spells out characters the standard fonts lack.
+var substitutes = strings.NewReplacer(
+	"⅓", "1/3", "⅔", "2/3", "⅕", "1/5", "⅖", "2/5", "⅗", "3/5", "⅘", "4/5",
+	"⅙", "1/6", "⅚", "5/6", "⅛", "1/8", "⅜", "3/8", "⅝", "5/8", "⅞", "7/8",
+	"⁄", "/", "−", "-", "\u2009", " ", "\u202f", " ", "\t", " ",
+)
+
+// encode converts s to WinAnsiEncoding. Characters outside it become "?".
+func encode(s string) []byte {
+	s = substitutes.Replace(s)
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch b, ok := winAnsi[r]; {
+		case ok:
+			out = append(out, b)
+		case r == utf8.RuneError:
+			out = append(out, '?')
+		case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
@@ -0,0 +1,206 @@
+// Package pdf writes simple PDF documents: pages of text in the standard
+// Helvetica fonts, lines and filled rectangles. It needs no font files
+// because the standard fonts are built into every PDF reader.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Page sizes in points.
+const (
+	LetterWidth  = 612
+	LetterHeight = 792
+	A4Width      = 595
+	A4Height     = 842
+)
+
+// Font is one of the standard fonts.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+	HelveticaOblique
+)
+
+var fontNames = []string{"Helvetica", "Helvetica-Bold", "Helvetica-Oblique"}
+
+// Document is a PDF document under construction.
+type Document struct {
+	width, height float64
+	title         string
+	pages         []*Page
+}
+
+// Page is a page of a document. Coordinates are in points from the bottom
+// left corner of the page.
+type Page struct {
+	content bytes.Buffer
+}
+
+// New creates an empty document with pages of the given size.
+func New(width, height float64) *Document {
+	return &Document{width: width, height: height}
+}
+
+// SetTitle sets the title shown by PDF readers.
+func (d *Document) SetTitle(title string) {
+	d.title = title
+}
+
+// AddPage appends a blank page.
+func (d *Document) AddPage() *Page {
+	p := &Page{}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Pages returns the number of pages.
+func (d *Document) Pages() int {
+	return len(d.pages)
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td %s Tj ET\n", font+1, num(size), num(x), num(y), literal(encode(s)))
+}
+
+// SetGray sets the gray level, from 0 for black to 1 for white, used to
+// fill text and rectangles and to stroke lines.
+func (p *Page) SetGray(gray float64) {
+	fmt.Fprintf(&p.content, "%s g %s G\n", num(gray), num(gray))
+}
+
+// Line draws a line of the given width from (x1, y1) to (x2, y2).
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+// Rect fills a rectangle whose bottom left corner is at (x, y).
+func (p *Page) Rect(x, y, width, height float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n", num(x), num(y), num(width), num(height))
+}
+
+// TextWidth returns the width of s in points when set in the font and size.
+func TextWidth(s string, font Font, size float64) float64 {
+	widths := helveticaWidths
+	if font == HelveticaBold {
+		widths = helveticaBoldWidths
+	}
+	var total int
+	for _, b := range encode(s) {
+		total += charWidth(widths, b)
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap breaks s into lines no wider than width, breaking between words.
+// Words wider than a line are left on a line of their own.
+func Wrap(s string, font Font, size, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && TextWidth(candidate, font, size) > width {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// WriteTo writes the document in PDF format.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) int {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+		return len(offsets)
+	}
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// The catalog and page tree come first so that their numbers are known
+	// to the pages; the page tree is written once the kids are known.
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	offsets = append(offsets, 0)
+	pagesAt := len(offsets)
+	var fonts []string
+	for i, name := range fontNames {
+		id := object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fonts = append(fonts, fmt.Sprintf("/F%d %d 0 R", i+1, id))
+	}
+	resources := "<< /Font << " + strings.Join(fonts, " ") + " >> >>"
+	var kids []string
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = []*Page{{}}
+	}
+	for _, p := range pages {
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		zw.Write(p.content.Bytes())
+		zw.Close()
+		content := object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()))
+		page := object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources %s /Contents %d 0 R >>", resources, content))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	offsets[pagesAt-1] = buf.Len()
+	fmt.Fprintf(&buf, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>\nendobj\n",
+		strings.Join(kids, " "), len(kids), num(d.width), num(d.height))
+	info := object(fmt.Sprintf("<< /Title %s /Producer (alchemorsel) >>", literal(encode(d.title))))
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, info, xref)
+	return buf.WriteTo(w)
+}
+
+// Bytes returns the document in PDF format.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	d.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// num formats a number with at most two decimals.
+func num(x float64) string {
+	return strconv.FormatFloat(math.Round(x*100)/100, 'f', -1, 64)
+}
+
+// literal writes bytes as a PDF string, escaping delimiters and bytes
+// outside printable ASCII.
+func literal(b []byte) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, c := range b {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&sb, "\\%03o", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
@@ -0,0 +1,82 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	doc := New(LetterWidth, LetterHeight)
+	doc.SetTitle("Crème brûlée (classic)")
+	p := doc.AddPage()
+	p.SetGray(0.2)
+	p.Text(72, 700, HelveticaBold, 18, "Crème brûlée")
+	p.Rect(72, 600, 200, 40)
+	doc.AddPage().Text(72, 700, Helvetica, 11, "1 ⅓ cups cream – warmed")
+	data := doc.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("missing header or trailer")
+	}
+	if !bytes.Contains(data, []byte("/Count 2")) {
+		t.Fatalf("expected two pages")
+	}
+	if !bytes.Contains(data, []byte(`/Title (Cr\350me br\373l\351e \(classic\))`)) {
+		t.Fatalf("expected an escaped title")
+	}
+
+	// Every xref entry must point at the start of its object.
+	m := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(data)
+	start, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(data[start:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point at the xref table")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[start:], -1)
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := strconv.Itoa(i+1) + " 0 obj"; !bytes.HasPrefix(data[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, data[off:off+10])
+		}
+	}
+
+	var content []string
+	for _, s := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(data, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(s[1]))
+		if err != nil {
+			t.Fatalf("content stream: %v", err)
+		}
+		b, _ := io.ReadAll(zr)
+		content = append(content, string(b))
+	}
+	if len(content) != 2 {
+		t.Fatalf("expected two content streams, got %d", len(content))
+	}
+	if !strings.Contains(content[0], `/F2 18 Tf 72 700 Td (Cr\350me br\373l\351e) Tj`) {
+		t.Errorf("unexpected first page %q", content[0])
+	}
+	if !strings.Contains(content[1], `(1 1/3 cups cream \226 warmed) Tj`) {
+		t.Errorf("unexpected second page %q", content[1])
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	if got := TextWidth("Hello", Helvetica, 10); got != 22.78 {
+		t.Errorf("TextWidth(Hello) = %v, want 22.78", got)
+	}
+	if got := TextWidth("Hello", HelveticaBold, 10); got != 24.45 {
+		t.Errorf("bold TextWidth(Hello) = %v, want 24.45", got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	got := Wrap("Whisk the eggs with the sugar until pale\n\nFold in the flour", Helvetica, 10, 100)
+	want := []string{"Whisk the eggs with", "the sugar until pale", "", "Fold in the flour"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Wrap = %q, want %q", got, want)
+	}
+}
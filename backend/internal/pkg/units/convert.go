@@ -0,0 +1,112 @@
+package units
+
+import (
+	"math"
+	"strings"
+)
+
+// System is a system of units a recipe can be written in.
+type System string
+
+const (
+	// Metric measures in grams, millilitres and degrees Celsius.
+	Metric System = "metric"
+	// US measures in cups and spoons, ounces and pounds, and degrees
+	// Fahrenheit.
+	US System = "us"
+	// UK measures like Metric but keeps spoons for small amounts.
+	UK System = "uk"
+)
+
+// ParseSystem parses a unit system name without regard to case.
+func ParseSystem(name string) (System, bool) {
+	switch s := System(strings.ToLower(strings.TrimSpace(name))); s {
+	case Metric, US, UK:
+		return s, true
+	}
+	return "", false
+}
+
+// ladder returns the ladder a system writes amounts of the dimension in.
+func (s System) ladder(d Dimension) string {
+	switch {
+	case s == US && d == Volume:
+		return "us_volume"
+	case s == US && d == Mass:
+		return "us_mass"
+	case s == UK && d == Volume:
+		return "uk_volume"
+	case d == Volume:
+		return "metric_volume"
+	case d == Mass:
+		return "metric_mass"
+	}
+	return ""
+}
+
+// Temperature returns the unit the system writes temperatures in.
+func (s System) Temperature() Unit {
+	if s == US {
+		return Fahrenheit
+	}
+	return Celsius
+}
+
+// Convert converts an amount between two units of the same dimension. It
+// reports false when the dimensions differ.
+func Convert(amount float64, from, to Unit) (float64, bool) {
+	if from.Dimension != to.Dimension {
+		return 0, false
+	}
+	if from.Dimension == Temperature {
+		return convertTemperature(amount, from, to), true
+	}
+	return amount * from.Base / to.Base, true
+}
+
+func convertTemperature(v float64, from, to Unit) float64 {
+	switch {
+	case from == Fahrenheit && to == Celsius:
+		return (v - 32) * 5 / 9
+	case from == Celsius && to == Fahrenheit:
+		return v*9/5 + 32
+	}
+	return v
+}
+
+// ForSystem expresses an amount of an ingredient in the units of a system.
+// Metric systems weigh solid ingredients that are measured by volume
+// elsewhere, such as flour and sugar, and the US system measures weighed
+// ingredients by volume; the ingredient's density is looked up by name. The
+// UK system keeps spoon measures. Amounts that cannot be converted are
+// returned unchanged.
+func ForSystem(amount float64, u Unit, ingredient string, system System) (float64, Unit) {
+	if u.Dimension != Volume && u.Dimension != Mass {
+		return amount, u
+	}
+	base, dim := amount*u.Base, u.Dimension
+	spoon := u == Teaspoon || u == Tablespoon
+	if d, ok := LookupDensity(ingredient); ok {
+		switch {
+		case system == US && dim == Mass:
+			base, dim = base/d.GramsPerMl, Volume
+		case system != US && dim == Volume && !d.Liquid && !(system == UK && spoon):
+			base, dim = base*d.GramsPerMl, Mass
+		}
+	}
+	ladder := system.ladder(dim)
+	if ladder == "" {
+		return amount, u
+	}
+	return climb(base, ladder)
+}
+
+// RoundTemperature rounds an oven temperature the way recipes write it: to
+// the nearest 5 degrees, or 25 degrees Fahrenheit from 200 °F up.
+func RoundTemperature(v float64, u Unit) float64 {
+	step := 5.0
+	if u == Fahrenheit && v >= 200 {
+		step = 25
+	}
+	return math.Round(v/step) * step
+}
@@ -0,0 +1,114 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestForSystem(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     float64
+		unit       Unit
+		ingredient string
+		system     System
+		wantAmount float64
+		wantUnit   Unit
+	}{
+		{"flour is weighed in metric", 1, Cup, "all-purpose flour", Metric, 120, Gram},
+		{"sugar weighs more than flour", 1, Cup, "granulated sugar", Metric, 201, Gram},
+		{"brown sugar beats sugar", 1, Cup, "light brown sugar, packed", Metric, 213, Gram},
+		{"liquids stay volumes", 2, Cup, "whole milk", Metric, 473, Milliliter},
+		{"large liquids become litres", 5, Cup, "chicken stock", Metric, 1.18, Liter},
+		{"unknown volumes stay volumes", 1, Cup, "chopped onion", Metric, 237, Milliliter},
+		{"ounces become grams", 8, Ounce, "chicken thighs", Metric, 227, Gram},
+		{"pounds become kilograms", 3, Pound, "pork shoulder", Metric, 1.36, Kilogram},
+		{"spoons become millilitres in metric", 1, Teaspoon, "vanilla extract", Metric, 4.93, Milliliter},
+		{"spoon measured salt is weighed in metric", 1, Teaspoon, "salt", Metric, 6, Gram},
+		{"the UK keeps spoons", 1, Teaspoon, "salt", UK, 1, Teaspoon},
+		{"the UK keeps small spoon volumes", 2, Tablespoon, "olive oil", UK, 2, Tablespoon},
+		{"the UK measures large volumes in millilitres", 1, Cup, "milk", UK, 237, Milliliter},
+		{"the UK weighs flour", 2, Cup, "flour", UK, 241, Gram},
+		{"grams of flour become cups", 240, Gram, "flour", US, 2, Cup},
+		{"grams of butter become tablespoons", 28, Gram, "butter", US, 1.97, Tablespoon},
+		{"unknown masses become ounces", 200, Gram, "cheddar", US, 7.05, Ounce},
+		{"millilitres become cups", 250, Milliliter, "water", US, 1.06, Cup},
+		{"cups stay cups", 1.5, Cup, "flour", US, 1.5, Cup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, unit := ForSystem(tt.amount, tt.unit, tt.ingredient, tt.system)
+			if unit != tt.wantUnit || math.Abs(amount-tt.wantAmount) > tt.wantAmount*0.01 {
+				t.Fatalf("ForSystem(%v %s %s, %s) = %v %s, want %v %s",
+					tt.amount, tt.unit.Symbol, tt.ingredient, tt.system, amount, unit.Symbol, tt.wantAmount, tt.wantUnit.Symbol)
+			}
+		})
+	}
+}
+
+func TestParseSystem(t *testing.T) {
+	for in, want := range map[string]System{"metric": Metric, " US ": US, "uk": UK} {
+		if got, ok := ParseSystem(in); !ok || got != want {
+			t.Errorf("ParseSystem(%q) = %q, %v", in, got, ok)
+		}
+	}
+	if _, ok := ParseSystem("imperial"); ok {
+		t.Errorf("ParseSystem(imperial) should fail")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	if v, ok := Convert(1, Liter, Cup); !ok || math.Abs(v-4.23) > 0.01 {
+		t.Errorf("Convert(1 l, cup) = %v, %v", v, ok)
+	}
+	if v, ok := Convert(212, Fahrenheit, Celsius); !ok || v != 100 {
+		t.Errorf("Convert(212 °F, °C) = %v, %v", v, ok)
+	}
+	if _, ok := Convert(1, Cup, Gram); ok {
+		t.Errorf("Convert(cup, g) should fail")
+	}
+}
+
+func TestConvertTemperatures(t *testing.T) {
+	tests := []struct {
+		text   string
+		system System
+		want   string
+	}{
+		{"Bake at 350°F for 20 minutes.", Metric, "Bake at 175°C (350°F) for 20 minutes."},
+		{"Preheat the oven to 180 °C.", US, "Preheat the oven to 350°F (180°C)."},
+		{"Roast at 220 degrees C.", US, "Roast at 425°F (220°C)."},
+		{"Heat oil to 375°F.", UK, "Heat oil to 190°C (375°F)."},
+		{"Bake at 180°C (350°F).", US, "Bake at 350°F (180°C)."},
+		{"Bake at 180°C (350°F).", Metric, "Bake at 180°C (350°F)."},
+		{"Bake at 180°C.", Metric, "Bake at 180°C."},
+		{"Cut into 4 pieces.", Metric, "Cut into 4 pieces."},
+	}
+	for _, tt := range tests {
+		if got := ConvertTemperatures(tt.text, tt.system); got != tt.want {
+			t.Errorf("ConvertTemperatures(%q, %s) = %q, want %q", tt.text, tt.system, got, tt.want)
+		}
+	}
+}
+
+func TestLookupDensity(t *testing.T) {
+	tests := []struct {
+		name string
+		want float64
+		ok   bool
+	}{
+		{"Flour", 0.51, true},
+		{"bread flour", 0.54, true},
+		{"unsalted butter, softened", 0.96, true},
+		{"creamy peanut butter", 1.14, true},
+		{"buttermilk", 1.03, true},
+		{"rice vinegar", 1.01, true},
+		{"butternut squash", 0, false},
+	}
+	for _, tt := range tests {
+		d, ok := LookupDensity(tt.name)
+		if ok != tt.ok || d.GramsPerMl != tt.want {
+			t.Errorf("LookupDensity(%q) = %v, %v", tt.name, d.GramsPerMl, ok)
+		}
+	}
+}
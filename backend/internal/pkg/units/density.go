@@ -0,0 +1,78 @@
+package units
+
+import "strings"
+
+// Density relates the volume of an ingredient to its mass.
+type Density struct {
+	GramsPerMl float64
+	// Liquid ingredients are measured by volume even in metric recipes.
+	Liquid bool
+}
+
+// densities are typical densities of common ingredients as they are
+// measured in the kitchen, keyed by name. Most are derived from the weight
+// of a US cup: a cup of all-purpose flour weighs about 120 g and a cup of
+// granulated sugar about 200 g.
+var densities = map[string]Density{
+	"flour":               {0.51, false},
+	"all-purpose flour":   {0.51, false},
+	"bread flour":         {0.54, false},
+	"cake flour":          {0.48, false},
+	"whole wheat flour":   {0.48, false},
+	"almond flour":        {0.41, false},
+	"cornstarch":          {0.47, false},
+	"cornmeal":            {0.58, false},
+	"sugar":               {0.85, false},
+	"brown sugar":         {0.9, false},
+	"powdered sugar":      {0.48, false},
+	"icing sugar":         {0.48, false},
+	"confectioners sugar": {0.48, false},
+	"cocoa powder":        {0.36, false},
+	"baking powder":       {0.81, false},
+	"baking soda":         {1.22, false},
+	"salt":                {1.22, false},
+	"kosher salt":         {0.57, false},
+	"butter":              {0.96, false},
+	"peanut butter":       {1.14, false},
+	"yogurt":              {0.96, false},
+	"sour cream":          {0.96, false},
+	"cream cheese":        {0.96, false},
+	"rolled oats":         {0.38, false},
+	"oats":                {0.38, false},
+	"rice":                {0.84, false},
+	"chocolate chips":     {0.72, false},
+	"raisins":             {0.63, false},
+	"walnuts":             {0.48, false},
+	"parmesan":            {0.42, false},
+	"water":               {1, true},
+	"milk":                {1.03, true},
+	"buttermilk":          {1.03, true},
+	"cream":               {0.98, true},
+	"heavy cream":         {0.98, true},
+	"oil":                 {0.92, true},
+	"vinegar":             {1.01, true},
+	"honey":               {1.42, true},
+	"maple syrup":         {1.32, true},
+	"stock":               {1, true},
+	"broth":               {1, true},
+}
+
+// LookupDensity finds the density of an ingredient from its name. The
+// longest known name appearing as whole words wins, so "light brown sugar"
+// is brown sugar and "peanut butter" is not butter.
+func LookupDensity(ingredient string) (Density, bool) {
+	text := " " + strings.Join(strings.FieldsFunc(strings.ToLower(ingredient), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '(' || r == ')'
+	}), " ") + " "
+	var best string
+	for name := range densities {
+		longer := len(name) > len(best) || len(name) == len(best) && name < best
+		if longer && strings.Contains(text, " "+name+" ") {
+			best = name
+		}
+	}
+	if best == "" {
+		return Density{}, false
+	}
+	return densities[best], true
+}
@@ -0,0 +1,71 @@
+package units
+
+import (
+	"math"
+	"strconv"
+)
+
+// fractions are the fractional parts cooks measure with, in eighths and
+// thirds, and the glyphs used to write them.
+var fractions = []struct {
+	value float64
+	glyph string
+}{
+	{0, ""},
+	{1.0 / 8, "⅛"},
+	{1.0 / 4, "¼"},
+	{1.0 / 3, "⅓"},
+	{3.0 / 8, "⅜"},
+	{1.0 / 2, "½"},
+	{5.0 / 8, "⅝"},
+	{2.0 / 3, "⅔"},
+	{3.0 / 4, "¾"},
+	{7.0 / 8, "⅞"},
+	{1, ""},
+}
+
+// RoundFraction rounds x to the nearest cooking fraction. Amounts of 10 or
+// more are rounded to halves. A positive amount never rounds to zero.
+func RoundFraction(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 10 {
+		return math.Round(x*2) / 2
+	}
+	whole, frac := math.Modf(x)
+	best := fractions[0].value
+	for _, f := range fractions[1:] {
+		if math.Abs(frac-f.value) < math.Abs(frac-best) {
+			best = f.value
+		}
+	}
+	if whole == 0 && best == 0 {
+		return fractions[1].value
+	}
+	return whole + best
+}
+
+// FormatAmount writes an amount the way it appears in a recipe, using
+// fraction glyphs where possible: 1.5 becomes "1 ½" and 0.25 becomes "¼".
+// Other amounts are written as decimals with at most two places.
+func FormatAmount(x float64) string {
+	whole, frac := math.Modf(x)
+	for _, f := range fractions {
+		if math.Abs(frac-f.value) > 1e-6 {
+			continue
+		}
+		if f.value == 1 {
+			whole++
+		}
+		switch {
+		case f.glyph == "":
+			return strconv.FormatFloat(whole, 'f', -1, 64)
+		case whole == 0:
+			return f.glyph
+		default:
+			return strconv.FormatFloat(whole, 'f', -1, 64) + " " + f.glyph
+		}
+	}
+	return strconv.FormatFloat(math.Round(x*100)/100, 'f', -1, 64)
+}
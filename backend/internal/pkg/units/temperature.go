@@ -0,0 +1,46 @@
+package units
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// temperaturePattern matches temperatures written in text, such as "350°F",
+// "180 °C" or "200 degrees C", optionally followed by the same temperature in
+// the other scale in parentheses.
+var temperaturePattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*(?:°\s*|degrees?\s+)([CF])\b(?:\s*\((\d+(?:\.\d+)?)\s*(?:°\s*|degrees?\s+)([CF])\))?`)
+
+// ConvertTemperatures rewrites the temperatures in text into the system's
+// scale, keeping the original in parentheses: "Bake at 350°F" becomes "Bake
+// at 175°C (350°F)". Temperatures already given in both scales are reordered
+// so that the system's comes first.
+func ConvertTemperatures(text string, system System) string {
+	to := system.Temperature()
+	return temperaturePattern.ReplaceAllStringFunc(text, func(match string) string {
+		m := temperaturePattern.FindStringSubmatch(match)
+		from := temperatureUnit(m[2])
+		if m[3] != "" {
+			if temperatureUnit(m[4]) == to && from != to {
+				return m[3] + to.Symbol + " (" + m[1] + from.Symbol + ")"
+			}
+			return match
+		}
+		if from == to {
+			return match
+		}
+		v, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return match
+		}
+		converted, _ := Convert(v, from, to)
+		converted = RoundTemperature(converted, to)
+		return strconv.FormatFloat(converted, 'f', -1, 64) + to.Symbol + " (" + m[1] + from.Symbol + ")"
+	})
+}
+
+func temperatureUnit(scale string) Unit {
+	if scale == "F" {
+		return Fahrenheit
+	}
+	return Celsius
+}
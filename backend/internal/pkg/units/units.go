@@ -0,0 +1,167 @@
+// Package units describes the units of measure used in recipes, converts
+// amounts between unit systems and rounds them the way cooks write them.
+package units
+
+import (
+	"math"
+	"strings"
+)
+
+// Dimension is the physical quantity a unit measures.
+type Dimension int
+
+const (
+	Volume Dimension = iota + 1
+	Mass
+	Temperature
+)
+
+// Unit is a unit of measure. Base is the size of one unit in millilitres for
+// volumes and in grams for masses; temperatures have no base.
+type Unit struct {
+	Symbol    string
+	Dimension Dimension
+	Base      float64
+	// ladder names the family of units an amount may be promoted or demoted
+	// within, such as teaspoons, tablespoons and cups.
+	ladder string
+	// metric amounts are rounded to decimals rather than cooking fractions.
+	metric bool
+}
+
+// Units known to the package.
+var (
+	Teaspoon   = Unit{Symbol: "tsp", Dimension: Volume, Base: 4.92892, ladder: "us_volume"}
+	Tablespoon = Unit{Symbol: "tbsp", Dimension: Volume, Base: 14.7868, ladder: "us_volume"}
+	Cup        = Unit{Symbol: "cup", Dimension: Volume, Base: 236.588, ladder: "us_volume"}
+	FluidOunce = Unit{Symbol: "fl oz", Dimension: Volume, Base: 29.5735}
+	Pint       = Unit{Symbol: "pint", Dimension: Volume, Base: 473.176}
+	Quart      = Unit{Symbol: "quart", Dimension: Volume, Base: 946.353}
+	Gallon     = Unit{Symbol: "gallon", Dimension: Volume, Base: 3785.41}
+	Milliliter = Unit{Symbol: "ml", Dimension: Volume, Base: 1, ladder: "metric_volume", metric: true}
+	Liter      = Unit{Symbol: "l", Dimension: Volume, Base: 1000, ladder: "metric_volume", metric: true}
+	Gram       = Unit{Symbol: "g", Dimension: Mass, Base: 1, ladder: "metric_mass", metric: true}
+	Kilogram   = Unit{Symbol: "kg", Dimension: Mass, Base: 1000, ladder: "metric_mass", metric: true}
+	Ounce      = Unit{Symbol: "oz", Dimension: Mass, Base: 28.3495, ladder: "us_mass"}
+	Pound      = Unit{Symbol: "lb", Dimension: Mass, Base: 453.592, ladder: "us_mass"}
+	Celsius    = Unit{Symbol: "°C", Dimension: Temperature}
+	Fahrenheit = Unit{Symbol: "°F", Dimension: Temperature}
+)
+
+// rung is a step of a ladder: the unit and the smallest amount written in it
+// before the next smaller unit is preferred.
+type rung struct {
+	unit Unit
+	min  float64
+}
+
+// ladders lists each family of interchangeable units from largest to
+// smallest.
+var ladders = map[string][]rung{
+	"us_volume":     {{Cup, 0.25}, {Tablespoon, 1}, {Teaspoon, 0}},
+	"metric_volume": {{Liter, 1}, {Milliliter, 0}},
+	"metric_mass":   {{Kilogram, 1}, {Gram, 0}},
+	"us_mass":       {{Pound, 1}, {Ounce, 0}},
+	// uk_volume is metric, except that small amounts are measured with
+	// spoons.
+	"uk_volume": {{Liter, 1}, {Milliliter, 50}, {Tablespoon, 1}, {Teaspoon, 0}},
+}
+
+var aliases = map[string]Unit{}
+
+func init() {
+	for u, names := range map[Unit][]string{
+		Teaspoon:   {"t", "tsp", "tsps", "teaspoon", "teaspoons"},
+		Tablespoon: {"tbsp", "tbsps", "tbs", "tbl", "tablespoon", "tablespoons"},
+		Cup:        {"c", "cup", "cups"},
+		FluidOunce: {"fl oz", "fl. oz", "floz", "fluid ounce", "fluid ounces"},
+		Pint:       {"pt", "pint", "pints"},
+		Quart:      {"qt", "quart", "quarts"},
+		Gallon:     {"gal", "gallon", "gallons"},
+		Milliliter: {"ml", "milliliter", "milliliters", "millilitre", "millilitres"},
+		Liter:      {"l", "liter", "liters", "litre", "litres"},
+		Gram:       {"g", "gr", "gram", "grams"},
+		Kilogram:   {"kg", "kgs", "kilo", "kilos", "kilogram", "kilograms"},
+		Ounce:      {"oz", "ounce", "ounces"},
+		Pound:      {"lb", "lbs", "pound", "pounds"},
+	} {
+		for _, name := range names {
+			aliases[name] = u
+		}
+	}
+}
+
+// unmeasured are units that describe an amount too small or vague to scale.
+var unmeasured = map[string]bool{
+	"pinch": true, "pinches": true, "dash": true, "dashes": true, "smidgen": true,
+	"sprinkle": true, "to taste": true, "as needed": true, "as required": true,
+}
+
+// Lookup finds a unit by name or abbreviation. A capital "T" is read as a
+// tablespoon and any other spelling is matched without regard to case.
+func Lookup(name string) (Unit, bool) {
+	name = strings.TrimSuffix(strings.TrimSpace(name), ".")
+	if name == "T" {
+		return Tablespoon, true
+	}
+	u, ok := aliases[strings.ToLower(name)]
+	return u, ok
+}
+
+// Unmeasured reports whether the unit, such as "pinch" or "to taste", marks
+// an amount that should not be scaled.
+func Unmeasured(name string) bool {
+	return unmeasured[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// Label returns the unit symbol for the given amount, pluralizing the units
+// that are written out in full.
+func (u Unit) Label(amount float64) string {
+	switch u {
+	case Cup, Pint, Quart, Gallon:
+		if amount > 1 {
+			return u.Symbol + "s"
+		}
+	}
+	return u.Symbol
+}
+
+// Best expresses the amount in the most readable unit of u's family, so that
+// 6 teaspoons become 2 tablespoons and 0.5 litres become 500 millilitres.
+// Units outside a family are returned unchanged.
+func Best(amount float64, u Unit) (float64, Unit) {
+	if len(ladders[u.ladder]) == 0 {
+		return amount, u
+	}
+	return climb(amount*u.Base, u.ladder)
+}
+
+// climb expresses an amount in base units in the most readable unit of the
+// ladder.
+func climb(base float64, ladder string) (float64, Unit) {
+	rungs := ladders[ladder]
+	for _, r := range rungs {
+		// Tolerate rounding error so that 3 teaspoons make a tablespoon.
+		if v := base / r.unit.Base; v >= r.min*(1-1e-3) {
+			return v, r.unit
+		}
+	}
+	last := rungs[len(rungs)-1].unit
+	return base / last.Base, last
+}
+
+// Round rounds an amount in unit u: metric amounts to two significant digits,
+// or to the nearest 5 from 100 up, and other amounts to cooking fractions.
+func Round(amount float64, u Unit) float64 {
+	if !u.metric {
+		return RoundFraction(amount)
+	}
+	if amount >= 100 {
+		return math.Round(amount/5) * 5
+	}
+	if amount <= 0 {
+		return 0
+	}
+	scale := math.Pow(10, 1-math.Floor(math.Log10(amount)))
+	return math.Round(amount*scale) / scale
+}
@@ -0,0 +1,118 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name string
+		want Unit
+		ok   bool
+	}{
+		{"tsp", Teaspoon, true},
+		{"t", Teaspoon, true},
+		{"T", Tablespoon, true},
+		{"Tbsp.", Tablespoon, true},
+		{" Cups ", Cup, true},
+		{"fl oz", FluidOunce, true},
+		{"Litres", Liter, true},
+		{"lbs", Pound, true},
+		{"clove", Unit{}, false},
+		{"", Unit{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Lookup(tt.name)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Lookup(%q) = %v, %v; want %v, %v", tt.name, got.Symbol, ok, tt.want.Symbol, tt.ok)
+		}
+	}
+}
+
+func TestBest(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     float64
+		unit       Unit
+		wantAmount float64
+		wantUnit   Unit
+	}{
+		{"teaspoons promote to tablespoons", 6, Teaspoon, 2, Tablespoon},
+		{"tablespoons promote to cups", 8, Tablespoon, 0.5, Cup},
+		{"three tablespoons stay", 3, Tablespoon, 3, Tablespoon},
+		{"three teaspoons make a tablespoon", 3, Teaspoon, 1, Tablespoon},
+		{"small cups demote to tablespoons", 0.125, Cup, 2, Tablespoon},
+		{"tiny cups demote to teaspoons", 1.0 / 48, Cup, 1, Teaspoon},
+		{"millilitres promote to litres", 1500, Milliliter, 1.5, Liter},
+		{"litres demote to millilitres", 0.25, Liter, 250, Milliliter},
+		{"grams promote to kilograms", 2000, Gram, 2, Kilogram},
+		{"ounces promote to pounds", 24, Ounce, 1.5, Pound},
+		{"pints are kept", 0.1, Pint, 0.1, Pint},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, unit := Best(tt.amount, tt.unit)
+			if unit != tt.wantUnit || math.Abs(amount-tt.wantAmount) > 0.01 {
+				t.Fatalf("Best(%v %s) = %v %s, want %v %s", tt.amount, tt.unit.Symbol, amount, unit.Symbol, tt.wantAmount, tt.wantUnit.Symbol)
+			}
+		})
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		amount float64
+		unit   Unit
+		want   float64
+	}{
+		{0.3, Cup, 1.0 / 3},
+		{0.7, Cup, 2.0 / 3},
+		{1.3, Cup, 1 + 1.0/3},
+		{0.02, Teaspoon, 1.0 / 8},
+		{0.96, Tablespoon, 1},
+		{12.3, Cup, 12.5},
+		{2.46, Gram, 2.5},
+		{37.5, Gram, 38},
+		{472, Milliliter, 470},
+		{1.234, Kilogram, 1.2},
+	}
+	for _, tt := range tests {
+		if got := Round(tt.amount, tt.unit); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Round(%v %s) = %v, want %v", tt.amount, tt.unit.Symbol, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   string
+	}{
+		{2, "2"},
+		{0.5, "½"},
+		{1.0 / 3, "⅓"},
+		{1 + 2.0/3, "1 ⅔"},
+		{2.75, "2 ¾"},
+		{0.9999999, "1"},
+		{1.2, "1.2"},
+		{1.234, "1.23"},
+	}
+	for _, tt := range tests {
+		if got := FormatAmount(tt.amount); got != tt.want {
+			t.Errorf("FormatAmount(%v) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	if got := Cup.Label(2); got != "cups" {
+		t.Errorf("Cup.Label(2) = %q", got)
+	}
+	if got := Cup.Label(0.5); got != "cup" {
+		t.Errorf("Cup.Label(0.5) = %q", got)
+	}
+	if got := Tablespoon.Label(3); got != "tbsp" {
+		t.Errorf("Tablespoon.Label(3) = %q", got)
+	}
+}
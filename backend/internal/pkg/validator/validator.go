@@ -0,0 +1,76 @@
+// Package validator cleans and checks user supplied input.
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+var (
+	// embeddedCode matches script and style elements including their content.
+	embeddedCode = regexp.MustCompile(`(?is)<(script|style)\b.*?</(script|style)\s*>`)
+	// markup matches HTML tags and comments.
+	markup     = regexp.MustCompile(`(?s)<!--.*?-->|</?[a-zA-Z][^<>]*>`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// SanitizeText turns user supplied plain text into a safe canonical form:
+// HTML markup and control characters are removed, line breaks normalized,
+// runs of blank lines collapsed and surrounding whitespace trimmed. The result
+// is still plain text and must be escaped when rendered as HTML.
+func SanitizeText(s string) string {
+	s = strings.ToValidUTF8(s, "")
+	s = embeddedCode.ReplaceAllString(s, "")
+	s = markup.ReplaceAllString(s, "")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return r
+		case unicode.IsControl(r), isBidiControl(r):
+			return -1
+		}
+		return r
+	}, s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	s = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(s)
+}
+
+// Text sanitizes s and checks that the result is between min and max
+// characters long. Violations are reported as invalid input naming field.
+func Text(field, s string, min, max int) (string, error) {
+	s = SanitizeText(s)
+	n := utf8.RuneCountInString(s)
+	switch {
+	case n < min && min == 1:
+		return "", InvalidField(field, field+" is required")
+	case n < min:
+		return "", InvalidField(field, fmt.Sprintf("%s must be at least %d characters", field, min))
+	case max > 0 && n > max:
+		return "", InvalidField(field, fmt.Sprintf("%s must be at most %d characters", field, max))
+	}
+	return s, nil
+}
+
+// isBidiControl reports whether r is an explicit bidirectional formatting
+// character, which can be used to disguise text.
+func isBidiControl(r rune) bool {
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
+
+// InvalidField reports invalid input in the named field, with reason as the
+// message.
+func InvalidField(field, reason string) error {
+	return apperrors.NewWithDetails(apperrors.ErrInvalidInput.Code, reason, apperrors.ErrInvalidInput.Status, map[string]any{"field": field})
+}
@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"testing"
+
+	apperrors "alchemorsel/backend/internal/pkg/errors"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Lovely recipe!", "Lovely recipe!"},
+		{"trims", "  hi \n", "hi"},
+		{"tags", "<b>so</b> <a href=\"x\">good</a>", "so good"},
+		{"script", "nice<script>alert(1)</script> dish", "nice dish"},
+		{"comment", "a<!-- hidden -->b", "ab"},
+		{"comparison kept", "use < 2 cups & > 1 cup", "use < 2 cups & > 1 cup"},
+		{"line endings", "a\r\nb\rc", "a\nb\nc"},
+		{"blank lines", "a\n\n\n\n\nb", "a\n\nb"},
+		{"trailing spaces", "a   \nb", "a\nb"},
+		{"control chars", "a\x00b\x1bc\td", "abc\td"},
+		{"bidi override", "abc\u202edef", "abcdef"},
+		{"emoji kept", "\U0001F469\u200d\U0001F373 yum", "\U0001F469\u200d\U0001F373 yum"},
+		{"invalid utf8", "a\xffb", "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeText(tt.in); got != tt.want {
+				t.Errorf("SanitizeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		min     int
+		max     int
+		want    string
+		wantErr string
+	}{
+		{"valid", " hello ", 1, 10, "hello", ""},
+		{"required", "<p> </p>", 1, 10, "", "body is required"},
+		{"too short", "ab", 3, 10, "", "body must be at least 3 characters"},
+		{"too long", "abcdef", 1, 5, "", "body must be at most 5 characters"},
+		{"counts runes", "ééééé", 1, 5, "ééééé", ""},
+		{"no limit", "anything", 0, 0, "anything", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Text("body", tt.in, tt.min, tt.max)
+			if tt.wantErr == "" {
+				if err != nil || got != tt.want {
+					t.Fatalf("Text() = %q, %v; want %q", got, err, tt.want)
+				}
+				return
+			}
+			appErr, ok := err.(*apperrors.AppError)
+			if !ok || appErr.Message != tt.wantErr || appErr.Details["field"] != "body" {
+				t.Fatalf("Text() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
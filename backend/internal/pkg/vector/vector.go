@@ -0,0 +1,21 @@
+package vector
+
+import "math"
+
+// Cosine returns the cosine similarity of a and b in the range [-1, 1]. It
+// returns 0 when the vectors differ in length or either has zero magnitude.
+func Cosine(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}